@@ -15,9 +15,14 @@ type AchievementMongo struct {
     Details         AchievementDetails   `bson:"details" json:"details"`
     Tags            []string             `bson:"tags" json:"tags"`
     Points          int                  `bson:"points,omitempty" json:"points,omitempty"`
+    Attachments     []Attachment         `bson:"attachments,omitempty" json:"attachments,omitempty"`
+    TeamMembers     []TeamMember         `bson:"teamMembers,omitempty" json:"teamMembers,omitempty"`
+    PointSplit      string               `bson:"pointSplit,omitempty" json:"pointSplit,omitempty"` // equal, full, lead_bonus
     CreatedAt       time.Time            `bson:"createdAt" json:"createdAt"`
     UpdatedAt       time.Time            `bson:"updatedAt" json:"updatedAt"`
     DeletedAt       *time.Time           `bson:"deletedAt,omitempty" json:"deletedAt"`
+    // AttachmentsPurgedAt: isi lampiran sudah dihapus dari storage (purge)
+    AttachmentsPurgedAt *time.Time       `bson:"attachmentsPurgedAt,omitempty" json:"attachmentsPurgedAt,omitempty"`
 }
 type AchievementDetails struct {
     CompetitionName  string     `bson:"competitionName,omitempty" json:"competitionName,omitempty"`
@@ -47,6 +52,109 @@ type AchievementDetails struct {
     CustomFields map[string]any  `bson:"customFields,omitempty" json:"customFields,omitempty"`
 }
 
+// Attachment: file pendukung prestasi. Kind menandai jenis dokumen
+// (certificate, photo, assignment_letter, other) untuk syarat pengajuan.
+// StorageKey menunjuk objek di backend storage; FileURL hanya terisi pada
+// lampiran lama yang masih berupa path di ./uploads.
+type Attachment struct {
+    ID         string    `bson:"id,omitempty" json:"id,omitempty"`
+    FileName   string    `bson:"fileName" json:"fileName"`
+    FileURL    string    `bson:"fileUrl,omitempty" json:"fileUrl,omitempty"`
+    StorageKey string    `bson:"storageKey,omitempty" json:"storageKey,omitempty"`
+    FileType   string    `bson:"fileType" json:"fileType"`
+    Size       int64     `bson:"size,omitempty" json:"size,omitempty"`
+    Kind       string    `bson:"kind,omitempty" json:"kind,omitempty"`
+    UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"`
+
+    // Version naik setiap kali file diganti; isi lama tersimpan di Versions
+    // untuk audit reviewer. Lampiran yang dihapus tetap disimpan (DeletedAt).
+    Version   int                 `bson:"version,omitempty" json:"version,omitempty"`
+    Versions  []AttachmentVersion `bson:"versions,omitempty" json:"versions,omitempty"`
+    DeletedAt *time.Time          `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"`
+    DeletedBy string              `bson:"deletedBy,omitempty" json:"deletedBy,omitempty"`
+
+    // ScanStatus kosong = belum pernah dipindai (lampiran lama atau scanner
+    // dimatikan). Lihat AttachmentPendingScan dst.
+    ScanStatus    string     `bson:"scanStatus,omitempty" json:"scanStatus,omitempty"`
+    ScanSignature string     `bson:"scanSignature,omitempty" json:"scanSignature,omitempty"`
+    ScannedAt     *time.Time `bson:"scannedAt,omitempty" json:"scannedAt,omitempty"`
+
+    // SHA256 (hex) isi file saat diunggah; dicek ulang berkala oleh job
+    // integritas (IntegrityStatus).
+    SHA256             string     `bson:"sha256,omitempty" json:"sha256,omitempty"`
+    IntegrityStatus    string     `bson:"integrityStatus,omitempty" json:"integrityStatus,omitempty"`
+    IntegrityCheckedAt *time.Time `bson:"integrityCheckedAt,omitempty" json:"integrityCheckedAt,omitempty"`
+}
+
+// Hasil pemeriksaan integritas lampiran.
+const (
+    IntegrityOK       = "ok"
+    IntegrityMismatch = "mismatch"
+    IntegrityMissing  = "missing"
+)
+
+// Status pemindaian malware lampiran. File infected dipindahkan ke area
+// karantina storage dan tidak bisa diunduh.
+const (
+    AttachmentPendingScan = "pending_scan"
+    AttachmentClean       = "clean"
+    AttachmentInfected    = "infected"
+)
+
+// Downloadable: belum selesai dipindai atau terinfeksi → tidak boleh dibuka.
+func (a Attachment) Downloadable() bool {
+    return a.ScanStatus != AttachmentPendingScan && a.ScanStatus != AttachmentInfected
+}
+
+// AttachmentVersion: isi lampiran sebelum diganti.
+type AttachmentVersion struct {
+    Version    int       `bson:"version" json:"version"`
+    FileName   string    `bson:"fileName" json:"fileName"`
+    FileURL    string    `bson:"fileUrl,omitempty" json:"fileUrl,omitempty"`
+    StorageKey string    `bson:"storageKey,omitempty" json:"storageKey,omitempty"`
+    FileType   string    `bson:"fileType" json:"fileType"`
+    Size       int64     `bson:"size,omitempty" json:"size,omitempty"`
+    UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"`
+    ReplacedAt time.Time `bson:"replacedAt" json:"replacedAt"`
+    ReplacedBy string    `bson:"replacedBy" json:"replacedBy"`
+    ScanStatus string    `bson:"scanStatus,omitempty" json:"scanStatus,omitempty"`
+    SHA256     string    `bson:"sha256,omitempty" json:"sha256,omitempty"`
+}
+
+// CurrentVersion: lampiran lama tanpa Version dianggap versi 1.
+func (a Attachment) CurrentVersion() int {
+    if a.Version < 1 {
+        return 1
+    }
+    return a.Version
+}
+
+// ActiveAttachments: lampiran yang belum dihapus.
+func (a *AchievementMongo) ActiveAttachments() []Attachment {
+    var active []Attachment
+    for _, att := range a.Attachments {
+        if att.DeletedAt == nil {
+            active = append(active, att)
+        }
+    }
+    return active
+}
+
+// Ref: pengenal lampiran untuk dirujuk komentar/banding.
+func (a Attachment) Ref() string {
+    if a.StorageKey != "" {
+        return a.StorageKey
+    }
+    return a.FileURL
+}
+
+// TeamMember: anggota tim prestasi beserta perannya (mis. leader, member).
+// Status konfirmasi disimpan di Postgres (achievement_team_members).
+type TeamMember struct {
+    StudentID string `bson:"studentId" json:"studentId"`
+    Role      string `bson:"role" json:"role"`
+}
+
 type Period struct {
     Start time.Time `bson:"start,omitempty" json:"start,omitempty"`
     End   time.Time `bson:"end,omitempty" json:"end,omitempty"`
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Status banding.
+const (
+	AppealPending    = "pending"
+	AppealOverturned = "overturned" // keputusan: prestasi menjadi verified
+	AppealUpheld     = "upheld"     // keputusan: penolakan dipertahankan
+)
+
+// AchievementAppeal: banding mahasiswa atas prestasi yang ditolak. Satu
+// prestasi hanya boleh dibanding sekali. RejectedBy disalin dari
+// verified_by saat banding diajukan supaya penolak tidak ikut memutus.
+type AchievementAppeal struct {
+	ID            string     `json:"id"`
+	ReferenceID   string     `json:"reference_id"`
+	StudentID     string     `json:"student_id"`
+	SubmittedBy   string     `json:"submitted_by"` // users.id
+	Justification string     `json:"justification"`
+	Evidence      []string   `json:"evidence"` // Ref() lampiran baru
+	RejectedBy    *string    `json:"rejected_by,omitempty"`
+	RejectionNote *string    `json:"rejection_note,omitempty"`
+	Status        string     `json:"status"`
+	DecidedBy     *string    `json:"decided_by,omitempty"`
+	DecisionNote  *string    `json:"decision_note,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DecidedAt     *time.Time `json:"decided_at,omitempty"`
+}
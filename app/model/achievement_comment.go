@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// AchievementComment: pesan diskusi pada satu prestasi. ParentID terisi
+// untuk balasan. Internal hanya terlihat oleh reviewer (dosen wali & admin).
+type AchievementComment struct {
+	ID          string     `json:"id"`
+	ReferenceID string     `json:"reference_id"`
+	ParentID    *string    `json:"parent_id,omitempty"`
+	AuthorID    string     `json:"author_id"` // users.id
+	AuthorRole  string     `json:"author_role"`
+	Body        string     `json:"body"`
+	Internal    bool       `json:"internal"`
+	Attachments []string   `json:"attachments,omitempty"` // Ref() lampiran prestasi
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// CommentUnreadScope: prestasi yang komentarnya dihitung untuk satu user
+// (aturan akses sama dengan detail prestasi).
+type CommentUnreadScope struct {
+	IncludeInternal bool
+	// StudentIDs nil → semua prestasi (admin); selain itu prestasi milik
+	// salah satu mahasiswa ini atau yang beranggotakan mereka dengan status
+	// MemberStatuses
+	StudentIDs     []string
+	MemberStatuses []string
+}
+
+func (c *AchievementComment) Deleted() bool {
+	return c.DeletedAt != nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// Jenis fingerprint untuk deteksi duplikat.
+const (
+	FingerprintCertificationNumber = "certification_number"
+	FingerprintCompetition         = "competition" // nama + tanggal + peringkat
+	FingerprintPublication         = "publication" // ISSN + judul
+	FingerprintAttachment          = "attachment"  // sha256 isi file
+)
+
+// AchievementFingerprint adalah nilai ternormalisasi dari satu prestasi
+// yang dibandingkan dengan prestasi lain.
+type AchievementFingerprint struct {
+	ReferenceID string    `json:"reference_id"`
+	StudentID   string    `json:"student_id"`
+	Kind        string    `json:"kind"`
+	Value       string    `json:"value"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DuplicateMatch: ReferenceID memiliki fingerprint yang sama dengan
+// MatchReferenceID milik MatchStudentID.
+type DuplicateMatch struct {
+	ReferenceID      string `json:"reference_id"`
+	MatchReferenceID string `json:"match_reference_id"`
+	MatchStudentID   string `json:"match_student_id"`
+	MatchStatus      string `json:"match_status"`
+	Kind             string `json:"kind"`
+}
@@ -6,7 +6,7 @@ type AchievementReference struct {
     ID            string     `db:"id"`
     StudentID     string     `db:"student_id"`
     MongoID       string     `db:"mongo_achievement_id"`
-    Status        string     `db:"status"` // draft, submitted, verified, rejected, deleted    
+    Status        string     `db:"status"` // draft, submitted, verified, rejected, appealed, deleted    
     SubmittedAt   *time.Time `db:"submitted_at"`
     VerifiedAt    *time.Time `db:"verified_at"`
     VerifiedBy    *string    `db:"verified_by"`
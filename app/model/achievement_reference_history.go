@@ -8,7 +8,18 @@ type AchievementReferenceHistory struct {
     OldStatus     string     `json:"oldStatus"`      // status sebelumnya
     NewStatus     string     `json:"newStatus"`      // status setelah perubahan
     Note          string     `json:"note,omitempty"` // untuk reject
-    ChangedBy     string     `json:"changedBy"`      // user_id dari admin / dosen wali / mahasiswa
+    ChangedBy     string     `json:"changedBy"`      // user_id dari admin / dosen wali / mahasiswa; kosong untuk aksi sistem
     ChangedByRole string     `json:"changedByRole"`  // role yang melakukan perubahan
+    OnBehalfOf    *string    `json:"onBehalfOf,omitempty"` // lecturer id dosen wali asli bila lewat delegasi
     CreatedAt     time.Time  `json:"createdAt"`      // timestamp perubahan
+    Evidence      []EvidenceHash `json:"evidence,omitempty"` // hash lampiran saat verifikasi
+}
+
+// EvidenceHash: sidik lampiran yang ikut diverifikasi, untuk membuktikan
+// file tidak diubah setelahnya.
+type EvidenceHash struct {
+    AttachmentID string `json:"attachmentId"`
+    FileName     string `json:"fileName"`
+    Version      int    `json:"version"`
+    SHA256       string `json:"sha256"`
 }
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// AchievementTeamMember adalah keanggotaan satu mahasiswa pada prestasi tim.
+// Pemilik prestasi ikut tercatat dengan status confirmed.
+type AchievementTeamMember struct {
+	ReferenceID string     `json:"reference_id"`
+	StudentID   string     `json:"student_id"`
+	TeamRole    string     `json:"team_role"`
+	Status      string     `json:"status"` // pending, confirmed, declined
+	RespondedAt *time.Time `json:"responded_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
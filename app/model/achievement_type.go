@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// AchievementType adalah kategori prestasi tambahan yang didefinisikan admin
+// (di luar competition/publication/organization/certification bawaan).
+type AchievementType struct {
+	ID            string    `json:"id"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	DefaultPoints int       `json:"default_points"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// AchievementCustomField mendefinisikan satu key di details.customFields
+// untuk tipe tertentu (bawaan maupun custom).
+type AchievementCustomField struct {
+	ID            string    `json:"id"`
+	TypeCode      string    `json:"type_code"`
+	Key           string    `json:"key"`
+	Label         string    `json:"label"`
+	DataType      string    `json:"data_type"` // string, number, boolean, date, enum
+	Required      bool      `json:"required"`
+	AllowedValues []string  `json:"allowed_values"`
+	DisplayOrder  int       `json:"display_order"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// AdvisorDelegation: dosen wali (From) menyerahkan tugas perwalian kepada
+// dosen lain (To) selama StartsAt–EndsAt. Id dosen adalah lecturers.id.
+type AdvisorDelegation struct {
+	ID             string     `json:"id"`
+	FromLecturerID string     `json:"from_lecturer_id"`
+	ToLecturerID   string     `json:"to_lecturer_id"`
+	StartsAt       time.Time  `json:"starts_at"`
+	EndsAt         time.Time  `json:"ends_at"`
+	Reason         string     `json:"reason"`
+	CreatedBy      string     `json:"created_by"` // users.id
+	CreatedAt      time.Time  `json:"created_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy      *string    `json:"revoked_by,omitempty"`
+}
+
+func (d *AdvisorDelegation) Active(now time.Time) bool {
+	return d.RevokedAt == nil && !now.Before(d.StartsAt) && now.Before(d.EndsAt)
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// AttachmentUpload: sesi upload lampiran bertahap. Potongan yang sudah
+// diterima disimpan di storage (ChunkKeys, urut offset); setelah Offset
+// mencapai Length isinya diproses seperti upload biasa lalu sesi dihapus.
+type AttachmentUpload struct {
+	ID          string    `json:"id"`
+	ReferenceID string    `json:"reference_id"`
+	UploadedBy  string    `json:"uploaded_by"` // users.id
+	FileName    string    `json:"file_name"`
+	Kind        string    `json:"kind"`
+	Length      int64     `json:"length"`
+	Offset      int64     `json:"offset"`
+	ChunkKeys   []string  `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (u *AttachmentUpload) Complete() bool {
+	return u.Offset >= u.Length
+}
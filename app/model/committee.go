@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// Aturan mayoritas komite, dihitung dari suara yang masuk.
+const (
+	MajoritySimple    = "simple"     // lebih dari separuh
+	MajorityTwoThirds = "two_thirds" // minimal dua pertiga
+	MajorityUnanimous = "unanimous"  // semua suara
+)
+
+var MajorityRules = []string{MajoritySimple, MajorityTwoThirds, MajorityUnanimous}
+
+// Committee: prestasi submitted yang cocok dengan AppliesTo (kondisi yang sama
+// dengan aturan poin) diputus lewat voting anggota, bukan oleh satu reviewer.
+type Committee struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	AppliesTo PointCondition `json:"applies_to"`
+	Members   []string       `json:"members"` // users.id
+	Quorum    int            `json:"quorum"`
+	Majority  string         `json:"majority"`
+	Active    bool           `json:"active"`
+	CreatedBy string         `json:"created_by"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (c *Committee) HasMember(userID string) bool {
+	for _, m := range c.Members {
+		if m == userID {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	VoteApprove = "approve"
+	VoteReject  = "reject"
+)
+
+// CommitteeVote: satu suara anggota untuk satu prestasi. Suara boleh diubah
+// selama prestasi belum diputus; catatan suara tetap disimpan setelahnya.
+type CommitteeVote struct {
+	ReferenceID string    `json:"reference_id"`
+	CommitteeID string    `json:"committee_id"`
+	VoterID     string    `json:"voter_id"`
+	VoterRole   string    `json:"voter_role"`
+	Vote        string    `json:"vote"`
+	Comment     string    `json:"comment,omitempty"`
+	VotedAt     time.Time `json:"voted_at"`
+}
+
+// VoteTally: rekap suara anggota komite. Outcome kosong berarti belum diputus.
+type VoteTally struct {
+	Approve  int    `json:"approve"`
+	Reject   int    `json:"reject"`
+	Members  int    `json:"members"`
+	Quorum   int    `json:"quorum"`
+	Majority string `json:"majority"`
+	Outcome  string `json:"outcome,omitempty"` // verified | rejected
+}
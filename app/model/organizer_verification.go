@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Status permintaan verifikasi penyelenggara.
+const (
+	OrganizerPending   = "pending"
+	OrganizerConfirmed = "confirmed" // penyelenggara membenarkan partisipasi
+	OrganizerDenied    = "denied"    // penyelenggara menyangkal partisipasi
+	OrganizerFailed    = "failed"    // email gagal dikirim
+	OrganizerExpired   = "expired"   // pending yang lewat ExpiresAt (tidak disimpan)
+)
+
+// OrganizerVerification: permintaan konfirmasi ke kontak penyelenggara lomba
+// lewat tautan publik bertanda tangan yang hanya bisa dipakai sekali.
+type OrganizerVerification struct {
+	ID             string     `json:"id"`
+	ReferenceID    string     `json:"reference_id"`
+	OrganizerEmail string     `json:"organizer_email"`
+	OrganizerName  string     `json:"organizer_name,omitempty"`
+	ClaimedRank    int        `json:"claimed_rank,omitempty"` // rank di prestasi saat diminta
+	RequestedBy    string     `json:"requested_by"`           // users.id
+	Status         string     `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"`
+	ConfirmedRank  *int       `json:"confirmed_rank,omitempty"`
+	ResponseNote   *string    `json:"response_note,omitempty"`
+	ResponderName  *string    `json:"responder_name,omitempty"`
+	ResponderIP    *string    `json:"responder_ip,omitempty"`
+}
+
+// CurrentStatus: pending yang lewat batas waktu dilaporkan sebagai expired.
+func (v *OrganizerVerification) CurrentStatus(now time.Time) string {
+	if v.Status == OrganizerPending && !now.Before(v.ExpiresAt) {
+		return OrganizerExpired
+	}
+	return v.Status
+}
+
+// OrganizerResponse: jawaban penyelenggara dari halaman publik.
+type OrganizerResponse struct {
+	Status        string
+	ConfirmedRank *int
+	Note          string
+	ResponderName string
+	ResponderIP   string
+	RespondedAt   time.Time
+}
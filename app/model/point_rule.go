@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// PointRuleSet adalah satu versi aturan poin. Hanya satu rule set yang
+// berstatus active; draft bisa di-preview sebelum diaktifkan.
+type PointRuleSet struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Version     int         `json:"version"`
+	Status      string      `json:"status"` // draft, active, archived
+	Rules       []PointRule `json:"rules"`
+	MaxPoints   int         `json:"max_points"` // 0 = tanpa batas
+	CreatedBy   string      `json:"created_by"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ActivatedAt *time.Time  `json:"activated_at"`
+}
+
+// PointRule: rule dengan Points adalah rule dasar (yang pertama cocok dipakai),
+// rule tanpa Points adalah modifier (multiplier/bonus) yang diterapkan berurutan.
+type PointRule struct {
+	Name       string         `json:"name"`
+	When       PointCondition `json:"when"`
+	Points     *int           `json:"points,omitempty"`
+	Multiplier float64        `json:"multiplier,omitempty"`
+	Bonus      int            `json:"bonus,omitempty"`
+	Cap        int            `json:"cap,omitempty"` // 0 = tanpa batas
+}
+
+// PointCondition: semua kondisi yang diisi harus terpenuhi (AND),
+// nilai di dalam satu list bersifat OR.
+type PointCondition struct {
+	Types            []string       `json:"types,omitempty"`
+	Levels           []string       `json:"levels,omitempty"`
+	RankMin          int            `json:"rank_min,omitempty"`
+	RankMax          int            `json:"rank_max,omitempty"`
+	Medals           []string       `json:"medals,omitempty"`
+	PublicationTypes []string       `json:"publication_types,omitempty"`
+	TagsAny          []string       `json:"tags_any,omitempty"`
+	CustomFields     map[string]any `json:"custom_fields,omitempty"`
+	TeamSizeMin      int            `json:"team_size_min,omitempty"`
+	TeamSizeMax      int            `json:"team_size_max,omitempty"`
+}
+
+// PointRecalculationRun mencatat satu kali job hitung ulang setelah aktivasi.
+type PointRecalculationRun struct {
+	ID          string     `json:"id"`
+	RuleSetID   string     `json:"rule_set_id"`
+	Status      string     `json:"status"` // running, completed, failed
+	Total       int        `json:"total"`
+	Changed     int        `json:"changed"`
+	Error       string     `json:"error,omitempty"`
+	TriggeredBy string     `json:"triggered_by"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// PointRecalculationAudit mencatat perubahan poin satu prestasi dalam sebuah run.
+type PointRecalculationAudit struct {
+	RunID       string    `json:"run_id"`
+	ReferenceID string    `json:"reference_id"`
+	OldPoints   int       `json:"old_points"`
+	NewPoints   int       `json:"new_points"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Jenis entri ledger poin.
+const (
+	LedgerEarned       = "earned"       // prestasi diverifikasi
+	LedgerRevoked      = "revoked"      // prestasi terverifikasi dihapus/dicabut
+	LedgerRecalculated = "recalculated" // selisih poin setelah dihitung ulang
+	LedgerAdjustment   = "adjustment"   // koreksi manual admin (boleh negatif)
+	LedgerBonus        = "bonus"        // bonus manual admin tanpa prestasi
+)
+
+// PointsLedgerEntry adalah satu baris ledger poin yang tidak pernah diubah
+// atau dihapus. Saldo mahasiswa = jumlah Points seluruh entrinya.
+type PointsLedgerEntry struct {
+	ID          string    `json:"id"`
+	StudentID   string    `json:"student_id"`
+	ReferenceID *string   `json:"reference_id"` // nil untuk adjustment/bonus tanpa prestasi
+	EntryType   string    `json:"entry_type"`
+	Points      int       `json:"points"` // bertanda: negatif untuk pengurangan
+	Period      string    `json:"period"` // periode akademik, mis. 2024/2025-1
+	Reason      string    `json:"reason"`
+	ActorID     string    `json:"actor_id"`
+	ActorRole   string    `json:"actor_role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PointsBalance adalah saldo poin satu mahasiswa pada satu periode
+// (Period kosong berarti seluruh periode).
+type PointsBalance struct {
+	StudentID string `json:"student_id"`
+	Period    string `json:"period,omitempty"`
+	Points    int    `json:"points"`
+}
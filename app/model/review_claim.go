@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// ReviewClaim: prestasi submitted yang sedang dipegang seorang reviewer.
+// Klaim berlaku sampai ExpiresAt; setelah itu reviewer lain boleh mengambil.
+type ReviewClaim struct {
+	ReferenceID  string    `json:"reference_id"`
+	ReviewerID   string    `json:"reviewer_id"` // users.id
+	ReviewerRole string    `json:"reviewer_role"`
+	AssignedBy   *string   `json:"assigned_by,omitempty"` // diisi bila ditugaskan admin
+	ClaimedAt    time.Time `json:"claimed_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func (c *ReviewClaim) Live(now time.Time) bool {
+	return c.ExpiresAt.After(now)
+}
+
+// Reviewer: user yang boleh memegang klaim review.
+type Reviewer struct {
+	UserID     string
+	Role       string
+	LecturerID string // kosong bila bukan dosen
+}
+
+// ReviewQueueFilter: filter antrian review. StudentIDs nil berarti semua.
+type ReviewQueueFilter struct {
+	StudentIDs    []string
+	StudentID     string
+	Claim         string // "", unclaimed, claimed, mine
+	ReviewerID    string // dipakai Claim=mine
+	SubmittedFrom *time.Time
+	SubmittedTo   *time.Time
+	Limit         int
+	Offset        int
+}
+
+type ReviewQueueItem struct {
+	Reference AchievementReference `json:"reference"`
+	Claim     *ReviewClaim         `json:"claim"`
+}
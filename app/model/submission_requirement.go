@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Jenis lampiran yang dikenali syarat pengajuan.
+var AttachmentKinds = []string{"certificate", "photo", "assignment_letter", "other"}
+
+// SubmissionRequirement adalah syarat yang harus dipenuhi sebuah prestasi
+// bertipe TypeCode sebelum boleh diajukan (submit).
+type SubmissionRequirement struct {
+	TypeCode       string    `json:"type_code"`
+	MinAttachments int       `json:"min_attachments"`
+	RequiredKinds  []string  `json:"required_kinds"`  // jenis lampiran wajib
+	RequiredFields []string  `json:"required_fields"` // mis. eventDate, customFields.nomorSk
+	UpdatedBy      string    `json:"updated_by,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Status kode verifikasi publik.
+const (
+	VerificationCodeActive  = "active"
+	VerificationCodeRevoked = "revoked"
+)
+
+// VerificationCode: kode publik yang membuktikan prestasi sudah diverifikasi
+// universitas. Kode dicabut bila prestasinya dicabut.
+type VerificationCode struct {
+	Code         string     `json:"code"`
+	ReferenceID  string     `json:"reference_id"`
+	IssuedAt     time.Time  `json:"issued_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy    *string    `json:"revoked_by,omitempty"`
+	RevokeReason *string    `json:"revoke_reason,omitempty"`
+}
+
+func (v *VerificationCode) Status() string {
+	if v.RevokedAt != nil {
+		return VerificationCodeRevoked
+	}
+	return VerificationCodeActive
+}
+
+// VerificationSummary: data minimal yang ditampilkan ke publik. Tidak memuat
+// NIM, poin, lampiran atau kontak mahasiswa.
+type VerificationSummary struct {
+	VerificationCode
+	MongoID      string     `json:"-"`
+	RefStatus    string     `json:"-"` // status prestasi saat ini
+	StudentName  string     `json:"student_name"`
+	VerifiedAt   *time.Time `json:"verified_at"`
+	VerifierUnit string     `json:"verifier_unit"`
+}
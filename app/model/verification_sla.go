@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Jenis event SLA verifikasi.
+const (
+	SLAReminder   = "reminder"
+	SLAEscalation = "escalation"
+)
+
+// VerificationSLAEvent: pengingat/eskalasi yang sudah dikirim, supaya
+// scheduler tidak mengirim ulang setiap kali berjalan.
+type VerificationSLAEvent struct {
+	ID          string    `json:"id"`
+	ReferenceID string    `json:"reference_id"`
+	Kind        string    `json:"kind"`
+	RecipientID string    `json:"recipient_id"` // users.id
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OverdueReference: prestasi submitted yang melewati batas SLA.
+type OverdueReference struct {
+	Reference      AchievementReference `json:"reference"`
+	AdvisorUserID  string               `json:"advisor_user_id,omitempty"`
+	LastReminderAt *time.Time           `json:"last_reminder_at,omitempty"`
+	Escalated      bool                 `json:"escalated"`
+}
+
+// Notification: pesan untuk satu user (dikirim lewat Notifier).
+type Notification struct {
+	RecipientID string    `json:"recipient_id"`
+	Kind        string    `json:"kind"`
+	ReferenceID string    `json:"reference_id,omitempty"`
+	Subject     string    `json:"subject"`
+	Message     string    `json:"message"`
+	CreatedAt   time.Time `json:"created_at"`
+}
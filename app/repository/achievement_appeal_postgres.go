@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AchievementAppealPostgresRepository interface {
+	Create(appeal *model.AchievementAppeal) error
+	GetByReference(refID string) (*model.AchievementAppeal, error)
+	// List: status kosong berarti semua status, terlama dulu.
+	List(status string) ([]model.AchievementAppeal, error)
+	// Decide hanya berhasil untuk banding yang masih pending.
+	Decide(id, status, decidedBy, note string, at time.Time) (bool, error)
+}
+
+type achievementAppealPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementAppealPostgresRepository() AchievementAppealPostgresRepository {
+	return &achievementAppealPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+const appealColumns = `id, reference_id, student_id, submitted_by, justification, evidence,
+	rejected_by::text, rejection_note, status, decided_by::text, decision_note, created_at, decided_at`
+
+func scanAppeal(row interface{ Scan(...interface{}) error }) (*model.AchievementAppeal, error) {
+	var a model.AchievementAppeal
+	err := row.Scan(
+		&a.ID, &a.ReferenceID, &a.StudentID, &a.SubmittedBy, &a.Justification, &a.Evidence,
+		&a.RejectedBy, &a.RejectionNote, &a.Status, &a.DecidedBy, &a.DecisionNote, &a.CreatedAt, &a.DecidedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *achievementAppealPostgresRepo) Create(a *model.AchievementAppeal) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO achievement_appeals
+		 (id, reference_id, student_id, submitted_by, justification, evidence,
+		  rejected_by, rejection_note, status, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		a.ID, a.ReferenceID, a.StudentID, a.SubmittedBy, a.Justification, a.Evidence,
+		a.RejectedBy, a.RejectionNote, a.Status, a.CreatedAt,
+	)
+	return err
+}
+
+func (r *achievementAppealPostgresRepo) GetByReference(refID string) (*model.AchievementAppeal, error) {
+	a, err := scanAppeal(r.pool.QueryRow(context.Background(),
+		`SELECT `+appealColumns+` FROM achievement_appeals WHERE reference_id::text = $1`,
+		refID,
+	))
+	if err != nil {
+		return nil, errors.New("appeal not found")
+	}
+	return a, nil
+}
+
+func (r *achievementAppealPostgresRepo) List(status string) ([]model.AchievementAppeal, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+appealColumns+`
+		 FROM achievement_appeals
+		 WHERE $1 = '' OR status = $1
+		 ORDER BY created_at ASC`,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.AchievementAppeal
+	for rows.Next() {
+		a, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *a)
+	}
+	return list, nil
+}
+
+func (r *achievementAppealPostgresRepo) Decide(id, status, decidedBy, note string, at time.Time) (bool, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_appeals
+		 SET status = $2, decided_by = $3, decision_note = $4, decided_at = $5
+		 WHERE id = $1 AND status = 'pending'`,
+		id, status, decidedBy, note, at,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AchievementCommentPostgresRepository interface {
+	Create(comment *model.AchievementComment) error
+	Get(id string) (*model.AchievementComment, error)
+	// List mengembalikan semua komentar satu prestasi (termasuk yang dihapus)
+	// urut waktu; includeInternal=false menyaring catatan internal.
+	List(refID string, includeInternal bool) ([]model.AchievementComment, error)
+	Update(id, body string, attachments []string, editedAt time.Time) error
+	SoftDelete(id string, at time.Time) error
+	MarkRead(refID, userID string, at time.Time) error
+	// UnreadCounts: jumlah komentar dari user lain yang lebih baru dari
+	// penanda baca userID, per reference_id, untuk prestasi (tidak terhapus)
+	// dalam scope. Hanya reference dengan hitungan > 0.
+	UnreadCounts(userID string, scope model.CommentUnreadScope) (map[string]int, error)
+}
+
+type achievementCommentPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementCommentPostgresRepository() AchievementCommentPostgresRepository {
+	return &achievementCommentPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+const commentColumns = `id, reference_id, parent_id::text, author_id, author_role, body,
+	internal, attachments, created_at, edited_at, deleted_at`
+
+func scanComment(row interface{ Scan(...interface{}) error }) (*model.AchievementComment, error) {
+	var c model.AchievementComment
+	err := row.Scan(
+		&c.ID, &c.ReferenceID, &c.ParentID, &c.AuthorID, &c.AuthorRole, &c.Body,
+		&c.Internal, &c.Attachments, &c.CreatedAt, &c.EditedAt, &c.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *achievementCommentPostgresRepo) Create(c *model.AchievementComment) error {
+	attachments := c.Attachments
+	if attachments == nil {
+		attachments = []string{}
+	}
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO achievement_comments
+		 (id, reference_id, parent_id, author_id, author_role, body, internal, attachments, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+		c.ID, c.ReferenceID, c.ParentID, c.AuthorID, c.AuthorRole, c.Body, c.Internal, attachments, c.CreatedAt,
+	)
+	return err
+}
+
+func (r *achievementCommentPostgresRepo) Get(id string) (*model.AchievementComment, error) {
+	c, err := scanComment(r.pool.QueryRow(context.Background(),
+		`SELECT `+commentColumns+` FROM achievement_comments WHERE id::text = $1`,
+		id,
+	))
+	if err != nil {
+		return nil, errors.New("comment not found")
+	}
+	return c, nil
+}
+
+func (r *achievementCommentPostgresRepo) List(refID string, includeInternal bool) ([]model.AchievementComment, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+commentColumns+`
+		 FROM achievement_comments
+		 WHERE reference_id = $1 AND ($2 OR NOT internal)
+		 ORDER BY created_at ASC`,
+		refID, includeInternal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.AchievementComment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *c)
+	}
+	return list, nil
+}
+
+func (r *achievementCommentPostgresRepo) Update(id, body string, attachments []string, editedAt time.Time) error {
+	if attachments == nil {
+		attachments = []string{}
+	}
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_comments
+		 SET body = $2, attachments = $3, edited_at = $4
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		id, body, attachments, editedAt,
+	)
+	return err
+}
+
+func (r *achievementCommentPostgresRepo) SoftDelete(id string, at time.Time) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_comments
+		 SET deleted_at = $2, body = '', attachments = '{}'
+		 WHERE id = $1 AND deleted_at IS NULL`,
+		id, at,
+	)
+	return err
+}
+
+func (r *achievementCommentPostgresRepo) MarkRead(refID, userID string, at time.Time) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO achievement_comment_reads (reference_id, user_id, last_read_at)
+		 VALUES ($1,$2,$3)
+		 ON CONFLICT (reference_id, user_id) DO UPDATE
+		 SET last_read_at = GREATEST(achievement_comment_reads.last_read_at, EXCLUDED.last_read_at)`,
+		refID, userID, at,
+	)
+	return err
+}
+
+func (r *achievementCommentPostgresRepo) UnreadCounts(userID string, scope model.CommentUnreadScope) (map[string]int, error) {
+	args := []interface{}{userID, scope.IncludeInternal}
+	where := ""
+	if scope.StudentIDs != nil {
+		args = append(args, scope.StudentIDs, scope.MemberStatuses)
+		// pemilik atau anggota tim
+		where = `AND (ar.student_id::text = ANY($3) OR EXISTS (
+			SELECT 1 FROM achievement_team_members tm
+			WHERE tm.reference_id = ar.id AND tm.status = ANY($4)
+			  AND tm.student_id::text = ANY($3)))`
+	}
+
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT c.reference_id::text, COUNT(*)
+		 FROM achievement_comments c
+		 JOIN achievement_references ar ON ar.id = c.reference_id
+		 LEFT JOIN achievement_comment_reads rd
+		        ON rd.reference_id = c.reference_id AND rd.user_id::text = $1
+		 WHERE c.author_id::text <> $1
+		   AND c.deleted_at IS NULL
+		   AND ($2 OR NOT c.internal)
+		   AND (rd.last_read_at IS NULL OR c.created_at > rd.last_read_at)
+		   AND ar.status <> 'deleted'
+		   `+where+`
+		 GROUP BY c.reference_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var refID string
+		var n int
+		if err := rows.Scan(&refID, &n); err != nil {
+			return nil, err
+		}
+		counts[refID] = n
+	}
+	return counts, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AchievementFingerprintPostgresRepository interface {
+	// ReplaceFingerprints mengganti fingerprint jenis-jenis kinds milik refID.
+	ReplaceFingerprints(refID string, kinds []string, fps []model.AchievementFingerprint) error
+	AddFingerprints(fps []model.AchievementFingerprint) error
+	// RemoveFingerprint menghapus satu fingerprint milik refID.
+	RemoveFingerprint(refID, kind, value string) error
+	// FindMatches: prestasi lain (tidak terhapus) dengan fingerprint yang sama.
+	FindMatches(refID string, fps []model.AchievementFingerprint) ([]model.DuplicateMatch, error)
+	// MatchesForReferences: duplikat yang sudah tersimpan untuk banyak prestasi.
+	MatchesForReferences(refIDs []string) (map[string][]model.DuplicateMatch, error)
+}
+
+type achievementFingerprintPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementFingerprintPostgresRepository() AchievementFingerprintPostgresRepository {
+	return &achievementFingerprintPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *achievementFingerprintPostgresRepo) ReplaceFingerprints(refID string, kinds []string, fps []model.AchievementFingerprint) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM achievement_fingerprints WHERE reference_id = $1 AND kind = ANY($2)`,
+		refID, kinds,
+	); err != nil {
+		return err
+	}
+
+	for _, fp := range fps {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO achievement_fingerprints (reference_id, student_id, kind, value, created_at)
+			 VALUES ($1,$2,$3,$4,$5)
+			 ON CONFLICT DO NOTHING`,
+			fp.ReferenceID, fp.StudentID, fp.Kind, fp.Value, fp.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *achievementFingerprintPostgresRepo) AddFingerprints(fps []model.AchievementFingerprint) error {
+	for _, fp := range fps {
+		if _, err := r.pool.Exec(context.Background(),
+			`INSERT INTO achievement_fingerprints (reference_id, student_id, kind, value, created_at)
+			 VALUES ($1,$2,$3,$4,$5)
+			 ON CONFLICT DO NOTHING`,
+			fp.ReferenceID, fp.StudentID, fp.Kind, fp.Value, fp.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *achievementFingerprintPostgresRepo) RemoveFingerprint(refID, kind, value string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`DELETE FROM achievement_fingerprints WHERE reference_id = $1 AND kind = $2 AND value = $3`,
+		refID, kind, value,
+	)
+	return err
+}
+
+func (r *achievementFingerprintPostgresRepo) FindMatches(refID string, fps []model.AchievementFingerprint) ([]model.DuplicateMatch, error) {
+	var matches []model.DuplicateMatch
+
+	for _, fp := range fps {
+		rows, err := r.pool.Query(context.Background(),
+			`SELECT DISTINCT f.reference_id, f.student_id, ar.status
+			 FROM achievement_fingerprints f
+			 JOIN achievement_references ar ON ar.id = f.reference_id
+			 WHERE f.kind = $1 AND f.value = $2
+			   AND f.reference_id <> $3
+			   AND ar.status <> 'deleted'`,
+			fp.Kind, fp.Value, refID)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			m := model.DuplicateMatch{ReferenceID: refID, Kind: fp.Kind}
+			if err := rows.Scan(&m.MatchReferenceID, &m.MatchStudentID, &m.MatchStatus); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			matches = append(matches, m)
+		}
+		rows.Close()
+	}
+
+	return matches, nil
+}
+
+func (r *achievementFingerprintPostgresRepo) MatchesForReferences(refIDs []string) (map[string][]model.DuplicateMatch, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT DISTINCT a.reference_id, b.reference_id, b.student_id, ar.status, a.kind
+		 FROM achievement_fingerprints a
+		 JOIN achievement_fingerprints b
+		   ON b.kind = a.kind AND b.value = a.value AND b.reference_id <> a.reference_id
+		 JOIN achievement_references ar ON ar.id = b.reference_id
+		 WHERE a.reference_id = ANY($1)
+		   AND ar.status <> 'deleted'`,
+		refIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string][]model.DuplicateMatch{}
+	for rows.Next() {
+		var m model.DuplicateMatch
+		if err := rows.Scan(&m.ReferenceID, &m.MatchReferenceID, &m.MatchStudentID, &m.MatchStatus, &m.Kind); err != nil {
+			return nil, err
+		}
+		result[m.ReferenceID] = append(result[m.ReferenceID], m)
+	}
+	return result, nil
+}
@@ -5,25 +5,36 @@ import (
 	"prestasi_api/app/model"
 	"context"
 	"errors"
+	"strconv"
 	"time"
-	 "io"
-	 "os"
-	  "path/filepath"
-    "mime/multipart"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AchievementMongoRepository interface {
 	CreateAchievementMongo(data *model.AchievementMongo) (primitive.ObjectID, error)
 	SoftDeleteAchievementMongo(id primitive.ObjectID) error
+	DeleteAchievementMongo(id primitive.ObjectID) error
 	RestoreAchievementMongo(id primitive.ObjectID) error
 	GetByID(id primitive.ObjectID) (*model.AchievementMongo, error)
 	GetAll() ([]model.AchievementMongo, error)
 	UpdateAchievementMongo(id primitive.ObjectID, a *model.AchievementMongo) error
-    AddAttachmentMongo(id primitive.ObjectID, file *multipart.FileHeader) (string, error)
+    AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error
+    ListWithAttachments() ([]model.AchievementMongo, error)
+    SetAttachmentStorageKey(id primitive.ObjectID, index int, key string) error
+    SetAttachmentMongo(id primitive.ObjectID, index int, a model.Attachment) error
+    ListPurgeable(deletedBefore time.Time) ([]model.AchievementMongo, error)
+    MarkAttachmentsPurged(id primitive.ObjectID, at time.Time) error
+    ListPendingScan(limit int) ([]model.AchievementMongo, error)
+    SetAttachmentScan(storageKey string, status, signature, newKey string, at time.Time) (bool, error)
+    CountStorageKeyRefs(storageKey string, exclude primitive.ObjectID) (int64, error)
+    ListIntegrityDue(checkedBefore time.Time, limit int) ([]model.AchievementMongo, error)
+    SetAttachmentIntegrity(storageKey, status string, at time.Time) error
+    ListIntegrityIssues() ([]model.AchievementMongo, error)
     GetAllForReport() ([]model.AchievementMongo, error)
+    UpdatePointsMongo(id primitive.ObjectID, points int) error
 
 }
 
@@ -73,6 +84,17 @@ func (r *achievementMongoRepo) SoftDeleteAchievementMongo(id primitive.ObjectID)
 }
 
 
+// HARD DELETE — hanya untuk membatalkan pembuatan prestasi yang gagal
+
+func (r *achievementMongoRepo) DeleteAchievementMongo(id primitive.ObjectID) error {
+	ctx := context.TODO()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+
+	return err
+}
+
+
 // RESTORE (SoftDelete)
 
 func (r *achievementMongoRepo) RestoreAchievementMongo(id primitive.ObjectID) error {
@@ -147,52 +169,220 @@ func (r *achievementMongoRepo) UpdateAchievementMongo(id primitive.ObjectID, a *
     return err
 }
 
-// ADD ATTACHMENT: saves file to ./uploads/<id>/ and returns URL/path
-func (r *achievementMongoRepo) AddAttachmentMongo(id primitive.ObjectID, file *multipart.FileHeader) (string, error) {
+// ADD ATTACHMENT: hanya metadata; isi file sudah disimpan di storage
+func (r *achievementMongoRepo) AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error {
     ctx := context.TODO()
-    // ensure uploads directory
-    uploadDir := filepath.Join(".", "uploads", id.Hex())
-    if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-        return "", err
+
+    _, err := r.collection.UpdateByID(ctx, id, bson.M{
+        "$push": bson.M{"attachments": a},
+        "$set":  bson.M{"updatedAt": time.Now()},
+    })
+    return err
+}
+
+// LIST WITH ATTACHMENTS: semua prestasi berlampiran, termasuk yang dihapus
+// (dipakai migrasi uploads/ ke storage)
+func (r *achievementMongoRepo) ListWithAttachments() ([]model.AchievementMongo, error) {
+    ctx := context.TODO()
+
+    cursor, err := r.collection.Find(ctx, bson.M{
+        "attachments.0": bson.M{"$exists": true},
+    })
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []model.AchievementMongo
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
     }
+    return results, nil
+}
+
+// SET ATTACHMENT STORAGE KEY: lampiran lama → key storage, path lama dihapus
+func (r *achievementMongoRepo) SetAttachmentStorageKey(id primitive.ObjectID, index int, key string) error {
+    ctx := context.TODO()
+
+    field := "attachments." + strconv.Itoa(index)
+    _, err := r.collection.UpdateByID(ctx, id, bson.M{
+        "$set":   bson.M{field + ".storageKey": key},
+        "$unset": bson.M{field + ".fileUrl": ""},
+    })
+    return err
+}
+
+// SET ATTACHMENT: ganti/hapus lampiran pada posisi index. Lampiran tidak
+// pernah dikeluarkan dari array, jadi index tetap stabil.
+func (r *achievementMongoRepo) SetAttachmentMongo(id primitive.ObjectID, index int, a model.Attachment) error {
+    ctx := context.TODO()
+
+    _, err := r.collection.UpdateByID(ctx, id, bson.M{
+        "$set": bson.M{
+            "attachments." + strconv.Itoa(index): a,
+            "updatedAt":                           time.Now(),
+        },
+    })
+    return err
+}
+
+// LIST PURGEABLE: prestasi yang dihapus sebelum deletedBefore dan lampirannya
+// belum dipurge
+func (r *achievementMongoRepo) ListPurgeable(deletedBefore time.Time) ([]model.AchievementMongo, error) {
+    ctx := context.TODO()
 
-    // open uploaded file
-    src, err := file.Open()
+    cursor, err := r.collection.Find(ctx, bson.M{
+        "deletedAt":           bson.M{"$lt": deletedBefore},
+        "attachmentsPurgedAt": bson.M{"$exists": false},
+        "attachments.0":       bson.M{"$exists": true},
+    })
     if err != nil {
-        return "", err
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []model.AchievementMongo
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
     }
-    defer src.Close()
+    return results, nil
+}
+
+func (r *achievementMongoRepo) MarkAttachmentsPurged(id primitive.ObjectID, at time.Time) error {
+    ctx := context.TODO()
 
-    // destination path
-    dstPath := filepath.Join(uploadDir, file.Filename)
-    dst, err := os.Create(dstPath)
+    _, err := r.collection.UpdateByID(ctx, id, bson.M{
+        "$set": bson.M{"attachmentsPurgedAt": at},
+    })
+    return err
+}
+
+// LIST PENDING SCAN: prestasi dengan lampiran menunggu pemindaian malware
+func (r *achievementMongoRepo) ListPendingScan(limit int) ([]model.AchievementMongo, error) {
+    ctx := context.TODO()
+
+    cursor, err := r.collection.Find(ctx, bson.M{
+        "attachments.scanStatus": model.AttachmentPendingScan,
+    }, options.Find().SetLimit(int64(limit)))
     if err != nil {
-        return "", err
+        return nil, err
     }
-    defer dst.Close()
+    defer cursor.Close(ctx)
 
-    // copy content
-    if _, err := io.Copy(dst, src); err != nil {
-        return "", err
+    var results []model.AchievementMongo
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
     }
+    return results, nil
+}
 
-    // Optionally update the achievement document with attachment metadata
-    _, err = r.collection.UpdateByID(ctx, id, bson.M{
-        "$push": bson.M{
-            "attachments": bson.M{
-                "fileName":  file.Filename,
-                "fileUrl":   dstPath, // change to actual public URL if needed
-                "fileType":  file.Header.Get("Content-Type"),
-                "uploadedAt": time.Now(),
-            },
+// SET ATTACHMENT SCAN: hasil pemindaian untuk semua lampiran yang storageKey-nya
+// masih sama (isi yang sama dipakai bersama; lampiran yang keburu diganti
+// tidak ikut berubah). newKey terisi bila file dipindah ke karantina.
+// false = tidak ada lampiran yang memakai key tersebut.
+func (r *achievementMongoRepo) SetAttachmentScan(storageKey string, status, signature, newKey string, at time.Time) (bool, error) {
+    ctx := context.TODO()
+
+    set := bson.M{
+        "attachments.$[a].scanStatus": status,
+        "attachments.$[a].scannedAt":  at,
+    }
+    if signature != "" {
+        set["attachments.$[a].scanSignature"] = signature
+    }
+    if newKey != "" {
+        set["attachments.$[a].storageKey"] = newKey
+    }
+    res, err := r.collection.UpdateMany(ctx, bson.M{
+        "attachments.storageKey": storageKey,
+    }, bson.M{"$set": set}, attachmentKeyFilter(storageKey))
+    if err != nil {
+        return false, err
+    }
+    return res.MatchedCount > 0, nil
+}
+
+// attachmentKeyFilter: arrayFilters untuk elemen lampiran dengan storageKey tertentu
+func attachmentKeyFilter(storageKey string) *options.UpdateOptions {
+    return options.Update().SetArrayFilters(options.ArrayFilters{
+        Filters: []interface{}{bson.M{"a.storageKey": storageKey}},
+    })
+}
+
+// COUNT STORAGE KEY REFS: jumlah prestasi lain (belum dipurge) yang masih
+// memakai objek storage ini, termasuk sebagai versi lama
+func (r *achievementMongoRepo) CountStorageKeyRefs(storageKey string, exclude primitive.ObjectID) (int64, error) {
+    ctx := context.TODO()
+
+    return r.collection.CountDocuments(ctx, bson.M{
+        "_id":                 bson.M{"$ne": exclude},
+        "attachmentsPurgedAt": bson.M{"$exists": false},
+        "$or": []bson.M{
+            {"attachments.storageKey": storageKey},
+            {"attachments.versions.storageKey": storageKey},
         },
-        "$set": bson.M{"updatedAt": time.Now()},
     })
+}
+
+// LIST INTEGRITY DUE: prestasi dengan lampiran ber-hash yang belum pernah
+// dicek atau terakhir dicek sebelum checkedBefore
+func (r *achievementMongoRepo) ListIntegrityDue(checkedBefore time.Time, limit int) ([]model.AchievementMongo, error) {
+    ctx := context.TODO()
+
+    cursor, err := r.collection.Find(ctx, bson.M{
+        "attachmentsPurgedAt": bson.M{"$exists": false},
+        "attachments": bson.M{"$elemMatch": bson.M{
+            "sha256":     bson.M{"$exists": true},
+            "storageKey": bson.M{"$exists": true},
+            "$or": []bson.M{
+                {"integrityCheckedAt": bson.M{"$exists": false}},
+                {"integrityCheckedAt": bson.M{"$lt": checkedBefore}},
+            },
+        }},
+    }, options.Find().SetLimit(int64(limit)))
     if err != nil {
-        return "", err
+        return nil, err
     }
+    defer cursor.Close(ctx)
 
-    return dstPath, nil
+    var results []model.AchievementMongo
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
+}
+
+// SET ATTACHMENT INTEGRITY: hasil cek integritas untuk semua lampiran
+// yang memakai objek storage ini
+func (r *achievementMongoRepo) SetAttachmentIntegrity(storageKey, status string, at time.Time) error {
+    ctx := context.TODO()
+
+    _, err := r.collection.UpdateMany(ctx, bson.M{
+        "attachments.storageKey": storageKey,
+    }, bson.M{"$set": bson.M{
+        "attachments.$[a].integrityStatus":    status,
+        "attachments.$[a].integrityCheckedAt": at,
+    }}, attachmentKeyFilter(storageKey))
+    return err
+}
+
+// LIST INTEGRITY ISSUES: prestasi dengan lampiran yang isinya berubah atau hilang
+func (r *achievementMongoRepo) ListIntegrityIssues() ([]model.AchievementMongo, error) {
+    ctx := context.TODO()
+
+    cursor, err := r.collection.Find(ctx, bson.M{
+        "attachments.integrityStatus": bson.M{"$in": []string{model.IntegrityMismatch, model.IntegrityMissing}},
+    })
+    if err != nil {
+        return nil, err
+    }
+    defer cursor.Close(ctx)
+
+    var results []model.AchievementMongo
+    if err := cursor.All(ctx, &results); err != nil {
+        return nil, err
+    }
+    return results, nil
 }
 
 func (r *achievementMongoRepo) GetAllForReport() ([]model.AchievementMongo, error) {
@@ -214,3 +404,16 @@ func (r *achievementMongoRepo) GetAllForReport() ([]model.AchievementMongo, erro
     return results, nil
 }
 
+
+// UPDATE POINTS (dipakai job hitung ulang poin)
+func (r *achievementMongoRepo) UpdatePointsMongo(id primitive.ObjectID, points int) error {
+    ctx := context.TODO()
+
+    _, err := r.collection.UpdateByID(ctx, id, bson.M{
+        "$set": bson.M{
+            "points":    points,
+            "updatedAt": time.Now(),
+        },
+    })
+    return err
+}
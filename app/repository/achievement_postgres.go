@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -13,12 +14,16 @@ import (
 
 type AchievementPostgresRepository interface {
 	CreateReferencePostgres(ref *model.AchievementReference) error
+	DeleteReferencePostgres(refID string) error
 	UpdateReferenceStatusPostgres(refID string, status string) error
 	GetMongoID(refID string) (string, error)
 	GetReferenceByID(refID string) (*model.AchievementReference, error)
 	GetByStudentIDs(studentIDs []string) ([]model.AchievementReference, error)
 	UpdateVerifyStatus(refID string, verifierID string) error
 	RejectReference(refID string, userID string, note string) error
+	// DecideSubmitted: verified/rejected hanya bila status masih submitted;
+	// false berarti sudah diputus proses lain (mis. suara komite bersamaan).
+	DecideSubmitted(refID, status, userID, note string) (bool, error)
 	SaveSubmittedAt(refID string, t time.Time) error
 	GetByStudentID(studentID string) ([]model.AchievementReference, error)
 	GetAllReferences() ([]model.AchievementReference, error)
@@ -54,6 +59,16 @@ func (r *achievementPostgresRepo) CreateReferencePostgres(ref *model.Achievement
 	return err
 }
 
+// DELETE REFERENCE — hanya untuk membatalkan pembuatan prestasi yang gagal
+func (r *achievementPostgresRepo) DeleteReferencePostgres(refID string) error {
+	_, err := r.pool.Exec(
+		context.Background(),
+		`DELETE FROM achievement_references WHERE id=$1`,
+		refID,
+	)
+	return err
+}
+
 // UPDATE STATUS
 func (r *achievementPostgresRepo) UpdateReferenceStatusPostgres(refID string, status string) error {
 	_, err := r.pool.Exec(
@@ -155,6 +170,22 @@ func (r *achievementPostgresRepo) RejectReference(refID string, userID string, n
 	return err
 }
 
+// DECIDE (bersyarat status submitted)
+func (r *achievementPostgresRepo) DecideSubmitted(refID, status, userID, note string) (bool, error) {
+	tag, err := r.pool.Exec(
+		context.Background(),
+		`UPDATE achievement_references
+		 SET status=$1::text,
+		     rejection_note=CASE WHEN $1::text = 'rejected' THEN $2 ELSE rejection_note END,
+		     verified_by=$3, verified_at=NOW(),
+		     updated_at=NOW()
+		 WHERE id=$4 AND status='submitted'`,
+		status, note, userID, refID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
 
 // SAVE SUBMITTED_AT
 func (r *achievementPostgresRepo) SaveSubmittedAt(refID string, t time.Time) error {
@@ -225,7 +256,8 @@ func (r *achievementPostgresRepo) GetAllReferences() ([]model.AchievementReferen
 
 func (r *achievementPostgresRepo) GetHistoryByReferenceID(refID string) ([]map[string]interface{}, error) {
     rows, err := r.pool.Query(context.Background(),
-        `SELECT old_status, new_status, note, changed_by, changed_by_role, created_at
+        `SELECT old_status, new_status, note, changed_by, changed_by_role,
+                on_behalf_of::text, created_at, evidence
          FROM achievement_reference_history
          WHERE reference_id=$1
          ORDER BY created_at ASC`,
@@ -239,10 +271,11 @@ func (r *achievementPostgresRepo) GetHistoryByReferenceID(refID string) ([]map[s
     var history []map[string]interface{}
 
     for rows.Next() {
-        var oldStatus, newStatus, note, changedBy, changedByRole *string
+        var oldStatus, newStatus, note, changedBy, changedByRole, onBehalfOf *string
         var createdAt *time.Time
+        var evidence []model.EvidenceHash
 
-        rows.Scan(&oldStatus, &newStatus, &note, &changedBy, &changedByRole, &createdAt)
+        rows.Scan(&oldStatus, &newStatus, &note, &changedBy, &changedByRole, &onBehalfOf, &createdAt, &evidence)
 
         entry := map[string]interface{}{
             "old_status":      oldStatus,
@@ -252,6 +285,12 @@ func (r *achievementPostgresRepo) GetHistoryByReferenceID(refID string) ([]map[s
             "changed_by_role": changedByRole,
             "created_at":      createdAt,
         }
+        if onBehalfOf != nil {
+            entry["on_behalf_of"] = onBehalfOf
+        }
+        if len(evidence) > 0 {
+            entry["evidence"] = evidence
+        }
 
         history = append(history, entry)
     }
@@ -260,14 +299,24 @@ func (r *achievementPostgresRepo) GetHistoryByReferenceID(refID string) ([]map[s
 }
 
 func (r *achievementPostgresRepo) InsertHistory(h *model.AchievementReferenceHistory) error {
+    // evidence hanya ada pada riwayat verifikasi; selain itu NULL
+    var evidence []byte
+    if len(h.Evidence) > 0 {
+        evidence, _ = json.Marshal(h.Evidence)
+    }
+    // aksi sistem (mis. eskalasi SLA) tidak punya user → changed_by NULL
+    var changedBy *string
+    if h.ChangedBy != "" {
+        changedBy = &h.ChangedBy
+    }
     _, err := r.pool.Exec(
         context.Background(),
         `INSERT INTO achievement_reference_history
             (id, reference_id, old_status, new_status, note,
-             changed_by, changed_by_role, created_at)
-         VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+             changed_by, changed_by_role, on_behalf_of, created_at, evidence)
+         VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
         h.ID, h.ReferenceID, h.OldStatus, h.NewStatus, h.Note,
-        h.ChangedBy, h.ChangedByRole, h.CreatedAt,
+        changedBy, h.ChangedByRole, h.OnBehalfOf, h.CreatedAt, evidence,
     )
     return err
 }
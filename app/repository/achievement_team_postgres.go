@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AchievementTeamPostgresRepository interface {
+	AddMembers(members []model.AchievementTeamMember) error
+	ListMembers(refID string) ([]model.AchievementTeamMember, error)
+	GetMember(refID, studentID string) (*model.AchievementTeamMember, error)
+	RespondMember(refID, studentID, status string, at time.Time) error
+	// ReferencesByMembers: prestasi tim yang diikuti mahasiswa tersebut
+	// (bukan sebagai pemilik). confirmedOnly=false ikut menyertakan pending.
+	ReferencesByMembers(studentIDs []string, confirmedOnly bool) ([]model.AchievementReference, error)
+	// ConfirmedMembersByMongoID: anggota confirmed selain pemilik, dikelompokkan
+	// per id dokumen Mongo (dipakai laporan).
+	ConfirmedMembersByMongoID() (map[string][]string, error)
+}
+
+type achievementTeamPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementTeamPostgresRepository() AchievementTeamPostgresRepository {
+	return &achievementTeamPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *achievementTeamPostgresRepo) AddMembers(members []model.AchievementTeamMember) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, m := range members {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO achievement_team_members
+			 (reference_id, student_id, team_role, status, responded_at, created_at)
+			 VALUES ($1,$2,$3,$4,$5,$6)`,
+			m.ReferenceID, m.StudentID, m.TeamRole, m.Status, m.RespondedAt, m.CreatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *achievementTeamPostgresRepo) ListMembers(refID string) ([]model.AchievementTeamMember, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT reference_id, student_id, team_role, status, responded_at, created_at
+		 FROM achievement_team_members
+		 WHERE reference_id = $1
+		 ORDER BY created_at, student_id`,
+		refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.AchievementTeamMember
+	for rows.Next() {
+		var m model.AchievementTeamMember
+		if err := rows.Scan(&m.ReferenceID, &m.StudentID, &m.TeamRole, &m.Status, &m.RespondedAt, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, nil
+}
+
+func (r *achievementTeamPostgresRepo) GetMember(refID, studentID string) (*model.AchievementTeamMember, error) {
+	var m model.AchievementTeamMember
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT reference_id, student_id, team_role, status, responded_at, created_at
+		 FROM achievement_team_members
+		 WHERE reference_id = $1 AND student_id = $2`,
+		refID, studentID,
+	).Scan(&m.ReferenceID, &m.StudentID, &m.TeamRole, &m.Status, &m.RespondedAt, &m.CreatedAt)
+	if err != nil {
+		return nil, errors.New("team member not found")
+	}
+	return &m, nil
+}
+
+func (r *achievementTeamPostgresRepo) RespondMember(refID, studentID, status string, at time.Time) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_team_members
+		 SET status = $1, responded_at = $2
+		 WHERE reference_id = $3 AND student_id = $4`,
+		status, at, refID, studentID,
+	)
+	return err
+}
+
+func (r *achievementTeamPostgresRepo) ReferencesByMembers(studentIDs []string, confirmedOnly bool) ([]model.AchievementReference, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT DISTINCT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.created_at, ar.updated_at
+		 FROM achievement_team_members tm
+		 JOIN achievement_references ar ON ar.id = tm.reference_id
+		 WHERE tm.student_id = ANY($1)
+		   AND tm.student_id <> ar.student_id
+		   AND (tm.status = 'confirmed' OR (NOT $2 AND tm.status = 'pending'))`,
+		studentIDs, confirmedOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []model.AchievementReference
+	for rows.Next() {
+		var ref model.AchievementReference
+		if err := rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoID, &ref.Status, &ref.CreatedAt, &ref.UpdatedAt); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (r *achievementTeamPostgresRepo) ConfirmedMembersByMongoID() (map[string][]string, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT ar.mongo_achievement_id, tm.student_id
+		 FROM achievement_team_members tm
+		 JOIN achievement_references ar ON ar.id = tm.reference_id
+		 WHERE tm.status = 'confirmed' AND tm.student_id <> ar.student_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[string][]string{}
+	for rows.Next() {
+		var mongoID, studentID string
+		if err := rows.Scan(&mongoID, &studentID); err != nil {
+			return nil, err
+		}
+		result[mongoID] = append(result[mongoID], studentID)
+	}
+	return result, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrAchievementTypeNotFound: kode tipe tidak terdaftar.
+var ErrAchievementTypeNotFound = errors.New("achievement type not found")
+
+type AchievementTypePostgresRepository interface {
+	ListTypes(activeOnly bool) ([]model.AchievementType, error)
+	GetTypeByCode(code string) (*model.AchievementType, error)
+	CreateType(t *model.AchievementType) error
+	UpdateType(t *model.AchievementType) error
+
+	ListFields(typeCode string) ([]model.AchievementCustomField, error)
+	ListAllFields() ([]model.AchievementCustomField, error)
+	GetField(id string) (*model.AchievementCustomField, error)
+	CreateField(f *model.AchievementCustomField) error
+	UpdateField(f *model.AchievementCustomField) error
+	DeleteField(id string) error
+}
+
+type achievementTypePostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementTypePostgresRepository() AchievementTypePostgresRepository {
+	return &achievementTypePostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+// ======================================================
+// TYPES
+// ======================================================
+
+func (r *achievementTypePostgresRepo) ListTypes(activeOnly bool) ([]model.AchievementType, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT id, code, name, description, default_points, is_active, created_at, updated_at
+		 FROM achievement_types
+		 WHERE is_active OR NOT $1
+		 ORDER BY code`,
+		activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.AchievementType
+	for rows.Next() {
+		var t model.AchievementType
+		if err := rows.Scan(
+			&t.ID, &t.Code, &t.Name, &t.Description, &t.DefaultPoints,
+			&t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+func (r *achievementTypePostgresRepo) GetTypeByCode(code string) (*model.AchievementType, error) {
+	var t model.AchievementType
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT id, code, name, description, default_points, is_active, created_at, updated_at
+		 FROM achievement_types WHERE code = $1`,
+		code,
+	).Scan(
+		&t.ID, &t.Code, &t.Name, &t.Description, &t.DefaultPoints,
+		&t.IsActive, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAchievementTypeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *achievementTypePostgresRepo) CreateType(t *model.AchievementType) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO achievement_types
+		 (id, code, name, description, default_points, is_active, created_at, updated_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		t.ID, t.Code, t.Name, t.Description, t.DefaultPoints,
+		t.IsActive, t.CreatedAt, t.UpdatedAt,
+	)
+	return err
+}
+
+func (r *achievementTypePostgresRepo) UpdateType(t *model.AchievementType) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_types
+		 SET name=$1, description=$2, default_points=$3, is_active=$4, updated_at=NOW()
+		 WHERE code=$5`,
+		t.Name, t.Description, t.DefaultPoints, t.IsActive, t.Code,
+	)
+	return err
+}
+
+// ======================================================
+// CUSTOM FIELDS
+// ======================================================
+
+const customFieldColumns = `id, type_code, key, label, data_type, required,
+	allowed_values, display_order, created_at`
+
+func scanCustomFields(rows interface {
+	Next() bool
+	Scan(dest ...any) error
+}) ([]model.AchievementCustomField, error) {
+	var list []model.AchievementCustomField
+	for rows.Next() {
+		var f model.AchievementCustomField
+		if err := rows.Scan(
+			&f.ID, &f.TypeCode, &f.Key, &f.Label, &f.DataType, &f.Required,
+			&f.AllowedValues, &f.DisplayOrder, &f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, nil
+}
+
+func (r *achievementTypePostgresRepo) ListFields(typeCode string) ([]model.AchievementCustomField, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+customFieldColumns+`
+		 FROM achievement_custom_fields
+		 WHERE type_code = $1
+		 ORDER BY display_order, key`,
+		typeCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCustomFields(rows)
+}
+
+func (r *achievementTypePostgresRepo) ListAllFields() ([]model.AchievementCustomField, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+customFieldColumns+`
+		 FROM achievement_custom_fields
+		 ORDER BY type_code, display_order, key`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCustomFields(rows)
+}
+
+func (r *achievementTypePostgresRepo) GetField(id string) (*model.AchievementCustomField, error) {
+	var f model.AchievementCustomField
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT `+customFieldColumns+` FROM achievement_custom_fields WHERE id = $1`,
+		id,
+	).Scan(
+		&f.ID, &f.TypeCode, &f.Key, &f.Label, &f.DataType, &f.Required,
+		&f.AllowedValues, &f.DisplayOrder, &f.CreatedAt,
+	)
+	if err != nil {
+		return nil, errors.New("custom field not found")
+	}
+	return &f, nil
+}
+
+func (r *achievementTypePostgresRepo) CreateField(f *model.AchievementCustomField) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO achievement_custom_fields
+		 (id, type_code, key, label, data_type, required, allowed_values, display_order, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+		f.ID, f.TypeCode, f.Key, f.Label, f.DataType, f.Required,
+		f.AllowedValues, f.DisplayOrder, f.CreatedAt,
+	)
+	return err
+}
+
+func (r *achievementTypePostgresRepo) UpdateField(f *model.AchievementCustomField) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_custom_fields
+		 SET label=$1, data_type=$2, required=$3, allowed_values=$4, display_order=$5
+		 WHERE id=$6`,
+		f.Label, f.DataType, f.Required, f.AllowedValues, f.DisplayOrder, f.ID,
+	)
+	return err
+}
+
+func (r *achievementTypePostgresRepo) DeleteField(id string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`DELETE FROM achievement_custom_fields WHERE id = $1`,
+		id,
+	)
+	return err
+}
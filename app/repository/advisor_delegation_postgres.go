@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AdvisorDelegationPostgresRepository interface {
+	Create(d *model.AdvisorDelegation) error
+	Get(id string) (*model.AdvisorDelegation, error)
+	// List: lecturerID kosong → semua, selain itu yang diberikan/diterima dosen tsb.
+	List(lecturerID string) ([]model.AdvisorDelegation, error)
+	Revoke(id, userID string, at time.Time) error
+	// ActiveFor: delegasi aktif pada waktu at yang diterima toLecturerID.
+	ActiveFor(toLecturerID string, at time.Time) ([]model.AdvisorDelegation, error)
+	// Overlapping: delegasi belum dicabut dari fromLecturerID yang beririsan dengan jendela waktu.
+	Overlapping(fromLecturerID string, startsAt, endsAt time.Time) ([]model.AdvisorDelegation, error)
+}
+
+type advisorDelegationPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdvisorDelegationPostgresRepository() AdvisorDelegationPostgresRepository {
+	return &advisorDelegationPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+const delegationColumns = `id, from_lecturer_id, to_lecturer_id, starts_at, ends_at, reason,
+	created_by, created_at, revoked_at, revoked_by::text`
+
+func scanDelegations(rows interface {
+	Next() bool
+	Scan(dest ...any) error
+}) ([]model.AdvisorDelegation, error) {
+	var list []model.AdvisorDelegation
+	for rows.Next() {
+		var d model.AdvisorDelegation
+		if err := rows.Scan(
+			&d.ID, &d.FromLecturerID, &d.ToLecturerID, &d.StartsAt, &d.EndsAt, &d.Reason,
+			&d.CreatedBy, &d.CreatedAt, &d.RevokedAt, &d.RevokedBy,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, nil
+}
+
+func (r *advisorDelegationPostgresRepo) Create(d *model.AdvisorDelegation) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO advisor_delegations
+		 (id, from_lecturer_id, to_lecturer_id, starts_at, ends_at, reason, created_by, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		d.ID, d.FromLecturerID, d.ToLecturerID, d.StartsAt, d.EndsAt, d.Reason, d.CreatedBy, d.CreatedAt,
+	)
+	return err
+}
+
+func (r *advisorDelegationPostgresRepo) Get(id string) (*model.AdvisorDelegation, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+delegationColumns+` FROM advisor_delegations WHERE id = $1`,
+		id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list, err := scanDelegations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, errors.New("delegation not found")
+	}
+	return &list[0], nil
+}
+
+func (r *advisorDelegationPostgresRepo) List(lecturerID string) ([]model.AdvisorDelegation, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+delegationColumns+`
+		 FROM advisor_delegations
+		 WHERE $1 = '' OR from_lecturer_id::text = $1 OR to_lecturer_id::text = $1
+		 ORDER BY starts_at DESC`,
+		lecturerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDelegations(rows)
+}
+
+func (r *advisorDelegationPostgresRepo) Revoke(id, userID string, at time.Time) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE advisor_delegations
+		 SET revoked_at = $1, revoked_by = $2
+		 WHERE id = $3 AND revoked_at IS NULL`,
+		at, userID, id,
+	)
+	return err
+}
+
+func (r *advisorDelegationPostgresRepo) ActiveFor(toLecturerID string, at time.Time) ([]model.AdvisorDelegation, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+delegationColumns+`
+		 FROM advisor_delegations
+		 WHERE to_lecturer_id::text = $1
+		   AND revoked_at IS NULL
+		   AND starts_at <= $2 AND ends_at > $2
+		 ORDER BY starts_at`,
+		toLecturerID, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDelegations(rows)
+}
+
+func (r *advisorDelegationPostgresRepo) Overlapping(fromLecturerID string, startsAt, endsAt time.Time) ([]model.AdvisorDelegation, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+delegationColumns+`
+		 FROM advisor_delegations
+		 WHERE from_lecturer_id::text = $1
+		   AND revoked_at IS NULL
+		   AND starts_at < $3 AND ends_at > $2`,
+		fromLecturerID, startsAt, endsAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDelegations(rows)
+}
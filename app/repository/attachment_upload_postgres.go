@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AttachmentUploadPostgresRepository interface {
+	Create(u *model.AttachmentUpload) error
+	Get(id string) (*model.AttachmentUpload, error)
+	// AppendChunk mencatat potongan baru hanya bila offset masih sama
+	// (false = sudah didahului request lain).
+	AppendChunk(id string, offset, newOffset int64, key string, expiresAt time.Time) (bool, error)
+	Delete(id string) error
+	ListExpired(before time.Time) ([]model.AttachmentUpload, error)
+}
+
+type attachmentUploadPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAttachmentUploadPostgresRepository() AttachmentUploadPostgresRepository {
+	return &attachmentUploadPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+const uploadColumns = `id, reference_id, uploaded_by, file_name, kind, length,
+	upload_offset, chunk_keys, created_at, expires_at`
+
+func scanUpload(row interface{ Scan(...interface{}) error }) (*model.AttachmentUpload, error) {
+	var u model.AttachmentUpload
+	err := row.Scan(
+		&u.ID, &u.ReferenceID, &u.UploadedBy, &u.FileName, &u.Kind, &u.Length,
+		&u.Offset, &u.ChunkKeys, &u.CreatedAt, &u.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *attachmentUploadPostgresRepo) Create(u *model.AttachmentUpload) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO attachment_uploads
+		 (id, reference_id, uploaded_by, file_name, kind, length, created_at, expires_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		u.ID, u.ReferenceID, u.UploadedBy, u.FileName, u.Kind, u.Length, u.CreatedAt, u.ExpiresAt,
+	)
+	return err
+}
+
+func (r *attachmentUploadPostgresRepo) Get(id string) (*model.AttachmentUpload, error) {
+	u, err := scanUpload(r.pool.QueryRow(context.Background(),
+		`SELECT `+uploadColumns+` FROM attachment_uploads WHERE id::text = $1`,
+		id,
+	))
+	if err != nil {
+		return nil, errors.New("upload not found")
+	}
+	return u, nil
+}
+
+func (r *attachmentUploadPostgresRepo) AppendChunk(id string, offset, newOffset int64, key string, expiresAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		`UPDATE attachment_uploads
+		 SET upload_offset = $3, chunk_keys = array_append(chunk_keys, $4), expires_at = $5
+		 WHERE id::text = $1 AND upload_offset = $2`,
+		id, offset, newOffset, key, expiresAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *attachmentUploadPostgresRepo) Delete(id string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`DELETE FROM attachment_uploads WHERE id::text = $1`,
+		id,
+	)
+	return err
+}
+
+func (r *attachmentUploadPostgresRepo) ListExpired(before time.Time) ([]model.AttachmentUpload, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+uploadColumns+` FROM attachment_uploads WHERE expires_at < $1`,
+		before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.AttachmentUpload
+	for rows.Next() {
+		u, err := scanUpload(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *u)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CommitteePostgresRepository interface {
+	// List: activeOnly=true hanya komite aktif, urut waktu dibuat.
+	List(activeOnly bool) ([]model.Committee, error)
+	Get(id string) (*model.Committee, error)
+	Create(c *model.Committee) error
+	Update(c *model.Committee) error
+	CastVote(v *model.CommitteeVote) error
+	Votes(refID string) ([]model.CommitteeVote, error)
+}
+
+type committeePostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewCommitteePostgresRepository() CommitteePostgresRepository {
+	return &committeePostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+const committeeColumns = `id, name, applies_to, members::text[], quorum, majority,
+	active, created_by, created_at, updated_at`
+
+func scanCommittee(row interface{ Scan(...interface{}) error }) (*model.Committee, error) {
+	var c model.Committee
+	var appliesTo []byte
+	err := row.Scan(
+		&c.ID, &c.Name, &appliesTo, &c.Members, &c.Quorum, &c.Majority,
+		&c.Active, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(appliesTo, &c.AppliesTo); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *committeePostgresRepo) List(activeOnly bool) ([]model.Committee, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+committeeColumns+`
+		 FROM committees
+		 WHERE active OR NOT $1
+		 ORDER BY created_at ASC`,
+		activeOnly,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Committee
+	for rows.Next() {
+		c, err := scanCommittee(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *c)
+	}
+	return list, nil
+}
+
+func (r *committeePostgresRepo) Get(id string) (*model.Committee, error) {
+	c, err := scanCommittee(r.pool.QueryRow(context.Background(),
+		`SELECT `+committeeColumns+` FROM committees WHERE id::text = $1`,
+		id,
+	))
+	if err != nil {
+		return nil, errors.New("committee not found")
+	}
+	return c, nil
+}
+
+func (r *committeePostgresRepo) Create(c *model.Committee) error {
+	appliesTo, err := json.Marshal(c.AppliesTo)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(),
+		`INSERT INTO committees
+		 (id, name, applies_to, members, quorum, majority, active, created_by, created_at, updated_at)
+		 VALUES ($1,$2,$3,$4::uuid[],$5,$6,$7,$8,$9,$10)`,
+		c.ID, c.Name, appliesTo, c.Members, c.Quorum, c.Majority, c.Active, c.CreatedBy, c.CreatedAt, c.UpdatedAt,
+	)
+	return err
+}
+
+func (r *committeePostgresRepo) Update(c *model.Committee) error {
+	appliesTo, err := json.Marshal(c.AppliesTo)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(context.Background(),
+		`UPDATE committees
+		 SET name=$2, applies_to=$3, members=$4::uuid[], quorum=$5, majority=$6, active=$7, updated_at=$8
+		 WHERE id=$1`,
+		c.ID, c.Name, appliesTo, c.Members, c.Quorum, c.Majority, c.Active, c.UpdatedAt,
+	)
+	return err
+}
+
+func (r *committeePostgresRepo) CastVote(v *model.CommitteeVote) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO committee_votes
+		 (reference_id, committee_id, voter_id, voter_role, vote, comment, voted_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)
+		 ON CONFLICT (reference_id, voter_id) DO UPDATE
+		 SET committee_id = EXCLUDED.committee_id,
+		     voter_role   = EXCLUDED.voter_role,
+		     vote         = EXCLUDED.vote,
+		     comment      = EXCLUDED.comment,
+		     voted_at     = EXCLUDED.voted_at`,
+		v.ReferenceID, v.CommitteeID, v.VoterID, v.VoterRole, v.Vote, v.Comment, v.VotedAt,
+	)
+	return err
+}
+
+func (r *committeePostgresRepo) Votes(refID string) ([]model.CommitteeVote, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT reference_id, committee_id, voter_id, voter_role, vote, comment, voted_at
+		 FROM committee_votes
+		 WHERE reference_id::text = $1
+		 ORDER BY voted_at ASC`,
+		refID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.CommitteeVote
+	for rows.Next() {
+		var v model.CommitteeVote
+		if err := rows.Scan(&v.ReferenceID, &v.CommitteeID, &v.VoterID, &v.VoterRole, &v.Vote, &v.Comment, &v.VotedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OrganizerVerificationPostgresRepository interface {
+	Create(v *model.OrganizerVerification) error
+	Get(id string) (*model.OrganizerVerification, error)
+	ListByReference(refID string) ([]model.OrganizerVerification, error)
+	SetStatus(id, status string) error
+	// Respond hanya berhasil sekali, untuk permintaan pending yang belum kedaluwarsa.
+	Respond(id string, r model.OrganizerResponse) (bool, error)
+}
+
+type organizerVerificationPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewOrganizerVerificationPostgresRepository() OrganizerVerificationPostgresRepository {
+	return &organizerVerificationPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+const organizerVerificationColumns = `id, reference_id, organizer_email, organizer_name, claimed_rank,
+	requested_by, status, expires_at, created_at, responded_at, confirmed_rank,
+	response_note, responder_name, responder_ip`
+
+func scanOrganizerVerification(row interface{ Scan(...interface{}) error }) (*model.OrganizerVerification, error) {
+	var v model.OrganizerVerification
+	err := row.Scan(
+		&v.ID, &v.ReferenceID, &v.OrganizerEmail, &v.OrganizerName, &v.ClaimedRank,
+		&v.RequestedBy, &v.Status, &v.ExpiresAt, &v.CreatedAt, &v.RespondedAt, &v.ConfirmedRank,
+		&v.ResponseNote, &v.ResponderName, &v.ResponderIP,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *organizerVerificationPostgresRepo) Create(v *model.OrganizerVerification) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO organizer_verifications
+		 (id, reference_id, organizer_email, organizer_name, claimed_rank, requested_by, status, expires_at, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`,
+		v.ID, v.ReferenceID, v.OrganizerEmail, v.OrganizerName, v.ClaimedRank, v.RequestedBy, v.Status, v.ExpiresAt, v.CreatedAt,
+	)
+	return err
+}
+
+func (r *organizerVerificationPostgresRepo) Get(id string) (*model.OrganizerVerification, error) {
+	v, err := scanOrganizerVerification(r.pool.QueryRow(context.Background(),
+		`SELECT `+organizerVerificationColumns+` FROM organizer_verifications WHERE id::text = $1`,
+		id,
+	))
+	if err != nil {
+		return nil, errors.New("organizer verification not found")
+	}
+	return v, nil
+}
+
+func (r *organizerVerificationPostgresRepo) ListByReference(refID string) ([]model.OrganizerVerification, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+organizerVerificationColumns+`
+		 FROM organizer_verifications
+		 WHERE reference_id::text = $1
+		 ORDER BY created_at DESC`,
+		refID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.OrganizerVerification
+	for rows.Next() {
+		v, err := scanOrganizerVerification(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *v)
+	}
+	return list, nil
+}
+
+func (r *organizerVerificationPostgresRepo) SetStatus(id, status string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE organizer_verifications SET status = $2 WHERE id = $1`,
+		id, status,
+	)
+	return err
+}
+
+func (r *organizerVerificationPostgresRepo) Respond(id string, resp model.OrganizerResponse) (bool, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		`UPDATE organizer_verifications
+		 SET status = $2, confirmed_rank = $3, response_note = $4,
+		     responder_name = $5, responder_ip = $6, responded_at = $7
+		 WHERE id::text = $1 AND status = 'pending' AND expires_at > $7`,
+		id, resp.Status, resp.ConfirmedRank, resp.Note, resp.ResponderName, resp.ResponderIP, resp.RespondedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrNoActiveRuleSet: belum ada rule set aktif (bukan kegagalan database).
+var ErrNoActiveRuleSet = errors.New("no active rule set")
+
+type PointRulePostgresRepository interface {
+	ListRuleSets() ([]model.PointRuleSet, error)
+	GetRuleSet(id string) (*model.PointRuleSet, error)
+	GetActiveRuleSet() (*model.PointRuleSet, error)
+	NextVersion() (int, error)
+	CreateRuleSet(rs *model.PointRuleSet) error
+	UpdateRuleSet(rs *model.PointRuleSet) error
+	// ActivateRuleSet mengaktifkan rule set sekaligus mencatat job hitung
+	// ulangnya; bila salah satu gagal, keduanya dibatalkan.
+	ActivateRuleSet(id string, run *model.PointRecalculationRun) error
+
+	FinishRun(run *model.PointRecalculationRun) error
+	GetRun(id string) (*model.PointRecalculationRun, error)
+	InsertAudit(a *model.PointRecalculationAudit) error
+	ListAudit(runID string) ([]model.PointRecalculationAudit, error)
+}
+
+type pointRulePostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPointRulePostgresRepository() PointRulePostgresRepository {
+	return &pointRulePostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+// ======================================================
+// RULE SETS
+// ======================================================
+
+const ruleSetColumns = `id, name, version, status, rules, max_points,
+	created_by, created_at, activated_at`
+
+func scanRuleSet(row interface{ Scan(dest ...any) error }) (*model.PointRuleSet, error) {
+	var rs model.PointRuleSet
+	var rules []byte
+
+	if err := row.Scan(
+		&rs.ID, &rs.Name, &rs.Version, &rs.Status, &rules, &rs.MaxPoints,
+		&rs.CreatedBy, &rs.CreatedAt, &rs.ActivatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &rs.Rules); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+func (r *pointRulePostgresRepo) ListRuleSets() ([]model.PointRuleSet, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT `+ruleSetColumns+` FROM point_rule_sets ORDER BY version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PointRuleSet
+	for rows.Next() {
+		rs, err := scanRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *rs)
+	}
+	return list, nil
+}
+
+func (r *pointRulePostgresRepo) GetRuleSet(id string) (*model.PointRuleSet, error) {
+	rs, err := scanRuleSet(r.pool.QueryRow(context.Background(),
+		`SELECT `+ruleSetColumns+` FROM point_rule_sets WHERE id = $1`, id))
+	if err != nil {
+		return nil, errors.New("rule set not found")
+	}
+	return rs, nil
+}
+
+func (r *pointRulePostgresRepo) GetActiveRuleSet() (*model.PointRuleSet, error) {
+	rs, err := scanRuleSet(r.pool.QueryRow(context.Background(),
+		`SELECT `+ruleSetColumns+` FROM point_rule_sets WHERE status = 'active' LIMIT 1`))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoActiveRuleSet
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (r *pointRulePostgresRepo) NextVersion() (int, error) {
+	var v int
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT COALESCE(MAX(version), 0) + 1 FROM point_rule_sets`,
+	).Scan(&v)
+	return v, err
+}
+
+func (r *pointRulePostgresRepo) CreateRuleSet(rs *model.PointRuleSet) error {
+	rules, err := json.Marshal(rs.Rules)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(context.Background(),
+		`INSERT INTO point_rule_sets
+		 (id, name, version, status, rules, max_points, created_by, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+		rs.ID, rs.Name, rs.Version, rs.Status, rules, rs.MaxPoints, rs.CreatedBy, rs.CreatedAt,
+	)
+	return err
+}
+
+// UpdateRuleSet hanya mengubah rule set yang masih draft.
+func (r *pointRulePostgresRepo) UpdateRuleSet(rs *model.PointRuleSet) error {
+	rules, err := json.Marshal(rs.Rules)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(context.Background(),
+		`UPDATE point_rule_sets
+		 SET name=$1, rules=$2, max_points=$3
+		 WHERE id=$4 AND status='draft'`,
+		rs.Name, rules, rs.MaxPoints, rs.ID,
+	)
+	return err
+}
+
+// ActivateRuleSet mengarsipkan rule set aktif lama, mengaktifkan yang baru,
+// dan membuat run hitung ulang dalam satu transaksi.
+func (r *pointRulePostgresRepo) ActivateRuleSet(id string, run *model.PointRecalculationRun) error {
+	ctx := context.Background()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE point_rule_sets SET status='archived' WHERE status='active'`,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE point_rule_sets SET status='active', activated_at=NOW() WHERE id=$1`,
+		id,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO point_recalculation_runs
+		 (id, rule_set_id, status, total, changed, triggered_by, started_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+		run.ID, run.RuleSetID, run.Status, run.Total, run.Changed, run.TriggeredBy, run.StartedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ======================================================
+// RECALCULATION RUNS
+// ======================================================
+
+func (r *pointRulePostgresRepo) FinishRun(run *model.PointRecalculationRun) error {
+	_, err := r.pool.Exec(context.Background(),
+		`UPDATE point_recalculation_runs
+		 SET status=$1, total=$2, changed=$3, error=$4, finished_at=$5
+		 WHERE id=$6`,
+		run.Status, run.Total, run.Changed, run.Error, run.FinishedAt, run.ID,
+	)
+	return err
+}
+
+func (r *pointRulePostgresRepo) GetRun(id string) (*model.PointRecalculationRun, error) {
+	var run model.PointRecalculationRun
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT id, rule_set_id, status, total, changed, error, triggered_by, started_at, finished_at
+		 FROM point_recalculation_runs WHERE id = $1`,
+		id,
+	).Scan(
+		&run.ID, &run.RuleSetID, &run.Status, &run.Total, &run.Changed,
+		&run.Error, &run.TriggeredBy, &run.StartedAt, &run.FinishedAt,
+	)
+	if err != nil {
+		return nil, errors.New("recalculation run not found")
+	}
+	return &run, nil
+}
+
+func (r *pointRulePostgresRepo) InsertAudit(a *model.PointRecalculationAudit) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO point_recalculation_audit
+		 (run_id, reference_id, old_points, new_points, created_at)
+		 VALUES ($1,$2,$3,$4,$5)`,
+		a.RunID, a.ReferenceID, a.OldPoints, a.NewPoints, a.CreatedAt,
+	)
+	return err
+}
+
+func (r *pointRulePostgresRepo) ListAudit(runID string) ([]model.PointRecalculationAudit, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT run_id, reference_id, old_points, new_points, created_at
+		 FROM point_recalculation_audit
+		 WHERE run_id = $1
+		 ORDER BY created_at`,
+		runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PointRecalculationAudit
+	for rows.Next() {
+		var a model.PointRecalculationAudit
+		if err := rows.Scan(&a.RunID, &a.ReferenceID, &a.OldPoints, &a.NewPoints, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, nil
+}
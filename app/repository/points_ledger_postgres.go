@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PointsLedgerPostgresRepository interface {
+	Append(e *model.PointsLedgerEntry) error
+	ListByStudent(studentID, period string) ([]model.PointsLedgerEntry, error)
+	// ReferenceNets: poin yang saat ini tercatat untuk satu prestasi, per mahasiswa
+	ReferenceNets(referenceID string) (map[string]int, error)
+	// Balances: saldo per mahasiswa (period kosong = semua periode)
+	Balances(period string) ([]model.PointsBalance, error)
+	// StudentBalances: saldo satu mahasiswa per periode
+	StudentBalances(studentID string) ([]model.PointsBalance, error)
+}
+
+type pointsLedgerPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPointsLedgerPostgresRepository() PointsLedgerPostgresRepository {
+	return &pointsLedgerPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *pointsLedgerPostgresRepo) Append(e *model.PointsLedgerEntry) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO points_ledger
+		 (id, student_id, reference_id, entry_type, points, period, reason, actor_id, actor_role, created_at)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		e.ID, e.StudentID, e.ReferenceID, e.EntryType, e.Points, e.Period,
+		e.Reason, e.ActorID, e.ActorRole, e.CreatedAt,
+	)
+	return err
+}
+
+func (r *pointsLedgerPostgresRepo) ListByStudent(studentID, period string) ([]model.PointsLedgerEntry, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT id, student_id, reference_id, entry_type, points, period, reason, actor_id, actor_role, created_at
+		 FROM points_ledger
+		 WHERE student_id = $1 AND ($2 = '' OR period = $2)
+		 ORDER BY created_at`,
+		studentID, period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PointsLedgerEntry
+	for rows.Next() {
+		var e model.PointsLedgerEntry
+		if err := rows.Scan(
+			&e.ID, &e.StudentID, &e.ReferenceID, &e.EntryType, &e.Points, &e.Period,
+			&e.Reason, &e.ActorID, &e.ActorRole, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+func (r *pointsLedgerPostgresRepo) ReferenceNets(referenceID string) (map[string]int, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT student_id, SUM(points)
+		 FROM points_ledger
+		 WHERE reference_id = $1
+		 GROUP BY student_id`,
+		referenceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nets := map[string]int{}
+	for rows.Next() {
+		var studentID string
+		var points int
+		if err := rows.Scan(&studentID, &points); err != nil {
+			return nil, err
+		}
+		nets[studentID] = points
+	}
+	return nets, nil
+}
+
+func (r *pointsLedgerPostgresRepo) Balances(period string) ([]model.PointsBalance, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT student_id, SUM(points)
+		 FROM points_ledger
+		 WHERE $1 = '' OR period = $1
+		 GROUP BY student_id
+		 ORDER BY SUM(points) DESC`,
+		period)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PointsBalance
+	for rows.Next() {
+		b := model.PointsBalance{Period: period}
+		if err := rows.Scan(&b.StudentID, &b.Points); err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return list, nil
+}
+
+func (r *pointsLedgerPostgresRepo) StudentBalances(studentID string) ([]model.PointsBalance, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT period, SUM(points)
+		 FROM points_ledger
+		 WHERE student_id = $1
+		 GROUP BY period
+		 ORDER BY period`,
+		studentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.PointsBalance
+	for rows.Next() {
+		b := model.PointsBalance{StudentID: studentID}
+		if err := rows.Scan(&b.Period, &b.Points); err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return list, nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrClaimNotFound: prestasi belum pernah diklaim.
+var ErrClaimNotFound = errors.New("review claim not found")
+
+type ReviewClaimPostgresRepository interface {
+	Get(refID string) (*model.ReviewClaim, error)
+	// Claim mengambil klaim bila belum ada, sudah kedaluwarsa, atau milik
+	// reviewer yang sama (perpanjangan). false berarti klaim aktif milik orang lain.
+	Claim(claim *model.ReviewClaim) (bool, error)
+	// Assign menimpa klaim apa pun (penugasan oleh admin).
+	Assign(claim *model.ReviewClaim) error
+	Release(refID string) error
+	Queue(filter model.ReviewQueueFilter, now time.Time) ([]model.ReviewQueueItem, error)
+	GetReviewer(userID string) (*model.Reviewer, error)
+}
+
+type reviewClaimPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewReviewClaimPostgresRepository() ReviewClaimPostgresRepository {
+	return &reviewClaimPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *reviewClaimPostgresRepo) Get(refID string) (*model.ReviewClaim, error) {
+	var c model.ReviewClaim
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT reference_id, reviewer_id, reviewer_role, assigned_by::text, claimed_at, expires_at
+		 FROM review_claims
+		 WHERE reference_id = $1`,
+		refID,
+	).Scan(&c.ReferenceID, &c.ReviewerID, &c.ReviewerRole, &c.AssignedBy, &c.ClaimedAt, &c.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrClaimNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *reviewClaimPostgresRepo) Claim(c *model.ReviewClaim) (bool, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		`INSERT INTO review_claims (reference_id, reviewer_id, reviewer_role, assigned_by, claimed_at, expires_at)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (reference_id) DO UPDATE
+		 SET reviewer_id   = EXCLUDED.reviewer_id,
+		     reviewer_role = EXCLUDED.reviewer_role,
+		     assigned_by   = CASE WHEN review_claims.reviewer_id = EXCLUDED.reviewer_id
+		                          THEN review_claims.assigned_by END,
+		     claimed_at    = EXCLUDED.claimed_at,
+		     expires_at    = EXCLUDED.expires_at
+		 WHERE review_claims.expires_at <= EXCLUDED.claimed_at
+		    OR review_claims.reviewer_id = EXCLUDED.reviewer_id`,
+		c.ReferenceID, c.ReviewerID, c.ReviewerRole, c.AssignedBy, c.ClaimedAt, c.ExpiresAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *reviewClaimPostgresRepo) Assign(c *model.ReviewClaim) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO review_claims (reference_id, reviewer_id, reviewer_role, assigned_by, claimed_at, expires_at)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (reference_id) DO UPDATE
+		 SET reviewer_id   = EXCLUDED.reviewer_id,
+		     reviewer_role = EXCLUDED.reviewer_role,
+		     assigned_by   = EXCLUDED.assigned_by,
+		     claimed_at    = EXCLUDED.claimed_at,
+		     expires_at    = EXCLUDED.expires_at`,
+		c.ReferenceID, c.ReviewerID, c.ReviewerRole, c.AssignedBy, c.ClaimedAt, c.ExpiresAt,
+	)
+	return err
+}
+
+func (r *reviewClaimPostgresRepo) Release(refID string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`DELETE FROM review_claims WHERE reference_id = $1`,
+		refID,
+	)
+	return err
+}
+
+func (r *reviewClaimPostgresRepo) Queue(f model.ReviewQueueFilter, now time.Time) ([]model.ReviewQueueItem, error) {
+	where := []string{"ar.status = 'submitted'"}
+	var args []interface{}
+
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if f.StudentIDs != nil {
+		p := arg(f.StudentIDs)
+		// pemilik atau anggota tim confirmed
+		where = append(where, `(ar.student_id::text = ANY(`+p+`) OR EXISTS (
+			SELECT 1 FROM achievement_team_members tm
+			WHERE tm.reference_id = ar.id AND tm.status = 'confirmed'
+			  AND tm.student_id::text = ANY(`+p+`)))`)
+	}
+	if f.StudentID != "" {
+		where = append(where, "ar.student_id::text = "+arg(f.StudentID))
+	}
+	// now hanya diikat bila dipakai: parameter tanpa referensi membuat
+	// Postgres gagal menentukan tipenya
+	switch f.Claim {
+	case "unclaimed":
+		where = append(where, "(rc.reference_id IS NULL OR rc.expires_at <= "+arg(now)+")")
+	case "claimed":
+		where = append(where, "rc.expires_at > "+arg(now))
+	case "mine":
+		where = append(where, "rc.expires_at > "+arg(now)+" AND rc.reviewer_id::text = "+arg(f.ReviewerID))
+	}
+	if f.SubmittedFrom != nil {
+		where = append(where, "ar.submitted_at >= "+arg(*f.SubmittedFrom))
+	}
+	if f.SubmittedTo != nil {
+		where = append(where, "ar.submitted_at < "+arg(*f.SubmittedTo))
+	}
+
+	query := `SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+	                 ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+	                 ar.created_at, ar.updated_at,
+	                 rc.reviewer_id::text, rc.reviewer_role, rc.assigned_by::text, rc.claimed_at, rc.expires_at
+	          FROM achievement_references ar
+	          LEFT JOIN review_claims rc ON rc.reference_id = ar.id
+	          WHERE ` + strings.Join(where, " AND ") + `
+	          ORDER BY ar.submitted_at ASC NULLS LAST, ar.created_at ASC
+	          LIMIT ` + arg(f.Limit) + ` OFFSET ` + arg(f.Offset)
+
+	rows, err := r.pool.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.ReviewQueueItem
+	for rows.Next() {
+		var item model.ReviewQueueItem
+		var reviewerID, reviewerRole *string
+		var assignedBy *string
+		var claimedAt, expiresAt *time.Time
+
+		ref := &item.Reference
+		if err := rows.Scan(
+			&ref.ID, &ref.StudentID, &ref.MongoID, &ref.Status,
+			&ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy, &ref.RejectionNote,
+			&ref.CreatedAt, &ref.UpdatedAt,
+			&reviewerID, &reviewerRole, &assignedBy, &claimedAt, &expiresAt,
+		); err != nil {
+			return nil, err
+		}
+
+		// klaim kedaluwarsa tidak ditampilkan
+		if reviewerID != nil && expiresAt.After(now) {
+			item.Claim = &model.ReviewClaim{
+				ReferenceID:  ref.ID,
+				ReviewerID:   *reviewerID,
+				ReviewerRole: *reviewerRole,
+				AssignedBy:   assignedBy,
+				ClaimedAt:    *claimedAt,
+				ExpiresAt:    *expiresAt,
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *reviewClaimPostgresRepo) GetReviewer(userID string) (*model.Reviewer, error) {
+	var rv model.Reviewer
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT u.id, ro.name, COALESCE(l.id::text, '')
+		 FROM users u
+		 JOIN roles ro ON ro.id = u.role_id
+		 LEFT JOIN lecturers l ON l.user_id = u.id
+		 WHERE u.id::text = $1 AND u.is_active`,
+		userID,
+	).Scan(&rv.UserID, &rv.Role, &rv.LecturerID)
+	if err != nil {
+		return nil, errors.New("reviewer not found")
+	}
+	return &rv, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrRequirementNotFound: admin belum mengatur syarat untuk tipe ini.
+var ErrRequirementNotFound = errors.New("submission requirement not found")
+
+type SubmissionRequirementPostgresRepository interface {
+	List() ([]model.SubmissionRequirement, error)
+	Get(typeCode string) (*model.SubmissionRequirement, error)
+	Upsert(req *model.SubmissionRequirement) error
+	Delete(typeCode string) error
+}
+
+type submissionRequirementPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewSubmissionRequirementPostgresRepository() SubmissionRequirementPostgresRepository {
+	return &submissionRequirementPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *submissionRequirementPostgresRepo) List() ([]model.SubmissionRequirement, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT type_code, min_attachments, required_kinds, required_fields,
+		        COALESCE(updated_by::text, ''), updated_at
+		 FROM submission_requirements
+		 ORDER BY type_code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.SubmissionRequirement
+	for rows.Next() {
+		var req model.SubmissionRequirement
+		if err := rows.Scan(
+			&req.TypeCode, &req.MinAttachments, &req.RequiredKinds, &req.RequiredFields,
+			&req.UpdatedBy, &req.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, req)
+	}
+	return list, nil
+}
+
+func (r *submissionRequirementPostgresRepo) Get(typeCode string) (*model.SubmissionRequirement, error) {
+	var req model.SubmissionRequirement
+
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT type_code, min_attachments, required_kinds, required_fields,
+		        COALESCE(updated_by::text, ''), updated_at
+		 FROM submission_requirements
+		 WHERE type_code = $1`,
+		typeCode,
+	).Scan(
+		&req.TypeCode, &req.MinAttachments, &req.RequiredKinds, &req.RequiredFields,
+		&req.UpdatedBy, &req.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRequirementNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func (r *submissionRequirementPostgresRepo) Upsert(req *model.SubmissionRequirement) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO submission_requirements
+		 (type_code, min_attachments, required_kinds, required_fields, updated_by, updated_at)
+		 VALUES ($1,$2,$3,$4,NULLIF($5,'')::uuid,$6)
+		 ON CONFLICT (type_code) DO UPDATE
+		 SET min_attachments = EXCLUDED.min_attachments,
+		     required_kinds  = EXCLUDED.required_kinds,
+		     required_fields = EXCLUDED.required_fields,
+		     updated_by      = EXCLUDED.updated_by,
+		     updated_at      = EXCLUDED.updated_at`,
+		req.TypeCode, req.MinAttachments, req.RequiredKinds, req.RequiredFields, req.UpdatedBy, req.UpdatedAt,
+	)
+	return err
+}
+
+func (r *submissionRequirementPostgresRepo) Delete(typeCode string) error {
+	_, err := r.pool.Exec(context.Background(),
+		`DELETE FROM submission_requirements WHERE type_code = $1`,
+		typeCode,
+	)
+	return err
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type VerificationCodePostgresRepository interface {
+	// Create gagal bila prestasi sudah punya kode aktif (unique index).
+	Create(v *model.VerificationCode) error
+	// GetLatestByReference: kode terbaru (aktif atau dicabut).
+	GetLatestByReference(refID string) (*model.VerificationCode, error)
+	// GetSummary: kode beserta data publik prestasinya.
+	GetSummary(code string) (*model.VerificationSummary, error)
+	// RevokeByReference mencabut kode aktif; false bila tidak ada.
+	RevokeByReference(refID, userID, reason string, at time.Time) (bool, error)
+}
+
+type verificationCodePostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewVerificationCodePostgresRepository() VerificationCodePostgresRepository {
+	return &verificationCodePostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *verificationCodePostgresRepo) Create(v *model.VerificationCode) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO achievement_verification_codes (code, reference_id, issued_at)
+		 VALUES ($1, $2, $3)`,
+		v.Code, v.ReferenceID, v.IssuedAt,
+	)
+	return err
+}
+
+func (r *verificationCodePostgresRepo) GetLatestByReference(refID string) (*model.VerificationCode, error) {
+	var v model.VerificationCode
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT code, reference_id::text, issued_at, revoked_at, revoked_by::text, revoke_reason
+		 FROM achievement_verification_codes
+		 WHERE reference_id::text = $1
+		 ORDER BY (revoked_at IS NULL) DESC, issued_at DESC
+		 LIMIT 1`,
+		refID,
+	).Scan(&v.Code, &v.ReferenceID, &v.IssuedAt, &v.RevokedAt, &v.RevokedBy, &v.RevokeReason)
+	if err != nil {
+		return nil, errors.New("verification code not found")
+	}
+	return &v, nil
+}
+
+// GetSummary: unit verifikator = departemen dosen bila verifikatornya dosen,
+// selain itu nama role-nya (mis. Admin).
+func (r *verificationCodePostgresRepo) GetSummary(code string) (*model.VerificationSummary, error) {
+	var s model.VerificationSummary
+	err := r.pool.QueryRow(context.Background(),
+		`SELECT vc.code, vc.reference_id::text, vc.issued_at, vc.revoked_at, vc.revoked_by::text, vc.revoke_reason,
+		        ar.mongo_achievement_id, ar.status, u.full_name, ar.verified_at,
+		        COALESCE(NULLIF(l.department, ''), ro.name, '')
+		 FROM achievement_verification_codes vc
+		 JOIN achievement_references ar ON ar.id = vc.reference_id
+		 JOIN students st ON st.id = ar.student_id
+		 JOIN users u ON u.id = st.user_id
+		 LEFT JOIN users vu ON vu.id = ar.verified_by
+		 LEFT JOIN roles ro ON ro.id = vu.role_id
+		 LEFT JOIN lecturers l ON l.user_id = ar.verified_by
+		 WHERE vc.code = $1`,
+		code,
+	).Scan(
+		&s.Code, &s.ReferenceID, &s.IssuedAt, &s.RevokedAt, &s.RevokedBy, &s.RevokeReason,
+		&s.MongoID, &s.RefStatus, &s.StudentName, &s.VerifiedAt, &s.VerifierUnit,
+	)
+	if err != nil {
+		return nil, errors.New("verification code not found")
+	}
+	return &s, nil
+}
+
+func (r *verificationCodePostgresRepo) RevokeByReference(refID, userID, reason string, at time.Time) (bool, error) {
+	tag, err := r.pool.Exec(context.Background(),
+		`UPDATE achievement_verification_codes
+		 SET revoked_at = $1, revoked_by = $2, revoke_reason = $3
+		 WHERE reference_id::text = $4 AND revoked_at IS NULL`,
+		at, userID, reason, refID,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
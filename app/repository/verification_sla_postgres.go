@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type VerificationSLAPostgresRepository interface {
+	// Overdue: prestasi submitted dengan submitted_at <= before, beserta
+	// user dosen wali pemilik dan jejak pengingat/eskalasi.
+	Overdue(before time.Time) ([]model.OverdueReference, error)
+	RecordEvent(e *model.VerificationSLAEvent) error
+	ListEvents(refID string) ([]model.VerificationSLAEvent, error)
+	AdminUserIDs() ([]string, error)
+}
+
+type verificationSLAPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewVerificationSLAPostgresRepository() VerificationSLAPostgresRepository {
+	return &verificationSLAPostgresRepo{
+		pool: database.Pg,
+	}
+}
+
+func (r *verificationSLAPostgresRepo) Overdue(before time.Time) ([]model.OverdueReference, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status,
+		        ar.submitted_at, ar.verified_at, ar.verified_by, ar.rejection_note,
+		        ar.created_at, ar.updated_at,
+		        COALESCE(l.user_id::text, ''),
+		        (SELECT MAX(e.created_at) FROM verification_sla_events e
+		          WHERE e.reference_id = ar.id AND e.kind = 'reminder'
+		            AND e.created_at >= ar.submitted_at),
+		        EXISTS (SELECT 1 FROM verification_sla_events e
+		          WHERE e.reference_id = ar.id AND e.kind = 'escalation'
+		            AND e.created_at >= ar.submitted_at)
+		 FROM achievement_references ar
+		 JOIN students s ON s.id = ar.student_id
+		 LEFT JOIN lecturers l ON l.id = s.advisor_id
+		 WHERE ar.status = 'submitted' AND ar.submitted_at <= $1
+		 ORDER BY ar.submitted_at ASC`,
+		before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.OverdueReference
+	for rows.Next() {
+		var o model.OverdueReference
+		ref := &o.Reference
+		if err := rows.Scan(
+			&ref.ID, &ref.StudentID, &ref.MongoID, &ref.Status,
+			&ref.SubmittedAt, &ref.VerifiedAt, &ref.VerifiedBy, &ref.RejectionNote,
+			&ref.CreatedAt, &ref.UpdatedAt,
+			&o.AdvisorUserID, &o.LastReminderAt, &o.Escalated,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, nil
+}
+
+func (r *verificationSLAPostgresRepo) RecordEvent(e *model.VerificationSLAEvent) error {
+	_, err := r.pool.Exec(context.Background(),
+		`INSERT INTO verification_sla_events (id, reference_id, kind, recipient_id, created_at)
+		 VALUES ($1,$2,$3,$4,$5)`,
+		e.ID, e.ReferenceID, e.Kind, e.RecipientID, e.CreatedAt,
+	)
+	return err
+}
+
+func (r *verificationSLAPostgresRepo) ListEvents(refID string) ([]model.VerificationSLAEvent, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT id, reference_id, kind, recipient_id, created_at
+		 FROM verification_sla_events
+		 WHERE reference_id = $1
+		 ORDER BY created_at`,
+		refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.VerificationSLAEvent
+	for rows.Next() {
+		var e model.VerificationSLAEvent
+		if err := rows.Scan(&e.ID, &e.ReferenceID, &e.Kind, &e.RecipientID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, nil
+}
+
+func (r *verificationSLAPostgresRepo) AdminUserIDs() ([]string, error) {
+	rows, err := r.pool.Query(context.Background(),
+		`SELECT u.id
+		 FROM users u
+		 JOIN roles ro ON ro.id = u.role_id
+		 WHERE ro.name = 'Admin' AND u.is_active
+		 ORDER BY u.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
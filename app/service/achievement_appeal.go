@@ -0,0 +1,250 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	minAppealJustification = 30
+	maxAppealJustification = 5000
+)
+
+type appealRequest struct {
+	Justification string   `json:"justification"`
+	Evidence      []string `json:"evidence"`
+}
+
+type appealDecisionRequest struct {
+	Decision string `json:"decision"` // overturn | uphold
+	Note     string `json:"note"`
+}
+
+// validateAppeal: alasan wajib dan minimal satu bukti baru, yaitu lampiran
+// prestasi yang diunggah setelah penolakan.
+func (s *AchievementService) validateAppeal(ref *model.AchievementReference, req *appealRequest) []FieldError {
+	var errs []FieldError
+
+	req.Justification = strings.TrimSpace(req.Justification)
+	switch n := len([]rune(req.Justification)); {
+	case n == 0:
+		errs = append(errs, FieldError{"justification", "wajib diisi"})
+	case n < minAppealJustification:
+		errs = append(errs, FieldError{"justification", "minimal " + strconv.Itoa(minAppealJustification) + " karakter"})
+	case n > maxAppealJustification:
+		errs = append(errs, FieldError{"justification", "maksimal " + strconv.Itoa(maxAppealJustification) + " karakter"})
+	}
+
+	if len(req.Evidence) == 0 {
+		errs = append(errs, FieldError{"evidence", "minimal satu lampiran bukti baru"})
+		return errs
+	}
+
+	uploaded := map[string]time.Time{}
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+		for _, a := range ach.Attachments {
+			uploaded[a.Ref()] = a.UploadedAt
+		}
+	}
+	for i, url := range req.Evidence {
+		at, ok := uploaded[url]
+		field := "evidence." + strconv.Itoa(i)
+		switch {
+		case !ok:
+			errs = append(errs, FieldError{field, "bukan lampiran prestasi ini"})
+		case ref.VerifiedAt != nil && !at.After(*ref.VerifiedAt):
+			errs = append(errs, FieldError{field, "harus diunggah setelah penolakan"})
+		}
+	}
+	return errs
+}
+
+// decideAppeal: inti keputusan banding. Pemutus tidak boleh reviewer yang
+// menolak. overturn → verified (poin masuk ledger), uphold → kembali rejected.
+func (s *AchievementService) decideAppeal(actor reviewActor, refID, decision, note string) (*model.AchievementAppeal, error) {
+	if s.AppealRepo == nil {
+		return nil, &reviewError{404, "appeals not available"}
+	}
+	ref, err := s.PostgresRepo.GetReferenceByID(refID)
+	if err != nil {
+		return nil, &reviewError{404, "reference tidak ditemukan"}
+	}
+	appeal, err := s.AppealRepo.GetByReference(ref.ID)
+	if err != nil {
+		return nil, &reviewError{404, "banding tidak ditemukan"}
+	}
+	if ref.Status != "appealed" || appeal.Status != model.AppealPending {
+		return nil, &reviewError{400, "banding sudah diputus"}
+	}
+	if appeal.RejectedBy != nil && *appeal.RejectedBy == actor.UserID {
+		return nil, &reviewError{403, "reviewer yang menolak tidak boleh memutus banding"}
+	}
+
+	status, newStatus := model.AppealUpheld, "rejected"
+	if decision == "overturn" {
+		status, newStatus = model.AppealOverturned, "verified"
+	}
+
+	now := time.Now()
+	ok, err := s.AppealRepo.Decide(appeal.ID, status, actor.UserID, note, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, &reviewError{409, "banding sudah diputus"}
+	}
+
+	// HISTORY: keputusan banding
+	s.saveHistory(ref.ID, ref.Status, newStatus, actor.UserID, actor.Role, "banding: "+note)
+
+	if newStatus == "verified" {
+		if err := s.PostgresRepo.UpdateVerifyStatus(ref.ID, actor.UserID); err != nil {
+			return nil, err
+		}
+		// LEDGER: poin prestasi masuk saldo mahasiswa
+		oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+		if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+			s.recordLedger(ref, ach, model.LedgerEarned, ach.Points, actor.UserID, actor.Role, "appeal overturned")
+		}
+		s.recordVerificationCode(ref.ID)
+	} else if err := s.PostgresRepo.UpdateReferenceStatusPostgres(ref.ID, "rejected"); err != nil {
+		return nil, err
+	}
+
+	appeal.Status = status
+	appeal.DecidedBy = &actor.UserID
+	appeal.DecisionNote = &note
+	appeal.DecidedAt = &now
+	return appeal, nil
+}
+
+// ======================================================
+// BANDING (Mahasiswa pemilik → Admin)
+// ======================================================
+
+// Appeal: rejected → appealed dengan alasan dan bukti baru.
+func (s *AchievementService) Appeal(c *fiber.Ctx) error {
+	if s.AppealRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "appeals not available"})
+	}
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+
+	studentID, _ := c.Locals("student_id").(string)
+	if ref.StudentID != studentID {
+		return c.Status(403).JSON(fiber.Map{"error": "not your achievement"})
+	}
+	if ref.Status != "rejected" {
+		return c.Status(400).JSON(fiber.Map{"error": "hanya prestasi rejected yang bisa dibanding"})
+	}
+	if _, err := s.AppealRepo.GetByReference(ref.ID); err == nil {
+		return c.Status(409).JSON(fiber.Map{"error": "banding sudah pernah diajukan"})
+	}
+
+	var req appealRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+	if errs := s.validateAppeal(ref, &req); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	appeal := &model.AchievementAppeal{
+		ID:            uuid.New().String(),
+		ReferenceID:   ref.ID,
+		StudentID:     ref.StudentID,
+		SubmittedBy:   userID,
+		Justification: req.Justification,
+		Evidence:      req.Evidence,
+		RejectedBy:    ref.VerifiedBy,
+		RejectionNote: ref.RejectionNote,
+		Status:        model.AppealPending,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.AppealRepo.Create(appeal); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// HISTORY: banding
+	s.saveHistory(ref.ID, ref.Status, "appealed", userID, "Mahasiswa", req.Justification)
+
+	if err := s.PostgresRepo.UpdateReferenceStatusPostgres(ref.ID, "appealed"); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(appeal)
+}
+
+// GetAppeal: banding satu prestasi, aturan visibilitas sama dengan Detail.
+func (s *AchievementService) GetAppeal(c *fiber.Ctx) error {
+	if s.AppealRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "appeals not available"})
+	}
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	if err := s.authorizeView(c, ref); err != nil {
+		return respondReviewError(c, err)
+	}
+
+	appeal, err := s.AppealRepo.GetByReference(ref.ID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "banding tidak ditemukan"})
+	}
+	return c.JSON(appeal)
+}
+
+// ListAppeals (Admin): ?status=pending|overturned|upheld
+func (s *AchievementService) ListAppeals(c *fiber.Ctx) error {
+	if s.AppealRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "appeals not available"})
+	}
+	status := c.Query("status")
+	if status != "" && !contains([]string{model.AppealPending, model.AppealOverturned, model.AppealUpheld}, status) {
+		return respondValidation(c, []FieldError{{"status", "harus salah satu dari: pending, overturned, upheld"}})
+	}
+
+	appeals, err := s.AppealRepo.List(status)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": appeals})
+}
+
+// DecideAppeal (Admin): {decision: overturn|uphold, note}
+func (s *AchievementService) DecideAppeal(c *fiber.Ctx) error {
+	var req appealDecisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	var errs []FieldError
+	if req.Decision != "overturn" && req.Decision != "uphold" {
+		errs = append(errs, FieldError{"decision", "harus salah satu dari: overturn, uphold"})
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" {
+		errs = append(errs, FieldError{"note", "wajib diisi"})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	appeal, err := s.decideAppeal(reviewActorFrom(c), c.Params("refId"), req.Decision, req.Note)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	return c.JSON(appeal)
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCKS =================
+
+type MockAppealRepo struct {
+	appeals []*model.AchievementAppeal
+}
+
+func (m *MockAppealRepo) Create(a *model.AchievementAppeal) error {
+	cp := *a
+	m.appeals = append(m.appeals, &cp)
+	return nil
+}
+func (m *MockAppealRepo) GetByReference(refID string) (*model.AchievementAppeal, error) {
+	for _, a := range m.appeals {
+		if a.ReferenceID == refID {
+			cp := *a
+			return &cp, nil
+		}
+	}
+	return nil, errors.New("appeal not found")
+}
+func (m *MockAppealRepo) List(status string) ([]model.AchievementAppeal, error) {
+	var list []model.AchievementAppeal
+	for _, a := range m.appeals {
+		if status == "" || a.Status == status {
+			list = append(list, *a)
+		}
+	}
+	return list, nil
+}
+func (m *MockAppealRepo) Decide(id, status, decidedBy, note string, at time.Time) (bool, error) {
+	for _, a := range m.appeals {
+		if a.ID == id && a.Status == model.AppealPending {
+			a.Status, a.DecidedBy, a.DecisionNote, a.DecidedAt = status, &decidedBy, &note, &at
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MockAppealPostgresRepo: satu reference (ref-1) yang ditolak lect-user-1.
+type MockAppealPostgresRepo struct {
+	MockHistoryPostgresRepo
+	ref model.AchievementReference
+}
+
+func newMockAppealPostgresRepo(rejectedAt time.Time) *MockAppealPostgresRepo {
+	rejecter, note := "lect-user-1", "sertifikat tidak terbaca"
+	return &MockAppealPostgresRepo{ref: model.AchievementReference{
+		ID:            "ref-1",
+		StudentID:     "student-1",
+		MongoID:       primitive.NewObjectID().Hex(),
+		Status:        "rejected",
+		VerifiedAt:    &rejectedAt,
+		VerifiedBy:    &rejecter,
+		RejectionNote: &note,
+	}}
+}
+
+func (m *MockAppealPostgresRepo) GetReferenceByID(id string) (*model.AchievementReference, error) {
+	if id != m.ref.ID {
+		return nil, errors.New("reference not found")
+	}
+	ref := m.ref
+	return &ref, nil
+}
+func (m *MockAppealPostgresRepo) UpdateReferenceStatusPostgres(id, status string) error {
+	m.ref.Status = status
+	return nil
+}
+func (m *MockAppealPostgresRepo) UpdateVerifyStatus(id, userID string) error {
+	m.ref.Status, m.ref.VerifiedBy = "verified", &userID
+	return nil
+}
+func (m *MockAppealPostgresRepo) RejectReference(id, userID, note string) error {
+	m.ref.Status, m.ref.VerifiedBy, m.ref.RejectionNote = "rejected", &userID, &note
+	return nil
+}
+func (m *MockAppealPostgresRepo) DecideSubmitted(id, status, userID, note string) (bool, error) {
+	if m.ref.Status != "submitted" {
+		return false, nil
+	}
+	m.ref.Status, m.ref.VerifiedBy = status, &userID
+	if status == "rejected" {
+		m.ref.RejectionNote = &note
+	}
+	return true, nil
+}
+
+// MockAppealMongoRepo: sertifikat lama + scan baru diunggah setelah penolakan.
+type MockAppealMongoRepo struct {
+	MockAchievementMongoRepo
+	rejectedAt time.Time
+}
+
+func (m *MockAppealMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	a, _ := m.MockAchievementMongoRepo.GetByID(id)
+	a.Attachments = []model.Attachment{
+		{FileURL: "/uploads/lama.pdf", Kind: "certificate", UploadedAt: m.rejectedAt.Add(-time.Hour)},
+		{FileURL: "/uploads/baru.pdf", Kind: "certificate", UploadedAt: m.rejectedAt.Add(time.Hour)},
+	}
+	return a, nil
+}
+
+func appealService() (*AchievementService, *MockAppealPostgresRepo, *MockAppealRepo) {
+	rejectedAt := time.Now().Add(-48 * time.Hour)
+	pg := newMockAppealPostgresRepo(rejectedAt)
+	appeals := &MockAppealRepo{}
+	return &AchievementService{
+		MongoRepo:    &MockAppealMongoRepo{rejectedAt: rejectedAt},
+		PostgresRepo: pg,
+		StudentRepo:  &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+		AppealRepo:   appeals,
+	}, pg, appeals
+}
+
+const validJustification = "Sertifikat asli sudah dipindai ulang dengan resolusi tinggi."
+
+// ================= UNIT TESTS =================
+
+func TestAppeal_Validation(t *testing.T) {
+	svc, _, _ := appealService()
+	app := fiber.New()
+	app.Post("/achievements/:refId/appeal", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-mhs")
+		return svc.Appeal(c)
+	})
+
+	resp, out := commentRequestAs(app, "POST", "/achievements/ref-1/appeal", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"justification": "salah", "evidence": []string{"/uploads/lama.pdf", "/uploads/x.pdf"}})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "justification", "message": "minimal 30 karakter"},
+		map[string]interface{}{"field": "evidence.0", "message": "harus diunggah setelah penolakan"},
+		map[string]interface{}{"field": "evidence.1", "message": "bukan lampiran prestasi ini"},
+	}, out["fields"])
+
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/appeal", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"justification": validJustification})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Len(t, out["fields"], 1)
+}
+
+func TestAppeal_OverturnedByAdmin(t *testing.T) {
+	svc, pg, appeals := appealService()
+	app := fiber.New()
+	app.Post("/achievements/:refId/appeal", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-mhs")
+		return svc.Appeal(c)
+	})
+	app.Post("/admin/achievements/:refId/appeal/decide", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("user_id", utils.CopyString(c.Get("X-User")))
+		return svc.DecideAppeal(c)
+	})
+
+	resp, out := commentRequestAs(app, "POST", "/achievements/ref-1/appeal", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"justification": validJustification, "evidence": []string{"/uploads/baru.pdf"}})
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "lect-user-1", out["rejected_by"])
+	assert.Equal(t, "appealed", pg.ref.Status)
+
+	// banding kedua ditolak
+	resp, _ = commentRequestAs(app, "POST", "/achievements/ref-1/appeal", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"justification": validJustification, "evidence": []string{"/uploads/baru.pdf"}})
+	assert.Equal(t, 400, resp.StatusCode)
+
+	decide := map[string]interface{}{"decision": "overturn", "note": "bukti baru valid"}
+
+	// penolak tidak boleh memutus bandingnya sendiri
+	resp, _ = commentRequestAs(app, "POST", "/admin/achievements/ref-1/appeal/decide", "Admin", "lect-user-1", decide)
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp, out = commentRequestAs(app, "POST", "/admin/achievements/ref-1/appeal/decide", "Admin", "admin-1", decide)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, model.AppealOverturned, out["status"])
+	assert.Equal(t, "verified", pg.ref.Status)
+	assert.Equal(t, model.AppealOverturned, appeals.appeals[0].Status)
+	assert.Equal(t, "admin-1", *appeals.appeals[0].DecidedBy)
+
+	resp, _ = commentRequestAs(app, "POST", "/admin/achievements/ref-1/appeal/decide", "Admin", "admin-1", decide)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	// history: rejected → appealed → verified
+	assert.Len(t, pg.history, 2)
+	assert.Equal(t, "appealed", pg.history[0].NewStatus)
+	assert.Equal(t, "verified", pg.history[1].NewStatus)
+	assert.Equal(t, "appealed", pg.history[1].OldStatus)
+}
+
+func TestAppeal_Upheld(t *testing.T) {
+	svc, pg, _ := appealService()
+	pg.ref.Status = "appealed"
+	svc.AppealRepo.Create(&model.AchievementAppeal{ID: "appeal-1", ReferenceID: "ref-1", RejectedBy: pg.ref.VerifiedBy, Status: model.AppealPending})
+
+	appeal, err := svc.decideAppeal(reviewActor{UserID: "admin-1", Role: "Admin"}, "ref-1", "uphold", "bukti tetap tidak cukup")
+	assert.NoError(t, err)
+	assert.Equal(t, model.AppealUpheld, appeal.Status)
+	assert.Equal(t, "rejected", pg.ref.Status)
+	assert.Equal(t, "lect-user-1", *pg.ref.VerifiedBy)
+}
+
+// appealUploadMongoRepo: lampiran yang diunggah lewat handler ikut terbaca GetByID.
+type appealUploadMongoRepo struct {
+	MockAppealMongoRepo
+	added []model.Attachment
+}
+
+func (m *appealUploadMongoRepo) AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error {
+	m.added = append(m.added, a)
+	return nil
+}
+func (m *appealUploadMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	a, _ := m.MockAppealMongoRepo.GetByID(id)
+	a.Attachments = append(a.Attachments, m.added...)
+	return a, nil
+}
+
+func TestAppeal_WithEvidenceUploadedAfterRejection(t *testing.T) {
+	svc, pg, _ := appealService()
+	mongoRepo := &appealUploadMongoRepo{MockAppealMongoRepo: *svc.MongoRepo.(*MockAppealMongoRepo)}
+	svc.MongoRepo = mongoRepo
+	svc.Storage = storage.NewLocal(t.TempDir())
+
+	app := uploadApp(svc)
+	app.Post("/achievements/:refId/appeal", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-mhs")
+		return svc.Appeal(c)
+	})
+
+	// prestasi rejected: mahasiswa tetap boleh mengunggah bukti baru
+	status, out := uploadRequest(app, "scan-ulang.pdf", "%PDF-1.4 scan ulang")
+	assert.Equal(t, 200, status)
+	key, _ := out["key"].(string)
+	assert.NotEmpty(t, key)
+
+	resp, _ := commentRequestAs(app, "POST", "/achievements/ref-1/appeal", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"justification": validJustification, "evidence": []string{key}})
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "appealed", pg.ref.Status)
+
+	// setelah banding diajukan, upload kembali ditutup
+	status, _ = uploadRequest(app, "lain.pdf", "%PDF-1.4 lain")
+	assert.Equal(t, 400, status)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxBatchReview membatasi jumlah prestasi dalam satu permintaan batch.
+const maxBatchReview = 100
+
+// batchReviewItem: note per item menimpa note bersama.
+type batchReviewItem struct {
+	ReferenceID string `json:"reference_id"`
+	Note        string `json:"note"`
+}
+
+type batchReviewRequest struct {
+	ReferenceIDs []string          `json:"reference_ids"`
+	Items        []batchReviewItem `json:"items"`
+	Note         string            `json:"note"`
+}
+
+// batchReviewResult: hasil per prestasi; Status berisi kode HTTP yang akan
+// dikembalikan endpoint tunggal untuk item tersebut.
+type batchReviewResult struct {
+	ReferenceID string `json:"reference_id"`
+	Success     bool   `json:"success"`
+	Status      int    `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// items menggabungkan reference_ids dan items menjadi satu daftar.
+func (r *batchReviewRequest) items() []batchReviewItem {
+	list := make([]batchReviewItem, 0, len(r.ReferenceIDs)+len(r.Items))
+	for _, id := range r.ReferenceIDs {
+		list = append(list, batchReviewItem{ReferenceID: id})
+	}
+	return append(list, r.Items...)
+}
+
+func validateBatchReview(items []batchReviewItem) []FieldError {
+	var errs []FieldError
+	if len(items) == 0 {
+		return []FieldError{{"reference_ids", "minimal satu prestasi"}}
+	}
+	if len(items) > maxBatchReview {
+		return []FieldError{{"reference_ids", fmt.Sprintf("maksimal %d prestasi per batch", maxBatchReview)}}
+	}
+
+	seen := map[string]bool{}
+	for i, item := range items {
+		id := strings.TrimSpace(item.ReferenceID)
+		if id == "" {
+			errs = append(errs, FieldError{fmt.Sprintf("items[%d].reference_id", i), "wajib diisi"})
+			continue
+		}
+		if seen[id] {
+			errs = append(errs, FieldError{fmt.Sprintf("items[%d].reference_id", i), "duplikat"})
+		}
+		seen[id] = true
+	}
+	return errs
+}
+
+// BATCH VERIFY (Dosen Wali & Admin)
+func (s *AchievementService) BatchVerify(c *fiber.Ctx) error {
+	return s.batchReview(c, s.verifyReference)
+}
+
+// BATCH REJECT (Dosen Wali & Admin)
+func (s *AchievementService) BatchReject(c *fiber.Ctx) error {
+	return s.batchReview(c, s.rejectReference)
+}
+
+// batchReview menjalankan review per item dengan otorisasi yang sama
+// seperti endpoint tunggal. Item yang gagal tidak membatalkan item lain.
+func (s *AchievementService) batchReview(c *fiber.Ctx, review func(actor reviewActor, refID, note string) error) error {
+	var body batchReviewRequest
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	items := body.items()
+	if errs := validateBatchReview(items); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	actor := reviewActorFrom(c)
+	results := make([]batchReviewResult, 0, len(items))
+	succeeded := 0
+
+	for _, item := range items {
+		note := item.Note
+		if note == "" {
+			note = body.Note
+		}
+
+		result := batchReviewResult{ReferenceID: strings.TrimSpace(item.ReferenceID), Success: true, Status: 200}
+		if err := review(actor, result.ReferenceID, note); err != nil {
+			result.Success = false
+			result.Status = 500
+			result.Error = err.Error()
+			if re, ok := err.(*reviewError); ok {
+				result.Status = re.Status
+			}
+		} else {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	return c.JSON(fiber.Map{
+		"total":     len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+		"results":   results,
+	})
+}
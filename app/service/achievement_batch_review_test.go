@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MockBatchPostgresRepo: reference per id dengan status dan pemilik berbeda.
+type MockBatchPostgresRepo struct {
+	MockAchievementPostgresRepo
+	refs     map[string]*model.AchievementReference
+	rejected map[string]string // refID → note
+}
+
+func newMockBatchPostgresRepo(refs ...model.AchievementReference) *MockBatchPostgresRepo {
+	m := &MockBatchPostgresRepo{refs: map[string]*model.AchievementReference{}, rejected: map[string]string{}}
+	for i := range refs {
+		refs[i].MongoID = primitive.NewObjectID().Hex()
+		m.refs[refs[i].ID] = &refs[i]
+	}
+	return m
+}
+
+func (m *MockBatchPostgresRepo) GetReferenceByID(id string) (*model.AchievementReference, error) {
+	ref, ok := m.refs[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	out := *ref
+	return &out, nil
+}
+func (m *MockBatchPostgresRepo) UpdateVerifyStatus(refID, verifierID string) error {
+	m.refs[refID].Status = "verified"
+	return nil
+}
+func (m *MockBatchPostgresRepo) RejectReference(refID, userID, note string) error {
+	m.refs[refID].Status = "rejected"
+	m.rejected[refID] = note
+	return nil
+}
+
+func batchReviewApp(svc *AchievementService) *fiber.App {
+	app := fiber.New()
+	asAdvisor := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", "Dosen Wali")
+			c.Locals("lecturer_id", "lect-1")
+			c.Locals("user_id", "user-1")
+			return h(c)
+		}
+	}
+	app.Post("/achievements/batch/verify", asAdvisor(svc.BatchVerify))
+	app.Post("/achievements/batch/reject", asAdvisor(svc.BatchReject))
+	return app
+}
+
+func postBatch(app *fiber.App, path string, payload map[string]interface{}) (*http.Response, map[string]interface{}) {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func TestBatchVerify_PartialSuccess(t *testing.T) {
+	pg := newMockBatchPostgresRepo(
+		model.AchievementReference{ID: "ref-1", StudentID: "student-1", Status: "submitted"},
+		model.AchievementReference{ID: "ref-2", StudentID: "student-1", Status: "draft"},
+		model.AchievementReference{ID: "ref-3", StudentID: "student-9", Status: "submitted"}, // bukan bimbingan
+	)
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: pg,
+		StudentRepo:  &MockStudentPostgresRepo{},
+	}
+
+	resp, out := postBatch(batchReviewApp(svc), "/achievements/batch/verify", map[string]interface{}{
+		"reference_ids": []string{"ref-1", "ref-2", "ref-3", "ref-404"},
+	})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, float64(1), out["succeeded"])
+	assert.Equal(t, float64(3), out["failed"])
+
+	statuses := []float64{}
+	for _, r := range out["results"].([]interface{}) {
+		statuses = append(statuses, r.(map[string]interface{})["status"].(float64))
+	}
+	assert.Equal(t, []float64{200, 400, 400, 404}, statuses)
+	assert.Equal(t, "verified", pg.refs["ref-1"].Status)
+	assert.Equal(t, "draft", pg.refs["ref-2"].Status)
+}
+
+func TestBatchReject_PerItemNoteOverridesSharedNote(t *testing.T) {
+	pg := newMockBatchPostgresRepo(
+		model.AchievementReference{ID: "ref-1", StudentID: "student-1", Status: "submitted"},
+		model.AchievementReference{ID: "ref-2", StudentID: "student-1", Status: "submitted"},
+	)
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: pg,
+		StudentRepo:  &MockStudentPostgresRepo{},
+	}
+
+	resp, out := postBatch(batchReviewApp(svc), "/achievements/batch/reject", map[string]interface{}{
+		"reference_ids": []string{"ref-1"},
+		"items":         []map[string]string{{"reference_id": "ref-2", "note": "Sertifikat buram"}},
+		"note":          "Lampiran tidak lengkap",
+	})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, float64(2), out["succeeded"])
+	assert.Equal(t, map[string]string{
+		"ref-1": "Lampiran tidak lengkap",
+		"ref-2": "Sertifikat buram",
+	}, pg.rejected)
+}
+
+func TestBatchVerify_InvalidList_ShouldFail(t *testing.T) {
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: newMockBatchPostgresRepo(),
+		StudentRepo:  &MockStudentPostgresRepo{},
+	}
+	app := batchReviewApp(svc)
+
+	resp, _ := postBatch(app, "/achievements/batch/verify", map[string]interface{}{"reference_ids": []string{}})
+	assert.Equal(t, 400, resp.StatusCode)
+
+	resp, _ = postBatch(app, "/achievements/batch/verify", map[string]interface{}{"reference_ids": []string{"ref-1", "ref-1"}})
+	assert.Equal(t, 400, resp.StatusCode)
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Batas diskusi: penulis boleh mengubah/menghapus komentarnya selama
+// commentEditWindow sejak dibuat; admin boleh menghapus kapan saja.
+const (
+	commentEditWindow     = 15 * time.Minute
+	maxCommentLength      = 5000
+	maxCommentAttachments = 10
+)
+
+type commentRequest struct {
+	Body        string   `json:"body"`
+	ParentID    string   `json:"parent_id"`
+	Internal    bool     `json:"internal"`
+	Attachments []string `json:"attachments"`
+}
+
+// commentThread: komentar beserta balasannya (berutas).
+type commentThread struct {
+	model.AchievementComment
+	Replies []*commentThread `json:"replies"`
+}
+
+// buildCommentThreads menyusun daftar komentar (urut waktu) menjadi utas.
+// Balasan yang induknya tidak terlihat ditampilkan di tingkat teratas.
+func buildCommentThreads(comments []model.AchievementComment) []*commentThread {
+	nodes := map[string]*commentThread{}
+	for _, cm := range comments {
+		nodes[cm.ID] = &commentThread{AchievementComment: cm, Replies: []*commentThread{}}
+	}
+
+	roots := []*commentThread{}
+	for _, cm := range comments {
+		node := nodes[cm.ID]
+		if cm.ParentID != nil {
+			if parent, ok := nodes[*cm.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// isReviewerRole: dosen wali & admin boleh membaca dan menulis catatan internal.
+func isReviewerRole(role string) bool {
+	return role == "Dosen Wali" || role == "Admin"
+}
+
+// commentReference: reference yang boleh dilihat user saat ini (aturan Detail).
+func (s *AchievementService) commentReference(c *fiber.Ctx) (*model.AchievementReference, error) {
+	if s.CommentRepo == nil {
+		return nil, &reviewError{404, "comments not available"}
+	}
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil || ref.Status == "deleted" {
+		return nil, &reviewError{404, "reference not found"}
+	}
+	if err := s.authorizeView(c, ref); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// visibleComment: komentar milik reference ini yang terlihat oleh role.
+func (s *AchievementService) visibleComment(ref *model.AchievementReference, commentID, role string) (*model.AchievementComment, error) {
+	cm, err := s.CommentRepo.Get(commentID)
+	if err != nil || cm.ReferenceID != ref.ID || (cm.Internal && !isReviewerRole(role)) {
+		return nil, &reviewError{404, "comment not found"}
+	}
+	return cm, nil
+}
+
+// validateComment memeriksa isi dan lampiran komentar. Lampiran harus
+// key (atau FileURL lama) dari lampiran prestasi yang sama.
+func (s *AchievementService) validateComment(ref *model.AchievementReference, req *commentRequest) []FieldError {
+	var errs []FieldError
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		errs = append(errs, FieldError{"body", "wajib diisi"})
+	} else if len([]rune(req.Body)) > maxCommentLength {
+		errs = append(errs, FieldError{"body", "maksimal " + strconv.Itoa(maxCommentLength) + " karakter"})
+	}
+
+	if len(req.Attachments) > maxCommentAttachments {
+		errs = append(errs, FieldError{"attachments", "maksimal " + strconv.Itoa(maxCommentAttachments) + " lampiran"})
+		return errs
+	}
+	if len(req.Attachments) > 0 {
+		var known []string
+		oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+		if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+			for _, a := range ach.Attachments {
+				known = append(known, a.Ref())
+			}
+		}
+		for i, url := range req.Attachments {
+			if !contains(known, url) {
+				errs = append(errs, FieldError{"attachments." + strconv.Itoa(i), "bukan lampiran prestasi ini"})
+			}
+		}
+	}
+	return errs
+}
+
+// canModifyComment: penulis dalam commentEditWindow; admin boleh menghapus kapan saja.
+func canModifyComment(cm *model.AchievementComment, actor reviewActor, deleting bool, now time.Time) error {
+	if cm.Deleted() {
+		return &reviewError{404, "comment not found"}
+	}
+	if deleting && actor.Role == "Admin" {
+		return nil
+	}
+	if cm.AuthorID != actor.UserID {
+		return &reviewError{403, "bukan komentar anda"}
+	}
+	if now.Sub(cm.CreatedAt) > commentEditWindow {
+		return &reviewError{403, "batas waktu perubahan komentar sudah lewat"}
+	}
+	return nil
+}
+
+// ======================================================
+// DISKUSI PRESTASI
+// ======================================================
+
+// ListComments: utas diskusi satu prestasi. Catatan internal hanya untuk
+// reviewer. Membaca utas menandai semua komentar sebagai sudah dibaca.
+func (s *AchievementService) ListComments(c *fiber.Ctx) error {
+	ref, err := s.commentReference(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	actor := reviewActorFrom(c)
+
+	comments, err := s.CommentRepo.List(ref.ID, isReviewerRole(actor.Role))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.CommentRepo.MarkRead(ref.ID, actor.UserID, time.Now())
+
+	return c.JSON(fiber.Map{
+		"reference_id": ref.ID,
+		"total":        len(comments),
+		"comments":     buildCommentThreads(comments),
+	})
+}
+
+func (s *AchievementService) CreateComment(c *fiber.Ctx) error {
+	ref, err := s.commentReference(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	actor := reviewActorFrom(c)
+
+	var req commentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	errs := s.validateComment(ref, &req)
+	if req.Internal && !isReviewerRole(actor.Role) {
+		errs = append(errs, FieldError{"internal", "catatan internal hanya untuk reviewer"})
+	}
+
+	var parentID *string
+	if req.ParentID != "" {
+		parent, err := s.visibleComment(ref, req.ParentID, actor.Role)
+		if err != nil || parent.Deleted() {
+			errs = append(errs, FieldError{"parent_id", "komentar induk tidak ditemukan"})
+		} else {
+			parentID = &parent.ID
+			// balasan atas catatan internal tetap internal
+			req.Internal = req.Internal || parent.Internal
+		}
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	now := time.Now()
+	comment := &model.AchievementComment{
+		ID:          uuid.New().String(),
+		ReferenceID: ref.ID,
+		ParentID:    parentID,
+		AuthorID:    actor.UserID,
+		AuthorRole:  actor.Role,
+		Body:        req.Body,
+		Internal:    req.Internal,
+		Attachments: req.Attachments,
+		CreatedAt:   now,
+	}
+	if err := s.CommentRepo.Create(comment); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// komentar sendiri tidak dihitung belum dibaca
+	s.CommentRepo.MarkRead(ref.ID, actor.UserID, now)
+
+	return c.Status(201).JSON(comment)
+}
+
+func (s *AchievementService) UpdateComment(c *fiber.Ctx) error {
+	ref, err := s.commentReference(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	actor := reviewActorFrom(c)
+
+	comment, err := s.visibleComment(ref, c.Params("commentId"), actor.Role)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	now := time.Now()
+	if err := canModifyComment(comment, actor, false, now); err != nil {
+		return respondReviewError(c, err)
+	}
+
+	var req commentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+	if errs := s.validateComment(ref, &req); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	if err := s.CommentRepo.Update(comment.ID, req.Body, req.Attachments, now); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	comment.Body = req.Body
+	comment.Attachments = req.Attachments
+	comment.EditedAt = &now
+
+	return c.JSON(comment)
+}
+
+func (s *AchievementService) DeleteComment(c *fiber.Ctx) error {
+	ref, err := s.commentReference(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	actor := reviewActorFrom(c)
+
+	comment, err := s.visibleComment(ref, c.Params("commentId"), actor.Role)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	now := time.Now()
+	if err := canModifyComment(comment, actor, true, now); err != nil {
+		return respondReviewError(c, err)
+	}
+
+	// soft delete: balasan tetap berada di utasnya
+	if err := s.CommentRepo.SoftDelete(comment.ID, now); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Comment deleted"})
+}
+
+// UnreadComments: jumlah komentar belum dibaca per prestasi yang terlihat
+// oleh user saat ini. Scope dihitung sekali dan disaring di query.
+func (s *AchievementService) UnreadComments(c *fiber.Ctx) error {
+	if s.CommentRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "comments not available"})
+	}
+	actor := reviewActorFrom(c)
+
+	scope := model.CommentUnreadScope{IncludeInternal: isReviewerRole(actor.Role)}
+	switch actor.Role {
+	case "Mahasiswa":
+		studentID, _ := c.Locals("student_id").(string)
+		scope.StudentIDs = []string{studentID}
+		scope.MemberStatuses = []string{"confirmed", "pending"}
+	case "Dosen Wali":
+		advisees, err := s.advisees(actor.LecturerID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		scope.StudentIDs = advisees.IDs()
+		scope.MemberStatuses = []string{"confirmed"}
+	}
+
+	counts, err := s.CommentRepo.UnreadCounts(actor.UserID, scope)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return c.JSON(fiber.Map{
+		"total":      total,
+		"references": counts,
+	})
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCKS =================
+
+type MockCommentRepo struct {
+	comments []*model.AchievementComment
+	reads    map[string]time.Time // refID|userID
+}
+
+func newMockCommentRepo() *MockCommentRepo {
+	return &MockCommentRepo{reads: map[string]time.Time{}}
+}
+
+func (m *MockCommentRepo) Create(c *model.AchievementComment) error {
+	cp := *c
+	m.comments = append(m.comments, &cp)
+	return nil
+}
+func (m *MockCommentRepo) Get(id string) (*model.AchievementComment, error) {
+	for _, c := range m.comments {
+		if c.ID == id {
+			cp := *c
+			return &cp, nil
+		}
+	}
+	return nil, errors.New("comment not found")
+}
+func (m *MockCommentRepo) List(refID string, includeInternal bool) ([]model.AchievementComment, error) {
+	var list []model.AchievementComment
+	for _, c := range m.comments {
+		if c.ReferenceID == refID && (includeInternal || !c.Internal) {
+			list = append(list, *c)
+		}
+	}
+	return list, nil
+}
+func (m *MockCommentRepo) Update(id, body string, attachments []string, editedAt time.Time) error {
+	for _, c := range m.comments {
+		if c.ID == id {
+			c.Body, c.Attachments, c.EditedAt = body, attachments, &editedAt
+		}
+	}
+	return nil
+}
+func (m *MockCommentRepo) SoftDelete(id string, at time.Time) error {
+	for _, c := range m.comments {
+		if c.ID == id {
+			c.Body, c.Attachments, c.DeletedAt = "", nil, &at
+		}
+	}
+	return nil
+}
+func (m *MockCommentRepo) MarkRead(refID, userID string, at time.Time) error {
+	m.reads[refID+"|"+userID] = at
+	return nil
+}
+func (m *MockCommentRepo) UnreadCounts(userID string, scope model.CommentUnreadScope) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, c := range m.comments {
+		read, ok := m.reads[c.ReferenceID+"|"+userID]
+		if c.AuthorID == userID || c.Deleted() || (c.Internal && !scope.IncludeInternal) || (ok && !c.CreatedAt.After(read)) {
+			continue
+		}
+		// pemilik sesuai MockCommentPostgresRepo (tanpa tim)
+		owner := "student-1"
+		if c.ReferenceID == "ref-other" {
+			owner = "student-9"
+		}
+		if scope.StudentIDs != nil && !contains(scope.StudentIDs, owner) {
+			continue
+		}
+		counts[c.ReferenceID]++
+	}
+	return counts, nil
+}
+
+// MockCommentPostgresRepo: ref-other milik student-9 (bukan bimbingan lect-1).
+type MockCommentPostgresRepo struct {
+	MockAchievementPostgresRepo
+}
+
+func (m *MockCommentPostgresRepo) GetReferenceByID(id string) (*model.AchievementReference, error) {
+	// id berasal dari c.Params (zero-copy) dan disimpan di komentar
+	id = utils.CopyString(id)
+	ref, _ := m.MockAchievementPostgresRepo.GetReferenceByID(id)
+	if id == "ref-other" {
+		ref.StudentID = "student-9"
+	}
+	return ref, nil
+}
+
+func commentService() (*AchievementService, *MockCommentRepo) {
+	repo := newMockCommentRepo()
+	return &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: &MockCommentPostgresRepo{},
+		StudentRepo:  &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+		CommentRepo:  repo,
+	}, repo
+}
+
+// commentApp: role & user diambil dari header X-Role / X-User.
+func commentApp(svc *AchievementService) *fiber.App {
+	app := fiber.New()
+	auth := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", utils.CopyString(c.Get("X-Role")))
+			c.Locals("user_id", utils.CopyString(c.Get("X-User")))
+			c.Locals("student_id", "student-1")
+			c.Locals("lecturer_id", "lect-1")
+			return h(c)
+		}
+	}
+	app.Get("/achievements/comments/unread", auth(svc.UnreadComments))
+	app.Get("/achievements/:refId/comments", auth(svc.ListComments))
+	app.Post("/achievements/:refId/comments", auth(svc.CreateComment))
+	app.Put("/achievements/:refId/comments/:commentId", auth(svc.UpdateComment))
+	app.Delete("/achievements/:refId/comments/:commentId", auth(svc.DeleteComment))
+	return app
+}
+
+func commentRequestAs(app *fiber.App, method, path, role, userID string, payload interface{}) (*http.Response, map[string]interface{}) {
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Role", role)
+	req.Header.Set("X-User", userID)
+	resp, _ := app.Test(req)
+
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+// ================= UNIT TESTS =================
+
+func TestBuildCommentThreads(t *testing.T) {
+	parent, hidden := "c-1", "c-hidden"
+	threads := buildCommentThreads([]model.AchievementComment{
+		{ID: "c-1"},
+		{ID: "c-2", ParentID: &parent},
+		{ID: "c-3"},
+		{ID: "c-4", ParentID: &hidden},
+	})
+
+	assert.Len(t, threads, 3)
+	assert.Equal(t, "c-1", threads[0].ID)
+	assert.Len(t, threads[0].Replies, 1)
+	assert.Equal(t, "c-2", threads[0].Replies[0].ID)
+	assert.Equal(t, "c-4", threads[2].ID)
+}
+
+func TestComments_InternalNotesHiddenFromStudent(t *testing.T) {
+	svc, repo := commentService()
+	app := commentApp(svc)
+
+	resp, out := commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"body": "Sertifikat sudah saya unggah", "attachments": []string{"/uploads/sertifikat.pdf"}})
+	assert.Equal(t, 201, resp.StatusCode)
+	studentComment := out["id"].(string)
+
+	// mahasiswa tidak boleh menulis catatan internal atau melampirkan file lain
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"body": "x", "internal": true, "attachments": []string{"/uploads/lain.pdf"}})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Len(t, out["fields"], 2)
+
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Dosen Wali", "user-lect",
+		map[string]interface{}{"body": "Cek nomor sertifikat", "internal": true})
+	assert.Equal(t, 201, resp.StatusCode)
+	internalNote := out["id"].(string)
+
+	// balasan atas catatan internal ikut internal
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Admin", "admin-1",
+		map[string]interface{}{"body": "Sudah dicek", "parent_id": internalNote})
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, true, out["internal"])
+
+	resp, _ = commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"body": "?", "parent_id": internalNote})
+	assert.Equal(t, 400, resp.StatusCode)
+
+	resp, _ = commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Dosen Wali", "user-lect",
+		map[string]interface{}{"body": "Terima kasih", "parent_id": studentComment})
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Len(t, repo.comments, 4)
+
+	_, out = commentRequestAs(app, "GET", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs", nil)
+	assert.Equal(t, float64(2), out["total"])
+	threads := out["comments"].([]interface{})
+	assert.Len(t, threads, 1)
+	assert.Len(t, threads[0].(map[string]interface{})["replies"], 1)
+
+	_, out = commentRequestAs(app, "GET", "/achievements/ref-1/comments", "Dosen Wali", "user-lect", nil)
+	assert.Equal(t, float64(4), out["total"])
+}
+
+func TestComments_VisibilityFollowsDetail(t *testing.T) {
+	svc, _ := commentService()
+	app := commentApp(svc)
+
+	resp, _ := commentRequestAs(app, "GET", "/achievements/ref-other/comments", "Mahasiswa", "user-mhs", nil)
+	assert.Equal(t, 403, resp.StatusCode)
+	resp, _ = commentRequestAs(app, "POST", "/achievements/ref-other/comments", "Dosen Wali", "user-lect",
+		map[string]interface{}{"body": "halo"})
+	assert.Equal(t, 403, resp.StatusCode)
+	resp, _ = commentRequestAs(app, "GET", "/achievements/ref-other/comments", "Admin", "admin-1", nil)
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestComments_EditDeleteWindow(t *testing.T) {
+	svc, repo := commentService()
+	app := commentApp(svc)
+
+	_, out := commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"body": "typo"})
+	id := out["id"].(string)
+	path := "/achievements/ref-1/comments/" + id
+
+	resp, _ := commentRequestAs(app, "PUT", path, "Dosen Wali", "user-lect", map[string]interface{}{"body": "bukan milik"})
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp, out = commentRequestAs(app, "PUT", path, "Mahasiswa", "user-mhs", map[string]interface{}{"body": "fixed"})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "fixed", out["body"])
+	assert.NotNil(t, repo.comments[0].EditedAt)
+
+	// lewat batas waktu: penulis tidak boleh lagi, admin tetap boleh menghapus
+	repo.comments[0].CreatedAt = time.Now().Add(-commentEditWindow - time.Minute)
+	resp, _ = commentRequestAs(app, "PUT", path, "Mahasiswa", "user-mhs", map[string]interface{}{"body": "late"})
+	assert.Equal(t, 403, resp.StatusCode)
+	resp, _ = commentRequestAs(app, "DELETE", path, "Mahasiswa", "user-mhs", nil)
+	assert.Equal(t, 403, resp.StatusCode)
+	resp, _ = commentRequestAs(app, "DELETE", path, "Admin", "admin-1", nil)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, repo.comments[0].Deleted())
+
+	resp, _ = commentRequestAs(app, "DELETE", path, "Admin", "admin-1", nil)
+	assert.Equal(t, 404, resp.StatusCode)
+}
+
+func TestComments_UnreadCounts(t *testing.T) {
+	svc, repo := commentService()
+	app := commentApp(svc)
+
+	commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Dosen Wali", "user-lect",
+		map[string]interface{}{"body": "Mohon lengkapi"})
+	commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Dosen Wali", "user-lect",
+		map[string]interface{}{"body": "catatan", "internal": true})
+	commentRequestAs(app, "POST", "/achievements/ref-other/comments", "Admin", "admin-1",
+		map[string]interface{}{"body": "bukan untuk mahasiswa ini"})
+	assert.Len(t, repo.comments, 3)
+
+	_, out := commentRequestAs(app, "GET", "/achievements/comments/unread", "Mahasiswa", "user-mhs", nil)
+	assert.Equal(t, float64(1), out["total"])
+	assert.Equal(t, map[string]interface{}{"ref-1": float64(1)}, out["references"])
+
+	// membaca utas menghapus hitungan
+	commentRequestAs(app, "GET", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs", nil)
+	_, out = commentRequestAs(app, "GET", "/achievements/comments/unread", "Mahasiswa", "user-mhs", nil)
+	assert.Equal(t, float64(0), out["total"])
+
+	_, out = commentRequestAs(app, "GET", "/achievements/comments/unread", "Admin", "admin-1", nil)
+	assert.Equal(t, float64(2), out["total"])
+
+	// dosen wali: hanya prestasi bimbingan (ref-other milik student-9)
+	commentRequestAs(app, "POST", "/achievements/ref-1/comments", "Mahasiswa", "user-mhs",
+		map[string]interface{}{"body": "Sudah saya lengkapi"})
+	_, out = commentRequestAs(app, "GET", "/achievements/comments/unread", "Dosen Wali", "user-lect", nil)
+	assert.Equal(t, float64(1), out["total"])
+	assert.Equal(t, map[string]interface{}{"ref-1": float64(1)}, out["references"])
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FieldError menunjuk satu field yang gagal validasi, misalnya
+// "details.competitionName", beserta pesan yang bisa ditampilkan ke user.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SchemaRule adalah aturan tambahan (tanggal, lintas field) untuk satu tipe.
+type SchemaRule func(a *model.AchievementMongo, now time.Time) []FieldError
+
+// AchievementSchema mendeskripsikan field details yang boleh dipakai oleh satu
+// achievementType. Field yang tidak ada di Required/Optional dianggap forbidden.
+type AchievementSchema struct {
+	Type     string              `json:"type"`
+	Label    string              `json:"label"`
+	Required []string            `json:"required"`
+	Optional []string            `json:"optional"`
+	Enums    map[string][]string `json:"enums,omitempty"`
+	Rules    []SchemaRule        `json:"-"`
+}
+
+// SchemaRegistry menyimpan schema per achievementType.
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]*AchievementSchema
+}
+
+func NewSchemaRegistry(schemas ...*AchievementSchema) *SchemaRegistry {
+	r := &SchemaRegistry{schemas: map[string]*AchievementSchema{}}
+	for _, s := range schemas {
+		r.Register(s)
+	}
+	return r
+}
+
+func (r *SchemaRegistry) Register(s *AchievementSchema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[s.Type] = s
+}
+
+func (r *SchemaRegistry) Lookup(achievementType string) (*AchievementSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.schemas[achievementType]
+	return s, ok
+}
+
+// Types mengembalikan semua schema, urut berdasarkan nama tipe.
+func (r *SchemaRegistry) Types() []*AchievementSchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list := make([]*AchievementSchema, 0, len(r.schemas))
+	for _, s := range r.schemas {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Type < list[j].Type })
+	return list
+}
+
+// TypeCatalog menyediakan tipe custom dan definisi custom field dari database.
+// Dipenuhi oleh repository.AchievementTypePostgresRepository.
+type TypeCatalog interface {
+	GetTypeByCode(code string) (*model.AchievementType, error)
+	ListFields(typeCode string) ([]model.AchievementCustomField, error)
+}
+
+// Validate memeriksa field wajib, field terlarang, enum dan aturan tambahan
+// untuk tipe bawaan. Hasil kosong berarti data valid.
+func (r *SchemaRegistry) Validate(a *model.AchievementMongo, now time.Time) []FieldError {
+	errs, _ := r.ValidateWithCatalog(a, now, nil)
+	return errs
+}
+
+// ValidateWithCatalog sama seperti Validate, tetapi juga mengenali tipe custom
+// dari catalog dan memvalidasi details.customFields sesuai definisinya.
+// Error hanya dikembalikan bila catalog gagal dibaca; tipe yang tidak
+// terdaftar tetap berupa FieldError.
+func (r *SchemaRegistry) ValidateWithCatalog(a *model.AchievementMongo, now time.Time, catalog TypeCatalog) ([]FieldError, error) {
+	var errs []FieldError
+
+	if strings.TrimSpace(a.Title) == "" {
+		errs = append(errs, FieldError{"title", "wajib diisi"})
+	}
+
+	if a.AchievementType == "" {
+		return append(errs, FieldError{"achievementType", "wajib diisi"}), nil
+	}
+
+	schema, ok := r.Lookup(a.AchievementType)
+	if !ok && catalog != nil {
+		t, err := catalog.GetTypeByCode(a.AchievementType)
+		if err != nil && !errors.Is(err, repository.ErrAchievementTypeNotFound) {
+			return nil, err
+		}
+		if err == nil && t.IsActive {
+			schema, ok = customTypeSchema(t), true
+		}
+	}
+	if !ok {
+		return append(errs, FieldError{
+			"achievementType",
+			fmt.Sprintf("tipe %q tidak dikenal", a.AchievementType),
+		}), nil
+	}
+
+	errs = append(errs, validateSchema(schema, a, now)...)
+
+	if catalog != nil {
+		defs, err := catalog.ListFields(a.AchievementType)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, validateCustomFields(defs, a.Details.CustomFields)...)
+	}
+
+	return errs, nil
+}
+
+func validateSchema(schema *AchievementSchema, a *model.AchievementMongo, now time.Time) []FieldError {
+	var errs []FieldError
+
+	allowed := map[string]bool{}
+	for _, f := range schema.Required {
+		allowed[f] = true
+	}
+	for _, f := range schema.Optional {
+		allowed[f] = true
+	}
+
+	for _, name := range schema.Required {
+		if f, ok := detailFields[name]; ok && !f.isSet(&a.Details) {
+			errs = append(errs, FieldError{"details." + name, "wajib diisi untuk tipe " + schema.Type})
+		}
+	}
+
+	for _, name := range detailFieldOrder {
+		if allowed[name] {
+			continue
+		}
+		if detailFields[name].isSet(&a.Details) {
+			errs = append(errs, FieldError{"details." + name, "tidak boleh diisi untuk tipe " + schema.Type})
+		}
+	}
+
+	for _, name := range sortedKeys(schema.Enums) {
+		f, ok := detailFields[name]
+		if !ok || f.str == nil {
+			continue
+		}
+		v := f.str(&a.Details)
+		if v == "" || contains(schema.Enums[name], v) {
+			continue
+		}
+		errs = append(errs, FieldError{
+			"details." + name,
+			"harus salah satu dari: " + strings.Join(schema.Enums[name], ", "),
+		})
+	}
+
+	for _, rule := range schema.Rules {
+		errs = append(errs, rule(a, now)...)
+	}
+
+	return errs
+}
+
+// respondValidation menulis response 400 standar untuk kesalahan validasi.
+func respondValidation(c *fiber.Ctx, errs []FieldError) error {
+	return c.Status(400).JSON(fiber.Map{
+		"error":  "validasi gagal",
+		"fields": errs,
+	})
+}
+
+// ======================================================
+// DETAIL FIELDS
+// ======================================================
+
+type detailField struct {
+	isSet func(d *model.AchievementDetails) bool
+	str   func(d *model.AchievementDetails) string // nil untuk field non-string
+}
+
+func stringField(get func(d *model.AchievementDetails) string) detailField {
+	return detailField{
+		isSet: func(d *model.AchievementDetails) bool { return strings.TrimSpace(get(d)) != "" },
+		str:   get,
+	}
+}
+
+// detailFieldOrder menjaga urutan error tetap stabil.
+var detailFieldOrder = []string{
+	"competitionName", "competitionLevel", "rank", "medalType",
+	"publicationType", "publicationTitle", "authors", "publisher", "issn",
+	"organizationName", "position", "period",
+	"certificationName", "issuedBy", "certificationNumber", "validUntil",
+	"eventDate", "location", "organizer", "score", "customFields",
+}
+
+var detailFields = map[string]detailField{
+	"competitionName":  stringField(func(d *model.AchievementDetails) string { return d.CompetitionName }),
+	"competitionLevel": stringField(func(d *model.AchievementDetails) string { return d.CompetitionLevel }),
+	"rank":             {isSet: func(d *model.AchievementDetails) bool { return d.Rank != 0 }},
+	"medalType":        stringField(func(d *model.AchievementDetails) string { return d.MedalType }),
+
+	"publicationType":  stringField(func(d *model.AchievementDetails) string { return d.PublicationType }),
+	"publicationTitle": stringField(func(d *model.AchievementDetails) string { return d.PublicationTitle }),
+	"authors":          {isSet: func(d *model.AchievementDetails) bool { return len(d.Authors) > 0 }},
+	"publisher":        stringField(func(d *model.AchievementDetails) string { return d.Publisher }),
+	"issn":             stringField(func(d *model.AchievementDetails) string { return d.ISSN }),
+
+	"organizationName": stringField(func(d *model.AchievementDetails) string { return d.OrganizationName }),
+	"position":         stringField(func(d *model.AchievementDetails) string { return d.Position }),
+	"period":           {isSet: func(d *model.AchievementDetails) bool { return d.Period != nil }},
+
+	"certificationName":   stringField(func(d *model.AchievementDetails) string { return d.CertificationName }),
+	"issuedBy":            stringField(func(d *model.AchievementDetails) string { return d.IssuedBy }),
+	"certificationNumber": stringField(func(d *model.AchievementDetails) string { return d.CertificationNumber }),
+	"validUntil":          {isSet: func(d *model.AchievementDetails) bool { return d.ValidUntil != nil }},
+
+	"eventDate":    {isSet: func(d *model.AchievementDetails) bool { return d.EventDate != nil }},
+	"location":     stringField(func(d *model.AchievementDetails) string { return d.Location }),
+	"organizer":    stringField(func(d *model.AchievementDetails) string { return d.Organizer }),
+	"score":        {isSet: func(d *model.AchievementDetails) bool { return d.Score != 0 }},
+	"customFields": {isSet: func(d *model.AchievementDetails) bool { return len(d.CustomFields) > 0 }},
+}
+
+// ======================================================
+// BUILT-IN SCHEMAS
+// ======================================================
+
+var (
+	competitionLevels = []string{"international", "national", "provincial", "city", "university"}
+	medalTypes        = []string{"gold", "silver", "bronze"}
+	publicationTypes  = []string{"journal", "conference", "book"}
+
+	issnPattern = regexp.MustCompile(`^\d{4}-\d{3}[\dX]$`)
+)
+
+// DefaultSchemaRegistry berisi empat tipe bawaan sistem.
+var DefaultSchemaRegistry = NewSchemaRegistry(
+	competitionSchema(),
+	publicationSchema(),
+	organizationSchema(),
+	certificationSchema(),
+)
+
+func competitionSchema() *AchievementSchema {
+	return &AchievementSchema{
+		Type:     "competition",
+		Label:    "Kompetisi",
+		Required: []string{"competitionName", "competitionLevel"},
+		Optional: []string{"rank", "medalType", "eventDate", "location", "organizer", "score", "customFields"},
+		Enums: map[string][]string{
+			"competitionLevel": competitionLevels,
+			"medalType":        medalTypes,
+		},
+		Rules: []SchemaRule{eventDateNotInFuture, competitionRankRule},
+	}
+}
+
+func publicationSchema() *AchievementSchema {
+	return &AchievementSchema{
+		Type:     "publication",
+		Label:    "Publikasi",
+		Required: []string{"publicationType", "publicationTitle", "authors"},
+		Optional: []string{"publisher", "issn", "eventDate", "customFields"},
+		Enums: map[string][]string{
+			"publicationType": publicationTypes,
+		},
+		Rules: []SchemaRule{eventDateNotInFuture, publicationRule},
+	}
+}
+
+func organizationSchema() *AchievementSchema {
+	return &AchievementSchema{
+		Type:     "organization",
+		Label:    "Organisasi",
+		Required: []string{"organizationName", "position", "period"},
+		Optional: []string{"location", "customFields"},
+		Rules:    []SchemaRule{periodRule},
+	}
+}
+
+func certificationSchema() *AchievementSchema {
+	return &AchievementSchema{
+		Type:     "certification",
+		Label:    "Sertifikasi",
+		Required: []string{"certificationName", "issuedBy"},
+		Optional: []string{"certificationNumber", "validUntil", "eventDate", "location", "score", "customFields"},
+		Rules:    []SchemaRule{eventDateNotInFuture, validUntilRule},
+	}
+}
+
+// customTypeSchema membangun schema untuk tipe custom: isinya dibawa lewat
+// customFields, ditambah field umum acara.
+func customTypeSchema(t *model.AchievementType) *AchievementSchema {
+	return &AchievementSchema{
+		Type:     t.Code,
+		Label:    t.Name,
+		Optional: []string{"eventDate", "location", "organizer", "customFields"},
+		Rules:    []SchemaRule{eventDateNotInFuture},
+	}
+}
+
+// ======================================================
+// CUSTOM FIELDS
+// ======================================================
+
+var customFieldDataTypes = []string{"string", "number", "boolean", "date", "enum"}
+
+// validateCustomFields mencocokkan details.customFields dengan definisi admin.
+// Bila tipe punya definisi field, key yang tidak terdefinisi ditolak supaya
+// customFields tidak lagi jadi "tas bebas". Tipe tanpa definisi (termasuk
+// tipe bawaan) tetap menerima customFields bebas seperti sebelumnya.
+func validateCustomFields(defs []model.AchievementCustomField, values map[string]any) []FieldError {
+	var errs []FieldError
+
+	known := map[string]bool{}
+	for _, def := range defs {
+		known[def.Key] = true
+		path := "details.customFields." + def.Key
+
+		v, present := values[def.Key]
+		if !present || v == nil || v == "" {
+			if def.Required {
+				errs = append(errs, FieldError{path, "wajib diisi"})
+			}
+			continue
+		}
+
+		if msg := checkCustomValue(def, v); msg != "" {
+			errs = append(errs, FieldError{path, msg})
+		}
+	}
+
+	if len(defs) == 0 {
+		return errs
+	}
+	for _, key := range sortedKeys(values) {
+		if !known[key] {
+			errs = append(errs, FieldError{"details.customFields." + key, "field tidak terdaftar untuk tipe ini"})
+		}
+	}
+
+	return errs
+}
+
+func checkCustomValue(def model.AchievementCustomField, v any) string {
+	switch def.DataType {
+	case "string", "enum":
+		str, ok := v.(string)
+		if !ok {
+			return "harus berupa teks"
+		}
+		if len(def.AllowedValues) > 0 && !contains(def.AllowedValues, str) {
+			return "harus salah satu dari: " + strings.Join(def.AllowedValues, ", ")
+		}
+	case "number":
+		switch v.(type) {
+		case float64, float32, int, int32, int64:
+		default:
+			return "harus berupa angka"
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return "harus berupa boolean"
+		}
+	case "date":
+		str, ok := v.(string)
+		if !ok {
+			return "harus berupa tanggal (YYYY-MM-DD)"
+		}
+		if _, err := time.Parse("2006-01-02", str); err != nil {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return "harus berupa tanggal (YYYY-MM-DD)"
+			}
+		}
+	}
+	return ""
+}
+
+// ======================================================
+// RULES
+// ======================================================
+
+func eventDateNotInFuture(a *model.AchievementMongo, now time.Time) []FieldError {
+	if a.Details.EventDate != nil && a.Details.EventDate.After(now) {
+		return []FieldError{{"details.eventDate", "tidak boleh di masa depan"}}
+	}
+	return nil
+}
+
+// medalRank memetakan medali ke peringkat yang sesuai.
+var medalRank = map[string]int{"gold": 1, "silver": 2, "bronze": 3}
+
+func competitionRankRule(a *model.AchievementMongo, _ time.Time) []FieldError {
+	d := a.Details
+	if d.Rank < 0 {
+		return []FieldError{{"details.rank", "harus bernilai 1 atau lebih"}}
+	}
+	if want, ok := medalRank[d.MedalType]; ok && d.Rank != 0 && d.Rank != want {
+		return []FieldError{{
+			"details.medalType",
+			fmt.Sprintf("medali %s hanya untuk peringkat %d", d.MedalType, want),
+		}}
+	}
+	return nil
+}
+
+func publicationRule(a *model.AchievementMongo, _ time.Time) []FieldError {
+	var errs []FieldError
+	d := a.Details
+
+	for i, author := range d.Authors {
+		if strings.TrimSpace(author) == "" {
+			errs = append(errs, FieldError{fmt.Sprintf("details.authors[%d]", i), "tidak boleh kosong"})
+		}
+	}
+
+	if d.ISSN != "" && !issnPattern.MatchString(d.ISSN) {
+		errs = append(errs, FieldError{"details.issn", "format harus NNNN-NNNN"})
+	}
+	if d.PublicationType == "journal" && d.ISSN == "" {
+		errs = append(errs, FieldError{"details.issn", "wajib diisi untuk publikasi jurnal"})
+	}
+
+	return errs
+}
+
+func periodRule(a *model.AchievementMongo, _ time.Time) []FieldError {
+	p := a.Details.Period
+	if p == nil {
+		return nil
+	}
+	if p.Start.IsZero() {
+		return []FieldError{{"details.period.start", "wajib diisi"}}
+	}
+	if !p.End.IsZero() && p.Start.After(p.End) {
+		return []FieldError{{"details.period.end", "tidak boleh sebelum period.start"}}
+	}
+	return nil
+}
+
+func validUntilRule(a *model.AchievementMongo, now time.Time) []FieldError {
+	if v := a.Details.ValidUntil; v != nil && !v.After(now) {
+		return []FieldError{{"details.validUntil", "sertifikat sudah tidak berlaku"}}
+	}
+	return nil
+}
+
+// ======================================================
+// UTIL
+// ======================================================
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
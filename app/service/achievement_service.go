@@ -1,10 +1,15 @@
 package service
 
 import (
+	"log"
+	"strings"
 	"time"
 
 	"prestasi_api/app/model"
 	"prestasi_api/app/repository"
+	"prestasi_api/imageproc"
+	"prestasi_api/scanner"
+	"prestasi_api/storage"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,6 +20,92 @@ type AchievementService struct {
 	MongoRepo    repository.AchievementMongoRepository
 	PostgresRepo repository.AchievementPostgresRepository
 	StudentRepo  repository.StudentPostgresRepository
+
+	// Schemas boleh nil → pakai DefaultSchemaRegistry
+	Schemas *SchemaRegistry
+	// TypeRepo boleh nil → hanya tipe bawaan yang dikenali
+	TypeRepo repository.AchievementTypePostgresRepository
+	// RuleRepo boleh nil → poin dihitung dengan defaultPointRuleSet
+	RuleRepo repository.PointRulePostgresRepository
+	// LedgerRepo boleh nil → perubahan poin tidak dicatat di ledger
+	LedgerRepo repository.PointsLedgerPostgresRepository
+	// TeamRepo boleh nil → prestasi hanya milik satu mahasiswa
+	TeamRepo repository.AchievementTeamPostgresRepository
+	// FingerprintRepo boleh nil → deteksi duplikat dilewati
+	FingerprintRepo repository.AchievementFingerprintPostgresRepository
+	// RequirementRepo boleh nil → syarat pengajuan memakai default bawaan
+	RequirementRepo repository.SubmissionRequirementPostgresRepository
+	// ClaimRepo boleh nil → tanpa antrian review dan klaim reviewer
+	ClaimRepo repository.ReviewClaimPostgresRepository
+	// DelegationRepo boleh nil → dosen wali hanya menangani bimbingannya sendiri
+	DelegationRepo repository.AdvisorDelegationPostgresRepository
+	// CommentRepo boleh nil → diskusi prestasi tidak tersedia
+	CommentRepo repository.AchievementCommentPostgresRepository
+	// AppealRepo boleh nil → banding atas penolakan tidak tersedia
+	AppealRepo repository.AchievementAppealPostgresRepository
+	// CommitteeRepo boleh nil → semua prestasi diputus satu reviewer
+	CommitteeRepo repository.CommitteePostgresRepository
+	// Storage boleh nil → lampiran disimpan di ./uploads (storage.Local)
+	Storage storage.Storage
+	// Scanner boleh nil → lampiran tidak dipindai malware
+	Scanner scanner.Scanner
+	// Images boleh nil → gambar diproses langsung di goroutine request
+	Images *imageproc.Pool
+	// UploadRepo boleh nil → upload bertahap (tus) tidak tersedia
+	UploadRepo repository.AttachmentUploadPostgresRepository
+	// Signer boleh nil → tautan lampiran bertanda tangan tidak tersedia
+	Signer *LinkSigner
+	// CodeRepo boleh nil → kode verifikasi publik tidak diterbitkan
+	CodeRepo repository.VerificationCodePostgresRepository
+	// PublicBaseURL: alamat publik untuk URL verifikasi di QR code;
+	// kosong → defaultPublicBaseURL
+	PublicBaseURL string
+	// UploadExpiry: sesi upload bertahap tanpa aktivitas selama ini dibuang;
+	// 0 → DefaultUploadExpiry
+	UploadExpiry time.Duration
+	// ResumableMaxSize: batas per file upload bertahap; 0 →
+	// DefaultResumableMaxSize
+	ResumableMaxSize int64
+	// AttachmentRetention: masa simpan lampiran prestasi yang dihapus sebelum
+	// dipurge; 0 → DefaultAttachmentRetention
+	AttachmentRetention time.Duration
+	// IntegrityRecheck: jarak minimum antar cek integritas lampiran yang
+	// sama; 0 → DefaultIntegrityRecheck
+	IntegrityRecheck time.Duration
+}
+
+// advisees: bimbingan sendiri + bimbingan yang didelegasikan saat ini.
+func (s *AchievementService) advisees(lecturerID string) (*AdviseeScope, error) {
+	return resolveAdvisees(s.StudentRepo, s.DelegationRepo, lecturerID, time.Now())
+}
+
+func (s *AchievementService) schemas() *SchemaRegistry {
+	if s.Schemas != nil {
+		return s.Schemas
+	}
+	return DefaultSchemaRegistry
+}
+
+// validateAchievement memvalidasi details terhadap tipe bawaan maupun custom.
+func (s *AchievementService) validateAchievement(a *model.AchievementMongo, now time.Time) ([]FieldError, error) {
+	if s.TypeRepo == nil {
+		return s.schemas().Validate(a, now), nil
+	}
+	return s.schemas().ValidateWithCatalog(a, now, s.TypeRepo)
+}
+
+// pointsFor menghitung poin dengan rule set yang sedang aktif.
+func (s *AchievementService) pointsFor(a *model.AchievementMongo) (int, error) {
+	calc := s.pointCalculator()
+	rs, err := calc.ActiveRuleSet()
+	if err != nil {
+		return 0, err
+	}
+	return calc.Evaluate(rs, a).Points, nil
+}
+
+func (s *AchievementService) pointCalculator() pointCalculator {
+	return pointCalculator{RuleRepo: s.RuleRepo, TypeRepo: s.TypeRepo, Schemas: s.schemas()}
 }
 
 
@@ -60,10 +151,30 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
     data.StudentID = studentID
 
     now := time.Now()
+
+    // === VALIDASI DETAIL PER TIPE ===
+    errs, err := s.validateAchievement(&data, now)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+    if s.TeamRepo != nil {
+        errs = append(errs, validateTeam(s.StudentRepo, studentID, &data)...)
+    } else if len(data.TeamMembers) > 0 {
+        errs = append(errs, FieldError{"teamMembers", "prestasi tim tidak didukung"})
+    }
+    if len(errs) > 0 {
+        return respondValidation(c, errs)
+    }
+
     data.CreatedAt = now
     data.UpdatedAt = now
+    // lampiran hanya lewat endpoint upload
+    data.Attachments = nil
 
-    data.Points = calculatePoints(&data)
+    data.Points, err = s.pointsFor(&data)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
 
     mongoID, err := s.MongoRepo.CreateAchievementMongo(&data)
     if err != nil {
@@ -80,8 +191,17 @@ func (s *AchievementService) Create(c *fiber.Ctx) error {
     }
 
     if err := s.PostgresRepo.CreateReferencePostgres(&ref); err != nil {
+        s.abortCreate(nil, mongoID)
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
+
+    // TEAM: pemilik confirmed, anggota lain menunggu konfirmasi
+    if members := teamMembersFor(ref.ID, studentID, &data, now); len(members) > 0 {
+        if err := s.TeamRepo.AddMembers(members); err != nil {
+            s.abortCreate(&ref, mongoID)
+            return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+        }
+    }
     // HISTORY: CREATED (status draft)
 s.saveHistory(
     ref.ID,
@@ -93,37 +213,30 @@ s.saveHistory(
 )
 
 
-    return c.JSON(fiber.Map{
+    response := fiber.Map{
         "message":     "Achievement created",
         "referenceID": ref.ID,
         "mongoID":     mongoID.Hex(),
-    })
-}
-
-
-func calculatePoints(a *model.AchievementMongo) int {
-    switch a.AchievementType {
-    case "competition":
-        switch a.Details.CompetitionLevel {
-        case "international":
-            return 200
-        case "national":
-            return 100
-        case "provincial":
-            return 60
-        case "city":
-            return 40
-        default:
-            return 20
+    }
+    // DUPLIKAT: hanya peringatan, prestasi tetap tersimpan
+    if matches := s.indexFingerprints(&ref, &data); len(matches) > 0 {
+        response["duplicates"] = duplicateWarnings(studentID, matches)
+    }
+
+    return c.JSON(response)
+}
+
+// abortCreate menghapus referensi (bila sudah dibuat) dan dokumen Mongo
+// ketika Create gagal di tengah jalan, supaya tidak tersisa prestasi
+// setengah jadi (mis. prestasi tim tanpa anggota).
+func (s *AchievementService) abortCreate(ref *model.AchievementReference, mongoID primitive.ObjectID) {
+    if ref != nil {
+        if err := s.PostgresRepo.DeleteReferencePostgres(ref.ID); err != nil {
+            log.Printf("create: gagal membatalkan referensi %s: %v", ref.ID, err)
         }
-    case "publication":
-        return 150
-    case "organization":
-        return 50
-    case "certification":
-        return 80
-    default:
-        return 10
+    }
+    if err := s.MongoRepo.DeleteAchievementMongo(mongoID); err != nil {
+        log.Printf("create: gagal membatalkan dokumen %s: %v", mongoID.Hex(), err)
     }
 }
 
@@ -150,6 +263,44 @@ func (s *AchievementService) Submit(c *fiber.Ctx) error {
     if ref.Status != "draft" {
         return c.Status(400).JSON(fiber.Map{"error": "only draft can be submitted"})
     }
+
+    // Validasi ulang isi prestasi (mis. validUntil bisa sudah lewat sejak draft dibuat)
+    oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+    achievement, err := s.MongoRepo.GetByID(oid)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+    }
+    errs, err := s.validateAchievement(achievement, time.Now())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+    if len(errs) > 0 {
+        return respondValidation(c, errs)
+    }
+
+    // Syarat pengajuan per tipe (lampiran & field wajib)
+    req, err := s.submissionRequirement(achievement)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+    if unmet := evaluateRequirement(req, achievement); len(unmet) > 0 {
+        return c.Status(400).JSON(fiber.Map{
+            "error": "syarat pengajuan belum terpenuhi",
+            "unmet": unmet,
+        })
+    }
+
+    // Prestasi tim baru bisa diajukan setelah semua anggota merespons
+    pending, err := s.pendingMembers(ref.ID)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+    if len(pending) > 0 {
+        return c.Status(400).JSON(fiber.Map{
+            "error":           "menunggu konfirmasi anggota tim",
+            "pending_members": pending,
+        })
+    }
 // HISTORY: submit
 s.saveHistory(
     ref.ID,
@@ -168,7 +319,12 @@ s.saveHistory(
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    return c.JSON(fiber.Map{"message": "Achievement submitted"})
+    response := fiber.Map{"message": "Achievement submitted"}
+    if matches := s.indexFingerprints(ref, achievement); len(matches) > 0 {
+        response["duplicates"] = duplicateWarnings(ref.StudentID, matches)
+    }
+
+    return c.JSON(response)
 }
 
 
@@ -198,6 +354,16 @@ func (s *AchievementService) Delete(c *fiber.Ctx) error {
     //=== Admin bebas delete tanpa syarat ===
 
     oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+
+    // prestasi terverifikasi yang dihapus → poinnya dicabut dari ledger
+    if ref.Status == "verified" {
+        if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+            s.recordLedger(ref, ach, model.LedgerRevoked, 0,
+                c.Locals("user_id").(string), role, "achievement deleted")
+        }
+        s.revokeVerificationCode(ref.ID, c.Locals("user_id").(string), "achievement deleted")
+    }
+
     if err := s.MongoRepo.SoftDeleteAchievementMongo(oid); err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
@@ -225,12 +391,12 @@ func (s *AchievementService) GetAdviseeAchievements(c *fiber.Ctx) error {
 	advisorID := c.Locals("lecturer_id").(string)
 
 
-	studentIDs, err := s.StudentRepo.GetStudentIDsByAdvisor(advisorID)
+	scope, err := s.advisees(advisorID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	refs, err := s.PostgresRepo.GetByStudentIDs(studentIDs)
+	refs, err := s.PostgresRepo.GetByStudentIDs(scope.IDs())
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -251,110 +417,163 @@ func (s *AchievementService) GetAdviseeAchievements(c *fiber.Ctx) error {
 }
 
 
-// FR-007 — VERIFY ACHIEVEMENT
-func (s *AchievementService) Verify(c *fiber.Ctx) error {
-    advisorLecturerID := c.Locals("lecturer_id").(string)
-    advisorUserID := c.Locals("user_id").(string) // <-- pakai user_id
+// reviewActor: dosen wali / admin yang memverifikasi atau menolak.
+type reviewActor struct {
+    UserID     string
+    LecturerID string
+    Role       string
+}
+
+func reviewActorFrom(c *fiber.Ctx) reviewActor {
+    userID, _ := c.Locals("user_id").(string)
+    lecturerID, _ := c.Locals("lecturer_id").(string)
     role, _ := c.Locals("role").(string)
+    return reviewActor{UserID: userID, LecturerID: lecturerID, Role: role}
+}
 
-    refID := c.Params("refId")
+// reviewError membawa status HTTP supaya Verify/Reject dan versi batch
+// mengembalikan kode dan pesan yang sama.
+type reviewError struct {
+    Status  int
+    Message string
+}
+
+func (e *reviewError) Error() string { return e.Message }
+
+func respondReviewError(c *fiber.Ctx, err error) error {
+    if re, ok := err.(*reviewError); ok {
+        return c.Status(re.Status).JSON(fiber.Map{"error": re.Message})
+    }
+    return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// authorizeReview: dosen wali hanya untuk prestasi mahasiswa bimbingan
+// (pemilik atau salah satu anggota tim), admin bebas. onBehalfOf terisi
+// bila akses dosen wali berasal dari delegasi.
+func (s *AchievementService) authorizeReview(actor reviewActor, ref *model.AchievementReference) (onBehalfOf string, err error) {
+    if actor.Role == "Dosen Wali" {
+        ok, onBehalfOf := s.adviseeAccess(actor.LecturerID, ref)
+        if !ok {
+            return "", &reviewError{400, "bukan mahasiswa bimbingan"}
+        }
+        return onBehalfOf, nil
+    }
+    if actor.Role != "Admin" {
+        // only Dosen Wali or Admin can review
+        return "", &reviewError{403, "Forbidden"}
+    }
+    return "", nil
+}
 
+// verifyReference: inti FR-007, dipakai Verify dan BatchVerify.
+func (s *AchievementService) verifyReference(actor reviewActor, refID, note string) error {
     ref, err := s.PostgresRepo.GetReferenceByID(refID)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"error": "reference tidak ditemukan"})
+        return &reviewError{404, "reference tidak ditemukan"}
     }
 
-    // If role is Dosen Wali => validate advisee
-    if role == "Dosen Wali" {
-        studentIDs, _ := s.StudentRepo.GetStudentIDsByAdvisor(advisorLecturerID)
-        valid := false
-        for _, sid := range studentIDs {
-            if sid == ref.StudentID {
-                valid = true
-                break
-            }
-        }
-        if !valid {
-            return c.Status(400).JSON(fiber.Map{"error": "bukan mahasiswa bimbingan"})
-        }
-    } else if role != "Admin" {
-        // only Dosen Wali or Admin can verify
-        return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+    onBehalfOf, err := s.authorizeReview(actor, ref)
+    if err != nil {
+        return err
     }
 
     if ref.Status != "submitted" {
-        return c.Status(400).JSON(fiber.Map{"error": "hanya status submitted yang bisa diverifikasi"})
+        return &reviewError{400, "hanya status submitted yang bisa diverifikasi"}
+    }
+
+    if err := s.checkClaim(actor, ref.ID); err != nil {
+        return err
+    }
+    if err := s.requireSingleReviewer(ref); err != nil {
+        return err
     }
 // HISTORY: verify
-s.saveHistory(
+s.saveHistoryOnBehalf(
     ref.ID,
     ref.Status,
     "verified",
-    advisorUserID,
-    role,
-    "",
+    actor.UserID,
+    actor.Role,
+    note,
+    onBehalfOf,
 )
 
     // verifier id: for Admin use user_id claim as well
-    if err := s.PostgresRepo.UpdateVerifyStatus(refID, advisorUserID); err != nil { // <-- save user_id
-        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    if err := s.PostgresRepo.UpdateVerifyStatus(refID, actor.UserID); err != nil { // <-- save user_id
+        return err
     }
+    s.releaseClaim(ref.ID)
 
-    return c.JSON(fiber.Map{"message": "Prestasi berhasil diverifikasi"})
-}
-
-
-// FR-008 — REJECT ACHIEVEMENT
-func (s *AchievementService) Reject(c *fiber.Ctx) error {
-    advisorLecturerID := c.Locals("lecturer_id").(string)
-    advisorUserID := c.Locals("user_id").(string) // <-- pakai user_id
-    role, _ := c.Locals("role").(string)
-
-    refID := c.Params("refId")
-
-    var body struct {
-        Note string `json:"note"`
+    // LEDGER: poin prestasi masuk saldo mahasiswa
+    oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+    if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+        s.recordLedger(ref, ach, model.LedgerEarned, ach.Points, actor.UserID, actor.Role, "")
     }
-    c.BodyParser(&body)
+    s.recordVerificationCode(ref.ID)
+
+    return nil
+}
 
+// rejectReference: inti FR-008, dipakai Reject dan BatchReject.
+func (s *AchievementService) rejectReference(actor reviewActor, refID, note string) error {
     ref, err := s.PostgresRepo.GetReferenceByID(refID)
     if err != nil {
-        return c.Status(404).JSON(fiber.Map{"error": "reference tidak ditemukan"})
+        return &reviewError{404, "reference tidak ditemukan"}
     }
 
     if ref.Status != "submitted" {
-        return c.Status(400).JSON(fiber.Map{"error": "hanya status submitted yang bisa ditolak"})
+        return &reviewError{400, "hanya status submitted yang bisa ditolak"}
     }
 
-    // If role is Dosen Wali => validate advisee
-    if role == "Dosen Wali" {
-        studentIDs, _ := s.StudentRepo.GetStudentIDsByAdvisor(advisorLecturerID)
-        valid := false
-        for _, sid := range studentIDs {
-            if sid == ref.StudentID {
-                valid = true
-                break
-            }
-        }
-        if !valid {
-            return c.Status(400).JSON(fiber.Map{"error": "bukan mahasiswa bimbingan"})
-        }
-    } else if role != "Admin" {
-        // only Dosen Wali or Admin can reject
-        return c.Status(403).JSON(fiber.Map{"error": "Forbidden"})
+    onBehalfOf, err := s.authorizeReview(actor, ref)
+    if err != nil {
+        return err
+    }
+
+    if err := s.checkClaim(actor, ref.ID); err != nil {
+        return err
+    }
+    if err := s.requireSingleReviewer(ref); err != nil {
+        return err
     }
 // HISTORY: reject
-s.saveHistory(
+s.saveHistoryOnBehalf(
     ref.ID,
     ref.Status,
     "rejected",
-    advisorUserID,
-    role,
-    body.Note,
+    actor.UserID,
+    actor.Role,
+    note,
+    onBehalfOf,
 )
 
-    if err := s.PostgresRepo.RejectReference(refID, advisorUserID, body.Note); err != nil { // <-- save user_id
-        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    if err := s.PostgresRepo.RejectReference(refID, actor.UserID, note); err != nil { // <-- save user_id
+        return err
+    }
+    s.releaseClaim(ref.ID)
+
+    return nil
+}
+
+// FR-007 — VERIFY ACHIEVEMENT
+func (s *AchievementService) Verify(c *fiber.Ctx) error {
+    if err := s.verifyReference(reviewActorFrom(c), c.Params("refId"), ""); err != nil {
+        return respondReviewError(c, err)
+    }
+
+    return c.JSON(fiber.Map{"message": "Prestasi berhasil diverifikasi"})
+}
+
+
+// FR-008 — REJECT ACHIEVEMENT
+func (s *AchievementService) Reject(c *fiber.Ctx) error {
+    var body struct {
+        Note string `json:"note"`
+    }
+    c.BodyParser(&body)
+
+    if err := s.rejectReference(reviewActorFrom(c), c.Params("refId"), body.Note); err != nil {
+        return respondReviewError(c, err)
     }
 
     return c.JSON(fiber.Map{"message": "Prestasi berhasil ditolak"})
@@ -373,6 +592,12 @@ func (s *AchievementService) ListOwn(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// prestasi tim yang diikuti (termasuk yang menunggu konfirmasi)
+	refs, err = s.teamReferences(refs, []string{studentID}, true)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	var result []map[string]interface{}
 	for _, ref := range refs {
 		oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
@@ -401,10 +626,12 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
   if role == "Dosen Wali" {
     advisorID, _ := c.Locals("lecturer_id").(string)
 
-    studentIDs, err := s.StudentRepo.GetStudentIDsByAdvisor(advisorID)
+    // termasuk bimbingan yang sedang didelegasikan kepadanya
+    scope, err := s.advisees(advisorID)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
+    studentIDs := scope.IDs()
 
     // Tidak punya mahasiswa bimbingan
     if len(studentIDs) == 0 {
@@ -416,6 +643,12 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
+    // prestasi tim dengan anggota confirmed dari mahasiswa bimbingan
+    refs, err = s.teamReferences(refs, studentIDs, false)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+
     // Ada mahasiswa tapi belum ada prestasi
     if len(refs) == 0 {
         return c.JSON(fiber.Map{"message": "Belum ada prestasi dari mahasiswa bimbingan"})
@@ -440,14 +673,21 @@ func (s *AchievementService) List(c *fiber.Ctx) error {
 
 // Helper to format response
 func buildAchievementResponse(c *fiber.Ctx, refs []model.AchievementReference, s *AchievementService) error {
+    refIDs := make([]string, 0, len(refs))
+    for _, ref := range refs {
+        refIDs = append(refIDs, ref.ID)
+    }
+    duplicates := s.storedDuplicates(refIDs)
+
     var result []map[string]interface{}
     for _, ref := range refs {
         oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
         ach, _ := s.MongoRepo.GetByID(oid)
 
         result = append(result, map[string]interface{}{
-            "reference":   ref,
-            "achievement": ach,
+            "reference":          ref,
+            "achievement":        ach,
+            "possible_duplicate": len(duplicates[ref.ID]) > 0,
         })
     }
     return c.JSON(result)
@@ -463,34 +703,10 @@ func (s *AchievementService) Detail(c *fiber.Ctx) error {
         return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
     }
 
-    // ROLE: Mahasiswa → hanya miliknya sendiri
-    if role == "Mahasiswa" {
-        studentID := c.Locals("student_id").(string)
-        if ref.StudentID != studentID {
-            return c.Status(403).JSON(fiber.Map{"error": "not your achievement"})
-        }
-    }
-
-    // ROLE: Dosen Wali → hanya mahasiswa bimbingan
-    if role == "Dosen Wali" {
-        lecturerID := c.Locals("lecturer_id").(string)
-
-        studentIDs, _ := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
-
-        isAdvisee := false
-        for _, sid := range studentIDs {
-            if sid == ref.StudentID {
-                isAdvisee = true
-                break
-            }
-        }
-        if !isAdvisee {
-            return c.Status(403).JSON(fiber.Map{"error": "not your advisee"})
-        }
+    if err := s.authorizeView(c, ref); err != nil {
+        return respondReviewError(c, err)
     }
 
-    // ROLE: Admin → bebas akses (tidak perlu validasi)
-
     // Ambil data dari Mongo
     oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
     achievement, err := s.MongoRepo.GetByID(oid)
@@ -498,13 +714,47 @@ func (s *AchievementService) Detail(c *fiber.Ctx) error {
         return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
     }
 
-    return c.JSON(fiber.Map{
+    response := fiber.Map{
         "reference":   ref,
         "achievement": achievement,
-    })
+    }
+    if members := s.teamMembers(ref.ID); len(members) > 0 {
+        response["team"] = members
+    }
+
+    // DUPLIKAT: reviewer melihat flag + tautan, mahasiswa hanya peringatan
+    if matches := s.storedDuplicates([]string{ref.ID})[ref.ID]; len(matches) > 0 {
+        if role == "Mahasiswa" {
+            response["duplicates"] = duplicateWarnings(ref.StudentID, matches)
+        } else {
+            response["possible_duplicate"] = true
+            response["duplicates"] = duplicateLinks(matches)
+        }
+    }
+
+    return c.JSON(response)
 }
 
 
+// authorizeView: aturan visibilitas Detail, dipakai juga oleh diskusi.
+// Mahasiswa → hanya miliknya sendiri (termasuk anggota tim yang belum konfirmasi),
+// Dosen Wali → hanya mahasiswa bimbingan, Admin → bebas akses.
+func (s *AchievementService) authorizeView(c *fiber.Ctx, ref *model.AchievementReference) error {
+    switch role, _ := c.Locals("role").(string); role {
+    case "Mahasiswa":
+        studentID, _ := c.Locals("student_id").(string)
+        if !s.isParticipant(ref, studentID, true) {
+            return &reviewError{403, "not your achievement"}
+        }
+    case "Dosen Wali":
+        lecturerID, _ := c.Locals("lecturer_id").(string)
+        if !s.isAdviseeAchievement(lecturerID, ref) {
+            return &reviewError{403, "not your advisee"}
+        }
+    }
+    return nil
+}
+
 // FR — UPDATE ACHIEVEMENT
 func (s *AchievementService) Update(c *fiber.Ctx) error {
     refID := c.Params("refId")
@@ -548,11 +798,48 @@ func (s *AchievementService) Update(c *fiber.Ctx) error {
     body.StudentID = "" // supaya tidak ke-set ulang di Mongo
 
     oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+    stored, err := s.MongoRepo.GetByID(oid)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+    }
+    // tim & pembagian poin tidak ikut disimpan Update → pakai yang tersimpan
+    body.TeamMembers = stored.TeamMembers
+    body.PointSplit = stored.PointSplit
+
+    errs, err := s.validateAchievement(&body, time.Now())
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+    if len(errs) > 0 {
+        return respondValidation(c, errs)
+    }
+
+    // tipe/detail bisa berubah → poin dihitung ulang, bukan diambil dari body
+    scored := body
+    scored.StudentID = stored.StudentID // ukuran tim menghitung pemilik
+    body.Points, err = s.pointsFor(&scored)
+    if err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+    }
+
     if err := s.MongoRepo.UpdateAchievementMongo(oid, &body); err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
 
-    return c.JSON(fiber.Map{"message": "Achievement updated"})
+    // prestasi terverifikasi diubah admin → selisih poin dicatat di ledger
+    if ref.Status == "verified" {
+        if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+            s.recordLedger(ref, ach, model.LedgerRecalculated, body.Points,
+                c.Locals("user_id").(string), role, "achievement updated")
+        }
+    }
+
+    response := fiber.Map{"message": "Achievement updated"}
+    if matches := s.indexFingerprints(ref, &body); len(matches) > 0 {
+        response["duplicates"] = duplicateWarnings(ref.StudentID, matches)
+    }
+
+    return c.JSON(response)
 }
 
 
@@ -573,6 +860,14 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
         return c.Status(400).JSON(fiber.Map{"error": "file is required"})
     }
 
+    // jenis lampiran dipakai untuk syarat pengajuan
+    kind := c.FormValue("kind", "other")
+    if !contains(model.AttachmentKinds, kind) {
+        return c.Status(400).JSON(fiber.Map{
+            "error": "kind harus salah satu dari: " + strings.Join(model.AttachmentKinds, ", "),
+        })
+    }
+
     ref, err := s.PostgresRepo.GetReferenceByID(refID)
     if err != nil {
         return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
@@ -588,18 +883,34 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
             })
         }
     }
+    if err := attachmentsUploadable(c, ref); err != nil {
+        return respondReviewError(c, err)
+    }
 
     oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+    ach, err := s.MongoRepo.GetByID(oid)
+    if err != nil {
+        return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+    }
+
+    // tipe dari isi file, ukuran & kuota prestasi
+    upload, errs := s.validateUpload(c.Context(), ach, file, kind)
+    if len(errs) > 0 {
+        return respondValidation(c, errs)
+    }
 
-    url, err := s.MongoRepo.AddAttachmentMongo(oid, file)
+    att, err := s.storeAttachment(c, oid, upload)
     if err != nil {
         return c.Status(500).JSON(fiber.Map{"error": err.Error()})
     }
+    s.queueThumbnails(*att)
 
-    return c.JSON(fiber.Map{
-        "message": "Attachment uploaded",
-        "url":     url,
-    })
+    response := uploadedResponse(ref, att)
+    if matches := s.indexAttachmentHash(ref, att.SHA256); len(matches) > 0 {
+        response["duplicates"] = duplicateWarnings(ref.StudentID, matches)
+    }
+
+    return c.JSON(response)
 }
 
 
@@ -621,7 +932,7 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
     // Mahasiswa → hanya miliknya sendiri
     if role == "Mahasiswa" {
         studentID := c.Locals("student_id").(string)
-        if ref.StudentID != studentID {
+        if !s.isParticipant(ref, studentID, true) {
             return c.Status(403).JSON(fiber.Map{
                 "error": "Anda tidak boleh mengakses history prestasi orang lain",
             })
@@ -632,17 +943,7 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
     if role == "Dosen Wali" {
         lecturerID := c.Locals("lecturer_id").(string)
 
-        adviseeIDs, _ := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
-
-        isAdvisee := false
-        for _, sid := range adviseeIDs {
-            if sid == ref.StudentID {
-                isAdvisee = true
-                break
-            }
-        }
-
-        if !isAdvisee {
+        if !s.isAdviseeAchievement(lecturerID, ref) {
             return c.Status(403).JSON(fiber.Map{
                 "error": "Prestasi ini bukan milik mahasiswa bimbingan Anda",
             })
@@ -661,6 +962,11 @@ func (s *AchievementService) History(c *fiber.Ctx) error {
 
 
 func (s *AchievementService) saveHistory(refID, oldStatus, newStatus, userID, role, note string) error {
+    return s.saveHistoryOnBehalf(refID, oldStatus, newStatus, userID, role, note, "")
+}
+
+// saveHistoryOnBehalf: aksi dosen delegasi dicatat atas nama dosen wali asli.
+func (s *AchievementService) saveHistoryOnBehalf(refID, oldStatus, newStatus, userID, role, note, onBehalfOf string) error {
     h := model.AchievementReferenceHistory{
         ID:            uuid.New().String(),
         ReferenceID:   refID,
@@ -671,6 +977,12 @@ func (s *AchievementService) saveHistory(refID, oldStatus, newStatus, userID, ro
         ChangedByRole: role,
         CreatedAt:     time.Now(),
     }
+    if onBehalfOf != "" {
+        h.OnBehalfOf = &onBehalfOf
+    }
+    if isVerificationRecord(newStatus, role) {
+        h.Evidence = s.attachmentEvidence(refID)
+    }
 
     return s.PostgresRepo.InsertHistory(&h)
 }
@@ -682,18 +994,7 @@ if err != nil {
 	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 }
 
-var result []map[string]interface{}
-for _, ref := range refs {
-	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
-	ach, _ := s.MongoRepo.GetByID(oid)
-
-	result = append(result, map[string]interface{}{
-		"reference":   ref,
-		"achievement": ach,
-	})
-}
-
-return c.JSON(result)
+return buildAchievementResponse(c, refs, s)
 }
 
 
@@ -7,7 +7,6 @@ import (
     "net/http/httptest"
     "testing"
     "time"
-	"mime/multipart"
 
     "prestasi_api/app/model"
 
@@ -69,7 +68,17 @@ func (m *MockAchievementMongoRepo) CreateAchievementMongo(a *model.AchievementMo
 	return primitive.NewObjectID(), nil
 }
 func (m *MockAchievementMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
-	return &model.AchievementMongo{Title: "Dummy"}, nil
+	return &model.AchievementMongo{
+		Title:           "Dummy",
+		AchievementType: "competition",
+		Details: model.AchievementDetails{
+			CompetitionName:  "Dummy Cup",
+			CompetitionLevel: "national",
+		},
+		Attachments: []model.Attachment{
+			{FileName: "sertifikat.pdf", FileURL: "/uploads/sertifikat.pdf", FileType: "application/pdf", Kind: "certificate"},
+		},
+	}, nil
 }
 func (m *MockAchievementMongoRepo) UpdateAchievementMongo(id primitive.ObjectID, a *model.AchievementMongo) error {
 	return nil
@@ -77,11 +86,44 @@ func (m *MockAchievementMongoRepo) UpdateAchievementMongo(id primitive.ObjectID,
 func (m *MockAchievementMongoRepo) SoftDeleteAchievementMongo(id primitive.ObjectID) error {
 	return nil
 }
-func (m *MockAchievementMongoRepo) AddAttachmentMongo(
-	id primitive.ObjectID,
-	file *multipart.FileHeader,
-) (string, error) {
-	return "http://dummy.url/file.pdf", nil
+func (m *MockAchievementMongoRepo) DeleteAchievementMongo(id primitive.ObjectID) error {
+	return nil
+}
+func (m *MockAchievementMongoRepo) AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error {
+	return nil
+}
+func (m *MockAchievementMongoRepo) ListWithAttachments() ([]model.AchievementMongo, error) {
+	return []model.AchievementMongo{}, nil
+}
+func (m *MockAchievementMongoRepo) SetAttachmentStorageKey(id primitive.ObjectID, index int, key string) error {
+	return nil
+}
+func (m *MockAchievementMongoRepo) SetAttachmentMongo(id primitive.ObjectID, index int, a model.Attachment) error {
+	return nil
+}
+func (m *MockAchievementMongoRepo) ListPurgeable(deletedBefore time.Time) ([]model.AchievementMongo, error) {
+	return []model.AchievementMongo{}, nil
+}
+func (m *MockAchievementMongoRepo) MarkAttachmentsPurged(id primitive.ObjectID, at time.Time) error {
+	return nil
+}
+func (m *MockAchievementMongoRepo) ListPendingScan(limit int) ([]model.AchievementMongo, error) {
+	return []model.AchievementMongo{}, nil
+}
+func (m *MockAchievementMongoRepo) SetAttachmentScan(storageKey string, status, signature, newKey string, at time.Time) (bool, error) {
+	return true, nil
+}
+func (m *MockAchievementMongoRepo) CountStorageKeyRefs(storageKey string, exclude primitive.ObjectID) (int64, error) {
+	return 0, nil
+}
+func (m *MockAchievementMongoRepo) ListIntegrityDue(checkedBefore time.Time, limit int) ([]model.AchievementMongo, error) {
+	return []model.AchievementMongo{}, nil
+}
+func (m *MockAchievementMongoRepo) SetAttachmentIntegrity(storageKey, status string, at time.Time) error {
+	return nil
+}
+func (m *MockAchievementMongoRepo) ListIntegrityIssues() ([]model.AchievementMongo, error) {
+	return []model.AchievementMongo{}, nil
 }
 func (m *MockAchievementMongoRepo) GetAll() ([]model.AchievementMongo, error) {
 	return []model.AchievementMongo{}, nil
@@ -92,12 +134,21 @@ func (m *MockAchievementMongoRepo) GetAllForReport() ([]model.AchievementMongo,
 func (m *MockAchievementMongoRepo) RestoreAchievementMongo(id primitive.ObjectID) error {
 	return nil
 }
+func (m *MockAchievementMongoRepo) UpdatePointsMongo(id primitive.ObjectID, points int) error {
+	return nil
+}
 
 
 // ---------- Postgres Repo ----------
 func (m *MockAchievementPostgresRepo) CreateReferencePostgres(r *model.AchievementReference) error {
 	return nil
 }
+func (m *MockAchievementPostgresRepo) DeleteReferencePostgres(id string) error {
+	return nil
+}
+func (m *MockAchievementPostgresRepo) DecideSubmitted(id, status, userID, note string) (bool, error) {
+	return true, nil
+}
 func (m *MockAchievementPostgresRepo) GetReferenceByID(id string) (*model.AchievementReference, error) {
 	return &model.AchievementReference{
 		ID:        id,
@@ -174,6 +225,7 @@ func TestCreateAchievement_Mahasiswa_Success(t *testing.T) {
 		"achievementType": "competition",
 		"title":           "Juara Nasional",
 		"details": map[string]interface{}{
+			"competitionName":  "Gemastik",
 			"competitionLevel": "national",
 		},
 	}
@@ -401,3 +453,89 @@ func TestAchievementList_Admin(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
 }
+
+func postCreateAchievement(t *testing.T, payload map[string]interface{}) (*http.Response, map[string]interface{}) {
+	svc, app := setupAchievementService()
+
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", uuid.New().String())
+		return svc.Create(c)
+	})
+
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func TestCreateAchievement_CompetitionWithoutName_ShouldFail(t *testing.T) {
+	resp, out := postCreateAchievement(t, map[string]interface{}{
+		"achievementType": "competition",
+		"title":           "Juara",
+		"details": map[string]interface{}{
+			"competitionLevel": "national",
+		},
+	})
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Contains(t, out["fields"], map[string]interface{}{
+		"field":   "details.competitionName",
+		"message": "wajib diisi untuk tipe competition",
+	})
+}
+
+func TestCreateAchievement_PublicationWithMedal_ShouldFail(t *testing.T) {
+	resp, out := postCreateAchievement(t, map[string]interface{}{
+		"achievementType": "publication",
+		"title":           "Paper",
+		"details": map[string]interface{}{
+			"publicationType":  "conference",
+			"publicationTitle": "Deteksi Objek",
+			"authors":          []string{"A"},
+			"medalType":        "gold",
+		},
+	})
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Contains(t, out["fields"], map[string]interface{}{
+		"field":   "details.medalType",
+		"message": "tidak boleh diisi untuk tipe publication",
+	})
+}
+
+func TestSchemaRegistry_DateRules(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.AddDate(0, -1, 0)
+
+	org := &model.AchievementMongo{
+		Title:           "Ketua BEM",
+		AchievementType: "organization",
+		Details: model.AchievementDetails{
+			OrganizationName: "BEM",
+			Position:         "Ketua",
+			Period:           &model.Period{Start: now, End: past},
+		},
+	}
+	assert.Equal(t, []FieldError{{"details.period.end", "tidak boleh sebelum period.start"}},
+		DefaultSchemaRegistry.Validate(org, now))
+
+	cert := &model.AchievementMongo{
+		Title:           "TOEFL",
+		AchievementType: "certification",
+		Details: model.AchievementDetails{
+			CertificationName: "TOEFL",
+			IssuedBy:          "ETS",
+			ValidUntil:        &past,
+		},
+	}
+	assert.Equal(t, []FieldError{{"details.validUntil", "sertifikat sudah tidak berlaku"}},
+		DefaultSchemaRegistry.Validate(cert, now))
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Aturan pembagian poin prestasi tim:
+//   - equal: poin dibagi rata, sisa pembagian ke leader lebih dulu
+//   - full: setiap anggota mendapat poin penuh
+//   - lead_bonus: leader berbobot 2, anggota lain berbobot 1
+var pointSplitRules = []string{"equal", "full", "lead_bonus"}
+
+const (
+	defaultPointSplit = "equal"
+	teamRoleLeader    = "leader"
+)
+
+// validateTeam memeriksa daftar anggota tim pada body create.
+func validateTeam(students repository.StudentPostgresRepository, ownerID string, a *model.AchievementMongo) []FieldError {
+	var errs []FieldError
+
+	if a.PointSplit != "" && !contains(pointSplitRules, a.PointSplit) {
+		errs = append(errs, FieldError{"pointSplit", "harus salah satu dari: " + strings.Join(pointSplitRules, ", ")})
+	}
+
+	seen := map[string]bool{}
+	for i, m := range a.TeamMembers {
+		path := fmt.Sprintf("teamMembers[%d]", i)
+
+		if m.StudentID == "" {
+			errs = append(errs, FieldError{path + ".studentId", "wajib diisi"})
+			continue
+		}
+		if seen[m.StudentID] {
+			errs = append(errs, FieldError{path + ".studentId", "duplikat"})
+			continue
+		}
+		seen[m.StudentID] = true
+
+		if m.Role == "" {
+			errs = append(errs, FieldError{path + ".role", "wajib diisi"})
+		}
+		if m.StudentID != ownerID {
+			if _, err := students.GetByID(m.StudentID); err != nil {
+				errs = append(errs, FieldError{path + ".studentId", "mahasiswa tidak ditemukan"})
+			}
+		}
+	}
+
+	return errs
+}
+
+// teamMembersFor menyusun baris keanggotaan: pemilik langsung confirmed,
+// anggota lain pending sampai mengonfirmasi sendiri.
+func teamMembersFor(refID, ownerID string, a *model.AchievementMongo, now time.Time) []model.AchievementTeamMember {
+	if len(a.TeamMembers) == 0 {
+		return nil
+	}
+
+	ownerRole := teamRoleLeader
+	var members []model.AchievementTeamMember
+	for _, m := range a.TeamMembers {
+		if m.StudentID == ownerID {
+			ownerRole = m.Role
+			continue
+		}
+		members = append(members, model.AchievementTeamMember{
+			ReferenceID: refID,
+			StudentID:   m.StudentID,
+			TeamRole:    m.Role,
+			Status:      "pending",
+			CreatedAt:   now,
+		})
+	}
+
+	owner := model.AchievementTeamMember{
+		ReferenceID: refID,
+		StudentID:   ownerID,
+		TeamRole:    ownerRole,
+		Status:      "confirmed",
+		RespondedAt: &now,
+		CreatedAt:   now,
+	}
+	return append([]model.AchievementTeamMember{owner}, members...)
+}
+
+// splitPoints membagi total poin ke anggota confirmed sesuai aturan.
+func splitPoints(total int, members []model.AchievementTeamMember, rule string) map[string]int {
+	shares := map[string]int{}
+	if len(members) == 0 {
+		return shares
+	}
+
+	// leader dulu, lalu urut student id supaya sisa pembagian deterministik
+	ordered := append([]model.AchievementTeamMember(nil), members...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		li, lj := ordered[i].TeamRole == teamRoleLeader, ordered[j].TeamRole == teamRoleLeader
+		if li != lj {
+			return li
+		}
+		return ordered[i].StudentID < ordered[j].StudentID
+	})
+
+	if rule == "full" {
+		for _, m := range ordered {
+			shares[m.StudentID] = total
+		}
+		return shares
+	}
+
+	weights := make([]int, len(ordered))
+	sum := 0
+	for i, m := range ordered {
+		weights[i] = 1
+		if rule == "lead_bonus" && m.TeamRole == teamRoleLeader {
+			weights[i] = 2
+		}
+		sum += weights[i]
+	}
+
+	given := 0
+	for i, m := range ordered {
+		shares[m.StudentID] = total * weights[i] / sum
+		given += shares[m.StudentID]
+	}
+	for i := 0; given < total; i = (i + 1) % len(ordered) {
+		shares[ordered[i].StudentID]++
+		given++
+	}
+
+	return shares
+}
+
+// pointShares: poin per mahasiswa untuk satu prestasi. Tanpa tim (atau tanpa
+// TeamRepo) seluruh poin milik pemilik.
+func pointShares(team repository.AchievementTeamPostgresRepository, ref *model.AchievementReference, ach *model.AchievementMongo, points int) (map[string]int, error) {
+	if team == nil {
+		return map[string]int{ref.StudentID: points}, nil
+	}
+
+	members, err := team.ListMembers(ref.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmed []model.AchievementTeamMember
+	for _, m := range members {
+		if m.Status == "confirmed" {
+			confirmed = append(confirmed, m)
+		}
+	}
+	if len(confirmed) == 0 {
+		return map[string]int{ref.StudentID: points}, nil
+	}
+
+	rule := ach.PointSplit
+	if rule == "" {
+		rule = defaultPointSplit
+	}
+	return splitPoints(points, confirmed, rule), nil
+}
+
+// settleLedger menyamakan ledger satu prestasi dengan poin terbarunya:
+// setiap mahasiswa mendapat entri sebesar selisih bagiannya dengan yang
+// sudah tercatat. Untuk revoked seluruh bagian dikembalikan ke 0.
+func settleLedger(
+	ledger repository.PointsLedgerPostgresRepository,
+	team repository.AchievementTeamPostgresRepository,
+	ref *model.AchievementReference,
+	ach *model.AchievementMongo,
+	entryType string,
+	points int,
+	actorID, role, reason string,
+) error {
+	if ledger == nil {
+		return nil
+	}
+
+	target := map[string]int{}
+	if entryType != model.LedgerRevoked {
+		shares, err := pointShares(team, ref, ach, points)
+		if err != nil {
+			return err
+		}
+		target = shares
+	}
+
+	nets, err := ledger.ReferenceNets(ref.ID)
+	if err != nil {
+		return err
+	}
+
+	students := map[string]bool{}
+	for sid := range target {
+		students[sid] = true
+	}
+	for sid := range nets {
+		students[sid] = true
+	}
+
+	for _, sid := range sortedKeys(students) {
+		delta := target[sid] - nets[sid]
+		if delta == 0 && entryType != model.LedgerEarned {
+			continue
+		}
+
+		refID := ref.ID
+		if err := appendLedger(ledger, model.PointsLedgerEntry{
+			StudentID:   sid,
+			ReferenceID: &refID,
+			EntryType:   entryType,
+			Points:      delta,
+			Period:      achievementPeriod(ach),
+			Reason:      reason,
+			ActorID:     actorID,
+			ActorRole:   role,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ======================================================
+// AKSES ANGGOTA TIM (AchievementService)
+// ======================================================
+
+// isParticipant: pemilik atau anggota tim. includePending=true ikut
+// menghitung anggota yang belum merespons (supaya bisa melihat lalu konfirmasi).
+func (s *AchievementService) isParticipant(ref *model.AchievementReference, studentID string, includePending bool) bool {
+	if ref.StudentID == studentID {
+		return true
+	}
+	if s.TeamRepo == nil {
+		return false
+	}
+
+	m, err := s.TeamRepo.GetMember(ref.ID, studentID)
+	if err != nil {
+		return false
+	}
+	return m.Status == "confirmed" || (includePending && m.Status == "pending")
+}
+
+// isAdviseeAchievement: prestasi terlihat oleh dosen wali bila pemilik atau
+// salah satu anggota confirmed adalah mahasiswa bimbingannya (termasuk lewat delegasi).
+func (s *AchievementService) isAdviseeAchievement(lecturerID string, ref *model.AchievementReference) bool {
+	ok, _ := s.adviseeAccess(lecturerID, ref)
+	return ok
+}
+
+// adviseeAccess seperti isAdviseeAchievement, ditambah dosen wali asli bila
+// akses berasal dari delegasi. Bimbingan sendiri didahulukan.
+func (s *AchievementService) adviseeAccess(lecturerID string, ref *model.AchievementReference) (bool, string) {
+	scope, err := s.advisees(lecturerID)
+	if err != nil {
+		return false, ""
+	}
+
+	studentIDs := []string{ref.StudentID}
+	for _, m := range s.teamMembers(ref.ID) {
+		if m.Status == "confirmed" {
+			studentIDs = append(studentIDs, m.StudentID)
+		}
+	}
+
+	onBehalfOf := ""
+	for _, id := range studentIDs {
+		if contains(scope.Own, id) {
+			return true, ""
+		}
+		if from := scope.OnBehalfOf(id); from != "" && onBehalfOf == "" {
+			onBehalfOf = from
+		}
+	}
+	return onBehalfOf != "", onBehalfOf
+}
+
+// teamReferences menambahkan prestasi tim yang diikuti studentIDs ke refs
+// tanpa duplikat.
+func (s *AchievementService) teamReferences(refs []model.AchievementReference, studentIDs []string, includePending bool) ([]model.AchievementReference, error) {
+	if s.TeamRepo == nil || len(studentIDs) == 0 {
+		return refs, nil
+	}
+
+	teamRefs, err := s.TeamRepo.ReferencesByMembers(studentIDs, !includePending)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		seen[ref.ID] = true
+	}
+	for _, ref := range teamRefs {
+		if !seen[ref.ID] {
+			seen[ref.ID] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// CONFIRM TEAM MEMBERSHIP (Mahasiswa anggota tim)
+func (s *AchievementService) ConfirmTeam(c *fiber.Ctx) error {
+	return s.respondTeam(c, "confirmed")
+}
+
+// DECLINE TEAM MEMBERSHIP (Mahasiswa anggota tim)
+func (s *AchievementService) DeclineTeam(c *fiber.Ctx) error {
+	return s.respondTeam(c, "declined")
+}
+
+func (s *AchievementService) respondTeam(c *fiber.Ctx, status string) error {
+	if s.TeamRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "team not found"})
+	}
+
+	refID := c.Params("refId")
+	studentID, ok := c.Locals("student_id").(string)
+	if !ok || studentID == "" {
+		return c.Status(401).JSON(fiber.Map{"error": "student not found in token"})
+	}
+
+	ref, err := s.PostgresRepo.GetReferenceByID(refID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+
+	member, err := s.TeamRepo.GetMember(refID, studentID)
+	if err != nil || ref.StudentID == studentID {
+		return c.Status(403).JSON(fiber.Map{"error": "not a team member"})
+	}
+	if member.Status != "pending" {
+		return c.Status(400).JSON(fiber.Map{"error": "team membership already " + member.Status})
+	}
+	if ref.Status != "draft" {
+		return c.Status(400).JSON(fiber.Map{"error": "only draft team achievement can be confirmed"})
+	}
+
+	if err := s.TeamRepo.RespondMember(refID, studentID, status, time.Now()); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Team membership " + status})
+}
+
+// TEAM MEMBERS (dipakai Detail)
+func (s *AchievementService) teamMembers(refID string) []model.AchievementTeamMember {
+	if s.TeamRepo == nil {
+		return nil
+	}
+	members, _ := s.TeamRepo.ListMembers(refID)
+	return members
+}
+
+// pendingMembers: anggota yang belum merespons; submit ditahan sampai kosong.
+func (s *AchievementService) pendingMembers(refID string) ([]string, error) {
+	if s.TeamRepo == nil {
+		return nil, nil
+	}
+	members, err := s.TeamRepo.ListMembers(refID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, m := range members {
+		if m.Status == "pending" {
+			pending = append(pending, m.StudentID)
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK TEAM REPOSITORY =================
+
+type MockTeamRepo struct {
+	members []model.AchievementTeamMember
+	addErr  error
+}
+
+func newMockTeamRepo(refID string, statuses map[string]string) *MockTeamRepo {
+	m := &MockTeamRepo{}
+	m.members = append(m.members, model.AchievementTeamMember{
+		ReferenceID: refID, StudentID: "student-1", TeamRole: "leader", Status: "confirmed",
+	})
+	for _, sid := range sortedKeys(statuses) {
+		m.members = append(m.members, model.AchievementTeamMember{
+			ReferenceID: refID, StudentID: sid, TeamRole: "member", Status: statuses[sid],
+		})
+	}
+	return m
+}
+
+func (m *MockTeamRepo) AddMembers(members []model.AchievementTeamMember) error {
+	if m.addErr != nil {
+		return m.addErr
+	}
+	m.members = append(m.members, members...)
+	return nil
+}
+func (m *MockTeamRepo) ListMembers(refID string) ([]model.AchievementTeamMember, error) {
+	var list []model.AchievementTeamMember
+	for _, tm := range m.members {
+		if tm.ReferenceID == refID {
+			list = append(list, tm)
+		}
+	}
+	return list, nil
+}
+func (m *MockTeamRepo) GetMember(refID, studentID string) (*model.AchievementTeamMember, error) {
+	for i := range m.members {
+		if m.members[i].ReferenceID == refID && m.members[i].StudentID == studentID {
+			return &m.members[i], nil
+		}
+	}
+	return nil, errors.New("team member not found")
+}
+func (m *MockTeamRepo) RespondMember(refID, studentID, status string, at time.Time) error {
+	tm, err := m.GetMember(refID, studentID)
+	if err != nil {
+		return err
+	}
+	tm.Status = status
+	tm.RespondedAt = &at
+	return nil
+}
+func (m *MockTeamRepo) ReferencesByMembers(studentIDs []string, confirmedOnly bool) ([]model.AchievementReference, error) {
+	return nil, nil
+}
+func (m *MockTeamRepo) ConfirmedMembersByMongoID() (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func teamAchievementService(team *MockTeamRepo, pg repository.AchievementPostgresRepository) *AchievementService {
+	return &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: pg,
+		StudentRepo:  &MockStudentPostgresRepo{},
+		TeamRepo:     team,
+	}
+}
+
+// ================= UNIT TESTS =================
+
+func TestSplitPoints(t *testing.T) {
+	members := []model.AchievementTeamMember{
+		{StudentID: "s-b", TeamRole: "member"},
+		{StudentID: "s-a", TeamRole: "leader"},
+		{StudentID: "s-c", TeamRole: "member"},
+	}
+
+	assert.Equal(t, map[string]int{"s-a": 34, "s-b": 33, "s-c": 33}, splitPoints(100, members, "equal"))
+	assert.Equal(t, map[string]int{"s-a": 50, "s-b": 25, "s-c": 25}, splitPoints(100, members, "lead_bonus"))
+	assert.Equal(t, map[string]int{"s-a": 100, "s-b": 100, "s-c": 100}, splitPoints(100, members, "full"))
+}
+
+func TestCreateAchievement_Team_InvalidMembers_ShouldFail(t *testing.T) {
+	svc := teamAchievementService(&MockTeamRepo{}, &MockAchievementPostgresRepo{})
+	app := fiber.New()
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Create(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"achievementType": "competition",
+		"title":           "Juara Gemastik",
+		"details":         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+		"teamMembers": []map[string]interface{}{
+			{"studentId": "student-2", "role": "member"},
+			{"studentId": "student-2", "role": "member"},
+			{"studentId": "student-3"},
+		},
+		"pointSplit": "random",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	var out struct {
+		Fields []FieldError `json:"fields"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []FieldError{
+		{"pointSplit", "harus salah satu dari: equal, full, lead_bonus"},
+		{"teamMembers[1].studentId", "duplikat"},
+		{"teamMembers[2].role", "wajib diisi"},
+	}, out.Fields)
+}
+
+// mencatat pembatalan Create
+type abortCreatePostgresRepo struct {
+	MockAchievementPostgresRepo
+	created, deleted []string
+}
+
+func (m *abortCreatePostgresRepo) CreateReferencePostgres(r *model.AchievementReference) error {
+	m.created = append(m.created, r.ID)
+	return nil
+}
+func (m *abortCreatePostgresRepo) DeleteReferencePostgres(id string) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+type abortCreateMongoRepo struct {
+	MockAchievementMongoRepo
+	created, deleted []primitive.ObjectID
+}
+
+func (m *abortCreateMongoRepo) CreateAchievementMongo(a *model.AchievementMongo) (primitive.ObjectID, error) {
+	id := primitive.NewObjectID()
+	m.created = append(m.created, id)
+	return id, nil
+}
+func (m *abortCreateMongoRepo) DeleteAchievementMongo(id primitive.ObjectID) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func TestCreateAchievement_Team_AddMembersFails_RollsBack(t *testing.T) {
+	pg := &abortCreatePostgresRepo{}
+	mongoRepo := &abortCreateMongoRepo{}
+	svc := teamAchievementService(&MockTeamRepo{addErr: errors.New("insert team failed")}, pg)
+	svc.MongoRepo = mongoRepo
+	app := fiber.New()
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Create(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"achievementType": "competition",
+		"title":           "Juara Gemastik",
+		"details":         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+		"teamMembers":     []map[string]interface{}{{"studentId": "student-2", "role": "member"}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 500, resp.StatusCode)
+	assert.Len(t, pg.created, 1)
+	assert.Equal(t, pg.created, pg.deleted)
+	assert.Len(t, mongoRepo.created, 1)
+	assert.Equal(t, mongoRepo.created, mongoRepo.deleted)
+}
+
+func TestSubmitAchievement_Team_PendingMember_ShouldFail(t *testing.T) {
+	team := newMockTeamRepo("ref-123", map[string]string{"student-2": "pending"})
+	svc := teamAchievementService(team, &MockAchievementPostgresRepoDraft{})
+	app := fiber.New()
+	app.Post("/achievements/:refId/submit", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Submit(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/submit", nil))
+	assert.Equal(t, 400, resp.StatusCode)
+
+	// anggota mengonfirmasi → submit berhasil
+	app.Post("/achievements/:refId/team/confirm", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-2")
+		return svc.ConfirmTeam(c)
+	})
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/team/confirm", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/submit", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestDetailAchievement_Team_MemberCanView(t *testing.T) {
+	team := newMockTeamRepo("ref-123", map[string]string{"student-2": "pending", "student-3": "declined"})
+	svc := teamAchievementService(team, &MockAchievementPostgresRepo{})
+	app := fiber.New()
+	app.Get("/achievements/:refId", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", c.Get("X-Student"))
+		return svc.Detail(c)
+	})
+
+	view := func(studentID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/achievements/ref-123", nil)
+		req.Header.Set("X-Student", studentID)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, view("student-2"))
+	assert.Equal(t, 403, view("student-3"))
+	assert.Equal(t, 403, view("student-4"))
+}
+
+func TestVerifyAchievement_Team_SplitsLedgerEntries(t *testing.T) {
+	team := newMockTeamRepo("ref-123", map[string]string{"student-2": "confirmed", "student-3": "declined"})
+	ledger := newMockLedgerRepo()
+	svc := teamAchievementService(team, &MockAchievementPostgresRepoSubmitted{})
+	svc.LedgerRepo = ledger
+	svc.MongoRepo = &MockPointRuleMongoRepo{} // poin 100
+
+	app := fiber.New()
+	app.Post("/achievements/:refId/verify", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("lecturer_id", "")
+		c.Locals("user_id", "admin-1")
+		return svc.Verify(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/verify", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	shares := map[string]int{}
+	for _, e := range ledger.entries {
+		assert.Equal(t, model.LedgerEarned, e.EntryType)
+		shares[e.StudentID] += e.Points
+	}
+	assert.Equal(t, map[string]int{"student-1": 50, "student-2": 50}, shares)
+}
+
+// storedTeamMongoRepo: prestasi tim dua orang yang sudah tersimpan.
+type storedTeamMongoRepo struct {
+	MockAchievementMongoRepo
+	updated *model.AchievementMongo
+}
+
+func (m *storedTeamMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	return &model.AchievementMongo{
+		StudentID:       "student-1",
+		AchievementType: "competition",
+		Title:           "Juara Gemastik",
+		Details:         model.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national"},
+		TeamMembers: []model.TeamMember{
+			{StudentID: "student-1", Role: "leader"},
+			{StudentID: "student-2", Role: "member"},
+		},
+		PointSplit: "equal",
+	}, nil
+}
+func (m *storedTeamMongoRepo) UpdateAchievementMongo(id primitive.ObjectID, a *model.AchievementMongo) error {
+	m.updated = a
+	return nil
+}
+
+func TestUpdateAchievement_PointsUseStoredTeam(t *testing.T) {
+	base := 10
+	mongoRepo := &storedTeamMongoRepo{}
+	svc := teamAchievementService(&MockTeamRepo{}, &MockAchievementPostgresRepo{})
+	svc.MongoRepo = mongoRepo
+	svc.RuleRepo = newMockPointRuleRepo(&model.PointRuleSet{
+		ID:     "rs-1",
+		Status: "active",
+		Rules: []model.PointRule{
+			{Name: "dasar", Points: &base},
+			{Name: "tim-besar", When: model.PointCondition{TeamSizeMin: 3}, Bonus: 100},
+		},
+	})
+	app := fiber.New()
+	app.Put("/achievements/:refId", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("user_id", "admin-1")
+		return svc.Update(c)
+	})
+
+	// body mengklaim tim empat orang, tapi Update tidak menyimpan anggota tim
+	body, _ := json.Marshal(map[string]interface{}{
+		"achievementType": "competition",
+		"title":           "Juara Gemastik",
+		"details":         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+		"teamMembers": []map[string]interface{}{
+			{"studentId": "student-2", "role": "member"},
+			{"studentId": "student-3", "role": "member"},
+			{"studentId": "student-4", "role": "member"},
+		},
+		"pointSplit": "full",
+	})
+	req := httptest.NewRequest(http.MethodPut, "/achievements/ref-123", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 10, mongoRepo.updated.Points)
+	assert.Len(t, mongoRepo.updated.TeamMembers, 2)
+	assert.Equal(t, "equal", mongoRepo.updated.PointSplit)
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AchievementTypeService struct {
+	TypeRepo repository.AchievementTypePostgresRepository
+	Schemas  *SchemaRegistry
+}
+
+func NewAchievementTypeService(typeRepo repository.AchievementTypePostgresRepository) *AchievementTypeService {
+	return &AchievementTypeService{
+		TypeRepo: typeRepo,
+		Schemas:  DefaultSchemaRegistry,
+	}
+}
+
+var (
+	typeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{2,49}$`)
+	fieldKeyPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,49}$`)
+)
+
+// ======================================================
+// METADATA (semua role) — dipakai frontend untuk render form
+// ======================================================
+func (s *AchievementTypeService) Metadata(c *fiber.Ctx) error {
+	custom, err := s.TypeRepo.ListTypes(true)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	fields, err := s.TypeRepo.ListAllFields()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	fieldsByType := map[string][]model.AchievementCustomField{}
+	for _, f := range fields {
+		fieldsByType[f.TypeCode] = append(fieldsByType[f.TypeCode], f)
+	}
+
+	var types []fiber.Map
+	for _, schema := range s.Schemas.Types() {
+		types = append(types, typeMetadata(schema, true, 0, fieldsByType[schema.Type]))
+	}
+	for i := range custom {
+		t := &custom[i]
+		types = append(types, typeMetadata(customTypeSchema(t), false, t.DefaultPoints, fieldsByType[t.Code]))
+	}
+
+	return c.JSON(fiber.Map{"types": types})
+}
+
+func typeMetadata(schema *AchievementSchema, builtIn bool, defaultPoints int, fields []model.AchievementCustomField) fiber.Map {
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].DisplayOrder < fields[j].DisplayOrder
+	})
+	if fields == nil {
+		fields = []model.AchievementCustomField{}
+	}
+
+	m := fiber.Map{
+		"code":         schema.Type,
+		"name":         schema.Label,
+		"builtIn":      builtIn,
+		"required":     schema.Required,
+		"optional":     schema.Optional,
+		"enums":        schema.Enums,
+		"customFields": fields,
+	}
+	if !builtIn {
+		m["defaultPoints"] = defaultPoints
+	}
+	return m
+}
+
+// ======================================================
+// ADMIN — ACHIEVEMENT TYPES
+// ======================================================
+
+func (s *AchievementTypeService) ListTypes(c *fiber.Ctx) error {
+	list, err := s.TypeRepo.ListTypes(false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []model.AchievementType{}
+	}
+	return c.JSON(list)
+}
+
+func (s *AchievementTypeService) CreateType(c *fiber.Ctx) error {
+	var body struct {
+		Code          string `json:"code"`
+		Name          string `json:"name"`
+		Description   string `json:"description"`
+		DefaultPoints int    `json:"default_points"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	body.Code = strings.TrimSpace(body.Code)
+	var errs []FieldError
+	if !typeCodePattern.MatchString(body.Code) {
+		errs = append(errs, FieldError{"code", "3-50 karakter huruf kecil, angka atau underscore, diawali huruf"})
+	} else if _, builtIn := s.Schemas.Lookup(body.Code); builtIn {
+		errs = append(errs, FieldError{"code", "bentrok dengan tipe bawaan"})
+	}
+	if strings.TrimSpace(body.Name) == "" {
+		errs = append(errs, FieldError{"name", "wajib diisi"})
+	}
+	if body.DefaultPoints < 0 {
+		errs = append(errs, FieldError{"default_points", "tidak boleh negatif"})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	if _, err := s.TypeRepo.GetTypeByCode(body.Code); err == nil {
+		return c.Status(409).JSON(fiber.Map{"error": "achievement type already exists"})
+	}
+
+	now := time.Now()
+	t := model.AchievementType{
+		ID:            uuid.New().String(),
+		Code:          body.Code,
+		Name:          strings.TrimSpace(body.Name),
+		Description:   body.Description,
+		DefaultPoints: body.DefaultPoints,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.TypeRepo.CreateType(&t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(t)
+}
+
+func (s *AchievementTypeService) UpdateType(c *fiber.Ctx) error {
+	t, err := s.TypeRepo.GetTypeByCode(c.Params("code"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement type not found"})
+	}
+
+	var body struct {
+		Name          *string `json:"name"`
+		Description   *string `json:"description"`
+		DefaultPoints *int    `json:"default_points"`
+		IsActive      *bool   `json:"is_active"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	if body.Name != nil {
+		if strings.TrimSpace(*body.Name) == "" {
+			return respondValidation(c, []FieldError{{"name", "wajib diisi"}})
+		}
+		t.Name = strings.TrimSpace(*body.Name)
+	}
+	if body.Description != nil {
+		t.Description = *body.Description
+	}
+	if body.DefaultPoints != nil {
+		if *body.DefaultPoints < 0 {
+			return respondValidation(c, []FieldError{{"default_points", "tidak boleh negatif"}})
+		}
+		t.DefaultPoints = *body.DefaultPoints
+	}
+	if body.IsActive != nil {
+		t.IsActive = *body.IsActive
+	}
+
+	if err := s.TypeRepo.UpdateType(t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(t)
+}
+
+// DeleteType hanya menonaktifkan tipe; prestasi lama tetap bisa dibaca.
+func (s *AchievementTypeService) DeleteType(c *fiber.Ctx) error {
+	t, err := s.TypeRepo.GetTypeByCode(c.Params("code"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement type not found"})
+	}
+
+	t.IsActive = false
+	if err := s.TypeRepo.UpdateType(t); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "achievement type deactivated"})
+}
+
+// ======================================================
+// ADMIN — CUSTOM FIELDS
+// ======================================================
+
+type customFieldBody struct {
+	Key           string   `json:"key"`
+	Label         string   `json:"label"`
+	DataType      string   `json:"data_type"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowed_values"`
+	DisplayOrder  int      `json:"display_order"`
+}
+
+func (b *customFieldBody) validate(checkKey bool) []FieldError {
+	var errs []FieldError
+	if checkKey && !fieldKeyPattern.MatchString(b.Key) {
+		errs = append(errs, FieldError{"key", "1-50 karakter huruf, angka atau underscore, diawali huruf"})
+	}
+	if strings.TrimSpace(b.Label) == "" {
+		errs = append(errs, FieldError{"label", "wajib diisi"})
+	}
+	if !contains(customFieldDataTypes, b.DataType) {
+		errs = append(errs, FieldError{"data_type", "harus salah satu dari: " + strings.Join(customFieldDataTypes, ", ")})
+	}
+	if b.DataType == "enum" && len(b.AllowedValues) == 0 {
+		errs = append(errs, FieldError{"allowed_values", "wajib diisi untuk data_type enum"})
+	}
+	if len(b.AllowedValues) > 0 && b.DataType != "enum" && b.DataType != "string" {
+		errs = append(errs, FieldError{"allowed_values", "hanya untuk data_type string atau enum"})
+	}
+	return errs
+}
+
+// typeExists: tipe bawaan atau tipe custom yang terdaftar.
+func (s *AchievementTypeService) typeExists(code string) bool {
+	if _, ok := s.Schemas.Lookup(code); ok {
+		return true
+	}
+	_, err := s.TypeRepo.GetTypeByCode(code)
+	return err == nil
+}
+
+func (s *AchievementTypeService) ListFields(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if !s.typeExists(code) {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement type not found"})
+	}
+
+	list, err := s.TypeRepo.ListFields(code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []model.AchievementCustomField{}
+	}
+	return c.JSON(list)
+}
+
+func (s *AchievementTypeService) CreateField(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if !s.typeExists(code) {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement type not found"})
+	}
+
+	var body customFieldBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if errs := body.validate(true); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	existing, err := s.TypeRepo.ListFields(code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, f := range existing {
+		if f.Key == body.Key {
+			return c.Status(409).JSON(fiber.Map{"error": "custom field key already exists for this type"})
+		}
+	}
+
+	f := model.AchievementCustomField{
+		ID:            uuid.New().String(),
+		TypeCode:      code,
+		Key:           body.Key,
+		Label:         strings.TrimSpace(body.Label),
+		DataType:      body.DataType,
+		Required:      body.Required,
+		AllowedValues: body.AllowedValues,
+		DisplayOrder:  body.DisplayOrder,
+		CreatedAt:     time.Now(),
+	}
+	if f.AllowedValues == nil {
+		f.AllowedValues = []string{}
+	}
+
+	if err := s.TypeRepo.CreateField(&f); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(f)
+}
+
+// UpdateField: key tidak bisa diubah karena sudah dipakai di dokumen Mongo.
+func (s *AchievementTypeService) UpdateField(c *fiber.Ctx) error {
+	f, err := s.TypeRepo.GetField(c.Params("fieldId"))
+	if err != nil || f.TypeCode != c.Params("code") {
+		return c.Status(404).JSON(fiber.Map{"error": "custom field not found"})
+	}
+
+	var body customFieldBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if errs := body.validate(false); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	f.Label = strings.TrimSpace(body.Label)
+	f.DataType = body.DataType
+	f.Required = body.Required
+	f.AllowedValues = body.AllowedValues
+	f.DisplayOrder = body.DisplayOrder
+	if f.AllowedValues == nil {
+		f.AllowedValues = []string{}
+	}
+
+	if err := s.TypeRepo.UpdateField(f); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(f)
+}
+
+func (s *AchievementTypeService) DeleteField(c *fiber.Ctx) error {
+	f, err := s.TypeRepo.GetField(c.Params("fieldId"))
+	if err != nil || f.TypeCode != c.Params("code") {
+		return c.Status(404).JSON(fiber.Map{"error": "custom field not found"})
+	}
+
+	if err := s.TypeRepo.DeleteField(f.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "custom field deleted"})
+}
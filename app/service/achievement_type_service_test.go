@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCK TYPE REPOSITORY =================
+
+type MockAchievementTypeRepo struct {
+	types  map[string]*model.AchievementType
+	fields []model.AchievementCustomField
+	getErr error // kegagalan database
+}
+
+func newMockAchievementTypeRepo() *MockAchievementTypeRepo {
+	return &MockAchievementTypeRepo{
+		types: map[string]*model.AchievementType{
+			"hki": {ID: "t-1", Code: "hki", Name: "Hak Kekayaan Intelektual", DefaultPoints: 120, IsActive: true},
+		},
+		fields: []model.AchievementCustomField{
+			{ID: "f-1", TypeCode: "hki", Key: "registrationNumber", Label: "Nomor Pendaftaran", DataType: "string", Required: true},
+			{ID: "f-2", TypeCode: "hki", Key: "hkiKind", Label: "Jenis HKI", DataType: "enum", AllowedValues: []string{"paten", "hak_cipta"}},
+		},
+	}
+}
+
+func (m *MockAchievementTypeRepo) ListTypes(activeOnly bool) ([]model.AchievementType, error) {
+	var list []model.AchievementType
+	for _, t := range m.types {
+		if !activeOnly || t.IsActive {
+			list = append(list, *t)
+		}
+	}
+	return list, nil
+}
+func (m *MockAchievementTypeRepo) GetTypeByCode(code string) (*model.AchievementType, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if t, ok := m.types[code]; ok {
+		return t, nil
+	}
+	return nil, repository.ErrAchievementTypeNotFound
+}
+func (m *MockAchievementTypeRepo) CreateType(t *model.AchievementType) error {
+	m.types[t.Code] = t
+	return nil
+}
+func (m *MockAchievementTypeRepo) UpdateType(t *model.AchievementType) error { return nil }
+func (m *MockAchievementTypeRepo) ListFields(typeCode string) ([]model.AchievementCustomField, error) {
+	var list []model.AchievementCustomField
+	for _, f := range m.fields {
+		if f.TypeCode == typeCode {
+			list = append(list, f)
+		}
+	}
+	return list, nil
+}
+func (m *MockAchievementTypeRepo) ListAllFields() ([]model.AchievementCustomField, error) {
+	return m.fields, nil
+}
+func (m *MockAchievementTypeRepo) GetField(id string) (*model.AchievementCustomField, error) {
+	for i := range m.fields {
+		if m.fields[i].ID == id {
+			return &m.fields[i], nil
+		}
+	}
+	return nil, errors.New("custom field not found")
+}
+func (m *MockAchievementTypeRepo) CreateField(f *model.AchievementCustomField) error {
+	m.fields = append(m.fields, *f)
+	return nil
+}
+func (m *MockAchievementTypeRepo) UpdateField(f *model.AchievementCustomField) error { return nil }
+func (m *MockAchievementTypeRepo) DeleteField(id string) error                       { return nil }
+
+func setupAchievementTypeService() (*AchievementTypeService, *fiber.App) {
+	return NewAchievementTypeService(newMockAchievementTypeRepo()), fiber.New()
+}
+
+// ================= UNIT TESTS =================
+
+func TestAchievementType_Create_Success(t *testing.T) {
+	svc, app := setupAchievementTypeService()
+	app.Post("/types", svc.CreateType)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"code":           "wirausaha",
+		"name":           "Wirausaha",
+		"default_points": 70,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/types", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, resp.StatusCode)
+}
+
+func TestAchievementType_Create_BuiltInCode_ShouldFail(t *testing.T) {
+	svc, app := setupAchievementTypeService()
+	app.Post("/types", svc.CreateType)
+
+	body, _ := json.Marshal(map[string]interface{}{"code": "competition", "name": "Lomba"})
+	req := httptest.NewRequest(http.MethodPost, "/types", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestAchievementType_CreateField_EnumWithoutValues_ShouldFail(t *testing.T) {
+	svc, app := setupAchievementTypeService()
+	app.Post("/types/:code/fields", svc.CreateField)
+
+	body, _ := json.Marshal(map[string]interface{}{"key": "level", "label": "Level", "data_type": "enum"})
+	req := httptest.NewRequest(http.MethodPost, "/types/hki/fields", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestAchievementType_Metadata(t *testing.T) {
+	svc, app := setupAchievementTypeService()
+	app.Get("/types", svc.Metadata)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/types", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var out struct {
+		Types []map[string]interface{} `json:"types"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+	assert.Len(t, out.Types, 5) // 4 bawaan + hki
+}
+
+func TestSchemaRegistry_CustomTypeAndFields(t *testing.T) {
+	repo := newMockAchievementTypeRepo()
+	now := time.Now()
+
+	a := &model.AchievementMongo{
+		Title:           "Paten Alat",
+		AchievementType: "hki",
+		Details: model.AchievementDetails{
+			CustomFields: map[string]any{"hkiKind": "merek", "unknown": 1.0},
+		},
+	}
+
+	errs, err := DefaultSchemaRegistry.ValidateWithCatalog(a, now, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldError{
+		{"details.customFields.registrationNumber", "wajib diisi"},
+		{"details.customFields.hkiKind", "harus salah satu dari: paten, hak_cipta"},
+		{"details.customFields.unknown", "field tidak terdaftar untuk tipe ini"},
+	}, errs)
+
+	a.Details.CustomFields = map[string]any{"registrationNumber": "P00202400123", "hkiKind": "paten"}
+	errs, err = DefaultSchemaRegistry.ValidateWithCatalog(a, now, repo)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+}
+
+func TestSchemaRegistry_BuiltInTypeKeepsFreeFormCustomFields(t *testing.T) {
+	a := &model.AchievementMongo{
+		Title:           "Juara 1 Gemastik",
+		AchievementType: "competition",
+		Details: model.AchievementDetails{
+			CompetitionName:  "Gemastik",
+			CompetitionLevel: "national",
+			Rank:             1,
+			CustomFields:     map[string]any{"sponsor": "Kominfo", "jumlahTim": 120.0},
+		},
+	}
+
+	errs, err := DefaultSchemaRegistry.ValidateWithCatalog(a, time.Now(), newMockAchievementTypeRepo())
+	assert.NoError(t, err)
+	for _, e := range errs {
+		assert.NotContains(t, e.Field, "details.customFields", e.Message)
+	}
+}
+
+func TestSchemaRegistry_CatalogErrorIsNotUnknownType(t *testing.T) {
+	repo := newMockAchievementTypeRepo()
+	a := &model.AchievementMongo{Title: "Paten Alat", AchievementType: "tidak-ada"}
+
+	errs, err := DefaultSchemaRegistry.ValidateWithCatalog(a, time.Now(), repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []FieldError{{"achievementType", `tipe "tidak-ada" tidak dikenal`}}, errs)
+
+	// database gagal → error (500), bukan validasi achievementType
+	repo.getErr = errors.New("connection refused")
+	a.AchievementType = "hki"
+	_, err = DefaultSchemaRegistry.ValidateWithCatalog(a, time.Now(), repo)
+	assert.EqualError(t, err, "connection refused")
+}
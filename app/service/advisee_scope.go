@@ -0,0 +1,79 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"prestasi_api/app/repository"
+)
+
+// AdviseeScope: mahasiswa yang boleh ditangani seorang dosen wali, yaitu
+// bimbingannya sendiri ditambah bimbingan dosen lain yang sedang
+// mendelegasikan tugas kepadanya.
+type AdviseeScope struct {
+	Own       []string
+	Delegated map[string]string // student id → lecturer id pemberi delegasi
+}
+
+// IDs: semua mahasiswa dalam scope (bimbingan sendiri lebih dulu).
+func (a *AdviseeScope) IDs() []string {
+	ids := append([]string{}, a.Own...)
+	for _, id := range sortedKeys(a.Delegated) {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func (a *AdviseeScope) Contains(studentID string) bool {
+	if contains(a.Own, studentID) {
+		return true
+	}
+	_, ok := a.Delegated[studentID]
+	return ok
+}
+
+// OnBehalfOf: dosen wali asli bila akses lewat delegasi, kosong bila milik sendiri.
+func (a *AdviseeScope) OnBehalfOf(studentID string) string {
+	if contains(a.Own, studentID) {
+		return ""
+	}
+	return a.Delegated[studentID]
+}
+
+// resolveAdvisees dipakai semua pemeriksaan mahasiswa bimbingan.
+// Delegasi tidak berantai: yang didelegasikan hanya bimbingan asli pemberi.
+// delegations boleh nil → hanya bimbingan sendiri.
+func resolveAdvisees(
+	students repository.StudentPostgresRepository,
+	delegations repository.AdvisorDelegationPostgresRepository,
+	lecturerID string,
+	now time.Time,
+) (*AdviseeScope, error) {
+	own, err := students.GetStudentIDsByAdvisor(lecturerID)
+	if err != nil {
+		return nil, err
+	}
+	scope := &AdviseeScope{Own: own, Delegated: map[string]string{}}
+	if delegations == nil || lecturerID == "" {
+		return scope, nil
+	}
+
+	active, err := delegations.ActiveFor(lecturerID, now)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(active, func(i, j int) bool { return active[i].StartsAt.Before(active[j].StartsAt) })
+
+	for _, d := range active {
+		ids, err := students.GetStudentIDsByAdvisor(d.FromLecturerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if _, seen := scope.Delegated[id]; !seen && !contains(own, id) {
+				scope.Delegated[id] = d.FromLecturerID
+			}
+		}
+	}
+	return scope, nil
+}
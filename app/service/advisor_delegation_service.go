@@ -0,0 +1,172 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxDelegationPeriod: delegasi terpanjang (satu tahun, cukup untuk sabbatical).
+const maxDelegationPeriod = 366 * 24 * time.Hour
+
+type AdvisorDelegationService struct {
+	DelegationRepo repository.AdvisorDelegationPostgresRepository
+	LecturerRepo   repository.LecturerPostgresRepository
+}
+
+func NewAdvisorDelegationService(
+	delegationRepo repository.AdvisorDelegationPostgresRepository,
+	lecturerRepo repository.LecturerPostgresRepository,
+) *AdvisorDelegationService {
+	return &AdvisorDelegationService{
+		DelegationRepo: delegationRepo,
+		LecturerRepo:   lecturerRepo,
+	}
+}
+
+// CREATE — dosen wali mendelegasikan bimbingannya sendiri; admin atas nama dosen
+func (s *AdvisorDelegationService) Create(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+
+	var body struct {
+		FromLecturerID string     `json:"from_lecturer_id"`
+		ToLecturerID   string     `json:"to_lecturer_id"`
+		StartsAt       *time.Time `json:"starts_at"`
+		EndsAt         *time.Time `json:"ends_at"`
+		Reason         string     `json:"reason"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	// Dosen Wali hanya boleh mendelegasikan bimbingannya sendiri
+	if role == "Dosen Wali" {
+		lecturerID, _ := c.Locals("lecturer_id").(string)
+		if body.FromLecturerID != "" && body.FromLecturerID != lecturerID {
+			return c.Status(403).JSON(fiber.Map{"error": "hanya bisa mendelegasikan bimbingan sendiri"})
+		}
+		body.FromLecturerID = lecturerID
+	}
+
+	now := time.Now()
+	startsAt := now
+	if body.StartsAt != nil {
+		startsAt = *body.StartsAt
+	}
+
+	var errs []FieldError
+	if body.FromLecturerID == "" {
+		errs = append(errs, FieldError{"from_lecturer_id", "wajib diisi"})
+	} else if _, err := s.LecturerRepo.Detail(body.FromLecturerID); err != nil {
+		errs = append(errs, FieldError{"from_lecturer_id", "dosen tidak ditemukan"})
+	}
+	if body.ToLecturerID == "" {
+		errs = append(errs, FieldError{"to_lecturer_id", "wajib diisi"})
+	} else if body.ToLecturerID == body.FromLecturerID {
+		errs = append(errs, FieldError{"to_lecturer_id", "tidak boleh sama dengan pemberi delegasi"})
+	} else if _, err := s.LecturerRepo.Detail(body.ToLecturerID); err != nil {
+		errs = append(errs, FieldError{"to_lecturer_id", "dosen tidak ditemukan"})
+	}
+	switch {
+	case body.EndsAt == nil:
+		errs = append(errs, FieldError{"ends_at", "wajib diisi"})
+	case !body.EndsAt.After(startsAt):
+		errs = append(errs, FieldError{"ends_at", "harus setelah starts_at"})
+	case !body.EndsAt.After(now):
+		errs = append(errs, FieldError{"ends_at", "sudah lewat"})
+	case body.EndsAt.Sub(startsAt) > maxDelegationPeriod:
+		errs = append(errs, FieldError{"ends_at", "delegasi maksimal satu tahun"})
+	}
+	if strings.TrimSpace(body.Reason) == "" {
+		errs = append(errs, FieldError{"reason", "wajib diisi"})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	// satu dosen hanya punya satu delegasi pada satu waktu, supaya
+	// atribusi "atas nama" tidak ambigu
+	overlapping, err := s.DelegationRepo.Overlapping(body.FromLecturerID, startsAt, *body.EndsAt)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(overlapping) > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":       "sudah ada delegasi pada rentang waktu tersebut",
+			"conflicting": overlapping,
+		})
+	}
+
+	d := model.AdvisorDelegation{
+		ID:             uuid.New().String(),
+		FromLecturerID: body.FromLecturerID,
+		ToLecturerID:   body.ToLecturerID,
+		StartsAt:       startsAt,
+		EndsAt:         *body.EndsAt,
+		Reason:         strings.TrimSpace(body.Reason),
+		CreatedBy:      userID,
+		CreatedAt:      now,
+	}
+	if err := s.DelegationRepo.Create(&d); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(d)
+}
+
+// LIST — dosen wali: delegasi yang diberikan/diterima; admin: semua (?lecturer_id=)
+func (s *AdvisorDelegationService) List(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+
+	lecturerID := c.Query("lecturer_id")
+	if role == "Dosen Wali" {
+		lecturerID, _ = c.Locals("lecturer_id").(string)
+	}
+
+	list, err := s.DelegationRepo.List(lecturerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	now := time.Now()
+	result := []fiber.Map{}
+	for i := range list {
+		result = append(result, fiber.Map{
+			"delegation": list[i],
+			"active":     list[i].Active(now),
+		})
+	}
+	return c.JSON(result)
+}
+
+// REVOKE — pemberi delegasi atau admin
+func (s *AdvisorDelegationService) Revoke(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+	userID, _ := c.Locals("user_id").(string)
+
+	d, err := s.DelegationRepo.Get(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "delegation not found"})
+	}
+
+	if role == "Dosen Wali" {
+		lecturerID, _ := c.Locals("lecturer_id").(string)
+		if d.FromLecturerID != lecturerID {
+			return c.Status(403).JSON(fiber.Map{"error": "hanya pemberi delegasi yang bisa mencabut"})
+		}
+	}
+	if d.RevokedAt != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "delegation already revoked"})
+	}
+
+	if err := s.DelegationRepo.Revoke(d.ID, userID, time.Now()); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Delegation revoked"})
+}
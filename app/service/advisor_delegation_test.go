@@ -0,0 +1,235 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCKS =================
+
+type MockDelegationRepo struct {
+	delegations []model.AdvisorDelegation
+}
+
+func (m *MockDelegationRepo) Create(d *model.AdvisorDelegation) error {
+	m.delegations = append(m.delegations, *d)
+	return nil
+}
+func (m *MockDelegationRepo) Get(id string) (*model.AdvisorDelegation, error) {
+	for i := range m.delegations {
+		if m.delegations[i].ID == id {
+			return &m.delegations[i], nil
+		}
+	}
+	return nil, errors.New("delegation not found")
+}
+func (m *MockDelegationRepo) List(lecturerID string) ([]model.AdvisorDelegation, error) {
+	var list []model.AdvisorDelegation
+	for _, d := range m.delegations {
+		if lecturerID == "" || d.FromLecturerID == lecturerID || d.ToLecturerID == lecturerID {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+func (m *MockDelegationRepo) Revoke(id, userID string, at time.Time) error {
+	d, err := m.Get(id)
+	if err != nil {
+		return err
+	}
+	d.RevokedAt, d.RevokedBy = &at, &userID
+	return nil
+}
+func (m *MockDelegationRepo) ActiveFor(toLecturerID string, at time.Time) ([]model.AdvisorDelegation, error) {
+	var list []model.AdvisorDelegation
+	for _, d := range m.delegations {
+		if d.ToLecturerID == toLecturerID && d.Active(at) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+func (m *MockDelegationRepo) Overlapping(fromLecturerID string, startsAt, endsAt time.Time) ([]model.AdvisorDelegation, error) {
+	var list []model.AdvisorDelegation
+	for _, d := range m.delegations {
+		if d.FromLecturerID == fromLecturerID && d.RevokedAt == nil && d.StartsAt.Before(endsAt) && d.EndsAt.After(startsAt) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+// MockDelegationLecturerRepo: hanya lect-1, lect-2, lect-3 yang terdaftar.
+type MockDelegationLecturerRepo struct {
+	MockLecturerRepo
+}
+
+func (m *MockDelegationLecturerRepo) Detail(id string) (*model.Lecturer, error) {
+	if contains([]string{"lect-1", "lect-2", "lect-3"}, id) {
+		return &model.Lecturer{ID: id}, nil
+	}
+	return nil, errors.New("lecturer not found")
+}
+
+// lect-1 (cuti) mendelegasikan ke lect-2 sampai besok.
+func activeDelegation() *MockDelegationRepo {
+	now := time.Now()
+	return &MockDelegationRepo{delegations: []model.AdvisorDelegation{
+		{ID: "del-1", FromLecturerID: "lect-1", ToLecturerID: "lect-2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(24 * time.Hour)},
+	}}
+}
+
+// ================= UNIT TESTS =================
+
+func TestResolveAdvisees_IncludesActiveDelegations(t *testing.T) {
+	now := time.Now()
+	students := &MockAdvisorStudentRepo{advisees: map[string][]string{
+		"lect-1": {"student-1", "student-2"},
+		"lect-2": {"student-3"},
+		"lect-3": {"student-4"},
+	}}
+	revokedAt := now.Add(-time.Minute)
+	delegations := activeDelegation()
+	delegations.delegations = append(delegations.delegations,
+		model.AdvisorDelegation{ID: "del-old", FromLecturerID: "lect-3", ToLecturerID: "lect-2", StartsAt: now.Add(-48 * time.Hour), EndsAt: now.Add(-24 * time.Hour)},
+		model.AdvisorDelegation{ID: "del-rev", FromLecturerID: "lect-3", ToLecturerID: "lect-2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), RevokedAt: &revokedAt},
+	)
+
+	scope, err := resolveAdvisees(students, delegations, "lect-2", now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"student-3", "student-1", "student-2"}, scope.IDs())
+	assert.Equal(t, "", scope.OnBehalfOf("student-3"))
+	assert.Equal(t, "lect-1", scope.OnBehalfOf("student-1"))
+	assert.False(t, scope.Contains("student-4"))
+
+	// tanpa repo delegasi → hanya bimbingan sendiri
+	scope, _ = resolveAdvisees(students, nil, "lect-2", now)
+	assert.Equal(t, []string{"student-3"}, scope.IDs())
+}
+
+func TestVerifyAchievement_ByDelegate_RecordsOnBehalfOf(t *testing.T) {
+	pg := &MockHistoryPostgresRepo{}
+	svc := &AchievementService{
+		MongoRepo:      &MockAchievementMongoRepo{},
+		PostgresRepo:   pg,
+		StudentRepo:    &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+		DelegationRepo: activeDelegation(),
+	}
+	app := fiber.New()
+	app.Post("/achievements/:refId/verify", func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", c.Get("X-Lecturer"))
+		c.Locals("user_id", "user-lect")
+		return svc.Verify(c)
+	})
+
+	verify := func(lecturerID string) int {
+		req := httptest.NewRequest(http.MethodPost, "/achievements/ref-1/verify", nil)
+		req.Header.Set("X-Lecturer", lecturerID)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 400, verify("lect-3"))
+	assert.Equal(t, 200, verify("lect-2"))
+
+	assert.Len(t, pg.history, 1)
+	assert.Equal(t, "verified", pg.history[0].NewStatus)
+	assert.Equal(t, "lect-1", *pg.history[0].OnBehalfOf)
+}
+
+func TestStudentDetail_ByDelegate_Allowed(t *testing.T) {
+	svc := NewStudentService(
+		&MockDelegationStudentRepo{},
+		&MockLecturerRepoSS{},
+		activeDelegation(),
+	)
+	app := fiber.New()
+	app.Get("/students/:id", func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", c.Get("X-Lecturer"))
+		return svc.Detail(c)
+	})
+
+	detail := func(lecturerID string) int {
+		req := httptest.NewRequest(http.MethodGet, "/students/student-1", nil)
+		req.Header.Set("X-Lecturer", lecturerID)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, detail("lect-1"))
+	assert.Equal(t, 200, detail("lect-2"))
+	assert.Equal(t, 403, detail("lect-3"))
+}
+
+// MockDelegationStudentRepo: student-1 dibimbing lect-1.
+type MockDelegationStudentRepo struct {
+	MockStudentRepoSS
+}
+
+func (m *MockDelegationStudentRepo) GetStudentIDsByAdvisor(advisorID string) ([]string, error) {
+	if advisorID == "lect-1" {
+		return []string{"student-1"}, nil
+	}
+	return nil, nil
+}
+
+func TestAdvisorDelegation_Create(t *testing.T) {
+	repo := &MockDelegationRepo{}
+	svc := NewAdvisorDelegationService(repo, &MockDelegationLecturerRepo{})
+	app := fiber.New()
+	app.Post("/delegations", func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", "lect-1")
+		c.Locals("user_id", "user-lect-1")
+		return svc.Create(c)
+	})
+
+	post := func(payload map[string]interface{}) (*http.Response, []FieldError) {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, "/delegations", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		var out struct {
+			Fields []FieldError `json:"fields"`
+		}
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp, out.Fields
+	}
+
+	resp, fields := post(map[string]interface{}{"to_lecturer_id": "lect-1"})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []FieldError{
+		{"to_lecturer_id", "tidak boleh sama dengan pemberi delegasi"},
+		{"ends_at", "wajib diisi"},
+		{"reason", "wajib diisi"},
+	}, fields)
+
+	resp, _ = post(map[string]interface{}{"from_lecturer_id": "lect-3", "to_lecturer_id": "lect-2"})
+	assert.Equal(t, 403, resp.StatusCode)
+
+	valid := map[string]interface{}{
+		"to_lecturer_id": "lect-2",
+		"ends_at":        time.Now().Add(90 * 24 * time.Hour),
+		"reason":         "Sabbatical",
+	}
+	resp, _ = post(valid)
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Len(t, repo.delegations, 1)
+	assert.Equal(t, "lect-1", repo.delegations[0].FromLecturerID)
+
+	// rentang waktu beririsan dengan delegasi yang ada
+	resp, _ = post(valid)
+	assert.Equal(t, 409, resp.StatusCode)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// attachmentView: lampiran beserta tautan unduhnya.
+type attachmentView struct {
+	model.Attachment
+	ID           string `json:"id"` // menimpa ID kosong pada lampiran lama
+	Size         *int64 `json:"size,omitempty"`
+	DownloadURL  string `json:"downloadUrl"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+}
+
+// attachmentID: lampiran lama (sebelum ada ID) dirujuk lewat urutannya.
+func attachmentID(a model.Attachment, index int) string {
+	if a.ID != "" {
+		return a.ID
+	}
+	return strconv.Itoa(index)
+}
+
+func findAttachment(ach *model.AchievementMongo, id string) (*model.Attachment, bool) {
+	i := attachmentIndex(ach, id)
+	if i < 0 {
+		return nil, false
+	}
+	return &ach.Attachments[i], true
+}
+
+func attachmentIndex(ach *model.AchievementMongo, id string) int {
+	for i, a := range ach.Attachments {
+		if attachmentID(a, i) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// attachmentOwner: reference + dokumen Mongo yang boleh dilihat user saat
+// ini (aturan Detail).
+func (s *AchievementService) attachmentOwner(c *fiber.Ctx) (*model.AchievementReference, *model.AchievementMongo, error) {
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil || ref.Status == "deleted" {
+		return nil, nil, &reviewError{404, "reference not found"}
+	}
+	if err := s.authorizeView(c, ref); err != nil {
+		return nil, nil, err
+	}
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return nil, nil, &reviewError{404, "achievement not found"}
+	}
+	return ref, ach, nil
+}
+
+// attachmentSource: key di storage; lampiran lama dibaca dari path lokalnya.
+func (s *AchievementService) attachmentSource(a *model.Attachment) (storage.Storage, string) {
+	if a.StorageKey != "" {
+		return s.attachmentStore(), a.StorageKey
+	}
+	return storage.NewLocal("."), strings.TrimPrefix(a.FileURL, "./")
+}
+
+// parseByteRange: satu rentang "bytes=a-b", "bytes=a-" atau "bytes=-n".
+// ok=false berarti header diabaikan (kosong atau multi-range) → 200 penuh.
+func parseByteRange(header string, size int64) (start, length int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, n, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false, errRangeNotSatisfiable
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, errRangeNotSatisfiable
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+	return start, end - start + 1, true, nil
+}
+
+// ======================================================
+// LAMPIRAN — daftar & unduh (aturan akses sama dengan Detail)
+// ======================================================
+
+func (s *AchievementService) ListAttachments(c *fiber.Ctx) error {
+	ref, ach, err := s.attachmentOwner(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+
+	// mahasiswa hanya melihat lampiran aktif; reviewer juga melihat yang
+	// dihapus beserta versi lamanya
+	reviewer := isReviewerRole(reviewActorFrom(c).Role)
+	base := "/api/v1/achievements/" + ref.ID + "/attachments/"
+	views := []attachmentView{}
+	for i, a := range ach.Attachments {
+		if !reviewer {
+			if a.DeletedAt != nil {
+				continue
+			}
+			a.Versions = nil
+		}
+		id := attachmentID(a, i)
+		view := attachmentView{Attachment: a, ID: id, DownloadURL: base + url.PathEscape(id), ThumbnailURL: thumbnailURL(ref.ID, a, i)}
+		store, key := s.attachmentSource(&a)
+		if info, err := store.Stat(c.Context(), key); err == nil {
+			view.Size = &info.Size
+		}
+		views = append(views, view)
+	}
+
+	return c.JSON(fiber.Map{
+		"reference_id": ref.ID,
+		"attachments":  views,
+	})
+}
+
+// DownloadAttachment: stream isi lampiran. Lampiran yang sudah dihapus
+// hanya bisa diunduh reviewer (audit).
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	_, ach, err := s.attachmentOwner(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	att, ok := findAttachment(ach, c.Params("attachmentId"))
+	role, _ := c.Locals("role").(string)
+	if !ok || (att.DeletedAt != nil && !isReviewerRole(role)) {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+	if !att.Downloadable() {
+		return respondNotDownloadable(c, att.ScanStatus)
+	}
+
+	store, key := s.attachmentSource(att)
+	return streamAttachment(c, store, key, att.FileName, att.FileType)
+}
+
+// inlineAttachmentTypes: tipe yang boleh ditampilkan di browser. Lampiran
+// lama menyimpan FileType kiriman client (bisa text/html), jadi tipe lain
+// selalu diunduh.
+var inlineAttachmentTypes = []string{"application/pdf", "image/jpeg", "image/png"}
+
+// streamAttachment mengirim objek storage dengan dukungan satu rentang
+// Range; ?inline=true untuk ditampilkan di browser (hanya
+// inlineAttachmentTypes).
+func streamAttachment(c *fiber.Ctx, store storage.Storage, key, fileName, fileType string) error {
+	ctx := c.Context()
+	info, err := store.Stat(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return c.Status(404).JSON(fiber.Map{"error": "file lampiran tidak ditemukan"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	contentType := fileType
+	if contentType == "" {
+		contentType = info.ContentType
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(fileName))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if c.QueryBool("inline") && contains(inlineAttachmentTypes, contentType) {
+		disposition = "inline"
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": fileName}))
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	if !info.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, info.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	start, length, partial, err := parseByteRange(c.Get(fiber.HeaderRange), info.Size)
+	if err != nil {
+		c.Set(fiber.HeaderContentRange, "bytes */"+strconv.FormatInt(info.Size, 10))
+		return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(fiber.Map{"error": "range tidak valid"})
+	}
+
+	var body io.ReadCloser
+	if partial {
+		body, _, err = store.GetRange(ctx, key, start, length)
+		c.Status(fiber.StatusPartialContent)
+		c.Set(fiber.HeaderContentRange, "bytes "+strconv.FormatInt(start, 10)+"-"+
+			strconv.FormatInt(start+length-1, 10)+"/"+strconv.FormatInt(info.Size, 10))
+	} else {
+		body, _, err = store.Get(ctx, key)
+		length = info.Size
+	}
+	if err != nil {
+		c.Status(500)
+		return c.JSON(fiber.Map{"error": err.Error()})
+	}
+	// fasthttp menutup body setelah selesai dikirim
+	return c.SendStream(body, int(length))
+}
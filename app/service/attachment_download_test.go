@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK =================
+
+type MockDownloadMongoRepo struct {
+	MockAchievementMongoRepo
+	attachments []model.Attachment
+}
+
+func (m *MockDownloadMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	return &model.AchievementMongo{ID: id, Attachments: m.attachments}, nil
+}
+
+func downloadApp(t *testing.T) *fiber.App {
+	store := storage.NewLocal(t.TempDir())
+	store.Put(context.Background(), "obj/abcd-sertifikat.pdf", strings.NewReader("0123456789"), 10, "application/pdf")
+
+	svc := &AchievementService{
+		MongoRepo: &MockDownloadMongoRepo{attachments: []model.Attachment{
+			{ID: "att-1", FileName: "sertifikat.pdf", StorageKey: "obj/abcd-sertifikat.pdf", FileType: "application/pdf"},
+			{FileName: "lama.pdf", FileURL: "uploads/tidak-ada/lama.pdf"},
+		}},
+		PostgresRepo: &MockAchievementPostgresRepo{},
+		StudentRepo:  &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+		Storage:      store,
+	}
+
+	app := fiber.New()
+	auth := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", utils.CopyString(c.Get("X-Role")))
+			c.Locals("student_id", utils.CopyString(c.Get("X-Student")))
+			c.Locals("lecturer_id", utils.CopyString(c.Get("X-Lecturer")))
+			return h(c)
+		}
+	}
+	app.Get("/achievements/:refId/attachments", auth(svc.ListAttachments))
+	app.Get("/achievements/:refId/attachments/:attachmentId", auth(svc.DownloadAttachment))
+	return app
+}
+
+func downloadRequest(app *fiber.App, path string, headers map[string]string) (int, map[string]string, string) {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("X-Role", "Mahasiswa")
+	req.Header.Set("X-Student", "student-1")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+
+	out := map[string]string{}
+	for _, h := range []string{"Content-Type", "Content-Range", "Content-Disposition", "Accept-Ranges", "X-Content-Type-Options"} {
+		out[h] = resp.Header.Get(h)
+	}
+	return resp.StatusCode, out, string(body)
+}
+
+// ================= TESTS =================
+
+func TestParseByteRange(t *testing.T) {
+	cases := []struct {
+		header        string
+		start, length int64
+		ok, invalid   bool
+	}{
+		{"", 0, 0, false, false},
+		{"bytes=0-4", 0, 5, true, false},
+		{"bytes=5-", 5, 5, true, false},
+		{"bytes=-3", 7, 3, true, false},
+		{"bytes=8-100", 8, 2, true, false},
+		{"bytes=0-1,4-5", 0, 0, false, false},
+		{"bytes=10-", 0, 0, false, true},
+		{"bytes=5-2", 0, 0, false, true},
+		{"bytes=abc", 0, 0, false, true},
+	}
+	for _, tc := range cases {
+		start, length, ok, err := parseByteRange(tc.header, 10)
+		assert.Equal(t, tc.invalid, err != nil, tc.header)
+		assert.Equal(t, tc.ok, ok, tc.header)
+		assert.Equal(t, tc.start, start, tc.header)
+		assert.Equal(t, tc.length, length, tc.header)
+	}
+}
+
+func TestListAttachments(t *testing.T) {
+	app := downloadApp(t)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1/attachments", nil)
+	req.Header.Set("X-Role", "Mahasiswa")
+	req.Header.Set("X-Student", "student-1")
+	resp, _ := app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var out struct {
+		Attachments []struct {
+			ID          string `json:"id"`
+			Size        *int64 `json:"size"`
+			DownloadURL string `json:"downloadUrl"`
+		} `json:"attachments"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+	assert.Len(t, out.Attachments, 2)
+	assert.Equal(t, "att-1", out.Attachments[0].ID)
+	assert.Equal(t, int64(10), *out.Attachments[0].Size)
+	assert.Equal(t, "/api/v1/achievements/ref-1/attachments/att-1", out.Attachments[0].DownloadURL)
+	// lampiran lama tanpa ID dirujuk lewat urutannya
+	assert.Equal(t, "1", out.Attachments[1].ID)
+	assert.Nil(t, out.Attachments[1].Size)
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	app := downloadApp(t)
+	path := "/achievements/ref-1/attachments/att-1"
+
+	status, headers, body := downloadRequest(app, path, nil)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "0123456789", body)
+	assert.Equal(t, "application/pdf", headers["Content-Type"])
+	assert.Equal(t, "bytes", headers["Accept-Ranges"])
+	assert.Equal(t, `attachment; filename=sertifikat.pdf`, headers["Content-Disposition"])
+	assert.Equal(t, "nosniff", headers["X-Content-Type-Options"])
+
+	status, headers, body = downloadRequest(app, path, map[string]string{"Range": "bytes=2-4"})
+	assert.Equal(t, 206, status)
+	assert.Equal(t, "234", body)
+	assert.Equal(t, "bytes 2-4/10", headers["Content-Range"])
+
+	status, _, body = downloadRequest(app, path, map[string]string{"Range": "bytes=-3"})
+	assert.Equal(t, 206, status)
+	assert.Equal(t, "789", body)
+
+	status, headers, _ = downloadRequest(app, path, map[string]string{"Range": "bytes=20-"})
+	assert.Equal(t, 416, status)
+	assert.Equal(t, "bytes */10", headers["Content-Range"])
+
+	status, _, _ = downloadRequest(app, "/achievements/ref-1/attachments/att-x", nil)
+	assert.Equal(t, 404, status)
+
+	// file lampiran lama sudah tidak ada di disk
+	status, _, _ = downloadRequest(app, "/achievements/ref-1/attachments/1", nil)
+	assert.Equal(t, 404, status)
+}
+
+func TestDownloadAttachment_AccessRules(t *testing.T) {
+	app := downloadApp(t)
+	path := "/achievements/ref-1/attachments/att-1"
+
+	status, _, _ := downloadRequest(app, path, map[string]string{"X-Student": "student-2"})
+	assert.Equal(t, 403, status)
+
+	status, _, _ = downloadRequest(app, path, map[string]string{"X-Role": "Dosen Wali", "X-Lecturer": "lect-2"})
+	assert.Equal(t, 403, status)
+
+	status, _, body := downloadRequest(app, path, map[string]string{"X-Role": "Dosen Wali", "X-Lecturer": "lect-1"})
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "0123456789", body)
+
+	status, _, _ = downloadRequest(app, path, map[string]string{"X-Role": "Admin"})
+	assert.Equal(t, 200, status)
+}
+
+func TestStreamAttachment_InlineOnlyForSafeTypes(t *testing.T) {
+	store := storage.NewLocal(t.TempDir())
+	ctx := context.Background()
+	store.Put(ctx, "obj/sertifikat.pdf", strings.NewReader(pdfBytes), int64(len(pdfBytes)), "application/pdf")
+	store.Put(ctx, "obj/halaman.html", strings.NewReader("<script>alert(1)</script>"), 25, "text/html")
+
+	app := fiber.New()
+	// lampiran lama: FileType dari client
+	app.Get("/legacy", func(c *fiber.Ctx) error {
+		return streamAttachment(c, store, "obj/halaman.html", "halaman.html", "text/html")
+	})
+	app.Get("/pdf", func(c *fiber.Ctx) error {
+		return streamAttachment(c, store, "obj/sertifikat.pdf", "sertifikat.pdf", "application/pdf")
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/legacy?inline=true", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, `attachment; filename=halaman.html`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/pdf?inline=true", nil))
+	assert.Equal(t, `inline; filename=sertifikat.pdf`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/imageproc"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// thumbnailSizes: nama ukuran → sisi terpanjang (piksel).
+var thumbnailSizes = map[string]int{
+	"small":  160,
+	"medium": 480,
+}
+
+const (
+	defaultThumbnailSize = "small"
+	thumbnailTimeout     = time.Minute
+)
+
+func isImageType(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/")
+}
+
+// thumbnailKey: thumbnail mengikuti hash isi, jadi lampiran dengan isi yang
+// sama memakai thumbnail yang sama.
+func thumbnailKey(sum, size string) string {
+	return "thumb/" + sum[:2] + "/" + sum + "-" + size + ".jpg"
+}
+
+// thumbnailURL: kosong untuk lampiran yang tidak punya thumbnail.
+func thumbnailURL(refID string, a model.Attachment, index int) string {
+	if !isImageType(a.FileType) || a.SHA256 == "" {
+		return ""
+	}
+	return "/api/v1/achievements/" + refID + "/attachments/" + url.PathEscape(attachmentID(a, index)) + "/thumbnail"
+}
+
+// prepareContent: isi file yang disimpan. Gambar dibuang metadatanya
+// (EXIF/GPS) dan orientasinya dinormalkan di worker pool.
+func (s *AchievementService) prepareContent(ctx context.Context, data []byte, mimeType string) ([]byte, error) {
+	if !isImageType(mimeType) {
+		return data, nil
+	}
+
+	var out []byte
+	err := s.Images.Do(ctx, func() (err error) {
+		out, err = imageproc.StripMetadata(data, mimeType)
+		return err
+	})
+	return out, err
+}
+
+// queueThumbnails membuat thumbnail di latar belakang. Antrean penuh →
+// thumbnail dibuat saat pertama kali diminta.
+func (s *AchievementService) queueThumbnails(att model.Attachment) {
+	if !isImageType(att.FileType) || att.SHA256 == "" || att.StorageKey == "" {
+		return
+	}
+	queued := s.Images.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), thumbnailTimeout)
+		defer cancel()
+		if err := s.generateThumbnails(ctx, att.StorageKey, att.SHA256); err != nil {
+			log.Printf("thumbnail: %s: %v", att.StorageKey, err)
+		}
+	})
+	if !queued {
+		log.Printf("thumbnail: antrean penuh, %s dilewati", att.StorageKey)
+	}
+}
+
+// generateThumbnails membuat semua ukuran thumbnail yang belum ada.
+func (s *AchievementService) generateThumbnails(ctx context.Context, key, sum string) error {
+	store := s.attachmentStore()
+	missing := map[string]int{}
+	for name, edge := range thumbnailSizes {
+		if _, err := store.Stat(ctx, thumbnailKey(sum, name)); errors.Is(err, storage.ErrNotFound) {
+			missing[name] = edge
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	rc, _, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, maxAttachmentSize+1))
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	for name, edge := range missing {
+		thumb, err := imageproc.Thumbnail(data, edge)
+		if err != nil {
+			return err
+		}
+		if err := store.Put(ctx, thumbnailKey(sum, name), bytes.NewReader(thumb), int64(len(thumb)), "image/jpeg"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// thumbnailObjects: key thumbnail milik sebuah objek lampiran.
+func thumbnailObjects(a *model.Attachment) []string {
+	if !isImageType(a.FileType) || a.SHA256 == "" {
+		return nil
+	}
+	var keys []string
+	for name := range thumbnailSizes {
+		keys = append(keys, thumbnailKey(a.SHA256, name))
+	}
+	return keys
+}
+
+// AttachmentThumbnail: thumbnail lampiran gambar (?size=small|medium).
+// Thumbnail yang belum jadi dijadwalkan dan dijawab 202.
+func (s *AchievementService) AttachmentThumbnail(c *fiber.Ctx) error {
+	_, ach, err := s.attachmentOwner(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	att, ok := findAttachment(ach, c.Params("attachmentId"))
+	role, _ := c.Locals("role").(string)
+	if !ok || (att.DeletedAt != nil && !isReviewerRole(role)) {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+	if !isImageType(att.FileType) || att.SHA256 == "" {
+		return c.Status(404).JSON(fiber.Map{"error": "thumbnail tidak tersedia untuk lampiran ini"})
+	}
+	if !att.Downloadable() {
+		return respondNotDownloadable(c, att.ScanStatus)
+	}
+
+	size := c.Query("size", defaultThumbnailSize)
+	if _, ok := thumbnailSizes[size]; !ok {
+		return respondValidation(c, []FieldError{{"size", "harus salah satu dari: small, medium"}})
+	}
+
+	key := thumbnailKey(att.SHA256, size)
+	if _, err := s.attachmentStore().Stat(c.Context(), key); errors.Is(err, storage.ErrNotFound) {
+		s.queueThumbnails(*att)
+		// pool nil menjalankan pembuatan thumbnail langsung
+		if _, err := s.attachmentStore().Stat(c.Context(), key); err != nil {
+			c.Set(fiber.HeaderRetryAfter, "5")
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "thumbnail sedang dibuat"})
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	name := strings.TrimSuffix(att.FileName, path.Ext(att.FileName)) + "-" + size + ".jpg"
+	return streamAttachment(c, s.attachmentStore(), key, name, "image/jpeg")
+}
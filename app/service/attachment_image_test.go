@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image/jpeg"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"prestasi_api/imageproc"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// jpegWithGPS: jpegBytes dengan segmen EXIF berisi data lokasi.
+func jpegWithGPS() string {
+	payload := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x00GPSLatitude -6.2088")
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return jpegBytes[:2] + string(seg) + string(payload) + jpegBytes[2:]
+}
+
+func imageFixture(t *testing.T) *scanFixture {
+	f := newScanFixture(t, nil)
+	f.app.Get("/achievements/:refId/attachments/:attachmentId/thumbnail", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		return f.svc.AttachmentThumbnail(c)
+	})
+	return f
+}
+
+func (f *scanFixture) thumbnail(id, query string) (int, string, []byte) {
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments/"+id+"/thumbnail"+query, nil))
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, resp.Header.Get("Content-Type"), body
+}
+
+func TestUploadAttachment_StripsImageMetadataAndBuildsThumbnails(t *testing.T) {
+	f := imageFixture(t)
+	ctx := context.Background()
+
+	status, out := uploadRequest(f.app, "medali.jpg", jpegWithGPS())
+	assert.Equal(t, 200, status)
+	att := f.mongo.ach.Attachments[0]
+	assert.Equal(t, "/api/v1/achievements/ref-1/attachments/"+att.ID+"/thumbnail", out["thumbnailUrl"])
+
+	// yang disimpan & di-hash adalah isi tanpa metadata
+	rc, _, err := f.store.Get(ctx, att.StorageKey)
+	assert.NoError(t, err)
+	stored, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.NotContains(t, string(stored), "GPSLatitude")
+	assert.Equal(t, sha256Hex(string(stored)), att.SHA256)
+	assert.Equal(t, int64(len(stored)), att.Size)
+
+	// pool nil → thumbnail langsung dibuat
+	for size := range thumbnailSizes {
+		_, err := f.store.Stat(ctx, thumbnailKey(att.SHA256, size))
+		assert.NoError(t, err, size)
+	}
+
+	status, contentType, body := f.thumbnail(att.ID, "?size=medium")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "image/jpeg", contentType)
+	_, err = jpeg.Decode(bytes.NewReader(body))
+	assert.NoError(t, err)
+}
+
+func TestUploadAttachment_RejectsCorruptImage(t *testing.T) {
+	f := imageFixture(t)
+
+	status, out := uploadRequest(f.app, "medali.jpg", "\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+	assert.Equal(t, 400, status)
+	assert.Contains(t, out["fields"].([]interface{})[0].(map[string]interface{})["message"], "gambar tidak dapat diproses")
+	assert.Empty(t, f.mongo.ach.Attachments)
+}
+
+func TestAttachmentThumbnail(t *testing.T) {
+	f := imageFixture(t)
+	uploadRequest(f.app, "medali.png", pngBytes)
+	uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+	img, pdf := f.mongo.ach.Attachments[0], f.mongo.ach.Attachments[1]
+
+	status, _, _ := f.thumbnail(img.ID, "?size=huge")
+	assert.Equal(t, 400, status)
+
+	status, _, _ = f.thumbnail(pdf.ID, "")
+	assert.Equal(t, 404, status)
+
+	// thumbnail hilang → dibuat ulang saat diminta
+	f.store.Delete(context.Background(), thumbnailKey(img.SHA256, "small"))
+	status, contentType, _ := f.thumbnail(img.ID, "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "image/jpeg", contentType)
+
+	// worker sibuk & antrean penuh → 202, dicoba lagi nanti
+	pool := imageproc.NewPool(1, 1)
+	defer pool.Close()
+	block, started := make(chan struct{}), make(chan struct{})
+	pool.Go(func() { close(started); <-block })
+	<-started
+	pool.Go(func() {})
+	defer close(block)
+	f.svc.Images = pool
+
+	f.store.Delete(context.Background(), thumbnailKey(img.SHA256, "small"))
+	status, _, _ = f.thumbnail(img.ID, "")
+	assert.Equal(t, 202, status)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cek integritas: tiap lampiran ber-hash di-hash ulang dari storage paling
+// lambat setiap DefaultIntegrityRecheck; job berjalan tiap
+// DefaultIntegrityCheckPeriod dan memproses integrityBatchSize prestasi.
+const (
+	integrityBatchSize          = 100
+	DefaultIntegrityCheckPeriod = time.Hour
+	DefaultIntegrityRecheck     = 7 * 24 * time.Hour
+)
+
+// IntegrityCheckIntervalFromEnv: ATTACHMENT_INTEGRITY_CHECK_MINUTES (default 60).
+func IntegrityCheckIntervalFromEnv() time.Duration {
+	return envDuration("ATTACHMENT_INTEGRITY_CHECK_MINUTES", time.Minute, DefaultIntegrityCheckPeriod)
+}
+
+// IntegrityRecheckFromEnv: ATTACHMENT_INTEGRITY_RECHECK_DAYS (default 7).
+func IntegrityRecheckFromEnv() time.Duration {
+	return envDuration("ATTACHMENT_INTEGRITY_RECHECK_DAYS", 24*time.Hour, DefaultIntegrityRecheck)
+}
+
+type AttachmentIntegrityResult struct {
+	Checked    int      `json:"checked"`
+	OK         int      `json:"ok"`
+	Mismatched int      `json:"mismatched"`
+	Missing    int      `json:"missing"`
+	Failed     []string `json:"failed"`
+}
+
+// AttachmentIntegrityIssue: lampiran yang isinya tidak lagi sesuai hash
+// saat diunggah, atau objeknya hilang dari storage.
+type AttachmentIntegrityIssue struct {
+	MongoID            string     `json:"mongoId"`
+	StudentID          string     `json:"studentId"`
+	AttachmentID       string     `json:"attachmentId"`
+	FileName           string     `json:"fileName"`
+	StorageKey         string     `json:"storageKey"`
+	SHA256             string     `json:"sha256"`
+	IntegrityStatus    string     `json:"integrityStatus"`
+	IntegrityCheckedAt *time.Time `json:"integrityCheckedAt"`
+}
+
+// hashObject: SHA-256 (hex) isi objek di storage.
+func hashObject(ctx context.Context, store storage.Storage, key string) (string, error) {
+	rc, _, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isVerificationRecord: riwayat verifikasi (termasuk konfirmasi
+// penyelenggara) menyimpan hash lampiran sebagai bukti.
+func isVerificationRecord(newStatus, role string) bool {
+	return newStatus == "verified" || role == "Organizer"
+}
+
+// attachmentEvidence: hash lampiran aktif prestasi saat ini. Lampiran lama
+// tanpa hash tidak ikut; gagal membaca data → tanpa evidence.
+func (s *AchievementService) attachmentEvidence(refID string) []model.EvidenceHash {
+	if s.MongoRepo == nil {
+		return nil
+	}
+	mongoID, err := s.PostgresRepo.GetMongoID(refID)
+	if err != nil {
+		return nil
+	}
+	oid, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return nil
+	}
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil || ach == nil {
+		return nil
+	}
+
+	var evidence []model.EvidenceHash
+	for i, att := range ach.Attachments {
+		if att.DeletedAt != nil || att.SHA256 == "" {
+			continue
+		}
+		evidence = append(evidence, model.EvidenceHash{
+			AttachmentID: attachmentID(att, i),
+			FileName:     att.FileName,
+			Version:      att.CurrentVersion(),
+			SHA256:       att.SHA256,
+		})
+	}
+	return evidence
+}
+
+// ======================================================
+// INTEGRITAS — hash ulang isi lampiran di storage
+// ======================================================
+
+// CheckAttachmentIntegrity meng-hash ulang lampiran yang jatuh tempo dan
+// membandingkannya dengan hash saat upload. Objek yang dipakai bersama
+// cukup di-hash sekali; hasilnya disimpan untuk semua lampiran pemakainya.
+func (s *AchievementService) CheckAttachmentIntegrity(ctx context.Context, now time.Time) (AttachmentIntegrityResult, error) {
+	result := AttachmentIntegrityResult{Failed: []string{}}
+
+	recheck := s.IntegrityRecheck
+	if recheck <= 0 {
+		recheck = DefaultIntegrityRecheck
+	}
+	before := now.Add(-recheck)
+	achievements, err := s.MongoRepo.ListIntegrityDue(before, integrityBatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	store := s.attachmentStore()
+	seen := map[string]bool{}
+	for _, ach := range achievements {
+		for _, att := range ach.Attachments {
+			if att.SHA256 == "" || att.StorageKey == "" || seen[att.StorageKey] {
+				continue
+			}
+			if att.IntegrityCheckedAt != nil && !att.IntegrityCheckedAt.Before(before) {
+				continue
+			}
+			key := att.StorageKey
+			seen[key] = true
+
+			status := model.IntegrityOK
+			sum, err := hashObject(ctx, store, key)
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				status = model.IntegrityMissing
+			case err != nil:
+				result.Failed = append(result.Failed, key+": "+err.Error())
+				continue
+			case sum != att.SHA256:
+				status = model.IntegrityMismatch
+			}
+
+			if err := s.MongoRepo.SetAttachmentIntegrity(key, status, now); err != nil {
+				result.Failed = append(result.Failed, key+": "+err.Error())
+				continue
+			}
+			result.Checked++
+			switch status {
+			case model.IntegrityOK:
+				result.OK++
+			case model.IntegrityMismatch:
+				result.Mismatched++
+				log.Printf("integrity: %s berubah (hash %s, tercatat %s)", key, sum, att.SHA256)
+			case model.IntegrityMissing:
+				result.Missing++
+				log.Printf("integrity: %s tidak ditemukan di storage", key)
+			}
+		}
+	}
+	return result, nil
+}
+
+// RunIntegrityCheck: job scheduler.
+func (s *AchievementService) RunIntegrityCheck(ctx context.Context) error {
+	result, err := s.CheckAttachmentIntegrity(ctx, time.Now())
+	if err == nil && len(result.Failed) > 0 {
+		log.Printf("integrity: %d lampiran gagal dicek, dicoba lagi nanti", len(result.Failed))
+	}
+	return err
+}
+
+// CheckIntegrityNow (Admin): jalankan cek integritas tanpa menunggu jadwal.
+func (s *AchievementService) CheckIntegrityNow(c *fiber.Ctx) error {
+	result, err := s.CheckAttachmentIntegrity(c.Context(), time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(result)
+}
+
+// ListIntegrityIssues (Admin): lampiran yang berubah atau hilang dari storage.
+func (s *AchievementService) ListIntegrityIssues(c *fiber.Ctx) error {
+	achievements, err := s.MongoRepo.ListIntegrityIssues()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	issues := []AttachmentIntegrityIssue{}
+	for _, ach := range achievements {
+		for i, att := range ach.Attachments {
+			if att.IntegrityStatus != model.IntegrityMismatch && att.IntegrityStatus != model.IntegrityMissing {
+				continue
+			}
+			issues = append(issues, AttachmentIntegrityIssue{
+				MongoID:            ach.ID.Hex(),
+				StudentID:          ach.StudentID,
+				AttachmentID:       attachmentID(att, i),
+				FileName:           att.FileName,
+				StorageKey:         att.StorageKey,
+				SHA256:             att.SHA256,
+				IntegrityStatus:    att.IntegrityStatus,
+				IntegrityCheckedAt: att.IntegrityCheckedAt,
+			})
+		}
+	}
+	return c.JSON(fiber.Map{"data": issues})
+}
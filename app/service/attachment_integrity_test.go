@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK =================
+
+type MockIntegrityMongoRepo struct {
+	MockAchievementMongoRepo
+	achievements []model.AchievementMongo
+	refs         int64
+	purged       []primitive.ObjectID
+}
+
+func (m *MockIntegrityMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	ach := m.achievements[0]
+	return &ach, nil
+}
+
+func (m *MockIntegrityMongoRepo) ListIntegrityDue(checkedBefore time.Time, limit int) ([]model.AchievementMongo, error) {
+	return m.achievements, nil
+}
+
+func (m *MockIntegrityMongoRepo) SetAttachmentIntegrity(storageKey, status string, at time.Time) error {
+	for i := range m.achievements {
+		for j, a := range m.achievements[i].Attachments {
+			if a.StorageKey == storageKey {
+				a.IntegrityStatus, a.IntegrityCheckedAt = status, &at
+				m.achievements[i].Attachments[j] = a
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockIntegrityMongoRepo) ListPurgeable(deletedBefore time.Time) ([]model.AchievementMongo, error) {
+	return m.achievements, nil
+}
+
+func (m *MockIntegrityMongoRepo) CountStorageKeyRefs(storageKey string, exclude primitive.ObjectID) (int64, error) {
+	return m.refs, nil
+}
+
+func (m *MockIntegrityMongoRepo) MarkAttachmentsPurged(id primitive.ObjectID, at time.Time) error {
+	m.purged = append(m.purged, id)
+	return nil
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func storedObjects(dir string) []string {
+	var files []string
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files
+}
+
+// putObject menyimpan isi ke storage dan mengembalikan lampiran ber-hash.
+func putObject(t *testing.T, store storage.Storage, id, content string) model.Attachment {
+	sum := sha256Hex(content)
+	key := contentKey(sum, ".pdf")
+	err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "application/pdf")
+	assert.NoError(t, err)
+	return model.Attachment{ID: id, FileName: id + ".pdf", StorageKey: key, SHA256: sum}
+}
+
+// ================= TESTS =================
+
+func TestUploadAttachment_DeduplicatesIdenticalContent(t *testing.T) {
+	dir := t.TempDir()
+	mongo := &MockUploadMongoRepo{}
+	app := uploadApp(&AchievementService{
+		MongoRepo:    mongo,
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		Storage:      storage.NewLocal(dir),
+	})
+
+	status, first := uploadRequest(app, "sertifikat.pdf", "%PDF-1.4 isi")
+	assert.Equal(t, 200, status)
+	status, second := uploadRequest(app, "salinan.pdf", "%PDF-1.4 isi")
+	assert.Equal(t, 200, status)
+
+	assert.Equal(t, first["key"], second["key"])
+	assert.NotEqual(t, first["id"], second["id"])
+	assert.Len(t, storedObjects(dir), 1)
+
+	// metadata gagal disimpan → objek bersama tidak ikut dihapus
+	mongo.fail = true
+	status, _ = uploadRequest(app, "lagi.pdf", "%PDF-1.4 isi")
+	assert.Equal(t, 500, status)
+	assert.Len(t, storedObjects(dir), 1)
+}
+
+func TestCheckAttachmentIntegrity(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocal(t.TempDir())
+	now := time.Now()
+
+	ok := putObject(t, store, "att-ok", "%PDF-1.4 asli")
+	changed := putObject(t, store, "att-changed", "%PDF-1.4 lain")
+	store.Put(ctx, changed.StorageKey, strings.NewReader("%PDF-1.4 diubah"), 15, "application/pdf")
+	missing := model.Attachment{ID: "att-missing", StorageKey: contentKey(sha256Hex("hilang"), ".pdf"), SHA256: sha256Hex("hilang")}
+	legacy := model.Attachment{ID: "att-legacy", FileURL: "/uploads/lama.pdf"}
+
+	recent := now.Add(-time.Hour)
+	fresh := putObject(t, store, "att-fresh", "%PDF-1.4 baru dicek")
+	fresh.IntegrityCheckedAt = &recent
+
+	// lampiran prestasi lain dengan isi yang sama
+	shared := ok
+	shared.ID = "att-shared"
+
+	mongo := &MockIntegrityMongoRepo{achievements: []model.AchievementMongo{
+		{ID: primitive.NewObjectID(), Attachments: []model.Attachment{ok, changed, missing, legacy, fresh}},
+		{ID: primitive.NewObjectID(), Attachments: []model.Attachment{shared}},
+	}}
+	svc := &AchievementService{MongoRepo: mongo, Storage: store}
+
+	result, err := svc.CheckAttachmentIntegrity(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, AttachmentIntegrityResult{Checked: 3, OK: 1, Mismatched: 1, Missing: 1, Failed: []string{}}, result)
+
+	atts := mongo.achievements[0].Attachments
+	assert.Equal(t, model.IntegrityOK, atts[0].IntegrityStatus)
+	assert.Equal(t, model.IntegrityMismatch, atts[1].IntegrityStatus)
+	assert.Equal(t, model.IntegrityMissing, atts[2].IntegrityStatus)
+	assert.Empty(t, atts[3].IntegrityStatus)
+	assert.Empty(t, atts[4].IntegrityStatus)
+	assert.Equal(t, model.IntegrityOK, mongo.achievements[1].Attachments[0].IntegrityStatus)
+}
+
+func TestPurgeDeletedAttachments_KeepsSharedObjects(t *testing.T) {
+	dir := t.TempDir()
+	store := storage.NewLocal(dir)
+	att := putObject(t, store, "att-1", "%PDF-1.4 dipakai bersama")
+
+	mongo := &MockIntegrityMongoRepo{
+		achievements: []model.AchievementMongo{{ID: primitive.NewObjectID(), Attachments: []model.Attachment{att}}},
+		refs:         1,
+	}
+	svc := &AchievementService{MongoRepo: mongo, Storage: store}
+
+	result, err := svc.PurgeDeletedAttachments(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Achievements)
+	assert.Equal(t, 0, result.Objects)
+	assert.Equal(t, 1, result.Shared)
+	assert.Len(t, storedObjects(dir), 1)
+	assert.Len(t, mongo.purged, 1)
+}
+
+func TestSaveHistory_VerificationRecordsEvidence(t *testing.T) {
+	store := storage.NewLocal(t.TempDir())
+	att := putObject(t, store, "att-1", "%PDF-1.4 bukti")
+	deleted := putObject(t, store, "att-2", "%PDF-1.4 dihapus")
+	now := time.Now()
+	deleted.DeletedAt = &now
+
+	pg := &MockHistoryPostgresRepo{}
+	svc := &AchievementService{
+		MongoRepo: &MockIntegrityMongoRepo{achievements: []model.AchievementMongo{
+			{ID: primitive.NewObjectID(), Attachments: []model.Attachment{att, deleted, {FileURL: "/uploads/lama.pdf"}}},
+		}},
+		PostgresRepo: pg,
+	}
+
+	svc.saveHistory("ref-1", "submitted", "verified", "lecturer-user-1", "Dosen Wali", "ok")
+	svc.saveHistory("ref-1", "draft", "submitted", "user-1", "Mahasiswa", "")
+
+	assert.Len(t, pg.history, 2)
+	assert.Equal(t, []model.EvidenceHash{{AttachmentID: "att-1", FileName: "att-1.pdf", Version: 1, SHA256: att.SHA256}}, pg.history[0].Evidence)
+	assert.Empty(t, pg.history[1].Evidence)
+}
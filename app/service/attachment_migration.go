@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"prestasi_api/app/repository"
+	"prestasi_api/storage"
+)
+
+// UploadMigrationOptions: Root adalah direktori tempat path lama (FileURL,
+// mis. "uploads/<mongoId>/file.pdf") relatif terhadapnya.
+type UploadMigrationOptions struct {
+	Root        string
+	DryRun      bool
+	DeleteLocal bool
+}
+
+type UploadMigrationReport struct {
+	Migrated int      `json:"migrated"`
+	Skipped  int      `json:"skipped"`
+	Missing  []string `json:"missing"`
+	Failed   []string `json:"failed"`
+}
+
+// MigrateUploads memindahkan lampiran lama (FileURL tanpa StorageKey) dari
+// disk lokal ke backend storage dengan key "<mongoId>/<nama file>", lalu
+// mengisi storageKey. Aman dijalankan ulang: objek yang sudah ada dengan
+// ukuran sama tidak diunggah lagi.
+func MigrateUploads(ctx context.Context, repo repository.AchievementMongoRepository, store storage.Storage, opts UploadMigrationOptions) (*UploadMigrationReport, error) {
+	achievements, err := repo.ListWithAttachments()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UploadMigrationReport{Missing: []string{}, Failed: []string{}}
+	for _, ach := range achievements {
+		for i, att := range ach.Attachments {
+			if att.StorageKey != "" || att.FileURL == "" {
+				report.Skipped++
+				continue
+			}
+
+			src := filepath.Join(opts.Root, filepath.FromSlash(strings.TrimPrefix(att.FileURL, "./")))
+			key := ach.ID.Hex() + "/" + path.Base(filepath.ToSlash(att.FileURL))
+
+			err := migrateUpload(ctx, store, src, key, att.FileType, opts.DryRun)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				report.Missing = append(report.Missing, att.FileURL)
+				continue
+			case err != nil:
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", att.FileURL, err))
+				continue
+			}
+			report.Migrated++
+			if opts.DryRun {
+				continue
+			}
+
+			if err := repo.SetAttachmentStorageKey(ach.ID, i, key); err != nil {
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", att.FileURL, err))
+				continue
+			}
+			if opts.DeleteLocal && !sameLocalObject(store, key, src) {
+				os.Remove(src)
+			}
+		}
+	}
+	return report, nil
+}
+
+func migrateUpload(ctx context.Context, store storage.Storage, src, key, contentType string, dryRun bool) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info, err := store.Stat(ctx, key); err == nil && info.Size == st.Size() {
+		return nil
+	}
+	if dryRun {
+		return nil
+	}
+	return store.Put(ctx, key, f, st.Size(), contentType)
+}
+
+// sameLocalObject: backend lokal yang menunjuk ke ./uploads menyimpan key
+// di path yang sama dengan file lama — file itu tidak boleh dihapus.
+func sameLocalObject(store storage.Storage, key, src string) bool {
+	local, ok := store.(*storage.Local)
+	if !ok {
+		return false
+	}
+	a, errA := filepath.Abs(filepath.Join(local.Dir, filepath.FromSlash(key)))
+	b, errB := filepath.Abs(src)
+	return errA == nil && errB == nil && a == b
+}
@@ -0,0 +1,544 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Upload bertahap mengikuti protokol tus 1.0 (core + creation, expiration,
+// termination): POST membuat sesi, PATCH mengirim potongan dari
+// Upload-Offset, HEAD menanyakan offset terakhir.
+const (
+	tusVersion          = "1.0.0"
+	tusExtensions       = "creation,expiration,termination"
+	tusChunkContentType = "application/offset+octet-stream"
+
+	DefaultUploadExpiry = 24 * time.Hour
+
+	// DefaultResumableMaxSize: batas per file upload bertahap (scan & video).
+	DefaultResumableMaxSize int64 = 512 << 20
+	// maxResumableImageSize: JPEG/PNG tetap diproses di memori (metadata
+	// dibuang), jadi dibatasi lebih kecil dari file lain.
+	maxResumableImageSize = 25 << 20
+)
+
+// UploadExpiryFromEnv: RESUMABLE_UPLOAD_EXPIRY_HOURS (default 24). Setiap
+// potongan yang diterima memperpanjang masa berlaku sesi.
+func UploadExpiryFromEnv() time.Duration {
+	return envDuration("RESUMABLE_UPLOAD_EXPIRY_HOURS", time.Hour, DefaultUploadExpiry)
+}
+
+// ResumableMaxSizeFromEnv: RESUMABLE_UPLOAD_MAX_MB (default 512). Lampiran
+// di atas batas upload biasa memakai kuota terpisah sebesar nilai ini per
+// prestasi.
+func ResumableMaxSizeFromEnv() int64 {
+	v := os.Getenv("RESUMABLE_UPLOAD_MAX_MB")
+	if v == "" {
+		return DefaultResumableMaxSize
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("resumable upload: RESUMABLE_UPLOAD_MAX_MB=%q tidak valid, pakai default %s", v, formatMB(DefaultResumableMaxSize))
+		return DefaultResumableMaxSize
+	}
+	return n << 20
+}
+
+type UploadExpiryResult struct {
+	Expired int      `json:"expired"`
+	Failed  []string `json:"failed"`
+}
+
+func (s *AchievementService) uploadExpiry() time.Duration {
+	if s.UploadExpiry <= 0 {
+		return DefaultUploadExpiry
+	}
+	return s.UploadExpiry
+}
+
+func (s *AchievementService) resumableMaxSize() int64 {
+	if s.ResumableMaxSize <= 0 {
+		return DefaultResumableMaxSize
+	}
+	return s.ResumableMaxSize
+}
+
+// checkResumableSize: file sampai maxAttachmentSize ikut aturan upload
+// biasa; di atasnya dibatasi resumableMaxSize dengan kuota lampiran besar
+// tersendiri supaya satu video tidak menghabiskan kuota sertifikat.
+func (s *AchievementService) checkResumableSize(ctx context.Context, ach *model.AchievementMongo, size int64) []FieldError {
+	if size <= maxAttachmentSize {
+		return s.checkUploadSize(ctx, ach, size)
+	}
+	limit := s.resumableMaxSize()
+	if size > limit {
+		return []FieldError{{"file", "ukuran maksimal " + formatMB(limit)}}
+	}
+	if used := s.attachmentsSize(ctx, ach, true); used+size > limit {
+		return []FieldError{{"file", "total lampiran besar prestasi maksimal " + formatMB(limit) +
+			" (terpakai " + formatMB(used) + ")"}}
+	}
+	return nil
+}
+
+// chunkKey: potongan disimpan per percobaan (suffix acak) supaya request
+// ganda dengan offset sama tidak saling menimpa.
+func chunkKey(uploadID string, offset int64) string {
+	return fmt.Sprintf("partial/%s/%020d-%s", uploadID, offset, uuid.New().String()[:8])
+}
+
+// parseUploadMetadata: "filename <base64>,kind <base64>".
+func parseUploadMetadata(header string) map[string]string {
+	meta := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		meta[key] = string(decoded)
+	}
+	return meta
+}
+
+func setTusHeaders(c *fiber.Ctx) {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set(fiber.HeaderCacheControl, "no-store")
+}
+
+// tusPrecondition: client yang mengirim Tus-Resumable harus memakai versi
+// yang didukung.
+func tusPrecondition(c *fiber.Ctx) error {
+	if v := c.Get("Tus-Resumable"); v != "" && v != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return &reviewError{fiber.StatusPreconditionFailed, "versi tus tidak didukung"}
+	}
+	return nil
+}
+
+// uploadTarget: prestasi tujuan upload; mahasiswa hanya ke prestasinya sendiri
+// selama draft (aturan UploadAttachment).
+func (s *AchievementService) uploadTarget(c *fiber.Ctx) (*model.AchievementReference, error) {
+	if s.UploadRepo == nil {
+		return nil, &reviewError{404, "resumable upload not available"}
+	}
+	if err := tusPrecondition(c); err != nil {
+		return nil, err
+	}
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil || ref.Status == "deleted" {
+		return nil, &reviewError{404, "reference not found"}
+	}
+	if role, _ := c.Locals("role").(string); role == "Mahasiswa" {
+		studentID, _ := c.Locals("student_id").(string)
+		if ref.StudentID != studentID {
+			return nil, &reviewError{403, "Anda tidak boleh mengunggah lampiran ke prestasi milik mahasiswa lain"}
+		}
+	}
+	if err := attachmentsUploadable(c, ref); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
+
+// uploadSession: sesi milik user saat ini pada prestasi di URL.
+func (s *AchievementService) uploadSession(c *fiber.Ctx) (*model.AttachmentUpload, error) {
+	if s.UploadRepo == nil {
+		return nil, &reviewError{404, "resumable upload not available"}
+	}
+	if err := tusPrecondition(c); err != nil {
+		return nil, err
+	}
+	up, err := s.UploadRepo.Get(c.Params("uploadId"))
+	userID, _ := c.Locals("user_id").(string)
+	if err != nil || up.ReferenceID != c.Params("refId") || up.UploadedBy != userID {
+		return nil, &reviewError{404, "upload not found"}
+	}
+	if time.Now().After(up.ExpiresAt) {
+		return nil, &reviewError{fiber.StatusGone, "upload sudah kedaluwarsa"}
+	}
+	return up, nil
+}
+
+// discardUpload menghapus potongan di storage lalu sesinya.
+func (s *AchievementService) discardUpload(ctx context.Context, up *model.AttachmentUpload) error {
+	store := s.attachmentStore()
+	for _, key := range up.ChunkKeys {
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return s.UploadRepo.Delete(up.ID)
+}
+
+// ======================================================
+// UPLOAD BERTAHAP (tus)
+// ======================================================
+
+// ResumableOptions: discovery tus. Tanpa login (preflight CORS tidak
+// membawa token) dan tanpa data prestasi apa pun.
+func (s *AchievementService) ResumableOptions(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Max-Size", strconv.FormatInt(s.resumableMaxSize(), 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateResumableUpload: POST dengan Upload-Length dan Upload-Metadata
+// (filename, kind). Ukuran & kuota dicek di awal supaya client tidak
+// mengirim file yang pasti ditolak.
+func (s *AchievementService) CreateResumableUpload(c *fiber.Ctx) error {
+	setTusHeaders(c)
+	ref, err := s.uploadTarget(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Upload-Length wajib diisi"})
+	}
+	if limit := s.resumableMaxSize(); length > limit {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "ukuran maksimal " + formatMB(limit)})
+	}
+
+	meta := parseUploadMetadata(c.Get("Upload-Metadata"))
+	kind := meta["kind"]
+	if kind == "" {
+		kind = "other"
+	}
+	if !contains(model.AttachmentKinds, kind) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "kind harus salah satu dari: " + strings.Join(model.AttachmentKinds, ", "),
+		})
+	}
+	if meta["filename"] == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Upload-Metadata filename wajib diisi"})
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+	}
+	if errs := s.checkResumableSize(c.Context(), ach, length); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	now := time.Now()
+	userID, _ := c.Locals("user_id").(string)
+	up := &model.AttachmentUpload{
+		ID:          uuid.New().String(),
+		ReferenceID: ref.ID,
+		UploadedBy:  userID,
+		FileName:    meta["filename"],
+		Kind:        kind,
+		Length:      length,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.uploadExpiry()),
+	}
+	if err := s.UploadRepo.Create(up); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	location := "/api/v1/achievements/" + ref.ID + "/uploads/" + up.ID
+	c.Set(fiber.HeaderLocation, location)
+	c.Set("Upload-Expires", up.ExpiresAt.UTC().Format(http.TimeFormat))
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"id":        up.ID,
+		"url":       location,
+		"expiresAt": up.ExpiresAt,
+	})
+}
+
+// ResumableUploadStatus: HEAD → Upload-Offset terakhir yang diterima.
+func (s *AchievementService) ResumableUploadStatus(c *fiber.Ctx) error {
+	setTusHeaders(c)
+	up, err := s.uploadSession(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(up.Length, 10))
+	c.Set("Upload-Expires", up.ExpiresAt.UTC().Format(http.TimeFormat))
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// PatchResumableUpload menerima potongan mulai Upload-Offset. Potongan
+// terakhir memproses file seperti UploadAttachment dan menjawab metadata
+// lampirannya; sebelum itu 204 dengan Upload-Offset baru.
+func (s *AchievementService) PatchResumableUpload(c *fiber.Ctx) error {
+	setTusHeaders(c)
+	up, err := s.uploadSession(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	if ct, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";"); ct != tusChunkContentType {
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{"error": "Content-Type harus " + tusChunkContentType})
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Upload-Offset wajib diisi"})
+	}
+	if offset != up.Offset {
+		c.Set("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Upload-Offset tidak sesuai", "offset": up.Offset})
+	}
+
+	body := c.Body()
+	if offset+int64(len(body)) > up.Length {
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{"error": "potongan melebihi Upload-Length"})
+	}
+
+	if len(body) > 0 {
+		key := chunkKey(up.ID, offset)
+		store := s.attachmentStore()
+		if err := store.Put(c.Context(), key, bytes.NewReader(body), int64(len(body)), "application/octet-stream"); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		newOffset := offset + int64(len(body))
+		expiresAt := time.Now().Add(s.uploadExpiry())
+		ok, err := s.UploadRepo.AppendChunk(up.ID, offset, newOffset, key, expiresAt)
+		if err != nil || !ok {
+			store.Delete(c.Context(), key)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+			}
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Upload-Offset tidak sesuai"})
+		}
+		up.Offset, up.ExpiresAt = newOffset, expiresAt
+		up.ChunkKeys = append(up.ChunkKeys, key)
+	}
+
+	c.Set("Upload-Offset", strconv.FormatInt(up.Offset, 10))
+	c.Set("Upload-Expires", up.ExpiresAt.UTC().Format(http.TimeFormat))
+	if !up.Complete() {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+	return s.completeResumableUpload(c, up)
+}
+
+// completeResumableUpload memeriksa file hasil upload lalu menyimpannya
+// seperti UploadAttachment. Potongan dibaca berurutan langsung dari storage;
+// hanya gambar yang dimuat ke memori untuk dibuang metadatanya. File yang
+// ditolak validasi membuang sesinya; error storage membiarkan sesi supaya
+// PATCH kosong di offset akhir bisa mencoba lagi.
+func (s *AchievementService) completeResumableUpload(c *fiber.Ctx, up *model.AttachmentUpload) error {
+	ctx := c.Context()
+	ref, err := s.PostgresRepo.GetReferenceByID(up.ReferenceID)
+	if err != nil || ref.Status == "deleted" {
+		s.discardUpload(ctx, up)
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	// prestasi bisa diajukan selama upload berjalan
+	if err := attachmentsUploadable(c, ref); err != nil {
+		s.discardUpload(ctx, up)
+		return respondReviewError(c, err)
+	}
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+	}
+	// kuota dicek ulang: lampiran lain bisa masuk selama upload berjalan
+	if errs := s.checkResumableSize(ctx, ach, up.Length); len(errs) > 0 {
+		s.discardUpload(ctx, up)
+		return respondValidation(c, errs)
+	}
+
+	upload, errs, err := s.checkResumableUpload(ctx, up)
+	if errors.Is(err, errIncompleteUpload) {
+		s.discardUpload(ctx, up)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(errs) > 0 {
+		s.discardUpload(ctx, up)
+		return respondValidation(c, errs)
+	}
+
+	att, err := s.storeAttachment(c, oid, upload)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.queueThumbnails(*att)
+	if err := s.discardUpload(ctx, up); err != nil {
+		log.Printf("resumable upload: gagal membersihkan %s: %v", up.ID, err)
+	}
+
+	response := uploadedResponse(ref, att)
+	if matches := s.indexAttachmentHash(ref, att.SHA256); len(matches) > 0 {
+		response["duplicates"] = duplicateWarnings(ref.StudentID, matches)
+	}
+	return c.JSON(response)
+}
+
+// errIncompleteUpload: isi potongan di storage tidak sama panjang dengan
+// Upload-Length; sesi tidak bisa dipulihkan.
+var errIncompleteUpload = errors.New("potongan upload tidak lengkap, ulangi upload")
+
+// checkResumableUpload: tipe dari awal file (allow-list upload bertahap).
+// Gambar dimuat ke memori dan diproses seperti upload biasa; file lain
+// hanya di-hash sambil dibaca dan disimpan dengan membaca potongannya
+// sekali lagi. err = gagal membaca storage.
+func (s *AchievementService) checkResumableUpload(ctx context.Context, up *model.AttachmentUpload) (*checkedUpload, []FieldError, error) {
+	open := func() (io.ReadCloser, error) {
+		return &chunkReader{ctx: ctx, store: s.attachmentStore(), keys: up.ChunkKeys}, nil
+	}
+
+	rc, _ := open()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(rc, head)
+	rc.Close()
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, nil, err
+	}
+	mimeType, ext, errs := checkUploadType(up.FileName, head[:n], up.Kind, resumableKindTypes)
+	if len(errs) > 0 {
+		return nil, errs, nil
+	}
+	upload := &checkedUpload{
+		Kind:     up.Kind,
+		FileName: sanitizeFileName(up.FileName, ext),
+		Type:     mimeType,
+		Ext:      ext,
+	}
+
+	rc, _ = open()
+	defer rc.Close()
+	if isImageType(mimeType) {
+		if up.Length > maxResumableImageSize {
+			return nil, []FieldError{{"file", "gambar maksimal " + formatMB(maxResumableImageSize)}}, nil
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxResumableImageSize+1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if int64(len(data)) != up.Length {
+			return nil, nil, errIncompleteUpload
+		}
+		if upload.Content, err = s.prepareContent(ctx, data, mimeType); err != nil {
+			return nil, []FieldError{{"file", "gambar tidak dapat diproses (rusak atau dimensi terlalu besar)"}}, nil
+		}
+		return upload, nil, nil
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(h, io.LimitReader(rc, s.resumableMaxSize()+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if size != up.Length {
+		return nil, nil, errIncompleteUpload
+	}
+	upload.Open, upload.SHA256, upload.Size = open, hex.EncodeToString(h.Sum(nil)), size
+	return upload, nil, nil
+}
+
+// DeleteResumableUpload: terminasi tus, potongan yang sudah diterima dibuang.
+func (s *AchievementService) DeleteResumableUpload(c *fiber.Ctx) error {
+	setTusHeaders(c)
+	up, err := s.uploadSession(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	if err := s.discardUpload(c.Context(), up); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExpireResumableUploads membuang sesi yang kedaluwarsa beserta potongannya.
+func (s *AchievementService) ExpireResumableUploads(ctx context.Context, now time.Time) (UploadExpiryResult, error) {
+	result := UploadExpiryResult{Failed: []string{}}
+	if s.UploadRepo == nil {
+		return result, nil
+	}
+	expired, err := s.UploadRepo.ListExpired(now)
+	if err != nil {
+		return result, err
+	}
+	for i := range expired {
+		if err := s.discardUpload(ctx, &expired[i]); err != nil {
+			result.Failed = append(result.Failed, expired[i].ID+": "+err.Error())
+			continue
+		}
+		result.Expired++
+	}
+	return result, nil
+}
+
+// RunUploadExpiry: job scheduler.
+func (s *AchievementService) RunUploadExpiry(ctx context.Context) error {
+	result, err := s.ExpireResumableUploads(ctx, time.Now())
+	if err == nil && (result.Expired > 0 || len(result.Failed) > 0) {
+		log.Printf("resumable upload: %d sesi kedaluwarsa dibuang, %d gagal", result.Expired, len(result.Failed))
+	}
+	return err
+}
+
+// chunkReader membaca potongan upload berurutan; potongan berikutnya baru
+// dibuka setelah potongan sebelumnya habis.
+type chunkReader struct {
+	ctx   context.Context
+	store storage.Storage
+	keys  []string
+	cur   io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			rc, _, err := r.store.Get(r.ctx, r.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			r.cur, r.keys = rc, r.keys[1:]
+		}
+		n, err := r.cur.Read(p)
+		if err == io.EOF {
+			r.cur.Close()
+			r.cur = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.cur == nil {
+		return nil
+	}
+	return r.cur.Close()
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCK =================
+
+type MockUploadSessionRepo struct {
+	uploads map[string]*model.AttachmentUpload
+}
+
+func newMockUploadSessionRepo() *MockUploadSessionRepo {
+	return &MockUploadSessionRepo{uploads: map[string]*model.AttachmentUpload{}}
+}
+
+func (m *MockUploadSessionRepo) Create(u *model.AttachmentUpload) error {
+	cp := *u
+	m.uploads[u.ID] = &cp
+	return nil
+}
+
+func (m *MockUploadSessionRepo) Get(id string) (*model.AttachmentUpload, error) {
+	u, ok := m.uploads[id]
+	if !ok {
+		return nil, errors.New("upload not found")
+	}
+	cp := *u
+	cp.ChunkKeys = append([]string{}, u.ChunkKeys...)
+	return &cp, nil
+}
+
+func (m *MockUploadSessionRepo) AppendChunk(id string, offset, newOffset int64, key string, expiresAt time.Time) (bool, error) {
+	u, ok := m.uploads[id]
+	if !ok || u.Offset != offset {
+		return false, nil
+	}
+	u.Offset, u.ExpiresAt = newOffset, expiresAt
+	u.ChunkKeys = append(u.ChunkKeys, key)
+	return true, nil
+}
+
+func (m *MockUploadSessionRepo) Delete(id string) error {
+	delete(m.uploads, id)
+	return nil
+}
+
+func (m *MockUploadSessionRepo) ListExpired(before time.Time) ([]model.AttachmentUpload, error) {
+	var list []model.AttachmentUpload
+	for _, u := range m.uploads {
+		if u.ExpiresAt.Before(before) {
+			list = append(list, *u)
+		}
+	}
+	return list, nil
+}
+
+type resumableFixture struct {
+	*scanFixture
+	uploads *MockUploadSessionRepo
+	dir     string
+}
+
+func newResumableFixture(t *testing.T) *resumableFixture {
+	f := &resumableFixture{scanFixture: newScanFixture(t, nil), uploads: newMockUploadSessionRepo()}
+	f.dir = f.store.Dir
+	f.svc.UploadRepo = f.uploads
+
+	auth := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", "Mahasiswa")
+			c.Locals("user_id", "user-1")
+			c.Locals("student_id", "student-1")
+			return h(c)
+		}
+	}
+	f.app.Options("/achievements/:refId/uploads", f.svc.ResumableOptions)
+	f.app.Post("/achievements/:refId/uploads", auth(f.svc.CreateResumableUpload))
+	f.app.Head("/achievements/:refId/uploads/:uploadId", auth(f.svc.ResumableUploadStatus))
+	f.app.Patch("/achievements/:refId/uploads/:uploadId", auth(f.svc.PatchResumableUpload))
+	f.app.Delete("/achievements/:refId/uploads/:uploadId", auth(f.svc.DeleteResumableUpload))
+	return f
+}
+
+func tusMetadata(filename, kind string) string {
+	return "filename " + base64.StdEncoding.EncodeToString([]byte(filename)) +
+		",kind " + base64.StdEncoding.EncodeToString([]byte(kind))
+}
+
+// create mengembalikan status dan path sesi (dari Location).
+func (f *resumableFixture) create(length int, metadata string) (int, string) {
+	req := httptest.NewRequest("POST", "/achievements/ref-1/uploads", nil)
+	req.Header.Set("Tus-Resumable", tusVersion)
+	req.Header.Set("Upload-Length", strconv.Itoa(length))
+	req.Header.Set("Upload-Metadata", metadata)
+	resp, _ := f.app.Test(req)
+	return resp.StatusCode, strings.TrimPrefix(resp.Header.Get("Location"), "/api/v1")
+}
+
+func (f *resumableFixture) head(path string) (int, string) {
+	resp, _ := f.app.Test(httptest.NewRequest("HEAD", path, nil))
+	return resp.StatusCode, resp.Header.Get("Upload-Offset")
+}
+
+func (f *resumableFixture) patch(path string, offset int, chunk string) (*http.Response, map[string]interface{}) {
+	req := httptest.NewRequest("PATCH", path, strings.NewReader(chunk))
+	req.Header.Set("Tus-Resumable", tusVersion)
+	req.Header.Set("Content-Type", tusChunkContentType)
+	req.Header.Set("Upload-Offset", strconv.Itoa(offset))
+	resp, _ := f.app.Test(req)
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+// mp4Header: box ftyp dengan brand mp42.
+const mp4Header = "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"
+
+// ================= TESTS =================
+
+func TestResumableUpload_ChunksFeedAttachmentPipeline(t *testing.T) {
+	f := newResumableFixture(t)
+	content := pngBytes
+	half := len(content) / 2
+
+	status, path := f.create(len(content), tusMetadata("medali.png", "photo"))
+	assert.Equal(t, 201, status)
+	assert.True(t, strings.HasPrefix(path, "/achievements/ref-1/uploads/"))
+	path = utils.CopyString(path)
+
+	status, offset := f.head(path)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "0", offset)
+
+	resp, _ := f.patch(path, 0, content[:half])
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, strconv.Itoa(half), resp.Header.Get("Upload-Offset"))
+
+	// koneksi putus → client menanyakan offset lalu melanjutkan
+	_, offset = f.head(path)
+	assert.Equal(t, strconv.Itoa(half), offset)
+
+	resp, _ = f.patch(path, 0, content[:half])
+	assert.Equal(t, 409, resp.StatusCode)
+
+	resp, out := f.patch(path, half, content[half:])
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "photo", out["kind"])
+	assert.Equal(t, "image/png", out["type"])
+
+	assert.Len(t, f.mongo.ach.Attachments, 1)
+	att := f.mongo.ach.Attachments[0]
+	assert.Equal(t, "medali.png", att.FileName)
+	assert.Equal(t, out["id"], att.ID)
+
+	// sesi & potongan dibuang setelah selesai
+	assert.Empty(t, f.uploads.uploads)
+	for _, p := range storedObjects(f.dir) {
+		assert.NotContains(t, p, "partial")
+	}
+	status, _ = f.head(path)
+	assert.Equal(t, 404, status)
+}
+
+func TestResumableUpload_RejectsAtCreation(t *testing.T) {
+	f := newResumableFixture(t)
+	f.svc.ResumableMaxSize = 8 << 20
+
+	status, _ := f.create(8<<20+1, tusMetadata("besar.pdf", "certificate"))
+	assert.Equal(t, 413, status)
+
+	status, _ = f.create(100, tusMetadata("x.pdf", "rahasia"))
+	assert.Equal(t, 400, status)
+
+	status, _ = f.create(100, "kind "+base64.StdEncoding.EncodeToString([]byte("certificate")))
+	assert.Equal(t, 400, status)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/uploads", nil)
+	req.Header.Set("Tus-Resumable", "0.2.2")
+	req.Header.Set("Upload-Length", "10")
+	resp, _ := f.app.Test(req)
+	assert.Equal(t, 412, resp.StatusCode)
+	assert.Empty(t, f.uploads.uploads)
+}
+
+func TestResumableUpload_InvalidFileDiscardsSession(t *testing.T) {
+	f := newResumableFixture(t)
+	content := "PK\x03\x04 arsip"
+
+	_, path := f.create(len(content), tusMetadata("sertifikat.pdf", "certificate"))
+	path = utils.CopyString(path)
+
+	resp, out := f.patch(path, 0, content)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, "validasi gagal", out["error"])
+	assert.Empty(t, f.mongo.ach.Attachments)
+	assert.Empty(t, f.uploads.uploads)
+	assert.Empty(t, storedObjects(f.dir))
+}
+
+func TestResumableUpload_ChunkChecks(t *testing.T) {
+	f := newResumableFixture(t)
+	_, path := f.create(len(pdfBytes), tusMetadata("sertifikat.pdf", "certificate"))
+	path = utils.CopyString(path)
+
+	// potongan melebihi Upload-Length
+	resp, _ := f.patch(path, 0, pdfBytes+"lebih")
+	assert.Equal(t, 413, resp.StatusCode)
+
+	req := httptest.NewRequest("PATCH", path, strings.NewReader(pdfBytes))
+	req.Header.Set("Content-Type", "application/pdf")
+	req.Header.Set("Upload-Offset", "0")
+	resp, _ = f.app.Test(req)
+	assert.Equal(t, 415, resp.StatusCode)
+
+	// terminasi
+	resp, _ = f.app.Test(httptest.NewRequest("DELETE", path, nil))
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Empty(t, f.uploads.uploads)
+}
+
+func TestResumableUpload_ExpiredSessions(t *testing.T) {
+	f := newResumableFixture(t)
+	_, path := f.create(len(pdfBytes), tusMetadata("sertifikat.pdf", "certificate"))
+	path = utils.CopyString(path)
+	f.patch(path, 0, pdfBytes[:5])
+	assert.NotEmpty(t, storedObjects(f.dir))
+
+	// lewat masa berlaku → 410, lalu dibuang job
+	later := time.Now().Add(DefaultUploadExpiry + time.Minute)
+	for _, u := range f.uploads.uploads {
+		u.ExpiresAt = time.Now().Add(-time.Second)
+	}
+	resp, _ := f.patch(path, 5, pdfBytes[5:])
+	assert.Equal(t, 410, resp.StatusCode)
+
+	result, err := f.svc.ExpireResumableUploads(context.Background(), later)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Expired)
+	assert.Empty(t, f.uploads.uploads)
+	assert.Empty(t, storedObjects(f.dir))
+}
+
+func TestResumableOptions(t *testing.T) {
+	f := newResumableFixture(t)
+	resp, _ := f.app.Test(httptest.NewRequest("OPTIONS", "/achievements/ref-1/uploads", nil))
+	assert.Equal(t, 204, resp.StatusCode)
+	assert.Equal(t, tusVersion, resp.Header.Get("Tus-Version"))
+	assert.Equal(t, tusExtensions, resp.Header.Get("Tus-Extension"))
+	assert.Equal(t, strconv.FormatInt(DefaultResumableMaxSize, 10), resp.Header.Get("Tus-Max-Size"))
+}
+
+func TestResumableUpload_LargeVideoStreamsToStorage(t *testing.T) {
+	f := newResumableFixture(t)
+	f.svc.ResumableMaxSize = 16 << 20
+	content := mp4Header + strings.Repeat("v", 6<<20)
+
+	status, path := f.create(len(content), tusMetadata("final.mp4", "other"))
+	assert.Equal(t, 201, status)
+	path = utils.CopyString(path)
+
+	chunk := 2 << 20
+	for offset := 0; offset < len(content); offset += chunk {
+		resp, out := f.patch(path, offset, content[offset:min(offset+chunk, len(content))])
+		if offset+chunk < len(content) {
+			assert.Equal(t, 204, resp.StatusCode)
+			continue
+		}
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, "video/mp4", out["type"])
+		assert.Equal(t, contentHash([]byte(content)), out["sha256"])
+	}
+
+	att := f.mongo.ach.Attachments[0]
+	assert.Equal(t, int64(len(content)), att.Size)
+	assert.Equal(t, contentKey(att.SHA256, ".mp4"), att.StorageKey)
+	assert.Contains(t, storedObjects(f.dir), filepath.Join(f.dir, att.StorageKey))
+	for _, p := range storedObjects(f.dir) {
+		assert.NotContains(t, p, "partial")
+	}
+
+	// kuota lampiran besar terpisah dari kuota lampiran biasa
+	status, _ = f.create(12<<20, tusMetadata("lagi.mp4", "other"))
+	assert.Equal(t, 400, status)
+	status, _ = f.create(len(pdfBytes), tusMetadata("sertifikat.pdf", "certificate"))
+	assert.Equal(t, 201, status)
+}
+
+func TestResumableUpload_VideoNotAllowedForCertificate(t *testing.T) {
+	f := newResumableFixture(t)
+	content := mp4Header + "isi video"
+
+	_, path := f.create(len(content), tusMetadata("sertifikat.mp4", "certificate"))
+	resp, _ := f.patch(utils.CopyString(path), 0, content)
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Empty(t, f.mongo.ach.Attachments)
+	assert.Empty(t, storedObjects(f.dir))
+}
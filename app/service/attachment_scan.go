@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// quarantinePrefix: file terinfeksi dipindah ke bawah prefix ini di backend
+// storage yang sama, terpisah dari lampiran yang bisa diunduh.
+const (
+	quarantinePrefix  = "quarantine/"
+	scanBatchSize     = 50
+	DefaultScanPeriod = 15 * time.Second
+)
+
+// ScanIntervalFromEnv: ATTACHMENT_SCAN_INTERVAL_SECONDS (default 15).
+func ScanIntervalFromEnv() time.Duration {
+	return envDuration("ATTACHMENT_SCAN_INTERVAL_SECONDS", time.Second, DefaultScanPeriod)
+}
+
+type AttachmentScanResult struct {
+	Scanned  int      `json:"scanned"`
+	Clean    int      `json:"clean"`
+	Infected int      `json:"infected"`
+	Failed   []string `json:"failed"`
+}
+
+// initialScanStatus: tanpa scanner lampiran langsung bisa dipakai.
+func (s *AchievementService) initialScanStatus() string {
+	if s.Scanner == nil {
+		return ""
+	}
+	return model.AttachmentPendingScan
+}
+
+// respondNotDownloadable: 423 untuk lampiran yang belum aman dibuka.
+func respondNotDownloadable(c *fiber.Ctx, status string) error {
+	msg := "lampiran masih menunggu pemindaian malware"
+	if status == model.AttachmentInfected {
+		msg = "lampiran terinfeksi malware dan dikarantina"
+	}
+	return c.Status(fiber.StatusLocked).JSON(fiber.Map{"error": msg, "scanStatus": status})
+}
+
+// scanErrors: lampiran aktif yang menghalangi pengajuan.
+func scanErrors(a *model.AchievementMongo) []FieldError {
+	var errs []FieldError
+	for i, att := range a.Attachments {
+		if att.DeletedAt != nil {
+			continue
+		}
+		field := "attachments." + attachmentID(att, i)
+		switch att.ScanStatus {
+		case model.AttachmentPendingScan:
+			errs = append(errs, FieldError{field, "menunggu pemindaian malware"})
+		case model.AttachmentInfected:
+			errs = append(errs, FieldError{field, "terinfeksi malware (" + att.ScanSignature + "), hapus atau ganti lampiran"})
+		}
+	}
+	return errs
+}
+
+// ScanPendingAttachments memindai lampiran pending_scan. File bersih →
+// clean; terinfeksi → dipindah ke karantina lalu infected. Error scanner
+// membiarkan lampiran tetap pending untuk dicoba lagi.
+func (s *AchievementService) ScanPendingAttachments(ctx context.Context, now time.Time) (AttachmentScanResult, error) {
+	result := AttachmentScanResult{Failed: []string{}}
+	if s.Scanner == nil {
+		return result, nil
+	}
+
+	achievements, err := s.MongoRepo.ListPendingScan(scanBatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	store := s.attachmentStore()
+	// isi yang sama (key yang sama) cukup dipindai sekali per run
+	seen := map[string]bool{}
+	for _, ach := range achievements {
+		for _, att := range ach.Attachments {
+			if att.ScanStatus != model.AttachmentPendingScan || att.StorageKey == "" || seen[att.StorageKey] {
+				continue
+			}
+			key := att.StorageKey
+			seen[key] = true
+
+			rc, _, err := store.Get(ctx, key)
+			if err != nil {
+				result.Failed = append(result.Failed, key+": "+err.Error())
+				continue
+			}
+			verdict, err := s.Scanner.Scan(ctx, rc)
+			rc.Close()
+			if err != nil {
+				result.Failed = append(result.Failed, key+": "+err.Error())
+				continue
+			}
+			result.Scanned++
+
+			if !verdict.Infected {
+				if _, err := s.MongoRepo.SetAttachmentScan(key, model.AttachmentClean, "", "", now); err != nil {
+					result.Failed = append(result.Failed, key+": "+err.Error())
+					continue
+				}
+				result.Clean++
+				continue
+			}
+
+			found, err := s.quarantine(ctx, key, verdict.Signature, now)
+			if err != nil {
+				result.Failed = append(result.Failed, key+": "+err.Error())
+				continue
+			}
+			if found {
+				result.Infected++
+				log.Printf("scan: %s terinfeksi %s, dikarantina", key, verdict.Signature)
+			}
+		}
+	}
+	return result, nil
+}
+
+// quarantine: salin ke karantina, tandai infected semua lampiran yang
+// memakai objek ini (metadata menunjuk ke salinan karantina), baru hapus
+// file asli. false = lampiran sudah diganti sebelum hasil pemindaian disimpan.
+func (s *AchievementService) quarantine(ctx context.Context, key, signature string, now time.Time) (bool, error) {
+	store := s.attachmentStore()
+	rc, info, err := store.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	qkey := quarantinePrefix + key
+	err = store.Put(ctx, qkey, rc, info.Size, "application/octet-stream")
+	rc.Close()
+	if err != nil {
+		return false, err
+	}
+
+	found, err := s.MongoRepo.SetAttachmentScan(key, model.AttachmentInfected, signature, qkey, now)
+	if err != nil || !found {
+		store.Delete(ctx, qkey)
+		return false, err
+	}
+	return true, store.Delete(ctx, key)
+}
+
+// RunAttachmentScan: job scheduler.
+func (s *AchievementService) RunAttachmentScan(ctx context.Context) error {
+	result, err := s.ScanPendingAttachments(ctx, time.Now())
+	if err == nil && len(result.Failed) > 0 {
+		log.Printf("scan: %d lampiran gagal dipindai, dicoba lagi nanti", len(result.Failed))
+	}
+	return err
+}
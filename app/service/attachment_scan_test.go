@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/scanner"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK =================
+
+type MockScanner struct {
+	err error
+}
+
+func (m *MockScanner) Scan(ctx context.Context, r io.Reader) (scanner.Result, error) {
+	if m.err != nil {
+		return scanner.Result{}, m.err
+	}
+	data, _ := io.ReadAll(r)
+	if strings.Contains(string(data), "EICAR") {
+		return scanner.Result{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return scanner.Result{}, nil
+}
+
+type MockScanMongoRepo struct {
+	MockAchievementMongoRepo
+	ach model.AchievementMongo
+}
+
+func (m *MockScanMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	ach := m.ach
+	ach.Attachments = append([]model.Attachment{}, m.ach.Attachments...)
+	return &ach, nil
+}
+
+func (m *MockScanMongoRepo) AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error {
+	m.ach.Attachments = append(m.ach.Attachments, a)
+	return nil
+}
+
+func (m *MockScanMongoRepo) SetAttachmentMongo(id primitive.ObjectID, index int, a model.Attachment) error {
+	m.ach.Attachments[index] = a
+	return nil
+}
+
+func (m *MockScanMongoRepo) ListPendingScan(limit int) ([]model.AchievementMongo, error) {
+	for _, a := range m.ach.Attachments {
+		if a.ScanStatus == model.AttachmentPendingScan {
+			return []model.AchievementMongo{m.ach}, nil
+		}
+	}
+	return []model.AchievementMongo{}, nil
+}
+
+func (m *MockScanMongoRepo) SetAttachmentScan(storageKey string, status, signature, newKey string, at time.Time) (bool, error) {
+	found := false
+	for i, a := range m.ach.Attachments {
+		if a.StorageKey != storageKey {
+			continue
+		}
+		a.ScanStatus, a.ScanSignature, a.ScannedAt = status, signature, &at
+		if newKey != "" {
+			a.StorageKey = newKey
+		}
+		m.ach.Attachments[i] = a
+		found = true
+	}
+	return found, nil
+}
+
+type scanFixture struct {
+	app   *fiber.App
+	svc   *AchievementService
+	mongo *MockScanMongoRepo
+	store *storage.Local
+}
+
+func newScanFixture(t *testing.T, sc scanner.Scanner) *scanFixture {
+	f := &scanFixture{
+		mongo: &MockScanMongoRepo{ach: model.AchievementMongo{ID: primitive.NewObjectID()}},
+		store: storage.NewLocal(t.TempDir()),
+	}
+	f.svc = &AchievementService{
+		MongoRepo:    f.mongo,
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		Storage:      f.store,
+		Scanner:      sc,
+	}
+	f.app = fiber.New()
+	auth := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", "Mahasiswa")
+			c.Locals("user_id", "user-1")
+			c.Locals("student_id", "student-1")
+			return h(c)
+		}
+	}
+	f.app.Post("/achievements/:refId/attachments", auth(f.svc.UploadAttachment))
+	f.app.Get("/achievements/:refId/attachments/:attachmentId", auth(f.svc.DownloadAttachment))
+	return f
+}
+
+func (f *scanFixture) download(id string) (int, map[string]interface{}) {
+	resp, _ := f.app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments/"+id, nil))
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+// ================= TESTS =================
+
+func TestScan_CleanFileBecomesDownloadable(t *testing.T) {
+	f := newScanFixture(t, &MockScanner{})
+
+	status, out := uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, model.AttachmentPendingScan, out["scanStatus"])
+	id := f.mongo.ach.Attachments[0].ID
+
+	status, out = f.download(id)
+	assert.Equal(t, 423, status)
+	assert.Equal(t, model.AttachmentPendingScan, out["scanStatus"])
+
+	result, err := f.svc.ScanPendingAttachments(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Scanned)
+	assert.Equal(t, 1, result.Clean)
+	assert.Equal(t, model.AttachmentClean, f.mongo.ach.Attachments[0].ScanStatus)
+	assert.NotNil(t, f.mongo.ach.Attachments[0].ScannedAt)
+
+	status, _ = f.download(id)
+	assert.Equal(t, 200, status)
+}
+
+func TestScan_InfectedFileIsQuarantined(t *testing.T) {
+	f := newScanFixture(t, &MockScanner{})
+	uploadRequest(f.app, "sertifikat.pdf", pdfBytes+" EICAR")
+	original := f.mongo.ach.Attachments[0].StorageKey
+
+	result, _ := f.svc.ScanPendingAttachments(context.Background(), time.Now())
+	assert.Equal(t, 1, result.Infected)
+
+	att := f.mongo.ach.Attachments[0]
+	assert.Equal(t, model.AttachmentInfected, att.ScanStatus)
+	assert.Equal(t, "Eicar-Test-Signature", att.ScanSignature)
+	assert.Equal(t, "quarantine/"+original, att.StorageKey)
+
+	_, err := f.store.Stat(context.Background(), original)
+	assert.Equal(t, storage.ErrNotFound, err)
+	_, err = f.store.Stat(context.Background(), att.StorageKey)
+	assert.NoError(t, err)
+
+	status, out := f.download(att.ID)
+	assert.Equal(t, 423, status)
+	assert.Equal(t, "lampiran terinfeksi malware dan dikarantina", out["error"])
+}
+
+func TestScan_ScannerErrorKeepsPending(t *testing.T) {
+	sc := &MockScanner{err: errors.New("clamd: connection refused")}
+	f := newScanFixture(t, sc)
+	uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+
+	result, err := f.svc.ScanPendingAttachments(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Scanned)
+	assert.Len(t, result.Failed, 1)
+	assert.Equal(t, model.AttachmentPendingScan, f.mongo.ach.Attachments[0].ScanStatus)
+
+	// scanner pulih → dicoba lagi pada run berikutnya
+	sc.err = nil
+	result, _ = f.svc.ScanPendingAttachments(context.Background(), time.Now())
+	assert.Equal(t, 1, result.Clean)
+}
+
+func TestScan_DisabledScannerLeavesAttachmentsUsable(t *testing.T) {
+	f := newScanFixture(t, nil)
+
+	status, out := uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+	assert.Equal(t, 200, status)
+	assert.Nil(t, out["scanStatus"])
+
+	status, _ = f.download(f.mongo.ach.Attachments[0].ID)
+	assert.Equal(t, 200, status)
+}
+
+func TestScanErrors_BlockSubmission(t *testing.T) {
+	deleted := time.Now()
+	ach := &model.AchievementMongo{Attachments: []model.Attachment{
+		{ID: "a", Kind: "certificate", ScanStatus: model.AttachmentClean},
+		{ID: "b", Kind: "photo", ScanStatus: model.AttachmentPendingScan},
+		{ID: "c", Kind: "other", ScanStatus: model.AttachmentInfected, ScanSignature: "Eicar-Test-Signature"},
+		{ID: "d", Kind: "other", ScanStatus: model.AttachmentInfected, DeletedAt: &deleted},
+		{ID: "e", Kind: "other"},
+	}}
+
+	assert.Equal(t, []FieldError{
+		{"attachments.b", "menunggu pemindaian malware"},
+		{"attachments.c", "terinfeksi malware (Eicar-Test-Signature), hapus atau ganti lampiran"},
+	}, scanErrors(ach))
+
+	unmet := evaluateRequirement(&model.SubmissionRequirement{}, ach)
+	assert.Contains(t, unmet, FieldError{"attachments.b", "menunggu pemindaian malware"})
+}
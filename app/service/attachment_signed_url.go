@@ -0,0 +1,121 @@
+package service
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultAttachmentLinkTTL = 15 * time.Minute
+	maxAttachmentLinkTTL     = 24 * time.Hour
+
+	// attachmentLinkPrefix membedakan token lampiran dari token tautan lain
+	// yang ditandatangani secret yang sama (verifikasi penyelenggara).
+	attachmentLinkPrefix = "attachment:"
+)
+
+func attachmentLinkID(refID, attachmentID string) string {
+	return attachmentLinkPrefix + refID + ":" + attachmentID
+}
+
+// parseAttachmentLinkID kebalikan attachmentLinkID.
+func parseAttachmentLinkID(id string) (refID, attachmentID string, ok bool) {
+	rest, found := strings.CutPrefix(id, attachmentLinkPrefix)
+	if !found {
+		return "", "", false
+	}
+	refID, attachmentID, ok = strings.Cut(rest, ":")
+	return refID, attachmentID, ok && refID != "" && attachmentID != ""
+}
+
+// ======================================================
+// TAUTAN LAMPIRAN BERTANDA TANGAN (untuk <img>/<iframe> tanpa header JWT)
+// ======================================================
+
+// SignAttachmentURL menerbitkan URL publik berbatas waktu untuk satu
+// lampiran. Aturan akses sama dengan unduhan biasa; URL tampil inline
+// (hapus ?inline=true untuk mengunduh).
+func (s *AchievementService) SignAttachmentURL(c *fiber.Ctx) error {
+	if s.Signer == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "tautan lampiran tidak tersedia"})
+	}
+
+	var body struct {
+		ExpiresInMinutes int `json:"expires_in_minutes"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+		}
+	}
+	ttl, ok := leaseFrom(body.ExpiresInMinutes, defaultAttachmentLinkTTL, maxAttachmentLinkTTL)
+	if !ok {
+		return respondValidation(c, []FieldError{{"expires_in_minutes", "harus 1-" + strconv.Itoa(int(maxAttachmentLinkTTL.Minutes()))}})
+	}
+
+	ref, ach, err := s.attachmentOwner(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	id := c.Params("attachmentId")
+	att, found := findAttachment(ach, id)
+	if !found || att.DeletedAt != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+	if !att.Downloadable() {
+		return respondNotDownloadable(c, att.ScanStatus)
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	token := s.Signer.Sign(attachmentLinkID(ref.ID, id), expiresAt)
+	return c.JSON(fiber.Map{
+		"url":        "/api/v1/public/attachments/" + url.PathEscape(token) + "?inline=true",
+		"expires_at": expiresAt,
+	})
+}
+
+// SignedAttachment: unduhan publik lewat token. Token hanya membuktikan
+// otorisasi saat diterbitkan; lampiran yang sudah dihapus, prestasi yang
+// dihapus, atau lampiran yang dikarantina tetap ditolak.
+func (s *AchievementService) SignedAttachment(c *fiber.Ctx) error {
+	if s.Signer == nil {
+		return c.Status(404).JSON(fiber.Map{"error": errInvalidLink.Error()})
+	}
+	linkID, err := s.Signer.Verify(c.Params("token"), time.Now())
+	if err == errExpiredLink {
+		return c.Status(410).JSON(fiber.Map{"error": err.Error()})
+	}
+	refID, attID, ok := parseAttachmentLinkID(linkID)
+	if err != nil || !ok {
+		return c.Status(404).JSON(fiber.Map{"error": errInvalidLink.Error()})
+	}
+
+	ref, err := s.PostgresRepo.GetReferenceByID(refID)
+	if err != nil || ref.Status == "deleted" {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+	att, found := findAttachment(ach, attID)
+	if !found || att.DeletedAt != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+	if !att.Downloadable() {
+		return respondNotDownloadable(c, att.ScanStatus)
+	}
+
+	// token adalah kredensial: jangan disimpan cache bersama dan jangan
+	// diteruskan lewat Referer ke situs lain
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set("Referrer-Policy", "no-referrer")
+	store, key := s.attachmentSource(att)
+	return streamAttachment(c, store, key, att.FileName, att.FileType)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func signedURLFixture(t *testing.T) *scanFixture {
+	f := newScanFixture(t, nil)
+	f.svc.Signer = &LinkSigner{Secret: []byte("rahasia-test")}
+	f.app.Post("/achievements/:refId/attachments/:attachmentId/signed-url", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("user_id", "user-1")
+		c.Locals("student_id", "student-1")
+		return f.svc.SignAttachmentURL(c)
+	})
+	// tanpa locals JWT, sama seperti route publik
+	f.app.Get("/api/v1/public/attachments/:token", f.svc.SignedAttachment)
+	return f
+}
+
+func (f *scanFixture) signURL(id, body string) (int, map[string]interface{}) {
+	req := httptest.NewRequest("POST", "/achievements/ref-1/attachments/"+id+"/signed-url", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := f.app.Test(req)
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+func TestSignedAttachmentURL_ServesWithoutJWT(t *testing.T) {
+	f := signedURLFixture(t)
+	uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+	id := f.mongo.ach.Attachments[0].ID
+
+	status, out := f.signURL(id, `{"expires_in_minutes": 30}`)
+	assert.Equal(t, 200, status)
+	link := out["url"].(string)
+	assert.True(t, strings.HasPrefix(link, "/api/v1/public/attachments/"))
+	expiresAt, err := time.Parse(time.RFC3339, out["expires_at"].(string))
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), expiresAt, 2*time.Second)
+
+	resp, _ := f.app.Test(httptest.NewRequest("GET", link, nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Disposition"), "inline"))
+	assert.Equal(t, "private, no-store", resp.Header.Get("Cache-Control"))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, pdfBytes, string(body))
+}
+
+func TestSignedAttachmentURL_RejectsBadTokens(t *testing.T) {
+	f := signedURLFixture(t)
+	uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+	id := f.mongo.ach.Attachments[0].ID
+	get := func(token string) int {
+		resp, _ := f.app.Test(httptest.NewRequest("GET", "/api/v1/public/attachments/"+token, nil))
+		return resp.StatusCode
+	}
+
+	valid := f.svc.Signer.Sign(attachmentLinkID("ref-1", id), time.Now().Add(time.Minute))
+	assert.Equal(t, 200, get(valid))
+
+	// tanda tangan diubah
+	assert.Equal(t, 404, get(valid[:len(valid)-2]+"AA"))
+	// secret lain
+	other := LinkSigner{Secret: []byte("lain")}
+	assert.Equal(t, 404, get(other.Sign(attachmentLinkID("ref-1", id), time.Now().Add(time.Minute))))
+	// kedaluwarsa
+	assert.Equal(t, 410, get(f.svc.Signer.Sign(attachmentLinkID("ref-1", id), time.Now().Add(-time.Second))))
+	// token dengan tujuan lain (mis. verifikasi penyelenggara) tidak berlaku
+	assert.Equal(t, 404, get(f.svc.Signer.Sign("ref-1", time.Now().Add(time.Minute))))
+
+	// lampiran dihapus setelah tautan diterbitkan
+	now := time.Now()
+	f.mongo.ach.Attachments[0].DeletedAt = &now
+	assert.Equal(t, 404, get(valid))
+}
+
+func TestSignedAttachmentURL_IssueChecks(t *testing.T) {
+	f := signedURLFixture(t)
+	uploadRequest(f.app, "sertifikat.pdf", pdfBytes)
+	id := f.mongo.ach.Attachments[0].ID
+
+	status, out := f.signURL(id, `{"expires_in_minutes": 2000}`)
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "validasi gagal", out["error"])
+
+	status, _ = f.signURL("tidak-ada", "")
+	assert.Equal(t, 404, status)
+
+	// lampiran yang belum lolos scan tidak bisa dibagikan
+	f.mongo.ach.Attachments[0].ScanStatus = model.AttachmentPendingScan
+	status, _ = f.signURL(id, "")
+	assert.Equal(t, 423, status)
+
+	f.svc.Signer = nil
+	status, _ = f.signURL(id, "")
+	assert.Equal(t, 404, status)
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultAttachmentDir: backend bawaan bila Storage tidak diisi.
+const defaultAttachmentDir = "uploads"
+
+// attachmentStore: Storage boleh nil → file disimpan di ./uploads.
+func (s *AchievementService) attachmentStore() storage.Storage {
+	if s.Storage == nil {
+		return storage.NewLocal(defaultAttachmentDir)
+	}
+	return s.Storage
+}
+
+// contentKey: "sha256/<2 hex pertama>/<hash><ext>". Isi yang sama selalu
+// mendapat key yang sama sehingga hanya disimpan sekali. Nama file kiriman
+// client tidak pernah dipakai sebagai nama objek.
+func contentKey(sum, ext string) string {
+	return "sha256/" + sum[:2] + "/" + sum + ext
+}
+
+// contentHash: SHA-256 (hex) isi file.
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// putAttachmentObject menyimpan isi file ke storage dan mengembalikan
+// metadata lampirannya (belum disimpan ke Mongo). created = false bila isi
+// yang sama sudah ada di storage (objeknya dipakai bersama, jangan dihapus
+// saat rollback).
+func (s *AchievementService) putAttachmentObject(c *fiber.Ctx, up *checkedUpload) (att *model.Attachment, created bool, err error) {
+	hash, size := up.SHA256, up.Size
+	if up.Open == nil {
+		hash, size = contentHash(up.Content), int64(len(up.Content))
+	}
+
+	att = &model.Attachment{
+		ID:         uuid.New().String(),
+		FileName:   up.FileName,
+		StorageKey: contentKey(hash, attachmentTypes[up.Type][0]),
+		FileType:   up.Type,
+		Size:       size,
+		Kind:       up.Kind,
+		UploadedAt: time.Now(),
+		ScanStatus: s.initialScanStatus(),
+		SHA256:     hash,
+	}
+
+	if info, err := s.attachmentStore().Stat(c.Context(), att.StorageKey); err == nil && info.Size == size {
+		return att, false, nil
+	}
+	if err := s.writeAttachmentObject(c, up, att); err != nil {
+		return nil, false, err
+	}
+	return att, true, nil
+}
+
+// writeAttachmentObject menulis isi upload ke att.StorageKey.
+func (s *AchievementService) writeAttachmentObject(c *fiber.Ctx, up *checkedUpload, att *model.Attachment) error {
+	store := s.attachmentStore()
+	if up.Open == nil {
+		return store.Put(c.Context(), att.StorageKey, bytes.NewReader(up.Content), att.Size, att.FileType)
+	}
+
+	// isi dibaca ulang dari sumbernya; hash dicek lagi supaya key
+	// content-addressed tidak pernah berisi isi lain
+	rc, err := up.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if err := store.Put(c.Context(), att.StorageKey, io.TeeReader(rc, h), att.Size, att.FileType); err != nil {
+		return err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != att.SHA256 {
+		store.Delete(c.Context(), att.StorageKey)
+		return errors.New("isi upload berubah saat disimpan")
+	}
+	return nil
+}
+
+// ensureAttachmentObject: lampiran yang memakai objek bersama (created =
+// false) dicek lagi setelah metadatanya tersimpan. Purge yang menghapus
+// objek itu tepat sebelumnya tidak melihat metadata baru, jadi isinya
+// ditulis ulang.
+func (s *AchievementService) ensureAttachmentObject(c *fiber.Ctx, up *checkedUpload, att *model.Attachment) {
+	_, err := s.attachmentStore().Stat(c.Context(), att.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		err = s.writeAttachmentObject(c, up, att)
+	}
+	if err != nil {
+		log.Printf("lampiran %s: gagal memastikan objek %s: %v", att.ID, att.StorageKey, err)
+	}
+}
+
+// storeAttachment menyimpan isi file ke storage lalu metadata ke Mongo.
+// Bila metadata gagal disimpan, objek yang baru dibuat dihapus lagi.
+func (s *AchievementService) storeAttachment(c *fiber.Ctx, mongoID primitive.ObjectID, up *checkedUpload) (*model.Attachment, error) {
+	att, created, err := s.putAttachmentObject(c, up)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.MongoRepo.AddAttachmentMongo(mongoID, *att); err != nil {
+		if created {
+			s.attachmentStore().Delete(c.Context(), att.StorageKey)
+		}
+		return nil, err
+	}
+	if !created {
+		s.ensureAttachmentObject(c, up, att)
+	}
+	return att, nil
+}
+
+// uploadedResponse: jawaban upload lampiran (biasa maupun bertahap).
+func uploadedResponse(ref *model.AchievementReference, att *model.Attachment) fiber.Map {
+	response := fiber.Map{
+		"message": "Attachment uploaded",
+		"id":      att.ID,
+		"key":     att.StorageKey,
+		"url":     "/api/v1/achievements/" + ref.ID + "/attachments/" + att.ID,
+		"kind":    att.Kind,
+		"type":    att.FileType,
+		"size":    att.Size,
+		"sha256":  att.SHA256,
+	}
+	if thumb := thumbnailURL(ref.ID, *att, -1); thumb != "" {
+		response["thumbnailUrl"] = thumb
+	}
+	if att.ScanStatus != "" {
+		response["scanStatus"] = att.ScanStatus
+	}
+	return response
+}
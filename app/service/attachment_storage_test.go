@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK =================
+
+type MockUploadMongoRepo struct {
+	MockAchievementMongoRepo
+	added []model.Attachment
+	fail  bool
+}
+
+func (m *MockUploadMongoRepo) AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error {
+	if m.fail {
+		return errors.New("mongo down")
+	}
+	m.added = append(m.added, a)
+	return nil
+}
+
+func uploadApp(svc *AchievementService) *fiber.App {
+	app := fiber.New()
+	app.Post("/achievements/:refId/attachments", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("user_id", "user-1")
+		c.Locals("student_id", "student-1")
+		return svc.UploadAttachment(c)
+	})
+	return app
+}
+
+func uploadRequest(app *fiber.App, filename, content string) (int, map[string]interface{}) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("kind", "certificate")
+	part, _ := w.CreateFormFile("file", filename)
+	io.WriteString(part, content)
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/attachments", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, _ := app.Test(req)
+
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	return resp.StatusCode, out
+}
+
+// ================= TESTS =================
+
+func TestContentKey(t *testing.T) {
+	sum := sha256Hex("%PDF-1.4 isi")
+
+	key := contentKey(sum, ".pdf")
+	assert.Equal(t, "sha256/"+sum[:2]+"/"+sum+".pdf", key)
+	assert.True(t, storage.ValidKey(key))
+}
+
+func TestUploadAttachment_StoresObjectAndKey(t *testing.T) {
+	mongo := &MockUploadMongoRepo{}
+	store := storage.NewLocal(t.TempDir())
+	app := uploadApp(&AchievementService{
+		MongoRepo:    mongo,
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		Storage:      store,
+	})
+
+	status, out := uploadRequest(app, "sertifikat.pdf", "%PDF-1.4 isi")
+	assert.Equal(t, 200, status)
+
+	key, _ := out["key"].(string)
+	assert.NotEmpty(t, key)
+	assert.Len(t, mongo.added, 1)
+	assert.Equal(t, key, mongo.added[0].StorageKey)
+	assert.Equal(t, contentKey(sha256Hex("%PDF-1.4 isi"), ".pdf"), key)
+	assert.Equal(t, sha256Hex("%PDF-1.4 isi"), mongo.added[0].SHA256)
+	assert.Equal(t, mongo.added[0].SHA256, out["sha256"])
+	assert.Equal(t, "application/pdf", mongo.added[0].FileType)
+	assert.Equal(t, int64(len("%PDF-1.4 isi")), mongo.added[0].Size)
+	assert.Empty(t, mongo.added[0].FileURL)
+	assert.Equal(t, "certificate", mongo.added[0].Kind)
+	assert.Equal(t, "/api/v1/achievements/ref-1/attachments/"+mongo.added[0].ID, out["url"])
+
+	rc, _, err := store.Get(context.Background(), key)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "%PDF-1.4 isi", string(data))
+}
+
+func TestUploadAttachment_MetadataFailureRemovesObject(t *testing.T) {
+	dir := t.TempDir()
+	app := uploadApp(&AchievementService{
+		MongoRepo:    &MockUploadMongoRepo{fail: true},
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		Storage:      storage.NewLocal(dir),
+	})
+
+	status, _ := uploadRequest(app, "sertifikat.pdf", "%PDF-1.4 isi")
+	assert.Equal(t, 500, status)
+
+	var leftover []string
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			leftover = append(leftover, p)
+		}
+		return nil
+	})
+	assert.Empty(t, leftover)
+}
+
+// purgingUploadMongoRepo: purge menghapus objek bersama tepat sebelum
+// metadata lampiran baru tersimpan.
+type purgingUploadMongoRepo struct {
+	*MockUploadMongoRepo
+	store *storage.Local
+}
+
+func (m *purgingUploadMongoRepo) AddAttachmentMongo(id primitive.ObjectID, a model.Attachment) error {
+	m.store.Delete(context.Background(), a.StorageKey)
+	return m.MockUploadMongoRepo.AddAttachmentMongo(id, a)
+}
+
+func TestUploadAttachment_RewritesSharedObjectPurgedMeanwhile(t *testing.T) {
+	store := storage.NewLocal(t.TempDir())
+	key := contentKey(sha256Hex("%PDF-1.4 isi"), ".pdf")
+	store.Put(context.Background(), key, strings.NewReader("%PDF-1.4 isi"), 12, "application/pdf")
+	mongo := &MockUploadMongoRepo{}
+	app := uploadApp(&AchievementService{
+		MongoRepo:    &purgingUploadMongoRepo{mongo, store},
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		Storage:      store,
+	})
+
+	status, out := uploadRequest(app, "sertifikat.pdf", "%PDF-1.4 isi")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, key, out["key"])
+	info, err := store.Stat(context.Background(), key)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), info.Size)
+}
+
+type MockMigrationMongoRepo struct {
+	MockAchievementMongoRepo
+	achievements []model.AchievementMongo
+}
+
+func (m *MockMigrationMongoRepo) ListWithAttachments() ([]model.AchievementMongo, error) {
+	return m.achievements, nil
+}
+
+func (m *MockMigrationMongoRepo) SetAttachmentStorageKey(id primitive.ObjectID, index int, key string) error {
+	for i := range m.achievements {
+		if m.achievements[i].ID == id {
+			m.achievements[i].Attachments[index].StorageKey = key
+			m.achievements[i].Attachments[index].FileURL = ""
+		}
+	}
+	return nil
+}
+
+func TestMigrateUploads(t *testing.T) {
+	root := t.TempDir()
+	id := primitive.NewObjectID()
+	legacy := "uploads/" + id.Hex() + "/sertifikat.pdf"
+	os.MkdirAll(filepath.Join(root, "uploads", id.Hex()), os.ModePerm)
+	os.WriteFile(filepath.Join(root, filepath.FromSlash(legacy)), []byte("%PDF-1.4 lama"), 0o644)
+
+	repo := &MockMigrationMongoRepo{achievements: []model.AchievementMongo{{
+		ID: id,
+		Attachments: []model.Attachment{
+			{FileName: "sertifikat.pdf", FileURL: legacy, FileType: "application/pdf"},
+			{FileName: "hilang.pdf", FileURL: "uploads/" + id.Hex() + "/hilang.pdf"},
+			{FileName: "baru.pdf", StorageKey: id.Hex() + "/abcd1234-baru.pdf"},
+		},
+	}}}
+	store := storage.NewLocal(t.TempDir())
+	ctx := context.Background()
+
+	// dry run: tidak ada yang berubah
+	report, err := MigrateUploads(ctx, repo, store, UploadMigrationOptions{Root: root, DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Migrated)
+	assert.Empty(t, repo.achievements[0].Attachments[0].StorageKey)
+	_, err = store.Stat(ctx, id.Hex()+"/sertifikat.pdf")
+	assert.Equal(t, storage.ErrNotFound, err)
+
+	report, err = MigrateUploads(ctx, repo, store, UploadMigrationOptions{Root: root, DeleteLocal: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Migrated)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []string{"uploads/" + id.Hex() + "/hilang.pdf"}, report.Missing)
+	assert.Empty(t, report.Failed)
+
+	att := repo.achievements[0].Attachments[0]
+	assert.Equal(t, id.Hex()+"/sertifikat.pdf", att.StorageKey)
+	assert.Empty(t, att.FileURL)
+
+	rc, _, err := store.Get(ctx, att.StorageKey)
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	assert.Equal(t, "%PDF-1.4 lama", string(data))
+
+	_, err = os.Stat(filepath.Join(root, filepath.FromSlash(legacy)))
+	assert.True(t, os.IsNotExist(err))
+
+	// dijalankan ulang: sudah punya key → dilewati
+	report, err = MigrateUploads(ctx, repo, store, UploadMigrationOptions{Root: root})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Migrated)
+	assert.Equal(t, 2, report.Skipped)
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"prestasi_api/app/model"
+)
+
+// Batas lampiran: per file dan total per prestasi. BodyLimit fiber di
+// main.go sedikit di atas maxAttachmentSize supaya file terlalu besar
+// mendapat pesan validasi, bukan 413 mentah.
+const (
+	maxAttachmentSize          = 5 << 20
+	maxAchievementAttachments  = 20 << 20
+	maxAttachmentFileNameRunes = 150
+)
+
+// attachmentTypes: MIME hasil sniffing yang dikenal → ekstensi yang sah.
+// Tipe yang boleh dipakai ditentukan allow-list per jenis di bawah.
+var attachmentTypes = map[string][]string{
+	"application/pdf": {".pdf"},
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/png":       {".png"},
+	"image/tiff":      {".tif", ".tiff"},
+	"video/mp4":       {".mp4", ".m4v"},
+	"video/quicktime": {".mov"},
+	"video/webm":      {".webm"},
+}
+
+// attachmentKindTypes: allow-list per jenis lampiran.
+var attachmentKindTypes = map[string][]string{
+	"certificate":       {"application/pdf", "image/jpeg", "image/png"},
+	"photo":             {"image/jpeg", "image/png"},
+	"assignment_letter": {"application/pdf", "image/jpeg", "image/png"},
+	"other":             {"application/pdf", "image/jpeg", "image/png"},
+}
+
+// resumableKindTypes: allow-list upload bertahap, ditambah scan TIFF dan
+// video dokumentasi yang terlalu besar untuk upload biasa.
+var resumableKindTypes = map[string][]string{
+	"certificate":       {"application/pdf", "image/jpeg", "image/png", "image/tiff"},
+	"photo":             {"image/jpeg", "image/png", "image/tiff"},
+	"assignment_letter": {"application/pdf", "image/jpeg", "image/png", "image/tiff"},
+	"other":             {"application/pdf", "image/jpeg", "image/png", "image/tiff", "video/mp4", "video/quicktime", "video/webm"},
+}
+
+// signature arsip & executable yang ditolak dengan pesan khusus.
+var blockedSignatures = []struct {
+	magic []byte
+	what  string
+}{
+	{[]byte("PK\x03\x04"), "arsip"},
+	{[]byte("\x1f\x8b"), "arsip"},
+	{[]byte("Rar!\x1a\x07"), "arsip"},
+	{[]byte("7z\xbc\xaf\x27\x1c"), "arsip"},
+	{[]byte("BZh"), "arsip"},
+	{[]byte("\xfd7zXZ\x00"), "arsip"},
+	{[]byte("MZ"), "executable"},
+	{[]byte("\x7fELF"), "executable"},
+	{[]byte("\xfe\xed\xfa\xce"), "executable"},
+	{[]byte("\xfe\xed\xfa\xcf"), "executable"},
+	{[]byte("\xce\xfa\xed\xfe"), "executable"},
+	{[]byte("\xcf\xfa\xed\xfe"), "executable"},
+	{[]byte("\xca\xfe\xba\xbe"), "executable"},
+	{[]byte("#!"), "executable"},
+}
+
+// checkedUpload: hasil validasi upload; Type & Ext dari isi file, bukan
+// dari Content-Type / nama file kiriman client.
+type checkedUpload struct {
+	Kind     string
+	FileName string
+	Type     string
+	Ext      string
+	// Content: isi yang disimpan (gambar sudah tanpa metadata)
+	Content []byte
+	// Open, SHA256 & Size dipakai sebagai ganti Content bila isi tidak
+	// dimuat ke memori (upload bertahap berukuran besar)
+	Open   func() (io.ReadCloser, error)
+	SHA256 string
+	Size   int64
+}
+
+// sniffAttachment membaca awal file untuk menentukan tipe sebenarnya.
+// blocked terisi bila file berupa arsip atau executable.
+func sniffAttachment(head []byte) (mimeType, blocked string) {
+	for _, sig := range blockedSignatures {
+		if bytes.HasPrefix(head, sig.magic) {
+			return "", sig.what
+		}
+	}
+	// http.DetectContentType tidak mengenali TIFF dan QuickTime
+	switch {
+	case bytes.HasPrefix(head, []byte("II*\x00")), bytes.HasPrefix(head, []byte("MM\x00*")):
+		return "image/tiff", ""
+	case len(head) >= 12 && string(head[4:12]) == "ftypqt  ":
+		return "video/quicktime", ""
+	}
+	mimeType, _, _ = strings.Cut(http.DetectContentType(head), ";")
+	return mimeType, ""
+}
+
+// sanitizeFileName: nama tampilan saja (file disimpan dengan ID server).
+// Path, karakter kontrol dan kutip dibuang, panjang dibatasi.
+func sanitizeFileName(name, ext string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == '/' || r == '\\' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if runes := []rune(base); len(runes) > maxAttachmentFileNameRunes {
+		base = string(runes[:maxAttachmentFileNameRunes])
+	}
+	if base == "" {
+		base = "lampiran"
+	}
+	return base + ext
+}
+
+// validateUpload memeriksa ukuran, kuota prestasi dan tipe isi file.
+func (s *AchievementService) validateUpload(ctx context.Context, ach *model.AchievementMongo, file *multipart.FileHeader, kind string) (*checkedUpload, []FieldError) {
+	// ukuran dari header dulu supaya file yang kebesaran tidak dibaca
+	if errs := s.checkUploadSize(ctx, ach, file.Size); len(errs) > 0 {
+		return nil, errs
+	}
+	src, err := file.Open()
+	if err != nil {
+		return nil, []FieldError{{"file", "file tidak dapat dibaca"}}
+	}
+	data, err := io.ReadAll(io.LimitReader(src, maxAttachmentSize+1))
+	src.Close()
+	if err != nil {
+		return nil, []FieldError{{"file", "file tidak dapat dibaca"}}
+	}
+	return s.checkUpload(ctx, ach, file.Filename, data, kind)
+}
+
+// checkUploadSize: batas per file dan kuota total prestasi.
+func (s *AchievementService) checkUploadSize(ctx context.Context, ach *model.AchievementMongo, size int64) []FieldError {
+	if size <= 0 {
+		return []FieldError{{"file", "file kosong"}}
+	}
+	if size > maxAttachmentSize {
+		return []FieldError{{"file", "ukuran maksimal " + formatMB(maxAttachmentSize)}}
+	}
+	if used := s.attachmentsSize(ctx, ach, false); used+size > maxAchievementAttachments {
+		return []FieldError{{"file", "total lampiran prestasi maksimal " + formatMB(maxAchievementAttachments) +
+			" (terpakai " + formatMB(used) + ")"}}
+	}
+	return nil
+}
+
+// checkUpload memeriksa isi file lengkap upload biasa lalu menyiapkan isi
+// yang akan disimpan.
+func (s *AchievementService) checkUpload(ctx context.Context, ach *model.AchievementMongo, fileName string, data []byte, kind string) (*checkedUpload, []FieldError) {
+	if errs := s.checkUploadSize(ctx, ach, int64(len(data))); len(errs) > 0 {
+		return nil, errs
+	}
+
+	mimeType, ext, errs := checkUploadType(fileName, data[:min(len(data), 512)], kind, attachmentKindTypes)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	content, err := s.prepareContent(ctx, data, mimeType)
+	if err != nil {
+		return nil, []FieldError{{"file", "gambar tidak dapat diproses (rusak atau dimensi terlalu besar)"}}
+	}
+
+	return &checkedUpload{
+		Kind:     kind,
+		FileName: sanitizeFileName(fileName, ext),
+		Type:     mimeType,
+		Ext:      ext,
+		Content:  content,
+	}, nil
+}
+
+// checkUploadType: tipe dari awal isi file harus ada di allow-list jenisnya
+// dan cocok dengan ekstensi nama file (bila ada).
+func checkUploadType(fileName string, head []byte, kind string, kindTypes map[string][]string) (mimeType, ext string, errs []FieldError) {
+	mimeType, blocked := sniffAttachment(head)
+	if blocked != "" {
+		return "", "", []FieldError{{"file", "file " + blocked + " tidak diizinkan"}}
+	}
+	exts, ok := attachmentTypes[mimeType]
+	if !ok || !contains(kindTypes[kind], mimeType) {
+		return "", "", []FieldError{{"file", "tipe file " + kind + " harus salah satu dari: " + allowedExtensions(kindTypes, kind)}}
+	}
+
+	ext = strings.ToLower(path.Ext(fileName))
+	switch {
+	case ext == "":
+		ext = exts[0]
+	case !contains(exts, ext):
+		return "", "", []FieldError{{"file", "ekstensi " + ext + " tidak sesuai isi file (" + mimeType + ")"}}
+	}
+	return mimeType, ext, nil
+}
+
+// attachmentsSize: total ukuran lampiran aktif prestasi. large = true
+// menghitung lampiran di atas maxAttachmentSize (hanya bisa lewat upload
+// bertahap, kuotanya terpisah), false sisanya. Lampiran lama tanpa Size
+// diukur dari storage.
+func (s *AchievementService) attachmentsSize(ctx context.Context, ach *model.AchievementMongo, large bool) int64 {
+	var total int64
+	for i := range ach.Attachments {
+		a := &ach.Attachments[i]
+		if a.DeletedAt != nil {
+			continue
+		}
+		size := a.Size
+		if size <= 0 {
+			store, key := s.attachmentSource(a)
+			if info, err := store.Stat(ctx, key); err == nil {
+				size = info.Size
+			}
+		}
+		if (size > maxAttachmentSize) == large {
+			total += size
+		}
+	}
+	return total
+}
+
+func allowedExtensions(kindTypes map[string][]string, kind string) string {
+	var exts []string
+	for _, t := range kindTypes[kind] {
+		exts = append(exts, attachmentTypes[t]...)
+	}
+	return strings.Join(exts, ", ")
+}
+
+func formatMB(n int64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64), ".0") + " MB"
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	pdfBytes  = "%PDF-1.4\n%âãÏÓ\n1 0 obj"
+	pngBytes  = encodeTestImage(png.Encode)
+	jpegBytes = encodeTestImage(func(w io.Writer, img image.Image) error { return jpeg.Encode(w, img, nil) })
+)
+
+// encodeTestImage: gambar 4×4 yang benar-benar bisa di-decode.
+func encodeTestImage(encode func(io.Writer, image.Image) error) string {
+	var buf bytes.Buffer
+	encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	return buf.String()
+}
+
+// multipartFile membuat FileHeader sungguhan lewat multipart.Reader.
+func multipartFile(t *testing.T, filename, content string) *multipart.FileHeader {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile("file", filename)
+	part.Write([]byte(content))
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	assert.NoError(t, err)
+	return form.File["file"][0]
+}
+
+func TestSniffAttachment(t *testing.T) {
+	cases := map[string][2]string{
+		pdfBytes:                    {"application/pdf", ""},
+		pngBytes:                    {"image/png", ""},
+		jpegBytes:                   {"image/jpeg", ""},
+		"II*\x00\x08\x00\x00\x00":   {"image/tiff", ""},
+		"\x00\x00\x00\x14ftypqt  ":  {"video/quicktime", ""},
+		mp4Header:                   {"video/mp4", ""},
+		"PK\x03\x04\x14\x00":        {"", "arsip"},
+		"\x1f\x8b\x08\x00":          {"", "arsip"},
+		"MZ\x90\x00\x03":            {"", "executable"},
+		"\x7fELF\x02\x01":           {"", "executable"},
+		"#!/bin/sh\nrm -rf /":       {"", "executable"},
+		"halo, ini file teks biasa": {"text/plain", ""},
+	}
+	for head, want := range cases {
+		mimeType, blocked := sniffAttachment([]byte(head))
+		assert.Equal(t, want[0], mimeType, head)
+		assert.Equal(t, want[1], blocked, head)
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	assert.Equal(t, "passwd.pdf", sanitizeFileName("../../etc/passwd.pdf", ".pdf"))
+	assert.Equal(t, "evil.pdf", sanitizeFileName(`C:\Users\x\evil.pdf`, ".pdf"))
+	assert.Equal(t, "abc.pdf", sanitizeFileName("a\x00b\"c\n.pdf", ".pdf"))
+	assert.Equal(t, "lampiran.png", sanitizeFileName("...", ".png"))
+	assert.Equal(t, "Sertifikat Lomba.jpeg", sanitizeFileName("Sertifikat Lomba.jpeg", ".jpeg"))
+
+	long := sanitizeFileName(strings.Repeat("é", 400)+".pdf", ".pdf")
+	assert.Equal(t, maxAttachmentFileNameRunes+len(".pdf"), len([]rune(long)))
+}
+
+func TestValidateUpload(t *testing.T) {
+	svc := &AchievementService{}
+	ctx := context.Background()
+	empty := &model.AchievementMongo{}
+
+	cases := []struct {
+		name, filename, content, kind, wantErr string
+	}{
+		{"pdf", "sertifikat.pdf", pdfBytes, "certificate", ""},
+		{"png photo", "foto.PNG", pngBytes, "photo", ""},
+		{"jpeg without ext", "foto", jpegBytes, "photo", ""},
+		{"pdf as photo", "foto.pdf", pdfBytes, "photo", "tipe file photo harus salah satu dari: .jpg, .jpeg, .png"},
+		{"zip", "sertifikat.pdf", "PK\x03\x04rest", "certificate", "file arsip tidak diizinkan"},
+		{"exe", "sertifikat.pdf", "MZ\x90\x00", "certificate", "file executable tidak diizinkan"},
+		{"text", "catatan.txt", "halo dunia", "other", "tipe file other harus salah satu dari"},
+		{"extension mismatch", "sertifikat.exe", pdfBytes, "certificate", "ekstensi .exe tidak sesuai isi file (application/pdf)"},
+		{"declared pdf but png", "sertifikat.pdf", pngBytes, "certificate", "ekstensi .pdf tidak sesuai isi file (image/png)"},
+		{"empty", "kosong.pdf", "", "certificate", "file kosong"},
+	}
+	for _, tc := range cases {
+		up, errs := svc.validateUpload(ctx, empty, multipartFile(t, tc.filename, tc.content), tc.kind)
+		if tc.wantErr == "" {
+			assert.Empty(t, errs, tc.name)
+			assert.NotNil(t, up, tc.name)
+			continue
+		}
+		if assert.Len(t, errs, 1, tc.name) {
+			assert.Equal(t, "file", errs[0].Field, tc.name)
+			assert.Contains(t, errs[0].Message, tc.wantErr, tc.name)
+		}
+	}
+
+	up, _ := svc.validateUpload(ctx, empty, multipartFile(t, "../foto.jpeg", jpegBytes), "photo")
+	assert.Equal(t, "image/jpeg", up.Type)
+	assert.Equal(t, ".jpeg", up.Ext)
+	assert.Equal(t, "foto.jpeg", up.FileName)
+}
+
+func TestValidateUpload_SizeLimits(t *testing.T) {
+	svc := &AchievementService{}
+	ctx := context.Background()
+
+	big := multipartFile(t, "besar.pdf", pdfBytes)
+	big.Size = maxAttachmentSize + 1
+	_, errs := svc.validateUpload(ctx, &model.AchievementMongo{}, big, "certificate")
+	assert.Equal(t, []FieldError{{"file", "ukuran maksimal 5 MB"}}, errs)
+
+	full := &model.AchievementMongo{Attachments: []model.Attachment{
+		{StorageKey: "x/a.pdf", Size: 5 << 20},
+		{StorageKey: "x/b.pdf", Size: 5 << 20},
+		{StorageKey: "x/c.pdf", Size: 5 << 20},
+		{StorageKey: "x/d.pdf", Size: 4 << 20},
+		// lampiran besar (upload bertahap) punya kuota sendiri
+		{StorageKey: "x/e.mp4", Size: 100 << 20},
+	}}
+	file := multipartFile(t, "lagi.pdf", pdfBytes)
+	file.Size = 2 << 20
+	_, errs = svc.validateUpload(ctx, full, file, "certificate")
+	assert.Equal(t, []FieldError{{"file", "total lampiran prestasi maksimal 20 MB (terpakai 19 MB)"}}, errs)
+
+	file.Size = 1 << 20
+	_, errs = svc.validateUpload(ctx, full, file, "certificate")
+	assert.Empty(t, errs)
+}
+
+func TestUploadAttachment_RejectsArchive(t *testing.T) {
+	mongo := &MockUploadMongoRepo{}
+	app := uploadApp(&AchievementService{
+		MongoRepo:    mongo,
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		Storage:      storage.NewLocal(t.TempDir()),
+	})
+
+	status, out := uploadRequest(app, "sertifikat.pdf", "PK\x03\x04isi zip")
+	assert.Equal(t, 400, status)
+	assert.Equal(t, "validasi gagal", out["error"])
+	assert.Empty(t, mongo.added)
+}
@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultAttachmentRetention: isi lampiran prestasi yang dihapus disimpan
+// selama ini (masih bisa di-restore) sebelum dipurge dari storage.
+const DefaultAttachmentRetention = 30 * 24 * time.Hour
+
+// purgeHoldPrefix: salinan sementara objek yang sedang dipurge.
+const purgeHoldPrefix = "purging/"
+
+// AttachmentRetentionFromEnv: ATTACHMENT_RETENTION_DAYS (default 30).
+func AttachmentRetentionFromEnv() time.Duration {
+	return envDuration("ATTACHMENT_RETENTION_DAYS", 24*time.Hour, DefaultAttachmentRetention)
+}
+
+type AttachmentPurgeResult struct {
+	Achievements int      `json:"achievements"`
+	Objects      int      `json:"objects"`
+	Shared       int      `json:"shared"` // objek dilewati karena masih dipakai prestasi lain
+	Failed       []string `json:"failed"`
+}
+
+// attachmentsEditable: lampiran (upload, ganti, hapus) mengikuti aturan
+// Update: mahasiswa hanya selama draft, admin kapan saja.
+func attachmentsEditable(c *fiber.Ctx, ref *model.AchievementReference) error {
+	if role, _ := c.Locals("role").(string); role == "Mahasiswa" && ref.Status != "draft" {
+		return &reviewError{400, "lampiran hanya bisa diubah selama draft"}
+	}
+	return nil
+}
+
+// attachmentsUploadable: upload lampiran baru juga boleh selama rejected,
+// karena banding mensyaratkan bukti yang diunggah setelah penolakan.
+// Lampiran lama tetap tidak bisa diganti/dihapus (attachmentsEditable).
+func attachmentsUploadable(c *fiber.Ctx, ref *model.AchievementReference) error {
+	if ref.Status == "rejected" {
+		return nil
+	}
+	return attachmentsEditable(c, ref)
+}
+
+// editableAttachment: lampiran aktif pada prestasi milik user (mahasiswa)
+// yang boleh diubah saat ini (attachmentsEditable).
+func (s *AchievementService) editableAttachment(c *fiber.Ctx) (*model.AchievementReference, *model.AchievementMongo, int, error) {
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil || ref.Status == "deleted" {
+		return nil, nil, -1, &reviewError{404, "reference not found"}
+	}
+	if role, _ := c.Locals("role").(string); role == "Mahasiswa" {
+		studentID, _ := c.Locals("student_id").(string)
+		if ref.StudentID != studentID {
+			return nil, nil, -1, &reviewError{403, "not your achievement"}
+		}
+	}
+	if err := attachmentsEditable(c, ref); err != nil {
+		return nil, nil, -1, err
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return nil, nil, -1, &reviewError{404, "achievement not found"}
+	}
+	i := attachmentIndex(ach, c.Params("attachmentId"))
+	if i < 0 || ach.Attachments[i].DeletedAt != nil {
+		return nil, nil, -1, &reviewError{404, "attachment not found"}
+	}
+	return ref, ach, i, nil
+}
+
+// attachmentObjects: semua objek storage milik lampiran (versi aktif & lama)
+// beserta tipe & hash-nya (untuk thumbnail).
+func (s *AchievementService) attachmentObjects(a *model.Attachment) []model.Attachment {
+	objects := []model.Attachment{{StorageKey: a.StorageKey, FileURL: a.FileURL, FileType: a.FileType, SHA256: a.SHA256}}
+	for _, v := range a.Versions {
+		objects = append(objects, model.Attachment{StorageKey: v.StorageKey, FileURL: v.FileURL, FileType: v.FileType, SHA256: v.SHA256})
+	}
+	return objects
+}
+
+// ======================================================
+// LAMPIRAN — hapus, ganti, versi lama
+// ======================================================
+
+// DeleteAttachment: lampiran ditandai terhapus. Isi file tetap disimpan
+// untuk audit reviewer sampai prestasinya dipurge.
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+	ref, ach, i, err := s.editableAttachment(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	actor := reviewActorFrom(c)
+
+	now := time.Now()
+	att := ach.Attachments[i]
+	att.DeletedAt = &now
+	att.DeletedBy = actor.UserID
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	if err := s.MongoRepo.SetAttachmentMongo(oid, i, att); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	s.dropAttachmentFingerprint(c.Context(), ref, ach, i)
+
+	// HISTORY: lampiran dihapus (status tidak berubah)
+	s.saveHistory(ref.ID, ref.Status, ref.Status, actor.UserID, actor.Role,
+		"lampiran dihapus: "+att.FileName+" ("+attachmentID(att, i)+")")
+
+	return c.JSON(fiber.Map{"message": "Attachment deleted"})
+}
+
+// ReplaceAttachment: unggah file baru untuk lampiran yang sama (ID & kind
+// tetap). Versi sebelumnya masuk ke Versions.
+func (s *AchievementService) ReplaceAttachment(c *fiber.Ctx) error {
+	ref, ach, i, err := s.editableAttachment(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	actor := reviewActorFrom(c)
+	old := ach.Attachments[i]
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "file is required"})
+	}
+
+	// kuota dihitung tanpa file yang diganti
+	others := *ach
+	others.Attachments = append(append([]model.Attachment{}, ach.Attachments[:i]...), ach.Attachments[i+1:]...)
+	upload, errs := s.validateUpload(c.Context(), &others, file, old.Kind)
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	obj, created, err := s.putAttachmentObject(c, upload)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	now := time.Now()
+	att := *obj
+	att.ID = old.ID
+	att.Version = old.CurrentVersion() + 1
+	att.Versions = append(old.Versions, model.AttachmentVersion{
+		Version:    old.CurrentVersion(),
+		FileName:   old.FileName,
+		FileURL:    old.FileURL,
+		StorageKey: old.StorageKey,
+		FileType:   old.FileType,
+		Size:       old.Size,
+		UploadedAt: old.UploadedAt,
+		ReplacedAt: now,
+		ReplacedBy: actor.UserID,
+		ScanStatus: old.ScanStatus,
+		SHA256:     old.SHA256,
+	})
+	if err := s.MongoRepo.SetAttachmentMongo(oid, i, att); err != nil {
+		if created {
+			s.attachmentStore().Delete(c.Context(), obj.StorageKey)
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !created {
+		s.ensureAttachmentObject(c, upload, obj)
+	}
+	s.dropAttachmentFingerprint(c.Context(), ref, ach, i)
+
+	s.queueThumbnails(att)
+
+	// HISTORY: lampiran diganti (status tidak berubah)
+	id := attachmentID(att, i)
+	s.saveHistory(ref.ID, ref.Status, ref.Status, actor.UserID, actor.Role,
+		"lampiran diganti: "+old.FileName+" → "+att.FileName+" ("+id+", versi "+strconv.Itoa(att.Version)+")")
+
+	response := fiber.Map{
+		"message": "Attachment replaced",
+		"id":      id,
+		"version": att.Version,
+		"url":     "/api/v1/achievements/" + ref.ID + "/attachments/" + id,
+		"type":    att.FileType,
+		"size":    att.Size,
+		"sha256":  att.SHA256,
+	}
+	if thumb := thumbnailURL(ref.ID, att, i); thumb != "" {
+		response["thumbnailUrl"] = thumb
+	}
+	if att.ScanStatus != "" {
+		response["scanStatus"] = att.ScanStatus
+	}
+	if matches := s.indexAttachmentHash(ref, att.SHA256); len(matches) > 0 {
+		response["duplicates"] = duplicateWarnings(ref.StudentID, matches)
+	}
+	return c.JSON(response)
+}
+
+// DownloadAttachmentVersion (reviewer): isi lampiran pada versi tertentu,
+// termasuk lampiran yang sudah dihapus.
+func (s *AchievementService) DownloadAttachmentVersion(c *fiber.Ctx) error {
+	if !isReviewerRole(reviewActorFrom(c).Role) {
+		return c.Status(403).JSON(fiber.Map{"error": "versi lampiran hanya untuk reviewer"})
+	}
+	_, ach, err := s.attachmentOwner(c)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+	att, ok := findAttachment(ach, c.Params("attachmentId"))
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "attachment not found"})
+	}
+
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "version not found"})
+	}
+	if version == att.CurrentVersion() {
+		if !att.Downloadable() {
+			return respondNotDownloadable(c, att.ScanStatus)
+		}
+		store, key := s.attachmentSource(att)
+		return streamAttachment(c, store, key, att.FileName, att.FileType)
+	}
+	for _, v := range att.Versions {
+		if v.Version == version {
+			if !(model.Attachment{ScanStatus: v.ScanStatus}).Downloadable() {
+				return respondNotDownloadable(c, v.ScanStatus)
+			}
+			store, key := s.attachmentSource(&model.Attachment{StorageKey: v.StorageKey, FileURL: v.FileURL})
+			return streamAttachment(c, store, key, v.FileName, v.FileType)
+		}
+	}
+	return c.Status(404).JSON(fiber.Map{"error": "version not found"})
+}
+
+// ======================================================
+// PURGE — isi lampiran prestasi yang dihapus lewat masa retensi
+// ======================================================
+
+// PurgeDeletedAttachments menghapus semua objek lampiran (termasuk versi
+// lama) milik prestasi yang dihapus sebelum now - retention, kecuali objek
+// yang isinya masih dipakai prestasi lain. Metadata tetap ada di Mongo dan
+// ditandai attachmentsPurgedAt.
+func (s *AchievementService) PurgeDeletedAttachments(ctx context.Context, now time.Time) (AttachmentPurgeResult, error) {
+	result := AttachmentPurgeResult{Failed: []string{}}
+
+	retention := s.AttachmentRetention
+	if retention <= 0 {
+		retention = DefaultAttachmentRetention
+	}
+	achievements, err := s.MongoRepo.ListPurgeable(now.Add(-retention))
+	if err != nil {
+		return result, err
+	}
+
+	for _, ach := range achievements {
+		failed := false
+		done := map[string]bool{}
+		for i := range ach.Attachments {
+			for _, obj := range s.attachmentObjects(&ach.Attachments[i]) {
+				store, key := s.attachmentSource(&obj)
+				if done[key] {
+					continue
+				}
+				done[key] = true
+
+				// objek dengan isi yang sama masih dipakai prestasi lain
+				if obj.StorageKey != "" {
+					shared, err := s.purgeSharedObject(ctx, key, ach.ID)
+					if err != nil {
+						result.Failed = append(result.Failed, ach.ID.Hex()+"/"+key+": "+err.Error())
+						failed = true
+						continue
+					}
+					if shared {
+						result.Shared++
+						continue
+					}
+				} else if err := store.Delete(ctx, key); err != nil {
+					result.Failed = append(result.Failed, ach.ID.Hex()+"/"+key+": "+err.Error())
+					failed = true
+					continue
+				}
+				result.Objects++
+
+				for _, thumb := range thumbnailObjects(&obj) {
+					if err := store.Delete(ctx, thumb); err != nil {
+						result.Failed = append(result.Failed, ach.ID.Hex()+"/"+thumb+": "+err.Error())
+						failed = true
+					}
+				}
+			}
+		}
+		// gagal sebagian → dicoba lagi pada run berikutnya
+		if failed {
+			continue
+		}
+		if err := s.MongoRepo.MarkAttachmentsPurged(ach.ID, now); err != nil {
+			result.Failed = append(result.Failed, ach.ID.Hex()+": "+err.Error())
+			continue
+		}
+		result.Achievements++
+	}
+	return result, nil
+}
+
+// purgeSharedObject menghapus objek content-addressed kecuali masih dipakai
+// prestasi lain (shared = true). Upload dengan isi sama bisa memakai objek
+// ini tepat saat dihapus (putAttachmentObject melewati Put bila objeknya
+// ada), jadi isinya disalin ke purgeHoldPrefix dulu dan referensi dihitung
+// ulang setelah dihapus; bila ternyata dipakai, objek dikembalikan.
+func (s *AchievementService) purgeSharedObject(ctx context.Context, key string, achID primitive.ObjectID) (shared bool, err error) {
+	refs, err := s.MongoRepo.CountStorageKeyRefs(key, achID)
+	if err != nil || refs > 0 {
+		return refs > 0, err
+	}
+
+	store := s.attachmentStore()
+	hold := purgeHoldPrefix + key
+	if err := copyObject(ctx, store, key, hold); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		store.Delete(ctx, hold)
+		return false, err
+	}
+
+	refs, err = s.MongoRepo.CountStorageKeyRefs(key, achID)
+	if err == nil && refs == 0 {
+		return false, store.Delete(ctx, hold)
+	}
+	// dipakai upload yang masuk di tengah purge (atau belum pasti): kembalikan.
+	// Salinan dibiarkan bila gagal dikembalikan supaya isinya tidak hilang.
+	if rerr := copyObject(ctx, store, hold, key); rerr != nil {
+		return false, rerr
+	}
+	store.Delete(ctx, hold)
+	return err == nil, err
+}
+
+// copyObject menyalin isi objek src ke dst pada storage yang sama.
+func copyObject(ctx context.Context, store storage.Storage, src, dst string) error {
+	rc, info, err := store.Get(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return store.Put(ctx, dst, rc, info.Size, info.ContentType)
+}
+
+// RunAttachmentPurge: job scheduler.
+func (s *AchievementService) RunAttachmentPurge(ctx context.Context) error {
+	result, err := s.PurgeDeletedAttachments(ctx, time.Now())
+	if err == nil && (result.Achievements > 0 || len(result.Failed) > 0) {
+		log.Printf("attachment purge: %d prestasi, %d objek, %d gagal", result.Achievements, result.Objects, len(result.Failed))
+	}
+	return err
+}
+
+// PurgeAttachmentsNow (Admin): jalankan purge tanpa menunggu jadwal.
+func (s *AchievementService) PurgeAttachmentsNow(c *fiber.Ctx) error {
+	result, err := s.PurgeDeletedAttachments(c.Context(), time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(result)
+}
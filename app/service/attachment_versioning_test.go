@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK =================
+
+type MockVersioningMongoRepo struct {
+	MockAchievementMongoRepo
+	ach    model.AchievementMongo
+	purged []primitive.ObjectID
+}
+
+func (m *MockVersioningMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	ach := m.ach
+	ach.Attachments = append([]model.Attachment{}, m.ach.Attachments...)
+	return &ach, nil
+}
+
+func (m *MockVersioningMongoRepo) SetAttachmentMongo(id primitive.ObjectID, index int, a model.Attachment) error {
+	m.ach.Attachments[index] = a
+	return nil
+}
+
+func (m *MockVersioningMongoRepo) ListPurgeable(deletedBefore time.Time) ([]model.AchievementMongo, error) {
+	if m.ach.DeletedAt == nil || !m.ach.DeletedAt.Before(deletedBefore) || m.ach.AttachmentsPurgedAt != nil {
+		return []model.AchievementMongo{}, nil
+	}
+	return []model.AchievementMongo{m.ach}, nil
+}
+
+func (m *MockVersioningMongoRepo) MarkAttachmentsPurged(id primitive.ObjectID, at time.Time) error {
+	m.purged = append(m.purged, id)
+	m.ach.AttachmentsPurgedAt = &at
+	return nil
+}
+
+type MockVersioningPostgresRepo struct {
+	MockHistoryPostgresRepo
+	status string
+}
+
+func (m *MockVersioningPostgresRepo) GetReferenceByID(id string) (*model.AchievementReference, error) {
+	return &model.AchievementReference{
+		ID:        utils.CopyString(id),
+		StudentID: "student-1",
+		MongoID:   primitive.NewObjectID().Hex(),
+		Status:    m.status,
+	}, nil
+}
+
+type versioningFixture struct {
+	app   *fiber.App
+	svc   *AchievementService
+	mongo *MockVersioningMongoRepo
+	pg    *MockVersioningPostgresRepo
+	store *storage.Local
+}
+
+func newVersioningFixture(t *testing.T, status string) *versioningFixture {
+	store := storage.NewLocal(t.TempDir())
+	store.Put(context.Background(), "obj/att-1.pdf", strings.NewReader(pdfBytes+" v1"), 0, "application/pdf")
+
+	f := &versioningFixture{
+		mongo: &MockVersioningMongoRepo{ach: model.AchievementMongo{
+			ID: primitive.NewObjectID(),
+			Attachments: []model.Attachment{{
+				ID: "att-1", FileName: "sertifikat.pdf", StorageKey: "obj/att-1.pdf",
+				FileType: "application/pdf", Size: int64(len(pdfBytes + " v1")), Kind: "certificate",
+			}},
+		}},
+		pg:    &MockVersioningPostgresRepo{status: status},
+		store: store,
+	}
+	f.svc = &AchievementService{
+		MongoRepo:    f.mongo,
+		PostgresRepo: f.pg,
+		StudentRepo:  &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+		Storage:      store,
+	}
+
+	f.app = fiber.New()
+	auth := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", utils.CopyString(c.Get("X-Role")))
+			c.Locals("user_id", "user-"+utils.CopyString(c.Get("X-Role")))
+			c.Locals("student_id", "student-1")
+			c.Locals("lecturer_id", "lect-1")
+			return h(c)
+		}
+	}
+	f.app.Get("/achievements/:refId/attachments", auth(f.svc.ListAttachments))
+	f.app.Get("/achievements/:refId/attachments/:attachmentId", auth(f.svc.DownloadAttachment))
+	f.app.Put("/achievements/:refId/attachments/:attachmentId", auth(f.svc.ReplaceAttachment))
+	f.app.Delete("/achievements/:refId/attachments/:attachmentId", auth(f.svc.DeleteAttachment))
+	f.app.Get("/achievements/:refId/attachments/:attachmentId/versions/:version", auth(f.svc.DownloadAttachmentVersion))
+	return f
+}
+
+func (f *versioningFixture) do(method, path, role string, file string) (int, string) {
+	var body bytes.Buffer
+	req := httptest.NewRequest(method, path, nil)
+	if file != "" {
+		w := multipart.NewWriter(&body)
+		part, _ := w.CreateFormFile("file", "sertifikat-revisi.pdf")
+		io.WriteString(part, file)
+		w.Close()
+		req = httptest.NewRequest(method, path, &body)
+		req.Header.Set("Content-Type", w.FormDataContentType())
+	}
+	req.Header.Set("X-Role", role)
+	resp, _ := f.app.Test(req)
+	out, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(out)
+}
+
+// ================= TESTS =================
+
+func TestReplaceAttachment_KeepsPreviousVersion(t *testing.T) {
+	f := newVersioningFixture(t, "draft")
+	path := "/achievements/ref-1/attachments/att-1"
+
+	status, _ := f.do("PUT", path, "Mahasiswa", pdfBytes+" v2")
+	assert.Equal(t, 200, status)
+
+	att := f.mongo.ach.Attachments[0]
+	assert.Equal(t, "att-1", att.ID)
+	assert.Equal(t, 2, att.Version)
+	assert.Equal(t, "sertifikat-revisi.pdf", att.FileName)
+	assert.Equal(t, "certificate", att.Kind)
+	assert.NotEqual(t, "obj/att-1.pdf", att.StorageKey)
+	if assert.Len(t, att.Versions, 1) {
+		assert.Equal(t, 1, att.Versions[0].Version)
+		assert.Equal(t, "obj/att-1.pdf", att.Versions[0].StorageKey)
+		assert.Equal(t, "user-Mahasiswa", att.Versions[0].ReplacedBy)
+	}
+
+	// versi aktif = isi baru
+	_, body := f.do("GET", path, "Mahasiswa", "")
+	assert.Equal(t, pdfBytes+" v2", body)
+
+	// versi lama hanya untuk reviewer
+	status, _ = f.do("GET", path+"/versions/1", "Mahasiswa", "")
+	assert.Equal(t, 403, status)
+	status, body = f.do("GET", path+"/versions/1", "Dosen Wali", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, pdfBytes+" v1", body)
+	_, body = f.do("GET", path+"/versions/2", "Admin", "")
+	assert.Equal(t, pdfBytes+" v2", body)
+	status, _ = f.do("GET", path+"/versions/3", "Admin", "")
+	assert.Equal(t, 404, status)
+
+	if assert.Len(t, f.pg.history, 1) {
+		h := f.pg.history[0]
+		assert.Equal(t, "draft", h.OldStatus)
+		assert.Equal(t, "draft", h.NewStatus)
+		assert.Contains(t, h.Note, "lampiran diganti: sertifikat.pdf → sertifikat-revisi.pdf")
+		assert.Contains(t, h.Note, "versi 2")
+	}
+}
+
+func TestReplaceAttachment_Validates(t *testing.T) {
+	f := newVersioningFixture(t, "draft")
+
+	status, body := f.do("PUT", "/achievements/ref-1/attachments/att-1", "Mahasiswa", "MZ\x90\x00")
+	assert.Equal(t, 400, status)
+	assert.Contains(t, body, "executable")
+	assert.Equal(t, 1, f.mongo.ach.Attachments[0].CurrentVersion())
+	assert.Empty(t, f.pg.history)
+}
+
+func TestDeleteAttachment_HiddenFromStudentKeptForReviewer(t *testing.T) {
+	f := newVersioningFixture(t, "draft")
+	path := "/achievements/ref-1/attachments/att-1"
+
+	status, _ := f.do("DELETE", path, "Mahasiswa", "")
+	assert.Equal(t, 200, status)
+	assert.NotNil(t, f.mongo.ach.Attachments[0].DeletedAt)
+	assert.Equal(t, "user-Mahasiswa", f.mongo.ach.Attachments[0].DeletedBy)
+	assert.Len(t, f.pg.history, 1)
+	assert.Contains(t, f.pg.history[0].Note, "lampiran dihapus: sertifikat.pdf")
+
+	// tidak bisa dihapus / diganti dua kali
+	status, _ = f.do("DELETE", path, "Mahasiswa", "")
+	assert.Equal(t, 404, status)
+	status, _ = f.do("PUT", path, "Admin", pdfBytes)
+	assert.Equal(t, 404, status)
+
+	var list struct {
+		Attachments []map[string]interface{} `json:"attachments"`
+	}
+	_, body := f.do("GET", "/achievements/ref-1/attachments", "Mahasiswa", "")
+	json.Unmarshal([]byte(body), &list)
+	assert.Empty(t, list.Attachments)
+	status, _ = f.do("GET", path, "Mahasiswa", "")
+	assert.Equal(t, 404, status)
+
+	_, body = f.do("GET", "/achievements/ref-1/attachments", "Dosen Wali", "")
+	json.Unmarshal([]byte(body), &list)
+	if assert.Len(t, list.Attachments, 1) {
+		assert.NotNil(t, list.Attachments[0]["deletedAt"])
+	}
+	status, body = f.do("GET", path, "Dosen Wali", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, pdfBytes+" v1", body)
+}
+
+func TestAttachmentChanges_OnlyWhileEditable(t *testing.T) {
+	f := newVersioningFixture(t, "submitted")
+	path := "/achievements/ref-1/attachments/att-1"
+
+	status, _ := f.do("DELETE", path, "Mahasiswa", "")
+	assert.Equal(t, 400, status)
+	status, _ = f.do("PUT", path, "Mahasiswa", pdfBytes)
+	assert.Equal(t, 400, status)
+
+	// admin mengikuti aturan Update: boleh kapan saja
+	status, _ = f.do("PUT", path, "Admin", pdfBytes+" admin")
+	assert.Equal(t, 200, status)
+
+	// lampiran baru mengikuti aturan yang sama
+	mongo := &MockUploadMongoRepo{}
+	app := uploadApp(&AchievementService{MongoRepo: mongo, PostgresRepo: &MockAchievementPostgresRepo{}, Storage: f.store})
+	status, _ = uploadRequest(app, "sertifikat.pdf", pdfBytes)
+	assert.Equal(t, 400, status)
+	assert.Empty(t, mongo.added)
+
+	r := newResumableFixture(t)
+	r.svc.PostgresRepo = &MockAchievementPostgresRepo{}
+	status, _ = r.create(len(pdfBytes), tusMetadata("sertifikat.pdf", "certificate"))
+	assert.Equal(t, 400, status)
+}
+
+// storage yang gagal menghapus satu key
+type failingDeleteStore struct {
+	*storage.Local
+	fail string
+}
+
+func (s failingDeleteStore) Delete(ctx context.Context, key string) error {
+	if key == s.fail {
+		return errors.New("storage down")
+	}
+	return s.Local.Delete(ctx, key)
+}
+
+func TestPurgeDeletedAttachments(t *testing.T) {
+	f := newVersioningFixture(t, "draft")
+	f.do("PUT", "/achievements/ref-1/attachments/att-1", "Mahasiswa", pdfBytes+" v2")
+	current := f.mongo.ach.Attachments[0].StorageKey
+
+	now := time.Now()
+	ctx := context.Background()
+
+	// belum dihapus → tidak dipurge
+	result, err := f.svc.PurgeDeletedAttachments(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Achievements)
+
+	// dihapus tapi masih dalam masa retensi
+	deletedAt := now.Add(-24 * time.Hour)
+	f.mongo.ach.DeletedAt = &deletedAt
+	result, _ = f.svc.PurgeDeletedAttachments(ctx, now)
+	assert.Equal(t, 0, result.Achievements)
+
+	// gagal sebagian → tidak ditandai purged
+	f.svc.Storage = failingDeleteStore{f.store, current}
+	result, _ = f.svc.PurgeDeletedAttachments(ctx, now.Add(DefaultAttachmentRetention))
+	assert.Equal(t, 0, result.Achievements)
+	assert.Len(t, result.Failed, 1)
+	assert.Nil(t, f.mongo.ach.AttachmentsPurgedAt)
+
+	f.svc.Storage = f.store
+	result, _ = f.svc.PurgeDeletedAttachments(ctx, now.Add(DefaultAttachmentRetention))
+	assert.Equal(t, 1, result.Achievements)
+	assert.Equal(t, 2, result.Objects)
+	assert.Empty(t, result.Failed)
+	assert.NotNil(t, f.mongo.ach.AttachmentsPurgedAt)
+
+	for _, key := range []string{"obj/att-1.pdf", current} {
+		_, err := f.store.Stat(ctx, key)
+		assert.Equal(t, storage.ErrNotFound, err, key)
+	}
+}
+
+// racingPurgeMongoRepo: upload dengan isi sama tersimpan tepat setelah purge
+// menghapus objeknya (referensi baru terlihat saat dihitung ulang).
+type racingPurgeMongoRepo struct {
+	*MockVersioningMongoRepo
+	store *storage.Local
+}
+
+func (m *racingPurgeMongoRepo) CountStorageKeyRefs(key string, exclude primitive.ObjectID) (int64, error) {
+	if _, err := m.store.Stat(context.Background(), key); err != nil {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func TestPurgeDeletedAttachments_RestoresObjectClaimedDuringPurge(t *testing.T) {
+	f := newVersioningFixture(t, "draft")
+	f.svc.MongoRepo = &racingPurgeMongoRepo{f.mongo, f.store}
+	now := time.Now()
+	ctx := context.Background()
+	deletedAt := now.Add(-DefaultAttachmentRetention - time.Hour)
+	f.mongo.ach.DeletedAt = &deletedAt
+
+	result, err := f.svc.PurgeDeletedAttachments(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Shared)
+	assert.Equal(t, 0, result.Objects)
+
+	rc, _, err := f.store.Get(ctx, "obj/att-1.pdf")
+	if assert.NoError(t, err) {
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		assert.Equal(t, pdfBytes+" v1", string(data))
+	}
+	_, err = f.store.Stat(ctx, purgeHoldPrefix+"obj/att-1.pdf")
+	assert.Equal(t, storage.ErrNotFound, err)
+}
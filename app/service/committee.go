@@ -0,0 +1,420 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// majorityReached: n suara dari cast memenuhi aturan mayoritas.
+func majorityReached(rule string, n, cast int) bool {
+	if cast == 0 {
+		return false
+	}
+	switch rule {
+	case model.MajorityTwoThirds:
+		return n*3 >= cast*2
+	case model.MajorityUnanimous:
+		return n == cast
+	default:
+		return n*2 > cast
+	}
+}
+
+// tallyVotes menghitung suara anggota aktif komite. Keputusan diambil setelah
+// kuorum: verified bila suara setuju memenuhi mayoritas, rejected bila suara
+// tolak memenuhi mayoritas atau semua anggota sudah memilih tanpa mayoritas setuju.
+func tallyVotes(committee *model.Committee, votes []model.CommitteeVote) model.VoteTally {
+	t := model.VoteTally{
+		Members:  len(committee.Members),
+		Quorum:   committee.Quorum,
+		Majority: committee.Majority,
+	}
+	for _, v := range votes {
+		if !committee.HasMember(v.VoterID) {
+			continue
+		}
+		if v.Vote == model.VoteApprove {
+			t.Approve++
+		} else {
+			t.Reject++
+		}
+	}
+
+	cast := t.Approve + t.Reject
+	switch {
+	case cast < t.Quorum:
+	case majorityReached(t.Majority, t.Approve, cast):
+		t.Outcome = "verified"
+	case majorityReached(t.Majority, t.Reject, cast), cast >= t.Members:
+		t.Outcome = "rejected"
+	}
+	return t
+}
+
+// committeeFor: komite yang memutus prestasi ini, nil bila cukup satu reviewer.
+// Bila sudah ada suara, komite pemilik suara pertama tetap dipakai walau
+// konfigurasi berubah di tengah review.
+func (s *AchievementService) committeeFor(ref *model.AchievementReference) (*model.Committee, error) {
+	if s.CommitteeRepo == nil {
+		return nil, nil
+	}
+
+	votes, err := s.CommitteeRepo.Votes(ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(votes) > 0 {
+		return s.CommitteeRepo.Get(votes[0].CommitteeID)
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return nil, err
+	}
+	committees, err := s.CommitteeRepo.List(true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range committees {
+		if ruleMatches(committees[i].AppliesTo, ach) {
+			return &committees[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// requireSingleReviewer dipanggil verify/reject biasa: prestasi komite
+// hanya bisa diputus lewat voting.
+func (s *AchievementService) requireSingleReviewer(ref *model.AchievementReference) error {
+	committee, err := s.committeeFor(ref)
+	if err != nil {
+		return err
+	}
+	if committee != nil {
+		return &reviewError{409, "prestasi ini diputus oleh komite " + committee.Name}
+	}
+	return nil
+}
+
+// applyCommitteeOutcome menjalankan transisi otomatis setelah kuorum tercapai.
+// verified_by diisi anggota yang memberikan suara penentu. Status diubah
+// bersyarat (masih submitted) sehingga dari beberapa suara bersamaan yang
+// sama-sama mencapai kuorum hanya satu yang mencatat history & ledger;
+// false berarti keputusan sudah diterapkan suara lain.
+func (s *AchievementService) applyCommitteeOutcome(actor reviewActor, ref *model.AchievementReference, committee *model.Committee, tally model.VoteTally, votes []model.CommitteeVote) (bool, error) {
+	note := fmt.Sprintf("komite %s: %d setuju, %d tolak", committee.Name, tally.Approve, tally.Reject)
+
+	// catatan penolakan: rekap + komentar suara tolak
+	reasons := []string{note}
+	if tally.Outcome == "rejected" {
+		for _, v := range votes {
+			if v.Vote == model.VoteReject && v.Comment != "" && committee.HasMember(v.VoterID) {
+				reasons = append(reasons, v.Comment)
+			}
+		}
+	}
+	applied, err := s.PostgresRepo.DecideSubmitted(ref.ID, tally.Outcome, actor.UserID, strings.Join(reasons, "\n"))
+	if err != nil || !applied {
+		return false, err
+	}
+
+	// HISTORY: keputusan komite
+	s.saveHistory(ref.ID, ref.Status, tally.Outcome, actor.UserID, "Committee", note)
+	s.releaseClaim(ref.ID)
+
+	if tally.Outcome == "verified" {
+		// LEDGER: poin prestasi masuk saldo mahasiswa
+		oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+		if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+			s.recordLedger(ref, ach, model.LedgerEarned, ach.Points, actor.UserID, "Committee", note)
+		}
+		s.recordVerificationCode(ref.ID)
+	}
+	return true, nil
+}
+
+// ======================================================
+// VOTING KOMITE (anggota komite)
+// ======================================================
+
+func (s *AchievementService) Vote(c *fiber.Ctx) error {
+	var body struct {
+		Vote    string `json:"vote"`
+		Comment string `json:"comment"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	var errs []FieldError
+	if body.Vote != model.VoteApprove && body.Vote != model.VoteReject {
+		errs = append(errs, FieldError{"vote", "harus salah satu dari: approve, reject"})
+	}
+	body.Comment = strings.TrimSpace(body.Comment)
+	if body.Vote == model.VoteReject && body.Comment == "" {
+		errs = append(errs, FieldError{"comment", "wajib diisi untuk suara tolak"})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	if ref.Status != "submitted" {
+		return c.Status(400).JSON(fiber.Map{"error": "hanya status submitted yang bisa divoting"})
+	}
+
+	committee, err := s.committeeFor(ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if committee == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "prestasi ini tidak memerlukan review komite"})
+	}
+
+	actor := reviewActorFrom(c)
+	if !committee.HasMember(actor.UserID) {
+		return c.Status(403).JSON(fiber.Map{"error": "bukan anggota komite " + committee.Name})
+	}
+
+	vote := &model.CommitteeVote{
+		ReferenceID: ref.ID,
+		CommitteeID: committee.ID,
+		VoterID:     actor.UserID,
+		VoterRole:   actor.Role,
+		Vote:        body.Vote,
+		Comment:     body.Comment,
+		VotedAt:     time.Now(),
+	}
+	if err := s.CommitteeRepo.CastVote(vote); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	votes, err := s.CommitteeRepo.Votes(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	tally := tallyVotes(committee, votes)
+
+	status := ref.Status
+	if tally.Outcome != "" {
+		applied, err := s.applyCommitteeOutcome(actor, ref, committee, tally, votes)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		status = tally.Outcome
+		// sudah diputus suara lain → laporkan status yang tersimpan
+		if !applied {
+			if current, err := s.PostgresRepo.GetReferenceByID(ref.ID); err == nil {
+				status = current.Status
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"vote":   vote,
+		"tally":  tally,
+		"status": status,
+	})
+}
+
+// Votes: catatan suara komite. Mahasiswa hanya melihat rekap tanpa identitas pemilih.
+func (s *AchievementService) Votes(c *fiber.Ctx) error {
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	if err := s.authorizeView(c, ref); err != nil {
+		return respondReviewError(c, err)
+	}
+
+	committee, err := s.committeeFor(ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if committee == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "prestasi ini tidak direview komite"})
+	}
+
+	votes, err := s.CommitteeRepo.Votes(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response := fiber.Map{
+		"committee": fiber.Map{"id": committee.ID, "name": committee.Name},
+		"tally":     tallyVotes(committee, votes),
+	}
+	if isReviewerRole(reviewActorFrom(c).Role) {
+		response["votes"] = votes
+	}
+	return c.JSON(response)
+}
+
+// ======================================================
+// KONFIGURASI KOMITE (Admin)
+// ======================================================
+
+type CommitteeService struct {
+	Repo repository.CommitteePostgresRepository
+	// ReviewerRepo dipakai untuk memastikan anggota adalah dosen wali/admin aktif
+	ReviewerRepo repository.ReviewClaimPostgresRepository
+}
+
+func NewCommitteeService(
+	repo repository.CommitteePostgresRepository,
+	reviewerRepo repository.ReviewClaimPostgresRepository,
+) *CommitteeService {
+	return &CommitteeService{
+		Repo:         repo,
+		ReviewerRepo: reviewerRepo,
+	}
+}
+
+type committeeRequest struct {
+	Name      string               `json:"name"`
+	AppliesTo model.PointCondition `json:"applies_to"`
+	Members   []string             `json:"members"`
+	Quorum    int                  `json:"quorum"`
+	Majority  string               `json:"majority"`
+	Active    *bool                `json:"active"`
+}
+
+func (s *CommitteeService) validate(req *committeeRequest) []FieldError {
+	var errs []FieldError
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		errs = append(errs, FieldError{"name", "wajib diisi"})
+	}
+	// tanpa tipe, komite akan mengambil alih semua prestasi
+	if len(req.AppliesTo.Types) == 0 {
+		errs = append(errs, FieldError{"applies_to.types", "minimal satu tipe prestasi"})
+	}
+
+	if len(req.Members) == 0 {
+		errs = append(errs, FieldError{"members", "minimal satu anggota"})
+	}
+	seen := map[string]bool{}
+	for i, userID := range req.Members {
+		field := fmt.Sprintf("members[%d]", i)
+		if seen[userID] {
+			errs = append(errs, FieldError{field, "anggota duplikat"})
+			continue
+		}
+		seen[userID] = true
+
+		if s.ReviewerRepo == nil {
+			continue
+		}
+		rv, err := s.ReviewerRepo.GetReviewer(userID)
+		if err != nil || !isReviewerRole(rv.Role) {
+			errs = append(errs, FieldError{field, "harus dosen wali atau admin aktif"})
+		}
+	}
+
+	if req.Quorum < 1 || req.Quorum > len(req.Members) {
+		errs = append(errs, FieldError{"quorum", fmt.Sprintf("harus 1-%d (jumlah anggota)", len(req.Members))})
+	}
+	if req.Majority == "" {
+		req.Majority = model.MajoritySimple
+	}
+	if !contains(model.MajorityRules, req.Majority) {
+		errs = append(errs, FieldError{"majority", "harus salah satu dari: " + strings.Join(model.MajorityRules, ", ")})
+	}
+	return errs
+}
+
+func (s *CommitteeService) List(c *fiber.Ctx) error {
+	list, err := s.Repo.List(c.QueryBool("active_only", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"data": list})
+}
+
+func (s *CommitteeService) Create(c *fiber.Ctx) error {
+	var req committeeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if errs := s.validate(&req); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	now := time.Now()
+	userID, _ := c.Locals("user_id").(string)
+	committee := &model.Committee{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		AppliesTo: req.AppliesTo,
+		Members:   req.Members,
+		Quorum:    req.Quorum,
+		Majority:  req.Majority,
+		Active:    req.Active == nil || *req.Active,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.Repo.Create(committee); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(committee)
+}
+
+// Update: anggota yang dikeluarkan tidak lagi dihitung pada voting berjalan.
+func (s *CommitteeService) Update(c *fiber.Ctx) error {
+	committee, err := s.Repo.Get(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "committee not found"})
+	}
+
+	var req committeeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+	if errs := s.validate(&req); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	committee.Name = req.Name
+	committee.AppliesTo = req.AppliesTo
+	committee.Members = req.Members
+	committee.Quorum = req.Quorum
+	committee.Majority = req.Majority
+	if req.Active != nil {
+		committee.Active = *req.Active
+	}
+	committee.UpdatedAt = time.Now()
+
+	if err := s.Repo.Update(committee); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(committee)
+}
+
+// Delete menonaktifkan komite; catatan suara yang sudah ada tetap tersimpan.
+func (s *CommitteeService) Delete(c *fiber.Ctx) error {
+	committee, err := s.Repo.Get(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "committee not found"})
+	}
+
+	committee.Active = false
+	committee.UpdatedAt = time.Now()
+	if err := s.Repo.Update(committee); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Committee deactivated"})
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCKS =================
+
+type MockCommitteeRepo struct {
+	committees []model.Committee
+	votes      []model.CommitteeVote
+}
+
+func (m *MockCommitteeRepo) List(activeOnly bool) ([]model.Committee, error) {
+	var list []model.Committee
+	for _, c := range m.committees {
+		if c.Active || !activeOnly {
+			list = append(list, c)
+		}
+	}
+	return list, nil
+}
+func (m *MockCommitteeRepo) Get(id string) (*model.Committee, error) {
+	for i := range m.committees {
+		if m.committees[i].ID == id {
+			c := m.committees[i]
+			return &c, nil
+		}
+	}
+	return nil, errors.New("committee not found")
+}
+func (m *MockCommitteeRepo) Create(c *model.Committee) error {
+	m.committees = append(m.committees, *c)
+	return nil
+}
+func (m *MockCommitteeRepo) Update(c *model.Committee) error {
+	for i := range m.committees {
+		if m.committees[i].ID == c.ID {
+			m.committees[i] = *c
+		}
+	}
+	return nil
+}
+func (m *MockCommitteeRepo) CastVote(v *model.CommitteeVote) error {
+	for i := range m.votes {
+		if m.votes[i].ReferenceID == v.ReferenceID && m.votes[i].VoterID == v.VoterID {
+			m.votes[i] = *v
+			return nil
+		}
+	}
+	m.votes = append(m.votes, *v)
+	return nil
+}
+func (m *MockCommitteeRepo) Votes(refID string) ([]model.CommitteeVote, error) {
+	var list []model.CommitteeVote
+	for _, v := range m.votes {
+		if v.ReferenceID == refID {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+// komite lomba nasional (MockAchievementMongoRepo: competition/national)
+func nationalCommittee() model.Committee {
+	return model.Committee{
+		ID:        "committee-1",
+		Name:      "Komite Lomba",
+		AppliesTo: model.PointCondition{Types: []string{"competition"}, Levels: []string{"national"}},
+		Members:   []string{"member-1", "member-2", "member-3"},
+		Quorum:    2,
+		Majority:  model.MajoritySimple,
+		Active:    true,
+	}
+}
+
+func committeeService() (*AchievementService, *MockAppealPostgresRepo, *MockCommitteeRepo) {
+	pg := newMockAppealPostgresRepo(time.Now())
+	pg.ref.Status = "submitted"
+	committees := &MockCommitteeRepo{committees: []model.Committee{nationalCommittee()}}
+	return &AchievementService{
+		MongoRepo:     &MockAchievementMongoRepo{},
+		PostgresRepo:  pg,
+		StudentRepo:   &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+		CommitteeRepo: committees,
+	}, pg, committees
+}
+
+func voteApp(svc *AchievementService) *fiber.App {
+	app := fiber.New()
+	app.Post("/achievements/:refId/votes", func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", "lect-1")
+		c.Locals("user_id", utils.CopyString(c.Get("X-User")))
+		return svc.Vote(c)
+	})
+	return app
+}
+
+// ================= UNIT TESTS =================
+
+func TestTallyVotes(t *testing.T) {
+	committee := nationalCommittee()
+	vote := func(voter, v string) model.CommitteeVote {
+		return model.CommitteeVote{VoterID: voter, Vote: v}
+	}
+
+	// belum kuorum
+	assert.Equal(t, "", tallyVotes(&committee, []model.CommitteeVote{vote("member-1", "approve")}).Outcome)
+	// suara bukan anggota tidak dihitung
+	assert.Equal(t, "", tallyVotes(&committee, []model.CommitteeVote{
+		vote("member-1", "approve"), vote("outsider", "approve"),
+	}).Outcome)
+	// 1-1: belum ada mayoritas, anggota ketiga belum memilih
+	assert.Equal(t, "", tallyVotes(&committee, []model.CommitteeVote{
+		vote("member-1", "approve"), vote("member-2", "reject"),
+	}).Outcome)
+	assert.Equal(t, "verified", tallyVotes(&committee, []model.CommitteeVote{
+		vote("member-1", "approve"), vote("member-2", "reject"), vote("member-3", "approve"),
+	}).Outcome)
+
+	committee.Majority = model.MajorityUnanimous
+	tally := tallyVotes(&committee, []model.CommitteeVote{
+		vote("member-1", "approve"), vote("member-2", "approve"), vote("member-3", "reject"),
+	})
+	assert.Equal(t, model.VoteTally{Approve: 2, Reject: 1, Members: 3, Quorum: 2, Majority: "unanimous", Outcome: "rejected"}, tally)
+
+	committee.Majority = model.MajorityTwoThirds
+	assert.Equal(t, "verified", tallyVotes(&committee, []model.CommitteeVote{
+		vote("member-1", "approve"), vote("member-2", "approve"), vote("member-3", "reject"),
+	}).Outcome)
+}
+
+func TestCommitteeVote_QuorumVerifies(t *testing.T) {
+	svc, pg, committees := committeeService()
+	app := voteApp(svc)
+
+	// verifikasi satu reviewer tidak berlaku untuk prestasi komite
+	err := svc.verifyReference(reviewActor{UserID: "lect-user", LecturerID: "lect-1", Role: "Dosen Wali"}, "ref-1", "")
+	assert.Equal(t, 409, err.(*reviewError).Status)
+
+	resp, _ := commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "outsider", map[string]interface{}{"vote": "approve"})
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp, out := commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "member-1", map[string]interface{}{"vote": "reject"})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Len(t, out["fields"], 1)
+
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "member-1", map[string]interface{}{"vote": "approve", "comment": "valid"})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "submitted", out["status"])
+
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "member-2", map[string]interface{}{"vote": "approve"})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "verified", out["status"])
+	assert.Equal(t, "verified", pg.ref.Status)
+	assert.Equal(t, "member-2", *pg.ref.VerifiedBy)
+
+	// catatan suara tetap tersimpan, keputusan tercatat di history
+	assert.Len(t, committees.votes, 2)
+	assert.Len(t, pg.history, 1)
+	assert.Equal(t, "Committee", pg.history[0].ChangedByRole)
+	assert.Equal(t, "komite Komite Lomba: 2 setuju, 0 tolak", pg.history[0].Note)
+
+	resp, _ = commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "member-3", map[string]interface{}{"vote": "approve"})
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestCommitteeVote_MajorityRejects(t *testing.T) {
+	svc, pg, _ := committeeService()
+	app := voteApp(svc)
+
+	commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "member-1", map[string]interface{}{"vote": "reject", "comment": "bukan lomba nasional"})
+	resp, out := commentRequestAs(app, "POST", "/achievements/ref-1/votes", "", "member-2", map[string]interface{}{"vote": "reject", "comment": "penyelenggara tidak jelas"})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "rejected", out["status"])
+	assert.Equal(t, "rejected", pg.ref.Status)
+	assert.Equal(t, "komite Komite Lomba: 0 setuju, 2 tolak\nbukan lomba nasional\npenyelenggara tidak jelas", *pg.ref.RejectionNote)
+}
+
+func TestCommitteeVote_ConcurrentQuorumAppliesOnce(t *testing.T) {
+	svc, pg, _ := committeeService()
+	ledger := newMockLedgerRepo()
+	svc.LedgerRepo = ledger
+	committee := nationalCommittee()
+	votes := []model.CommitteeVote{
+		{ReferenceID: "ref-1", VoterID: "member-1", Vote: model.VoteApprove},
+		{ReferenceID: "ref-1", VoterID: "member-2", Vote: model.VoteApprove},
+		{ReferenceID: "ref-1", VoterID: "member-3", Vote: model.VoteApprove},
+	}
+
+	// dua suara sama-sama membaca status submitted lalu mencapai kuorum
+	stale, _ := pg.GetReferenceByID("ref-1")
+	for _, voter := range []string{"member-2", "member-3"} {
+		actor := reviewActor{UserID: voter, Role: "Dosen Wali"}
+		tally := tallyVotes(&committee, votes)
+		svc.applyCommitteeOutcome(actor, stale, &committee, tally, votes)
+	}
+
+	assert.Equal(t, "verified", pg.ref.Status)
+	assert.Equal(t, "member-2", *pg.ref.VerifiedBy)
+	assert.Len(t, pg.history, 1)
+	assert.Len(t, ledger.entries, 1)
+}
+
+func TestCommitteeService_Validation(t *testing.T) {
+	repo := &MockCommitteeRepo{}
+	svc := NewCommitteeService(repo, nil)
+	app := fiber.New()
+	app.Post("/committees", asAdmin(svc.Create))
+
+	resp, out := commentRequestAs(app, "POST", "/committees", "", "", map[string]interface{}{
+		"members": []string{"member-1", "member-1"}, "quorum": 3, "majority": "most",
+	})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "name", "message": "wajib diisi"},
+		map[string]interface{}{"field": "applies_to.types", "message": "minimal satu tipe prestasi"},
+		map[string]interface{}{"field": "members[1]", "message": "anggota duplikat"},
+		map[string]interface{}{"field": "quorum", "message": "harus 1-2 (jumlah anggota)"},
+		map[string]interface{}{"field": "majority", "message": "harus salah satu dari: simple, two_thirds, unanimous"},
+	}, out["fields"])
+
+	committee := nationalCommittee()
+	resp, out = commentRequestAs(app, "POST", "/committees", "", "", map[string]interface{}{
+		"name": committee.Name, "applies_to": committee.AppliesTo, "members": committee.Members, "quorum": 2,
+	})
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "simple", out["majority"])
+	assert.Equal(t, true, out["active"])
+	assert.Len(t, repo.committees, 1)
+}
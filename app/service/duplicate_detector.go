@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// detailFingerprintKinds: fingerprint yang dihitung dari isi prestasi dan
+// diganti setiap kali prestasi diubah. Fingerprint lampiran ditambah saat
+// upload dan dibuang saat lampirannya dihapus atau diganti.
+var detailFingerprintKinds = []string{
+	model.FingerprintCertificationNumber,
+	model.FingerprintCompetition,
+	model.FingerprintPublication,
+}
+
+// normalizeCode: huruf besar, hanya huruf dan angka ("ab-123 / x" → "AB123X").
+func normalizeCode(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// normalizeText: huruf kecil, tanda baca dibuang, spasi dirapatkan.
+func normalizeText(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// achievementFingerprints menghitung fingerprint detail sebuah prestasi.
+func achievementFingerprints(refID, studentID string, a *model.AchievementMongo, now time.Time) []model.AchievementFingerprint {
+	d := a.Details
+	var fps []model.AchievementFingerprint
+
+	add := func(kind, value string) {
+		fps = append(fps, model.AchievementFingerprint{
+			ReferenceID: refID,
+			StudentID:   studentID,
+			Kind:        kind,
+			Value:       value,
+			CreatedAt:   now,
+		})
+	}
+
+	if v := normalizeCode(d.CertificationNumber); v != "" {
+		add(model.FingerprintCertificationNumber, v)
+	}
+	if name := normalizeText(d.CompetitionName); name != "" && d.EventDate != nil && d.Rank > 0 {
+		add(model.FingerprintCompetition, fmt.Sprintf("%s|%s|%d", name, d.EventDate.Format("2006-01-02"), d.Rank))
+	}
+	if issn, title := normalizeCode(d.ISSN), normalizeText(d.PublicationTitle); issn != "" && title != "" {
+		add(model.FingerprintPublication, issn+"|"+title)
+	}
+
+	return fps
+}
+
+// ======================================================
+// DUPLICATE DETECTION (AchievementService)
+// ======================================================
+
+// indexFingerprints menyimpan fingerprint detail prestasi lalu mengembalikan
+// prestasi lain yang cocok. Tanpa FingerprintRepo deteksi dilewati.
+func (s *AchievementService) indexFingerprints(ref *model.AchievementReference, a *model.AchievementMongo) []model.DuplicateMatch {
+	if s.FingerprintRepo == nil {
+		return nil
+	}
+
+	fps := achievementFingerprints(ref.ID, ref.StudentID, a, time.Now())
+	if err := s.FingerprintRepo.ReplaceFingerprints(ref.ID, detailFingerprintKinds, fps); err != nil {
+		log.Printf("gagal menyimpan fingerprint %s: %v", ref.ID, err)
+		return nil
+	}
+
+	return s.findDuplicates(ref.ID, fps)
+}
+
+// indexAttachmentHash menyimpan hash lampiran (Attachment.SHA256, dihitung
+// saat disimpan) lalu mengembalikan prestasi lain yang cocok.
+func (s *AchievementService) indexAttachmentHash(ref *model.AchievementReference, sum string) []model.DuplicateMatch {
+	if s.FingerprintRepo == nil {
+		return nil
+	}
+
+	fps := []model.AchievementFingerprint{{
+		ReferenceID: ref.ID,
+		StudentID:   ref.StudentID,
+		Kind:        model.FingerprintAttachment,
+		Value:       sum,
+		CreatedAt:   time.Now(),
+	}}
+	if err := s.FingerprintRepo.AddFingerprints(fps); err != nil {
+		log.Printf("gagal menyimpan fingerprint lampiran %s: %v", ref.ID, err)
+		return nil
+	}
+
+	return s.findDuplicates(ref.ID, fps)
+}
+
+// dropAttachmentFingerprint: lampiran ke-i dihapus/diganti sehingga isinya
+// tidak lagi menandai prestasi ini sebagai duplikat, kecuali lampiran aktif
+// lain berisi file yang sama. Lampiran lama tanpa SHA256 di-hash dari storage.
+func (s *AchievementService) dropAttachmentFingerprint(ctx context.Context, ref *model.AchievementReference, ach *model.AchievementMongo, i int) {
+	if s.FingerprintRepo == nil {
+		return
+	}
+	att := &ach.Attachments[i]
+	sum := att.SHA256
+	if sum == "" {
+		store, key := s.attachmentSource(att)
+		var err error
+		if sum, err = hashObject(ctx, store, key); err != nil {
+			log.Printf("gagal menghitung hash lampiran %s: %v", ref.ID, err)
+			return
+		}
+	}
+	for j := range ach.Attachments {
+		if j != i && ach.Attachments[j].DeletedAt == nil && ach.Attachments[j].SHA256 == sum {
+			return
+		}
+	}
+	if err := s.FingerprintRepo.RemoveFingerprint(ref.ID, model.FingerprintAttachment, sum); err != nil {
+		log.Printf("gagal menghapus fingerprint lampiran %s: %v", ref.ID, err)
+	}
+}
+
+func (s *AchievementService) findDuplicates(refID string, fps []model.AchievementFingerprint) []model.DuplicateMatch {
+	if len(fps) == 0 {
+		return nil
+	}
+	matches, err := s.FingerprintRepo.FindMatches(refID, fps)
+	if err != nil {
+		log.Printf("gagal mencari duplikat %s: %v", refID, err)
+		return nil
+	}
+	return matches
+}
+
+// storedDuplicates: duplikat dari fingerprint yang sudah tersimpan.
+func (s *AchievementService) storedDuplicates(refIDs []string) map[string][]model.DuplicateMatch {
+	if s.FingerprintRepo == nil || len(refIDs) == 0 {
+		return map[string][]model.DuplicateMatch{}
+	}
+	result, err := s.FingerprintRepo.MatchesForReferences(refIDs)
+	if err != nil {
+		log.Printf("gagal membaca duplikat: %v", err)
+		return map[string][]model.DuplicateMatch{}
+	}
+	return result
+}
+
+var duplicateKindLabels = map[string]string{
+	model.FingerprintCertificationNumber: "nomor sertifikat",
+	model.FingerprintCompetition:         "nama lomba, tanggal dan peringkat",
+	model.FingerprintPublication:         "ISSN dan judul publikasi",
+	model.FingerprintAttachment:          "file lampiran",
+}
+
+// duplicateWarnings: peringatan untuk mahasiswa. Prestasi milik mahasiswa
+// lain tidak ditampilkan id-nya.
+func duplicateWarnings(ownerID string, matches []model.DuplicateMatch) []fiber.Map {
+	warnings := []fiber.Map{}
+	for _, m := range matches {
+		w := fiber.Map{"kind": m.Kind}
+		if m.MatchStudentID == ownerID {
+			w["same_student"] = true
+			w["reference_id"] = m.MatchReferenceID
+			w["message"] = "Anda sudah pernah mencatat prestasi dengan " + duplicateKindLabels[m.Kind] + " yang sama"
+		} else {
+			w["same_student"] = false
+			w["message"] = duplicateKindLabels[m.Kind] + " yang sama sudah diklaim mahasiswa lain"
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// duplicateLinks: tampilan reviewer (dosen wali/admin) lengkap dengan tautan.
+func duplicateLinks(matches []model.DuplicateMatch) []fiber.Map {
+	links := []fiber.Map{}
+	for _, m := range matches {
+		links = append(links, fiber.Map{
+			"kind":         m.Kind,
+			"reference_id": m.MatchReferenceID,
+			"student_id":   m.MatchStudentID,
+			"status":       m.MatchStatus,
+			"link":         "/api/v1/achievements/" + m.MatchReferenceID,
+		})
+	}
+	return links
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCK FINGERPRINT REPOSITORY =================
+
+// satu prestasi lain (milik student-9) dengan nomor sertifikat yang sama
+type MockFingerprintRepo struct {
+	stored []model.AchievementFingerprint
+}
+
+func newMockFingerprintRepo() *MockFingerprintRepo {
+	return &MockFingerprintRepo{stored: []model.AchievementFingerprint{{
+		ReferenceID: "ref-other",
+		StudentID:   "student-9",
+		Kind:        model.FingerprintCertificationNumber,
+		Value:       "BNSP1234",
+	}}}
+}
+
+func (m *MockFingerprintRepo) ReplaceFingerprints(refID string, kinds []string, fps []model.AchievementFingerprint) error {
+	return m.AddFingerprints(fps)
+}
+func (m *MockFingerprintRepo) AddFingerprints(fps []model.AchievementFingerprint) error {
+	m.stored = append(m.stored, fps...)
+	return nil
+}
+func (m *MockFingerprintRepo) RemoveFingerprint(refID, kind, value string) error {
+	kept := m.stored[:0]
+	for _, fp := range m.stored {
+		if fp.ReferenceID != refID || fp.Kind != kind || fp.Value != value {
+			kept = append(kept, fp)
+		}
+	}
+	m.stored = kept
+	return nil
+}
+func (m *MockFingerprintRepo) FindMatches(refID string, fps []model.AchievementFingerprint) ([]model.DuplicateMatch, error) {
+	var matches []model.DuplicateMatch
+	for _, fp := range fps {
+		for _, s := range m.stored {
+			if s.ReferenceID != refID && s.Kind == fp.Kind && s.Value == fp.Value {
+				matches = append(matches, model.DuplicateMatch{
+					ReferenceID: refID, MatchReferenceID: s.ReferenceID,
+					MatchStudentID: s.StudentID, MatchStatus: "verified", Kind: s.Kind,
+				})
+			}
+		}
+	}
+	return matches, nil
+}
+func (m *MockFingerprintRepo) MatchesForReferences(refIDs []string) (map[string][]model.DuplicateMatch, error) {
+	result := map[string][]model.DuplicateMatch{}
+	for _, id := range refIDs {
+		result[id] = []model.DuplicateMatch{{
+			ReferenceID: id, MatchReferenceID: "ref-other",
+			MatchStudentID: "student-9", MatchStatus: "verified",
+			Kind: model.FingerprintCertificationNumber,
+		}}
+	}
+	return result, nil
+}
+
+// ================= UNIT TESTS =================
+
+func TestAchievementFingerprints_Normalized(t *testing.T) {
+	date := time.Date(2024, 10, 5, 9, 0, 0, 0, time.UTC)
+	a := &model.AchievementMongo{
+		Details: model.AchievementDetails{
+			CertificationNumber: " bnsp-1234 ",
+			CompetitionName:     "GEMASTIK  XVII,",
+			EventDate:           &date,
+			Rank:                2,
+			ISSN:                "1234-567x",
+			PublicationTitle:    "Deep Learning: A Survey",
+		},
+	}
+
+	values := map[string]string{}
+	for _, fp := range achievementFingerprints("ref-1", "student-1", a, date) {
+		values[fp.Kind] = fp.Value
+	}
+
+	assert.Equal(t, map[string]string{
+		model.FingerprintCertificationNumber: "BNSP1234",
+		model.FingerprintCompetition:         "gemastik xvii|2024-10-05|2",
+		model.FingerprintPublication:         "1234567X|deep learning a survey",
+	}, values)
+}
+
+func TestCreateAchievement_DuplicateCertificate_ReturnsWarning(t *testing.T) {
+	svc, app := setupAchievementService()
+	svc.FingerprintRepo = newMockFingerprintRepo()
+
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Create(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"achievementType": "certification",
+		"title":           "Sertifikasi Junior Web Developer",
+		"details": map[string]interface{}{
+			"certificationName":   "Junior Web Developer",
+			"issuedBy":            "BNSP",
+			"certificationNumber": "bnsp 1234",
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	var out struct {
+		Duplicates []map[string]interface{} `json:"duplicates"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Len(t, out.Duplicates, 1)
+	assert.Equal(t, false, out.Duplicates[0]["same_student"])
+	assert.NotContains(t, out.Duplicates[0], "reference_id") // milik mahasiswa lain
+}
+
+func TestDetailAchievement_Advisor_SeesDuplicateLinks(t *testing.T) {
+	svc, app := setupAchievementService()
+	svc.FingerprintRepo = newMockFingerprintRepo()
+
+	app.Get("/achievements/:refId", func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", "lect-1")
+		return svc.Detail(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/achievements/ref-123", nil))
+
+	var out struct {
+		PossibleDuplicate bool                     `json:"possible_duplicate"`
+		Duplicates        []map[string]interface{} `json:"duplicates"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.True(t, out.PossibleDuplicate)
+	assert.Equal(t, "/api/v1/achievements/ref-other", out.Duplicates[0]["link"])
+}
+
+func (m *MockFingerprintRepo) attachmentHashes(refID string) []string {
+	var sums []string
+	for _, fp := range m.stored {
+		if fp.ReferenceID == refID && fp.Kind == model.FingerprintAttachment {
+			sums = append(sums, fp.Value)
+		}
+	}
+	return sums
+}
+
+func TestAttachmentFingerprint_FollowsReplaceAndDelete(t *testing.T) {
+	f := newVersioningFixture(t, "draft")
+	fps := newMockFingerprintRepo()
+	f.svc.FingerprintRepo = fps
+	path := "/achievements/ref-1/attachments/att-1"
+
+	// lampiran lama tanpa SHA256: hash dihitung dari storage
+	oldSum := contentHash([]byte(pdfBytes + " v1"))
+	fps.AddFingerprints([]model.AchievementFingerprint{{ReferenceID: "ref-1", StudentID: "student-1", Kind: model.FingerprintAttachment, Value: oldSum}})
+
+	status, _ := f.do("PUT", path, "Mahasiswa", pdfBytes+" v2")
+	assert.Equal(t, 200, status)
+	newSum := f.mongo.ach.Attachments[0].SHA256
+	assert.Equal(t, contentHash([]byte(pdfBytes+" v2")), newSum)
+	assert.Equal(t, []string{newSum}, fps.attachmentHashes("ref-1"))
+
+	status, _ = f.do("DELETE", path, "Mahasiswa", "")
+	assert.Equal(t, 200, status)
+	assert.Empty(t, fps.attachmentHashes("ref-1"))
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidLink = errors.New("tautan tidak valid")
+	errExpiredLink = errors.New("tautan sudah kedaluwarsa")
+)
+
+// LinkSigner membuat token tautan publik: base64url(id.expires) + "." +
+// base64url(HMAC-SHA256). Token tidak menyimpan rahasia apa pun; sekali
+// pakai/dicabut ditentukan oleh status di database.
+type LinkSigner struct {
+	Secret []byte
+}
+
+// LinkSignerFromEnv memakai LINK_SIGNING_SECRET. Bila kosong dibuat secret
+// acak, artinya tautan lama tidak berlaku lagi setelah server restart.
+func LinkSignerFromEnv() LinkSigner {
+	if secret := os.Getenv("LINK_SIGNING_SECRET"); secret != "" {
+		return LinkSigner{Secret: []byte(secret)}
+	}
+	log.Printf("LINK_SIGNING_SECRET kosong, memakai secret acak (tautan tidak bertahan setelah restart)")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatal(err)
+	}
+	return LinkSigner{Secret: secret}
+}
+
+func (s LinkSigner) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.Secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func (s LinkSigner) Sign(id string, expiresAt time.Time) string {
+	payload := id + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+// Verify mengembalikan id bila tanda tangan cocok dan belum kedaluwarsa.
+func (s LinkSigner) Verify(token string, now time.Time) (string, error) {
+	encPayload, encSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", errInvalidLink
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return "", errInvalidLink
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encSig)
+	if err != nil || !hmac.Equal(sig, s.mac(string(payload))) {
+		return "", errInvalidLink
+	}
+
+	id, exp, ok := strings.Cut(string(payload), ".")
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if !ok || err != nil {
+		return "", errInvalidLink
+	}
+	if !now.Before(time.Unix(unix, 0)) {
+		return "", errExpiredLink
+	}
+	return id, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mail: email teks sederhana.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer mengirim email ke pihak luar (mis. penyelenggara lomba).
+type Mailer interface {
+	Send(m Mail) error
+}
+
+// FileMailer: mailer untuk development, setiap email ditulis sebagai file
+// .eml di Dir sehingga tautan bisa dibuka tanpa server SMTP.
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (f FileMailer) Send(m Mail) error {
+	if err := os.MkdirAll(f.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	name := time.Now().Format("20060102-150405") + "-" + uuid.New().String()[:8] + ".eml"
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(formatMail(f.From, m)), 0o644)
+}
+
+// SMTPMailer mengirim lewat server SMTP (PLAIN auth bila Username diisi).
+type SMTPMailer struct {
+	Addr     string // host:port
+	Username string
+	Password string
+	From     string
+}
+
+func (s SMTPMailer) Send(m Mail) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host := strings.Split(s.Addr, ":")[0]
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	return smtp.SendMail(s.Addr, auth, s.From, []string{m.To}, []byte(formatMail(s.From, m)))
+}
+
+// formatMail: nilai header bisa berasal dari input mahasiswa (mis. nama
+// lomba di subject), jadi CR/LF dibuang supaya tidak bisa menyisipkan header
+// lain, dan subject di-encode (RFC 2047) untuk karakter non-ASCII.
+func formatMail(from string, m Mail) string {
+	return fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		headerValue(from), headerValue(m.To), mime.QEncoding.Encode("UTF-8", headerValue(m.Subject)),
+		time.Now().Format(time.RFC1123Z), m.Body)
+}
+
+// headerValue mengganti CR/LF dengan spasi.
+func headerValue(v string) string {
+	return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(v)
+}
+
+// MailerFromEnv memilih mailer dari environment:
+//   - MAILER=file (default): tulis ke MAIL_OUTBOX_DIR (default ./mail_outbox)
+//   - MAILER=smtp: SMTP_HOST, SMTP_PORT (default 587), SMTP_USER, SMTP_PASSWORD
+//   - MAIL_FROM (default no-reply@localhost)
+func MailerFromEnv() Mailer {
+	from := envOr("MAIL_FROM", "no-reply@localhost")
+
+	if os.Getenv("MAILER") == "smtp" {
+		return SMTPMailer{
+			Addr:     envOr("SMTP_HOST", "localhost") + ":" + envOr("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}
+	}
+
+	dir := envOr("MAIL_OUTBOX_DIR", filepath.Join(".", "mail_outbox"))
+	log.Printf("mailer: email ditulis ke %s (MAILER=file)", dir)
+	return FileMailer{Dir: dir, From: from}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultOrganizerLinkTTL = 7 * 24 * time.Hour
+	maxOrganizerLinkTTL     = 30 * 24 * time.Hour
+)
+
+// OrganizerVerificationService: permintaan konfirmasi ke penyelenggara lomba.
+// Otorisasi reviewer memakai aturan AchievementService (bimbingan/delegasi).
+type OrganizerVerificationService struct {
+	Repo         repository.OrganizerVerificationPostgresRepository
+	Achievements *AchievementService
+	Mailer       Mailer
+	Signer       LinkSigner
+	// BaseURL: alamat publik API, dipakai membentuk tautan di email
+	BaseURL string
+}
+
+const defaultPublicBaseURL = "http://localhost:3000"
+
+func NewOrganizerVerificationService(
+	repo repository.OrganizerVerificationPostgresRepository,
+	achievements *AchievementService,
+	mailer Mailer,
+	signer LinkSigner,
+	baseURL string,
+) *OrganizerVerificationService {
+	if baseURL == "" {
+		baseURL = defaultPublicBaseURL
+	}
+	return &OrganizerVerificationService{
+		Repo:         repo,
+		Achievements: achievements,
+		Mailer:       mailer,
+		Signer:       signer,
+		BaseURL:      strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *OrganizerVerificationService) link(token string) string {
+	return s.BaseURL + "/api/v1/public/organizer-verifications/" + token
+}
+
+func organizerMail(v *model.OrganizerVerification, ach *model.AchievementMongo, link string) Mail {
+	greeting := "Bapak/Ibu"
+	if v.OrganizerName != "" {
+		greeting = v.OrganizerName
+	}
+	claim := fmt.Sprintf("%s (%s)", ach.Details.CompetitionName, ach.Details.CompetitionLevel)
+	if v.ClaimedRank > 0 {
+		claim += fmt.Sprintf(", peringkat %d", v.ClaimedRank)
+	}
+
+	return Mail{
+		To:      v.OrganizerEmail,
+		Subject: "Permintaan konfirmasi peserta: " + ach.Details.CompetitionName,
+		Body: fmt.Sprintf(`Yth. %s,
+
+Seorang mahasiswa melaporkan prestasi "%s" pada %s.
+Mohon konfirmasi apakah mahasiswa tersebut benar mengikuti kegiatan ini
+melalui tautan berikut (tanpa login, hanya bisa dipakai sekali):
+
+%s
+
+Tautan berlaku sampai %s.
+`, greeting, ach.Title, claim, link, v.ExpiresAt.Format("02 Jan 2006 15:04 MST")),
+	}
+}
+
+// ======================================================
+// PERMINTAAN VERIFIKASI (Dosen Wali & Admin)
+// ======================================================
+
+func (s *OrganizerVerificationService) Request(c *fiber.Ctx) error {
+	var body struct {
+		OrganizerEmail string `json:"organizer_email"`
+		OrganizerName  string `json:"organizer_name"`
+		ExpiresInHours int    `json:"expires_in_hours"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	var errs []FieldError
+	addr, err := mail.ParseAddress(strings.TrimSpace(body.OrganizerEmail))
+	if err != nil {
+		errs = append(errs, FieldError{"organizer_email", "email tidak valid"})
+	}
+	ttl, ok := leaseFrom(body.ExpiresInHours*60, defaultOrganizerLinkTTL, maxOrganizerLinkTTL)
+	if !ok {
+		errs = append(errs, FieldError{"expires_in_hours", "harus 1-" + strconv.Itoa(int(maxOrganizerLinkTTL.Hours()))})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	ach := s.Achievements
+	ref, err := ach.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	actor := reviewActorFrom(c)
+	if _, err := ach.authorizeReview(actor, ref); err != nil {
+		return respondReviewError(c, err)
+	}
+	if ref.Status != "submitted" && ref.Status != "verified" {
+		return c.Status(400).JSON(fiber.Map{"error": "hanya prestasi submitted atau verified yang bisa dikonfirmasi"})
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	achievement, err := ach.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+	}
+	if achievement.AchievementType != "competition" {
+		return c.Status(400).JSON(fiber.Map{"error": "verifikasi penyelenggara hanya untuk prestasi lomba"})
+	}
+
+	now := time.Now()
+	existing, err := s.Repo.ListByReference(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, v := range existing {
+		if v.CurrentStatus(now) == model.OrganizerPending {
+			return c.Status(409).JSON(fiber.Map{"error": "masih ada permintaan yang menunggu jawaban penyelenggara"})
+		}
+	}
+
+	v := &model.OrganizerVerification{
+		ID:             uuid.New().String(),
+		ReferenceID:    ref.ID,
+		OrganizerEmail: addr.Address,
+		OrganizerName:  strings.TrimSpace(body.OrganizerName),
+		ClaimedRank:    achievement.Details.Rank,
+		RequestedBy:    actor.UserID,
+		Status:         model.OrganizerPending,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+	}
+	if err := s.Repo.Create(v); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	token := s.Signer.Sign(v.ID, v.ExpiresAt)
+	if err := s.Mailer.Send(organizerMail(v, achievement, s.link(token))); err != nil {
+		s.Repo.SetStatus(v.ID, model.OrganizerFailed)
+		return c.Status(502).JSON(fiber.Map{"error": "gagal mengirim email ke penyelenggara: " + err.Error()})
+	}
+
+	return c.Status(201).JSON(v)
+}
+
+func (s *OrganizerVerificationService) List(c *fiber.Ctx) error {
+	ref, err := s.Achievements.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	if err := s.Achievements.authorizeView(c, ref); err != nil {
+		return respondReviewError(c, err)
+	}
+
+	list, err := s.Repo.ListByReference(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	now := time.Now()
+	for i := range list {
+		list[i].Status = list[i].CurrentStatus(now)
+	}
+	return c.JSON(fiber.Map{"data": list})
+}
+
+// ======================================================
+// HALAMAN PUBLIK PENYELENGGARA (tanpa login)
+// ======================================================
+
+// pendingFromToken: permintaan pending dari token yang valid. Tautan yang
+// sudah dipakai atau kedaluwarsa → 410.
+func (s *OrganizerVerificationService) pendingFromToken(token string, now time.Time) (*model.OrganizerVerification, error) {
+	id, err := s.Signer.Verify(token, now)
+	if err == errExpiredLink {
+		return nil, &reviewError{410, err.Error()}
+	}
+	if err != nil {
+		return nil, &reviewError{404, err.Error()}
+	}
+
+	v, err := s.Repo.Get(id)
+	if err != nil {
+		return nil, &reviewError{404, errInvalidLink.Error()}
+	}
+	if v.CurrentStatus(now) != model.OrganizerPending {
+		return nil, &reviewError{410, "tautan sudah digunakan atau tidak berlaku"}
+	}
+	return v, nil
+}
+
+// Show: ringkasan klaim yang perlu dikonfirmasi penyelenggara. Hanya data
+// yang diperlukan untuk konfirmasi yang ditampilkan.
+func (s *OrganizerVerificationService) Show(c *fiber.Ctx) error {
+	v, err := s.pendingFromToken(c.Params("token"), time.Now())
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+
+	ach := s.Achievements
+	ref, err := ach.PostgresRepo.GetReferenceByID(v.ReferenceID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	achievement, err := ach.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+	}
+
+	nim := ""
+	if student, err := ach.StudentRepo.GetByID(ref.StudentID); err == nil {
+		nim = student.StudentID
+	}
+
+	d := achievement.Details
+	return c.JSON(fiber.Map{
+		"organizer_name": v.OrganizerName,
+		"expires_at":     v.ExpiresAt,
+		"student_nim":    nim,
+		"claim": fiber.Map{
+			"title":             achievement.Title,
+			"competition_name":  d.CompetitionName,
+			"competition_level": d.CompetitionLevel,
+			"organizer":         d.Organizer,
+			"event_date":        d.EventDate,
+			"rank":              d.Rank,
+			"medal_type":        d.MedalType,
+		},
+	})
+}
+
+// Respond: penyelenggara membenarkan/menyangkal partisipasi dan peringkat.
+// Jawaban dicatat di history prestasi (status tidak berubah).
+func (s *OrganizerVerificationService) Respond(c *fiber.Ctx) error {
+	now := time.Now()
+	v, err := s.pendingFromToken(c.Params("token"), now)
+	if err != nil {
+		return respondReviewError(c, err)
+	}
+
+	var body struct {
+		Participated  *bool  `json:"participated"`
+		Rank          *int   `json:"rank"`
+		Note          string `json:"note"`
+		ResponderName string `json:"responder_name"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+
+	var errs []FieldError
+	if body.Participated == nil {
+		errs = append(errs, FieldError{"participated", "wajib diisi"})
+	}
+	if body.Rank != nil && *body.Rank < 1 {
+		errs = append(errs, FieldError{"rank", "minimal 1"})
+	}
+	body.ResponderName = strings.TrimSpace(body.ResponderName)
+	if body.ResponderName == "" {
+		errs = append(errs, FieldError{"responder_name", "wajib diisi"})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	resp := model.OrganizerResponse{
+		Status:        model.OrganizerDenied,
+		Note:          strings.TrimSpace(body.Note),
+		ResponderName: body.ResponderName,
+		ResponderIP:   c.IP(),
+		RespondedAt:   now,
+	}
+	if *body.Participated {
+		resp.Status = model.OrganizerConfirmed
+		resp.ConfirmedRank = body.Rank
+	}
+
+	ok, err := s.Repo.Respond(v.ID, resp)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(410).JSON(fiber.Map{"error": "tautan sudah digunakan atau tidak berlaku"})
+	}
+
+	// HISTORY: jawaban penyelenggara. changed_by diisi peminta karena
+	// penyelenggara bukan user aplikasi.
+	if ref, err := s.Achievements.PostgresRepo.GetReferenceByID(v.ReferenceID); err == nil {
+		s.Achievements.saveHistory(ref.ID, ref.Status, ref.Status, v.RequestedBy, "Organizer", organizerHistoryNote(v, resp))
+	}
+
+	return c.JSON(fiber.Map{"message": "Terima kasih, jawaban Anda sudah dicatat"})
+}
+
+func organizerHistoryNote(v *model.OrganizerVerification, r model.OrganizerResponse) string {
+	who := r.ResponderName + " <" + v.OrganizerEmail + ">"
+	if r.Status == model.OrganizerDenied {
+		note := "penyelenggara " + who + " menyangkal partisipasi"
+		if r.Note != "" {
+			note += ": " + r.Note
+		}
+		return note
+	}
+
+	note := "penyelenggara " + who + " mengonfirmasi partisipasi"
+	if r.ConfirmedRank != nil {
+		note += fmt.Sprintf(", peringkat %d", *r.ConfirmedRank)
+		if v.ClaimedRank > 0 && *r.ConfirmedRank != v.ClaimedRank {
+			note += fmt.Sprintf(" (berbeda dari klaim: %d)", v.ClaimedRank)
+		}
+	}
+	if r.Note != "" {
+		note += ": " + r.Note
+	}
+	return note
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCKS =================
+
+type MockOrganizerRepo struct {
+	requests []*model.OrganizerVerification
+}
+
+func (m *MockOrganizerRepo) Create(v *model.OrganizerVerification) error {
+	cp := *v
+	m.requests = append(m.requests, &cp)
+	return nil
+}
+func (m *MockOrganizerRepo) Get(id string) (*model.OrganizerVerification, error) {
+	for _, v := range m.requests {
+		if v.ID == id {
+			cp := *v
+			return &cp, nil
+		}
+	}
+	return nil, errors.New("organizer verification not found")
+}
+func (m *MockOrganizerRepo) ListByReference(refID string) ([]model.OrganizerVerification, error) {
+	var list []model.OrganizerVerification
+	for _, v := range m.requests {
+		if v.ReferenceID == refID {
+			list = append(list, *v)
+		}
+	}
+	return list, nil
+}
+func (m *MockOrganizerRepo) SetStatus(id, status string) error {
+	for _, v := range m.requests {
+		if v.ID == id {
+			v.Status = status
+		}
+	}
+	return nil
+}
+func (m *MockOrganizerRepo) Respond(id string, r model.OrganizerResponse) (bool, error) {
+	for _, v := range m.requests {
+		if v.ID == id && v.Status == model.OrganizerPending && r.RespondedAt.Before(v.ExpiresAt) {
+			v.Status, v.ConfirmedRank, v.ResponderName, v.RespondedAt = r.Status, r.ConfirmedRank, &r.ResponderName, &r.RespondedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type MockMailer struct {
+	sent []Mail
+	err  error
+}
+
+func (m *MockMailer) Send(mail Mail) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+func organizerService() (*OrganizerVerificationService, *MockOrganizerRepo, *MockMailer, *MockHistoryPostgresRepo) {
+	repo := &MockOrganizerRepo{}
+	mailer := &MockMailer{}
+	pg := &MockHistoryPostgresRepo{}
+	achievements := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: pg,
+		StudentRepo:  &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}},
+	}
+	svc := NewOrganizerVerificationService(repo, achievements, mailer, LinkSigner{Secret: []byte("test-secret")}, "https://prestasi.example.ac.id/")
+	return svc, repo, mailer, pg
+}
+
+func organizerApp(svc *OrganizerVerificationService) *fiber.App {
+	app := fiber.New()
+	reviewer := func(h fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			c.Locals("role", "Dosen Wali")
+			c.Locals("lecturer_id", "lect-1")
+			c.Locals("user_id", "lect-user-1")
+			return h(c)
+		}
+	}
+	app.Post("/achievements/:refId/organizer-verifications", reviewer(svc.Request))
+	app.Get("/public/organizer-verifications/:token", svc.Show)
+	app.Post("/public/organizer-verifications/:token", svc.Respond)
+	return app
+}
+
+var tokenPattern = regexp.MustCompile(`/api/v1/public/organizer-verifications/(\S+)`)
+
+// ================= UNIT TESTS =================
+
+func TestLinkSigner(t *testing.T) {
+	now := time.Now()
+	signer := LinkSigner{Secret: []byte("secret")}
+	token := signer.Sign("req-1", now.Add(time.Hour))
+
+	id, err := signer.Verify(token, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "req-1", id)
+
+	_, err = signer.Verify(token, now.Add(2*time.Hour))
+	assert.Equal(t, errExpiredLink, err)
+
+	_, err = LinkSigner{Secret: []byte("other")}.Verify(token, now)
+	assert.Equal(t, errInvalidLink, err)
+
+	// payload diubah → tanda tangan tidak cocok
+	forged := signer.Sign("req-2", now.Add(time.Hour))
+	_, err = signer.Verify(strings.Split(forged, ".")[0]+"."+strings.Split(token, ".")[1], now)
+	assert.Equal(t, errInvalidLink, err)
+
+	_, err = signer.Verify("garbage", now)
+	assert.Equal(t, errInvalidLink, err)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer := FileMailer{Dir: filepath.Join(dir, "outbox"), From: "no-reply@kampus.ac.id"}
+
+	assert.NoError(t, mailer.Send(Mail{To: "panitia@lomba.id", Subject: "Halo", Body: "tautan"}))
+
+	files, _ := os.ReadDir(mailer.Dir)
+	assert.Len(t, files, 1)
+	content, _ := os.ReadFile(filepath.Join(mailer.Dir, files[0].Name()))
+	assert.Contains(t, string(content), "To: panitia@lomba.id\r\n")
+	assert.Contains(t, string(content), "Subject: Halo\r\n")
+	assert.True(t, strings.HasSuffix(string(content), "tautan\r\n"))
+}
+
+func TestFormatMail_NewlineInCompetitionNameCannotInjectHeaders(t *testing.T) {
+	ach := &model.AchievementMongo{Title: "Juara 1"}
+	ach.Details.CompetitionName = "Dummy Cup\r\nBcc: korban@kampus.ac.id\nX-Evil: 1"
+	m := organizerMail(&model.OrganizerVerification{OrganizerEmail: "panitia@lomba.id", ExpiresAt: time.Now()}, ach, "https://x/link")
+
+	raw := formatMail("no-reply@kampus.ac.id", m)
+	headers := strings.SplitN(raw, "\r\n\r\n", 2)[0]
+
+	lines := strings.Split(headers, "\r\n")
+	assert.Len(t, lines, 5) // From, To, Subject, Date, Content-Type
+	for _, l := range lines {
+		assert.NotContains(t, l, "\n")
+		assert.False(t, strings.HasPrefix(l, "Bcc:") || strings.HasPrefix(l, "X-Evil:"), l)
+	}
+	assert.Contains(t, headers, "Subject: Permintaan konfirmasi peserta: Dummy Cup Bcc: korban@kampus.ac.id X-Evil: 1\r\n")
+}
+
+func TestOrganizerVerification_SingleUseLink(t *testing.T) {
+	svc, repo, mailer, pg := organizerService()
+	app := organizerApp(svc)
+
+	resp, out := commentRequestAs(app, "POST", "/achievements/ref-1/organizer-verifications", "", "",
+		map[string]interface{}{"organizer_email": "bukan email", "expires_in_hours": 10000})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Len(t, out["fields"], 2)
+
+	resp, out = commentRequestAs(app, "POST", "/achievements/ref-1/organizer-verifications", "", "",
+		map[string]interface{}{"organizer_email": "Panitia <panitia@lomba.id>", "organizer_name": "Panitia Dummy Cup"})
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Equal(t, "panitia@lomba.id", out["organizer_email"])
+	assert.Len(t, mailer.sent, 1)
+	assert.Equal(t, "panitia@lomba.id", mailer.sent[0].To)
+
+	// satu permintaan pending per prestasi
+	resp, _ = commentRequestAs(app, "POST", "/achievements/ref-1/organizer-verifications", "", "",
+		map[string]interface{}{"organizer_email": "panitia@lomba.id"})
+	assert.Equal(t, 409, resp.StatusCode)
+
+	match := tokenPattern.FindStringSubmatch(mailer.sent[0].Body)
+	assert.Len(t, match, 2)
+	assert.Contains(t, mailer.sent[0].Body, "https://prestasi.example.ac.id/api/v1/public/organizer-verifications/")
+	path := "/public/organizer-verifications/" + match[1]
+
+	resp, out = commentRequestAs(app, "GET", path, "", "", nil)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "Dummy Cup", out["claim"].(map[string]interface{})["competition_name"])
+
+	resp, out = commentRequestAs(app, "POST", path, "", "", map[string]interface{}{"rank": 0})
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Len(t, out["fields"], 3)
+
+	resp, _ = commentRequestAs(app, "POST", path, "", "",
+		map[string]interface{}{"participated": true, "rank": 2, "responder_name": "Budi"})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, model.OrganizerConfirmed, repo.requests[0].Status)
+
+	// jawaban tercatat di history, status prestasi tidak berubah
+	assert.Len(t, pg.history, 1)
+	assert.Equal(t, "Organizer", pg.history[0].ChangedByRole)
+	assert.Equal(t, "submitted", pg.history[0].NewStatus)
+	assert.Equal(t, "lect-user-1", pg.history[0].ChangedBy)
+	assert.Equal(t, "penyelenggara Budi <panitia@lomba.id> mengonfirmasi partisipasi, peringkat 2", pg.history[0].Note)
+
+	// tautan sekali pakai
+	resp, _ = commentRequestAs(app, "POST", path, "", "",
+		map[string]interface{}{"participated": false, "responder_name": "Budi"})
+	assert.Equal(t, 410, resp.StatusCode)
+	resp, _ = commentRequestAs(app, "GET", path, "", "", nil)
+	assert.Equal(t, 410, resp.StatusCode)
+
+	resp, _ = commentRequestAs(app, "GET", path+"x", "", "", nil)
+	assert.Equal(t, 404, resp.StatusCode)
+}
+
+func TestOrganizerVerification_MailFailure(t *testing.T) {
+	svc, repo, mailer, _ := organizerService()
+	mailer.err = errors.New("smtp down")
+	app := organizerApp(svc)
+
+	resp, _ := commentRequestAs(app, "POST", "/achievements/ref-1/organizer-verifications", "", "",
+		map[string]interface{}{"organizer_email": "panitia@lomba.id"})
+	assert.Equal(t, 502, resp.StatusCode)
+	assert.Equal(t, model.OrganizerFailed, repo.requests[0].Status)
+}
+
+func TestOrganizerHistoryNote_RankMismatch(t *testing.T) {
+	rank := 3
+	v := &model.OrganizerVerification{OrganizerEmail: "panitia@lomba.id", ClaimedRank: 1}
+	note := organizerHistoryNote(v, model.OrganizerResponse{Status: model.OrganizerConfirmed, ConfirmedRank: &rank, ResponderName: "Budi"})
+	assert.Equal(t, "penyelenggara Budi <panitia@lomba.id> mengonfirmasi partisipasi, peringkat 3 (berbeda dari klaim: 1)", note)
+
+	note = organizerHistoryNote(v, model.OrganizerResponse{Status: model.OrganizerDenied, ResponderName: "Budi", Note: "tidak terdaftar"})
+	assert.Equal(t, "penyelenggara Budi <panitia@lomba.id> menyangkal partisipasi: tidak terdaftar", note)
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// nonFinalizedStatuses: prestasi yang poinnya masih boleh berubah ketika
+// rule set baru diaktifkan. Prestasi verified/rejected/deleted tidak disentuh.
+var nonFinalizedStatuses = []string{"draft", "submitted"}
+
+// ======================================================
+// POINT CALCULATOR
+// ======================================================
+
+// pointCalculator menggabungkan rule set aktif dengan default_points tipe custom.
+type pointCalculator struct {
+	RuleRepo repository.PointRulePostgresRepository       // boleh nil
+	TypeRepo repository.AchievementTypePostgresRepository // boleh nil
+	Schemas  *SchemaRegistry
+}
+
+// ActiveRuleSet mengembalikan rule set aktif, atau default bila belum ada.
+// Kegagalan database dikembalikan supaya poin tidak diam-diam dihitung
+// dengan rule default.
+func (p pointCalculator) ActiveRuleSet() (*model.PointRuleSet, error) {
+	if p.RuleRepo == nil {
+		return defaultPointRuleSet(), nil
+	}
+	rs, err := p.RuleRepo.GetActiveRuleSet()
+	if errors.Is(err, repository.ErrNoActiveRuleSet) {
+		return defaultPointRuleSet(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// Evaluate: tipe custom yang tidak tercakup rule dasar memakai default_points.
+func (p pointCalculator) Evaluate(set *model.PointRuleSet, a *model.AchievementMongo) PointsResult {
+	fallback := 0
+	if _, builtIn := p.Schemas.Lookup(a.AchievementType); !builtIn && p.TypeRepo != nil {
+		if t, err := p.TypeRepo.GetTypeByCode(a.AchievementType); err == nil {
+			fallback = t.DefaultPoints
+		}
+	}
+	return EvaluatePoints(set, a, fallback)
+}
+
+// ======================================================
+// POINT RULE SERVICE (Admin)
+// ======================================================
+
+type PointRuleService struct {
+	RuleRepo     repository.PointRulePostgresRepository
+	MongoRepo    repository.AchievementMongoRepository
+	PostgresRepo repository.AchievementPostgresRepository
+	TypeRepo     repository.AchievementTypePostgresRepository
+
+	// hanya satu job hitung ulang boleh berjalan
+	recalcMu sync.Mutex
+}
+
+func NewPointRuleService(
+	ruleRepo repository.PointRulePostgresRepository,
+	mongoRepo repository.AchievementMongoRepository,
+	postgresRepo repository.AchievementPostgresRepository,
+	typeRepo repository.AchievementTypePostgresRepository,
+) *PointRuleService {
+	return &PointRuleService{
+		RuleRepo:     ruleRepo,
+		MongoRepo:    mongoRepo,
+		PostgresRepo: postgresRepo,
+		TypeRepo:     typeRepo,
+	}
+}
+
+func (s *PointRuleService) calculator() pointCalculator {
+	return pointCalculator{RuleRepo: s.RuleRepo, TypeRepo: s.TypeRepo, Schemas: DefaultSchemaRegistry}
+}
+
+type ruleSetBody struct {
+	Name      string            `json:"name"`
+	Rules     []model.PointRule `json:"rules"`
+	MaxPoints int               `json:"max_points"`
+}
+
+// LIST RULE SETS
+func (s *PointRuleService) List(c *fiber.Ctx) error {
+	list, err := s.RuleRepo.ListRuleSets()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []model.PointRuleSet{}
+	}
+	return c.JSON(list)
+}
+
+// DETAIL RULE SET
+func (s *PointRuleService) Detail(c *fiber.Ctx) error {
+	rs, err := s.RuleRepo.GetRuleSet(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "rule set not found"})
+	}
+	return c.JSON(rs)
+}
+
+// CREATE DRAFT RULE SET
+func (s *PointRuleService) Create(c *fiber.Ctx) error {
+	var body ruleSetBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	rs := model.PointRuleSet{
+		ID:        uuid.New().String(),
+		Name:      body.Name,
+		Status:    "draft",
+		Rules:     body.Rules,
+		MaxPoints: body.MaxPoints,
+		CreatedBy: c.Locals("user_id").(string),
+		CreatedAt: time.Now(),
+	}
+	if errs := validateRuleSet(&rs); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	version, err := s.RuleRepo.NextVersion()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	rs.Version = version
+
+	if err := s.RuleRepo.CreateRuleSet(&rs); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(rs)
+}
+
+// UPDATE DRAFT RULE SET
+func (s *PointRuleService) Update(c *fiber.Ctx) error {
+	rs, err := s.RuleRepo.GetRuleSet(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "rule set not found"})
+	}
+	if rs.Status != "draft" {
+		return c.Status(400).JSON(fiber.Map{"error": "only draft rule set can be updated"})
+	}
+
+	var body ruleSetBody
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	rs.Name = body.Name
+	rs.Rules = body.Rules
+	rs.MaxPoints = body.MaxPoints
+	if errs := validateRuleSet(rs); len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	if err := s.RuleRepo.UpdateRuleSet(rs); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(rs)
+}
+
+// PREVIEW — efek rule set terhadap prestasi yang belum final, tanpa menyimpan
+func (s *PointRuleService) Preview(c *fiber.Ctx) error {
+	rs, err := s.RuleRepo.GetRuleSet(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "rule set not found"})
+	}
+
+	refs, err := s.nonFinalizedReferences()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	calc := s.calculator()
+	changes := []fiber.Map{}
+	increased, decreased, before, after := 0, 0, 0, 0
+
+	for _, ref := range refs {
+		oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+		ach, err := s.MongoRepo.GetByID(oid)
+		if err != nil {
+			continue
+		}
+
+		res := calc.Evaluate(rs, ach)
+		before += ach.Points
+		after += res.Points
+
+		if res.Points == ach.Points {
+			continue
+		}
+		if res.Points > ach.Points {
+			increased++
+		} else {
+			decreased++
+		}
+
+		changes = append(changes, fiber.Map{
+			"reference_id": ref.ID,
+			"title":        ach.Title,
+			"type":         ach.AchievementType,
+			"old_points":   ach.Points,
+			"new_points":   res.Points,
+			"applied":      res.Applied,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"rule_set_id":         rs.ID,
+		"version":             rs.Version,
+		"total":               len(refs),
+		"changed":             len(changes),
+		"increased":           increased,
+		"decreased":           decreased,
+		"total_points_before": before,
+		"total_points_after":  after,
+		"changes":             changes,
+	})
+}
+
+// ACTIVATE — aktifkan rule set lalu jalankan job hitung ulang di background
+func (s *PointRuleService) Activate(c *fiber.Ctx) error {
+	rs, err := s.RuleRepo.GetRuleSet(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "rule set not found"})
+	}
+	if rs.Status == "active" {
+		return c.Status(400).JSON(fiber.Map{"error": "rule set already active"})
+	}
+
+	if !s.recalcMu.TryLock() {
+		return c.Status(409).JSON(fiber.Map{"error": "another recalculation is still running"})
+	}
+
+	run := &model.PointRecalculationRun{
+		ID:          uuid.New().String(),
+		RuleSetID:   rs.ID,
+		Status:      "running",
+		TriggeredBy: c.Locals("user_id").(string),
+		StartedAt:   time.Now(),
+	}
+	// aktivasi dan run dibuat bersama → tidak ada rule set aktif tanpa hitung ulang
+	if err := s.RuleRepo.ActivateRuleSet(rs.ID, run); err != nil {
+		s.recalcMu.Unlock()
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	go func() {
+		defer s.recalcMu.Unlock()
+		s.recalculate(run, rs)
+	}()
+
+	return c.Status(202).JSON(fiber.Map{
+		"message": "Rule set activated, recalculation started",
+		"run_id":  run.ID,
+	})
+}
+
+// RUN STATUS + AUDIT
+func (s *PointRuleService) Run(c *fiber.Ctx) error {
+	run, err := s.RuleRepo.GetRun(c.Params("runId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "recalculation run not found"})
+	}
+
+	audit, err := s.RuleRepo.ListAudit(run.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if audit == nil {
+		audit = []model.PointRecalculationAudit{}
+	}
+
+	return c.JSON(fiber.Map{
+		"run":   run,
+		"audit": audit,
+	})
+}
+
+// recalculate menerapkan rule set ke semua prestasi yang belum final dan
+// mencatat setiap perubahan poin di audit.
+func (s *PointRuleService) recalculate(run *model.PointRecalculationRun, rs *model.PointRuleSet) {
+	calc := s.calculator()
+
+	refs, err := s.nonFinalizedReferences()
+	if err == nil {
+		for _, ref := range refs {
+			oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+			ach, getErr := s.MongoRepo.GetByID(oid)
+			if getErr != nil {
+				continue
+			}
+			run.Total++
+
+			newPoints := calc.Evaluate(rs, ach).Points
+			if newPoints == ach.Points {
+				continue
+			}
+
+			if err = s.MongoRepo.UpdatePointsMongo(oid, newPoints); err != nil {
+				break
+			}
+			if err = s.RuleRepo.InsertAudit(&model.PointRecalculationAudit{
+				RunID:       run.ID,
+				ReferenceID: ref.ID,
+				OldPoints:   ach.Points,
+				NewPoints:   newPoints,
+				CreatedAt:   time.Now(),
+			}); err != nil {
+				break
+			}
+			run.Changed++
+		}
+	}
+
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Status = "completed"
+	if err != nil {
+		run.Status = "failed"
+		run.Error = err.Error()
+	}
+
+	if err := s.RuleRepo.FinishRun(run); err != nil {
+		log.Printf("gagal menyimpan status recalculation run %s: %v", run.ID, err)
+	}
+}
+
+func (s *PointRuleService) nonFinalizedReferences() ([]model.AchievementReference, error) {
+	refs, err := s.PostgresRepo.GetAllReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []model.AchievementReference
+	for _, ref := range refs {
+		if contains(nonFinalizedStatuses, ref.Status) {
+			result = append(result, ref)
+		}
+	}
+	return result, nil
+}
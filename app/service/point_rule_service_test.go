@@ -0,0 +1,276 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK REPOSITORIES =================
+
+type MockPointRuleRepo struct {
+	sets    map[string]*model.PointRuleSet
+	audit   []model.PointRecalculationAudit
+	lastRun *model.PointRecalculationRun
+
+	activeErr   error // kegagalan database saat membaca rule set aktif
+	activateErr error
+}
+
+func newMockPointRuleRepo(sets ...*model.PointRuleSet) *MockPointRuleRepo {
+	m := &MockPointRuleRepo{sets: map[string]*model.PointRuleSet{}}
+	for _, rs := range sets {
+		m.sets[rs.ID] = rs
+	}
+	return m
+}
+
+func (m *MockPointRuleRepo) ListRuleSets() ([]model.PointRuleSet, error) { return nil, nil }
+func (m *MockPointRuleRepo) GetRuleSet(id string) (*model.PointRuleSet, error) {
+	if rs, ok := m.sets[id]; ok {
+		return rs, nil
+	}
+	return nil, errors.New("rule set not found")
+}
+func (m *MockPointRuleRepo) GetActiveRuleSet() (*model.PointRuleSet, error) {
+	if m.activeErr != nil {
+		return nil, m.activeErr
+	}
+	for _, rs := range m.sets {
+		if rs.Status == "active" {
+			return rs, nil
+		}
+	}
+	return nil, repository.ErrNoActiveRuleSet
+}
+func (m *MockPointRuleRepo) NextVersion() (int, error)                  { return 1, nil }
+func (m *MockPointRuleRepo) CreateRuleSet(rs *model.PointRuleSet) error { return nil }
+func (m *MockPointRuleRepo) UpdateRuleSet(rs *model.PointRuleSet) error { return nil }
+func (m *MockPointRuleRepo) ActivateRuleSet(id string, r *model.PointRecalculationRun) error {
+	return m.activateErr
+}
+func (m *MockPointRuleRepo) FinishRun(r *model.PointRecalculationRun) error {
+	m.lastRun = r
+	return nil
+}
+func (m *MockPointRuleRepo) GetRun(id string) (*model.PointRecalculationRun, error) {
+	return nil, errors.New("recalculation run not found")
+}
+func (m *MockPointRuleRepo) InsertAudit(a *model.PointRecalculationAudit) error {
+	m.audit = append(m.audit, *a)
+	return nil
+}
+func (m *MockPointRuleRepo) ListAudit(runID string) ([]model.PointRecalculationAudit, error) {
+	return m.audit, nil
+}
+
+// satu referensi draft + satu verified
+type MockPointRulePostgresRepo struct {
+	MockAchievementPostgresRepo
+}
+
+func (m *MockPointRulePostgresRepo) GetAllReferences() ([]model.AchievementReference, error) {
+	return []model.AchievementReference{
+		{ID: "ref-draft", MongoID: primitive.NewObjectID().Hex(), Status: "draft"},
+		{ID: "ref-verified", MongoID: primitive.NewObjectID().Hex(), Status: "verified"},
+	}, nil
+}
+
+// dokumen Mongo: kompetisi nasional, juara 1, poin lama 100
+type MockPointRuleMongoRepo struct {
+	MockAchievementMongoRepo
+	updated map[primitive.ObjectID]int
+}
+
+func (m *MockPointRuleMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	return &model.AchievementMongo{
+		Title:           "Gemastik",
+		AchievementType: "competition",
+		Points:          100,
+		Details: model.AchievementDetails{
+			CompetitionName:  "Gemastik",
+			CompetitionLevel: "national",
+			Rank:             1,
+			MedalType:        "gold",
+		},
+	}, nil
+}
+func (m *MockPointRuleMongoRepo) UpdatePointsMongo(id primitive.ObjectID, points int) error {
+	m.updated[id] = points
+	return nil
+}
+
+func intPtr(v int) *int { return &v }
+
+func draftRuleSet() *model.PointRuleSet {
+	return &model.PointRuleSet{
+		ID:      "rs-2",
+		Name:    "2025",
+		Version: 2,
+		Status:  "draft",
+		Rules: []model.PointRule{
+			{Name: "national", When: model.PointCondition{Types: []string{"competition"}, Levels: []string{"national"}}, Points: intPtr(120)},
+			{Name: "juara-1", When: model.PointCondition{RankMax: 1}, Multiplier: 1.5},
+			{Name: "medal-bonus", When: model.PointCondition{Medals: []string{"gold"}}, Bonus: 20, Cap: 190},
+		},
+	}
+}
+
+// ================= UNIT TESTS =================
+
+func TestEvaluatePoints_DefaultRuleSetMatchesLegacyValues(t *testing.T) {
+	def := defaultPointRuleSet()
+
+	cases := map[string]struct {
+		a    model.AchievementMongo
+		want int
+	}{
+		"international": {model.AchievementMongo{AchievementType: "competition", Details: model.AchievementDetails{CompetitionLevel: "international"}}, 200},
+		"university":    {model.AchievementMongo{AchievementType: "competition", Details: model.AchievementDetails{CompetitionLevel: "university"}}, 20},
+		"publication":   {model.AchievementMongo{AchievementType: "publication"}, 150},
+		"organization":  {model.AchievementMongo{AchievementType: "organization"}, 50},
+		"certification": {model.AchievementMongo{AchievementType: "certification"}, 80},
+	}
+
+	for name, tc := range cases {
+		assert.Equal(t, tc.want, EvaluatePoints(def, &tc.a, 0).Points, name)
+	}
+}
+
+func TestEvaluatePoints_ModifiersAndCaps(t *testing.T) {
+	a := &model.AchievementMongo{
+		AchievementType: "competition",
+		Details:         model.AchievementDetails{CompetitionLevel: "national", Rank: 1, MedalType: "gold"},
+	}
+
+	res := EvaluatePoints(draftRuleSet(), a, 0)
+
+	// 120 × 1.5 = 180, +20 = 200, cap 190
+	assert.Equal(t, 190, res.Points)
+	assert.Equal(t, []string{"national", "juara-1", "medal-bonus"}, res.Applied)
+}
+
+func TestPointRule_Preview(t *testing.T) {
+	svc := NewPointRuleService(
+		newMockPointRuleRepo(draftRuleSet()),
+		&MockPointRuleMongoRepo{updated: map[primitive.ObjectID]int{}},
+		&MockPointRulePostgresRepo{},
+		nil,
+	)
+	app := fiber.New()
+	app.Post("/point-rules/:id/preview", svc.Preview)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/point-rules/rs-2/preview", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var out map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&out)
+	assert.Equal(t, float64(1), out["total"]) // verified tidak ikut
+	assert.Equal(t, float64(1), out["changed"])
+	assert.Equal(t, float64(190), out["total_points_after"])
+}
+
+func TestPointRule_Recalculate_OnlyNonFinalizedWithAudit(t *testing.T) {
+	rs := draftRuleSet()
+	ruleRepo := newMockPointRuleRepo(rs)
+	mongoRepo := &MockPointRuleMongoRepo{updated: map[primitive.ObjectID]int{}}
+
+	svc := NewPointRuleService(ruleRepo, mongoRepo, &MockPointRulePostgresRepo{}, nil)
+	run := &model.PointRecalculationRun{ID: "run-1", RuleSetID: rs.ID, Status: "running"}
+
+	svc.recalculate(run, rs)
+
+	assert.Equal(t, "completed", ruleRepo.lastRun.Status)
+	assert.Equal(t, 1, run.Total)
+	assert.Equal(t, 1, run.Changed)
+	assert.Len(t, mongoRepo.updated, 1)
+	assert.Equal(t, []model.PointRecalculationAudit{{
+		RunID: "run-1", ReferenceID: "ref-draft", OldPoints: 100, NewPoints: 190,
+		CreatedAt: ruleRepo.audit[0].CreatedAt,
+	}}, ruleRepo.audit)
+}
+
+func TestPointRule_Create_InvalidRule_ShouldFail(t *testing.T) {
+	svc := NewPointRuleService(newMockPointRuleRepo(), &MockAchievementMongoRepo{}, &MockAchievementPostgresRepo{}, nil)
+	app := fiber.New()
+	app.Post("/point-rules", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "admin-1")
+		return svc.Create(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":  "broken",
+		"rules": []map[string]interface{}{{"name": "empty", "when": map[string]interface{}{}}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/point-rules", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestPointRule_ActiveRuleSet_DatabaseErrorIsNotDefault(t *testing.T) {
+	ruleRepo := newMockPointRuleRepo()
+	calc := pointCalculator{RuleRepo: ruleRepo, Schemas: DefaultSchemaRegistry}
+
+	// belum ada rule set aktif → default
+	rs, err := calc.ActiveRuleSet()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultPointRuleSet(), rs)
+
+	ruleRepo.activeErr = errors.New("connection refused")
+	_, err = calc.ActiveRuleSet()
+	assert.EqualError(t, err, "connection refused")
+
+	svc, app := setupAchievementService()
+	svc.RuleRepo = ruleRepo
+	app.Post("/achievements", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Create(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"achievementType": "competition",
+		"title":           "Juara Nasional",
+		"details": map[string]interface{}{
+			"competitionName":  "Gemastik",
+			"competitionLevel": "national",
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, _ := app.Test(req)
+	assert.Equal(t, 500, resp.StatusCode)
+}
+
+func TestPointRule_Activate_FailureReleasesLock(t *testing.T) {
+	ruleRepo := newMockPointRuleRepo(draftRuleSet())
+	ruleRepo.activateErr = errors.New("insert run failed")
+
+	svc := NewPointRuleService(ruleRepo, &MockAchievementMongoRepo{}, &MockPointRulePostgresRepo{}, nil)
+	app := fiber.New()
+	app.Post("/point-rules/:id/activate", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "admin-1")
+		return svc.Activate(c)
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/point-rules/rs-2/activate", nil))
+		assert.Equal(t, 500, resp.StatusCode) // bukan 409: lock dilepas
+	}
+	assert.Nil(t, ruleRepo.lastRun)
+}
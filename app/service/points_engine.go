@@ -0,0 +1,195 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"prestasi_api/app/model"
+)
+
+// PointsResult adalah hasil evaluasi rule set untuk satu prestasi.
+type PointsResult struct {
+	Points  int      `json:"points"`
+	Applied []string `json:"applied"` // nama rule yang diterapkan, berurutan
+}
+
+// EvaluatePoints menghitung poin dengan dua tahap:
+//  1. rule dasar (Points != nil) pertama yang cocok menentukan poin awal,
+//     bila tidak ada yang cocok dipakai fallbackBase;
+//  2. rule modifier (tanpa Points) yang cocok diterapkan berurutan
+//     (multiplier lalu bonus lalu cap).
+//
+// Terakhir MaxPoints rule set membatasi hasil.
+func EvaluatePoints(set *model.PointRuleSet, a *model.AchievementMongo, fallbackBase int) PointsResult {
+	res := PointsResult{Points: fallbackBase, Applied: []string{}}
+
+	for _, rule := range set.Rules {
+		if rule.Points == nil || !ruleMatches(rule.When, a) {
+			continue
+		}
+		res.Points = applyCap(*rule.Points, rule.Cap)
+		res.Applied = append(res.Applied, rule.Name)
+		break
+	}
+
+	for _, rule := range set.Rules {
+		if rule.Points != nil || !ruleMatches(rule.When, a) {
+			continue
+		}
+
+		value := float64(res.Points)
+		if rule.Multiplier > 0 {
+			value *= rule.Multiplier
+		}
+		res.Points = applyCap(int(math.Round(value))+rule.Bonus, rule.Cap)
+		res.Applied = append(res.Applied, rule.Name)
+	}
+
+	res.Points = applyCap(res.Points, set.MaxPoints)
+	if res.Points < 0 {
+		res.Points = 0
+	}
+	return res
+}
+
+func applyCap(points, limit int) int {
+	if limit > 0 && points > limit {
+		return limit
+	}
+	return points
+}
+
+func ruleMatches(cond model.PointCondition, a *model.AchievementMongo) bool {
+	d := a.Details
+
+	if len(cond.Types) > 0 && !contains(cond.Types, a.AchievementType) {
+		return false
+	}
+	if len(cond.Levels) > 0 && !contains(cond.Levels, d.CompetitionLevel) {
+		return false
+	}
+	if len(cond.Medals) > 0 && !contains(cond.Medals, d.MedalType) {
+		return false
+	}
+	if len(cond.PublicationTypes) > 0 && !contains(cond.PublicationTypes, d.PublicationType) {
+		return false
+	}
+
+	// rank 0 berarti tidak diisi → tidak memenuhi kondisi rank apa pun
+	if cond.RankMin > 0 && (d.Rank == 0 || d.Rank < cond.RankMin) {
+		return false
+	}
+	if cond.RankMax > 0 && (d.Rank == 0 || d.Rank > cond.RankMax) {
+		return false
+	}
+
+	if len(cond.TagsAny) > 0 {
+		found := false
+		for _, tag := range a.Tags {
+			if contains(cond.TagsAny, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	for key, want := range cond.CustomFields {
+		got, ok := d.CustomFields[key]
+		if !ok || fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+
+	size := teamSize(a)
+	if cond.TeamSizeMin > 0 && size < cond.TeamSizeMin {
+		return false
+	}
+	if cond.TeamSizeMax > 0 && size > cond.TeamSizeMax {
+		return false
+	}
+
+	return true
+}
+
+// teamSize: jumlah anggota tim (pemilik ikut dihitung), untuk publikasi
+// jumlah penulis, selain itu dianggap perorangan.
+func teamSize(a *model.AchievementMongo) int {
+	if len(a.TeamMembers) > 0 {
+		n := len(a.TeamMembers)
+		for _, m := range a.TeamMembers {
+			if m.StudentID == a.StudentID {
+				return n
+			}
+		}
+		return n + 1
+	}
+	if n := len(a.Details.Authors); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// defaultPointRuleSet mereplikasi nilai poin hard-coded versi lama dan dipakai
+// selama belum ada rule set yang diaktifkan admin.
+func defaultPointRuleSet() *model.PointRuleSet {
+	points := func(v int) *int { return &v }
+	competition := []string{"competition"}
+
+	return &model.PointRuleSet{
+		Name:   "default",
+		Status: "active",
+		Rules: []model.PointRule{
+			{Name: "competition-international", When: model.PointCondition{Types: competition, Levels: []string{"international"}}, Points: points(200)},
+			{Name: "competition-national", When: model.PointCondition{Types: competition, Levels: []string{"national"}}, Points: points(100)},
+			{Name: "competition-provincial", When: model.PointCondition{Types: competition, Levels: []string{"provincial"}}, Points: points(60)},
+			{Name: "competition-city", When: model.PointCondition{Types: competition, Levels: []string{"city"}}, Points: points(40)},
+			{Name: "competition-other", When: model.PointCondition{Types: competition}, Points: points(20)},
+			{Name: "publication", When: model.PointCondition{Types: []string{"publication"}}, Points: points(150)},
+			{Name: "organization", When: model.PointCondition{Types: []string{"organization"}}, Points: points(50)},
+			{Name: "certification", When: model.PointCondition{Types: []string{"certification"}}, Points: points(80)},
+		},
+	}
+}
+
+// validateRuleSet memeriksa isi rule set sebelum disimpan.
+func validateRuleSet(rs *model.PointRuleSet) []FieldError {
+	var errs []FieldError
+
+	if rs.Name == "" {
+		errs = append(errs, FieldError{"name", "wajib diisi"})
+	}
+	if rs.MaxPoints < 0 {
+		errs = append(errs, FieldError{"max_points", "tidak boleh negatif"})
+	}
+	if len(rs.Rules) == 0 {
+		errs = append(errs, FieldError{"rules", "minimal satu rule"})
+	}
+
+	for i, rule := range rs.Rules {
+		path := fmt.Sprintf("rules[%d]", i)
+
+		if rule.Name == "" {
+			errs = append(errs, FieldError{path + ".name", "wajib diisi"})
+		}
+		if rule.Points != nil && *rule.Points < 0 {
+			errs = append(errs, FieldError{path + ".points", "tidak boleh negatif"})
+		}
+		if rule.Points == nil && rule.Multiplier == 0 && rule.Bonus == 0 && rule.Cap == 0 {
+			errs = append(errs, FieldError{path, "harus berisi points, multiplier, bonus atau cap"})
+		}
+		if rule.Multiplier < 0 {
+			errs = append(errs, FieldError{path + ".multiplier", "tidak boleh negatif"})
+		}
+		if rule.Cap < 0 {
+			errs = append(errs, FieldError{path + ".cap", "tidak boleh negatif"})
+		}
+		if rule.When.RankMin > 0 && rule.When.RankMax > 0 && rule.When.RankMin > rule.When.RankMax {
+			errs = append(errs, FieldError{path + ".when.rank_min", "tidak boleh lebih besar dari rank_max"})
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ======================================================
+// PERIODE AKADEMIK
+// ======================================================
+
+var academicPeriodPattern = regexp.MustCompile(`^(\d{4})/(\d{4})-([12])$`)
+
+// AcademicPeriod mengembalikan periode akademik dengan format "2024/2025-1".
+// Semester ganjil (1) Agustus–Januari, semester genap (2) Februari–Juli.
+func AcademicPeriod(t time.Time) string {
+	year := t.Year()
+	switch {
+	case t.Month() >= time.August:
+		return fmt.Sprintf("%d/%d-1", year, year+1)
+	case t.Month() == time.January:
+		return fmt.Sprintf("%d/%d-1", year-1, year)
+	default:
+		return fmt.Sprintf("%d/%d-2", year-1, year)
+	}
+}
+
+func validAcademicPeriod(p string) bool {
+	m := academicPeriodPattern.FindStringSubmatch(p)
+	if m == nil {
+		return false
+	}
+	var start, end int
+	fmt.Sscan(m[1], &start)
+	fmt.Sscan(m[2], &end)
+	return end == start+1
+}
+
+// achievementPeriod: periode dihitung dari tanggal kegiatan, bila kosong
+// dari tanggal prestasi dibuat.
+func achievementPeriod(a *model.AchievementMongo) string {
+	if a.Details.EventDate != nil {
+		return AcademicPeriod(*a.Details.EventDate)
+	}
+	return AcademicPeriod(a.CreatedAt)
+}
+
+// appendLedger menambah satu entri; repo nil berarti ledger tidak dipakai.
+func appendLedger(repo repository.PointsLedgerPostgresRepository, e model.PointsLedgerEntry) error {
+	if repo == nil {
+		return nil
+	}
+	e.ID = uuid.New().String()
+	e.CreatedAt = time.Now()
+	return repo.Append(&e)
+}
+
+// ======================================================
+// LEDGER HOOKS (AchievementService)
+// ======================================================
+
+// recordLedger menyamakan ledger prestasi (dibagi ke anggota tim bila ada).
+// Kegagalan hanya di-log supaya alur status prestasi tidak ikut gagal.
+func (s *AchievementService) recordLedger(ref *model.AchievementReference, ach *model.AchievementMongo, entryType string, points int, actorID, role, reason string) {
+	if err := settleLedger(s.LedgerRepo, s.TeamRepo, ref, ach, entryType, points, actorID, role, reason); err != nil {
+		log.Printf("gagal mencatat ledger %s untuk %s: %v", entryType, ref.ID, err)
+	}
+}
+
+// ======================================================
+// POINTS LEDGER SERVICE
+// ======================================================
+
+type PointsLedgerService struct {
+	LedgerRepo   repository.PointsLedgerPostgresRepository
+	StudentRepo  repository.StudentPostgresRepository
+	MongoRepo    repository.AchievementMongoRepository
+	PostgresRepo repository.AchievementPostgresRepository
+	RuleRepo     repository.PointRulePostgresRepository
+	TypeRepo     repository.AchievementTypePostgresRepository
+	// TeamRepo boleh nil → poin prestasi seluruhnya milik pemilik
+	TeamRepo repository.AchievementTeamPostgresRepository
+	// DelegationRepo boleh nil → dosen wali hanya melihat bimbingannya sendiri
+	DelegationRepo repository.AdvisorDelegationPostgresRepository
+}
+
+func NewPointsLedgerService(
+	ledgerRepo repository.PointsLedgerPostgresRepository,
+	studentRepo repository.StudentPostgresRepository,
+	mongoRepo repository.AchievementMongoRepository,
+	postgresRepo repository.AchievementPostgresRepository,
+	ruleRepo repository.PointRulePostgresRepository,
+	typeRepo repository.AchievementTypePostgresRepository,
+	teamRepo repository.AchievementTeamPostgresRepository,
+	delegationRepo repository.AdvisorDelegationPostgresRepository,
+) *PointsLedgerService {
+	return &PointsLedgerService{
+		LedgerRepo:     ledgerRepo,
+		StudentRepo:    studentRepo,
+		MongoRepo:      mongoRepo,
+		PostgresRepo:   postgresRepo,
+		RuleRepo:       ruleRepo,
+		TypeRepo:       typeRepo,
+		TeamRepo:       teamRepo,
+		DelegationRepo: delegationRepo,
+	}
+}
+
+// ADJUST — koreksi manual atau bonus (Admin)
+func (s *PointsLedgerService) Adjust(c *fiber.Ctx) error {
+	var body struct {
+		StudentID string `json:"student_id"`
+		EntryType string `json:"entry_type"` // adjustment | bonus
+		Points    int    `json:"points"`
+		Reason    string `json:"reason"`
+		Period    string `json:"period"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	if body.EntryType == "" {
+		body.EntryType = model.LedgerAdjustment
+	}
+	if body.Period == "" {
+		body.Period = AcademicPeriod(time.Now())
+	}
+
+	var errs []FieldError
+	if body.StudentID == "" {
+		errs = append(errs, FieldError{"student_id", "wajib diisi"})
+	}
+	if body.EntryType != model.LedgerAdjustment && body.EntryType != model.LedgerBonus {
+		errs = append(errs, FieldError{"entry_type", "harus salah satu dari: adjustment, bonus"})
+	}
+	if body.Points == 0 {
+		errs = append(errs, FieldError{"points", "tidak boleh 0"})
+	}
+	if body.EntryType == model.LedgerBonus && body.Points < 0 {
+		errs = append(errs, FieldError{"points", "bonus harus positif"})
+	}
+	if body.Reason == "" {
+		errs = append(errs, FieldError{"reason", "wajib diisi"})
+	}
+	if !validAcademicPeriod(body.Period) {
+		errs = append(errs, FieldError{"period", "format harus YYYY/YYYY-1 atau YYYY/YYYY-2"})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	if _, err := s.StudentRepo.GetByID(body.StudentID); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "student not found"})
+	}
+
+	entry := model.PointsLedgerEntry{
+		ID:        uuid.New().String(),
+		StudentID: body.StudentID,
+		EntryType: body.EntryType,
+		Points:    body.Points,
+		Period:    body.Period,
+		Reason:    body.Reason,
+		ActorID:   c.Locals("user_id").(string),
+		ActorRole: c.Locals("role").(string),
+		CreatedAt: time.Now(),
+	}
+	if err := s.LedgerRepo.Append(&entry); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(entry)
+}
+
+// RECALCULATE — hitung ulang poin prestasi terverifikasi dengan rule set
+// aktif, selisihnya dicatat sebagai entri recalculated (Admin)
+func (s *PointsLedgerService) Recalculate(c *fiber.Ctx) error {
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	if ref.Status != "verified" {
+		return c.Status(400).JSON(fiber.Map{"error": "only verified achievement can be recalculated"})
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+	}
+
+	calc := pointCalculator{RuleRepo: s.RuleRepo, TypeRepo: s.TypeRepo, Schemas: DefaultSchemaRegistry}
+	rs, err := calc.ActiveRuleSet()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	newPoints := calc.Evaluate(rs, ach).Points
+
+	nets, err := s.LedgerRepo.ReferenceNets(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	oldPoints := 0
+	for _, p := range nets {
+		oldPoints += p
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	c.BodyParser(&body)
+	if body.Reason == "" {
+		body.Reason = "recalculated with active rule set"
+	}
+
+	if err := settleLedger(s.LedgerRepo, s.TeamRepo, ref, ach, model.LedgerRecalculated, newPoints,
+		c.Locals("user_id").(string), c.Locals("role").(string), body.Reason); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if newPoints != ach.Points {
+		if err := s.MongoRepo.UpdatePointsMongo(oid, newPoints); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"reference_id": ref.ID,
+		"old_points":   oldPoints,
+		"new_points":   newPoints,
+	})
+}
+
+// BALANCES — saldo seluruh mahasiswa, opsional ?period= (Admin)
+func (s *PointsLedgerService) Balances(c *fiber.Ctx) error {
+	period := c.Query("period")
+	if period != "" && !validAcademicPeriod(period) {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid period"})
+	}
+
+	list, err := s.LedgerRepo.Balances(period)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if list == nil {
+		list = []model.PointsBalance{}
+	}
+
+	return c.JSON(list)
+}
+
+// STUDENT LEDGER — saldo per periode + riwayat entri satu mahasiswa
+func (s *PointsLedgerService) StudentLedger(c *fiber.Ctx) error {
+	targetStudentID := c.Params("id")
+	role := c.Locals("role").(string)
+
+	if role == "Mahasiswa" {
+		if c.Locals("student_id") != targetStudentID {
+			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
+		}
+	}
+
+	if role == "Dosen Wali" {
+		lecturerID := c.Locals("lecturer_id").(string)
+		scope, err := resolveAdvisees(s.StudentRepo, s.DelegationRepo, lecturerID, time.Now())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if !scope.Contains(targetStudentID) {
+			return c.Status(403).JSON(fiber.Map{"error": "forbidden"})
+		}
+	}
+
+	period := c.Query("period")
+	if period != "" && !validAcademicPeriod(period) {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid period"})
+	}
+
+	balances, err := s.LedgerRepo.StudentBalances(targetStudentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	entries, err := s.LedgerRepo.ListByStudent(targetStudentID, period)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	total := 0
+	byPeriod := map[string]int{}
+	for _, b := range balances {
+		total += b.Points
+		byPeriod[b.Period] = b.Points
+	}
+	if entries == nil {
+		entries = []model.PointsLedgerEntry{}
+	}
+
+	return c.JSON(fiber.Map{
+		"student_id":       targetStudentID,
+		"total_points":     total,
+		"points_by_period": byPeriod,
+		"entries":          entries,
+	})
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCK LEDGER REPOSITORY =================
+
+type MockLedgerRepo struct {
+	entries []model.PointsLedgerEntry
+}
+
+func newMockLedgerRepo(entries ...model.PointsLedgerEntry) *MockLedgerRepo {
+	return &MockLedgerRepo{entries: entries}
+}
+
+func (m *MockLedgerRepo) Append(e *model.PointsLedgerEntry) error {
+	m.entries = append(m.entries, *e)
+	return nil
+}
+func (m *MockLedgerRepo) ListByStudent(studentID, period string) ([]model.PointsLedgerEntry, error) {
+	var list []model.PointsLedgerEntry
+	for _, e := range m.entries {
+		if e.StudentID == studentID && (period == "" || e.Period == period) {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+func (m *MockLedgerRepo) ReferenceNets(referenceID string) (map[string]int, error) {
+	nets := map[string]int{}
+	for _, e := range m.entries {
+		if e.ReferenceID != nil && *e.ReferenceID == referenceID {
+			nets[e.StudentID] += e.Points
+		}
+	}
+	return nets, nil
+}
+func (m *MockLedgerRepo) Balances(period string) ([]model.PointsBalance, error) {
+	totals := map[string]int{}
+	for _, e := range m.entries {
+		if period == "" || e.Period == period {
+			totals[e.StudentID] += e.Points
+		}
+	}
+	var list []model.PointsBalance
+	for _, sid := range sortedKeys(totals) {
+		list = append(list, model.PointsBalance{StudentID: sid, Period: period, Points: totals[sid]})
+	}
+	return list, nil
+}
+func (m *MockLedgerRepo) StudentBalances(studentID string) ([]model.PointsBalance, error) {
+	totals := map[string]int{}
+	for _, e := range m.entries {
+		if e.StudentID == studentID {
+			totals[e.Period] += e.Points
+		}
+	}
+	var list []model.PointsBalance
+	for _, p := range sortedKeys(totals) {
+		list = append(list, model.PointsBalance{StudentID: studentID, Period: p, Points: totals[p]})
+	}
+	return list, nil
+}
+
+func setupPointsLedgerService(ledger *MockLedgerRepo) (*PointsLedgerService, *fiber.App) {
+	svc := NewPointsLedgerService(
+		ledger,
+		&MockStudentPostgresRepo{},
+		&MockAchievementMongoRepo{},
+		&MockAchievementPostgresRepo{},
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+	return svc, fiber.New()
+}
+
+func postAdjustment(app *fiber.App, payload map[string]interface{}) *http.Response {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/points/adjustments", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func asAdmin(handler fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("user_id", "admin-1")
+		return handler(c)
+	}
+}
+
+// ================= UNIT TESTS =================
+
+func TestAcademicPeriod(t *testing.T) {
+	assert.Equal(t, "2024/2025-1", AcademicPeriod(time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2024/2025-1", AcademicPeriod(time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2024/2025-2", AcademicPeriod(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2024/2025-2", AcademicPeriod(time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)))
+
+	assert.True(t, validAcademicPeriod("2024/2025-2"))
+	assert.False(t, validAcademicPeriod("2024/2026-1"))
+	assert.False(t, validAcademicPeriod("2024-1"))
+}
+
+func TestPointsLedger_Adjust_Bonus_Success(t *testing.T) {
+	ledger := newMockLedgerRepo()
+	svc, app := setupPointsLedgerService(ledger)
+	app.Post("/points/adjustments", asAdmin(svc.Adjust))
+
+	resp := postAdjustment(app, map[string]interface{}{
+		"student_id": "student-1",
+		"entry_type": "bonus",
+		"points":     50,
+		"reason":     "Perwakilan universitas di PIMNAS",
+		"period":     "2024/2025-1",
+	})
+
+	assert.Equal(t, 201, resp.StatusCode)
+	assert.Len(t, ledger.entries, 1)
+	assert.Equal(t, "admin-1", ledger.entries[0].ActorID)
+	assert.Nil(t, ledger.entries[0].ReferenceID)
+}
+
+func TestPointsLedger_Adjust_Invalid_ShouldFail(t *testing.T) {
+	ledger := newMockLedgerRepo()
+	svc, app := setupPointsLedgerService(ledger)
+	app.Post("/points/adjustments", asAdmin(svc.Adjust))
+
+	resp := postAdjustment(app, map[string]interface{}{
+		"student_id": "student-1",
+		"entry_type": "bonus",
+		"points":     -10,
+	})
+
+	var out struct {
+		Fields []FieldError `json:"fields"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []FieldError{
+		{"points", "bonus harus positif"},
+		{"reason", "wajib diisi"},
+	}, out.Fields)
+	assert.Empty(t, ledger.entries)
+}
+
+func TestVerifyAchievement_RecordsEarnedEntry(t *testing.T) {
+	ledger := newMockLedgerRepo()
+	app := fiber.New()
+
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: &MockAchievementPostgresRepoSubmitted{},
+		StudentRepo:  &MockStudentPostgresRepo{},
+		LedgerRepo:   ledger,
+	}
+
+	app.Post("/achievements/:refId/verify", func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", "lect-1")
+		c.Locals("user_id", "user-1")
+		return svc.Verify(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/verify", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Len(t, ledger.entries, 1)
+	assert.Equal(t, model.LedgerEarned, ledger.entries[0].EntryType)
+	assert.Equal(t, "ref-123", *ledger.entries[0].ReferenceID)
+	assert.Equal(t, "user-1", ledger.entries[0].ActorID)
+}
+
+func TestPointsLedger_StudentLedger_Mahasiswa_Forbidden(t *testing.T) {
+	svc, app := setupPointsLedgerService(newMockLedgerRepo())
+	app.Get("/points/students/:id", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		return svc.StudentLedger(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/points/students/student-2", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+}
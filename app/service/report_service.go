@@ -1,6 +1,9 @@
 package service
 
 import (
+	"time"
+
+	"prestasi_api/app/model"
 	"prestasi_api/app/repository"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,16 +12,48 @@ import (
 type ReportService struct {
 	MongoRepo   repository.AchievementMongoRepository
 	StudentRepo repository.StudentPostgresRepository
+	// poin dibaca dari ledger, bukan dari field points dokumen Mongo
+	LedgerRepo repository.PointsLedgerPostgresRepository
+	// TeamRepo boleh nil → prestasi hanya dihitung untuk pemiliknya
+	TeamRepo repository.AchievementTeamPostgresRepository
+	// DelegationRepo boleh nil → dosen wali hanya melihat bimbingannya sendiri
+	DelegationRepo repository.AdvisorDelegationPostgresRepository
 }
 
 func NewReportService(
 	mongo repository.AchievementMongoRepository,
 	student repository.StudentPostgresRepository,
+	ledger repository.PointsLedgerPostgresRepository,
+	team repository.AchievementTeamPostgresRepository,
+	delegation repository.AdvisorDelegationPostgresRepository,
 ) *ReportService {
 	return &ReportService{
-		MongoRepo:   mongo,
-		StudentRepo: student,
+		MongoRepo:      mongo,
+		StudentRepo:    student,
+		LedgerRepo:     ledger,
+		TeamRepo:       team,
+		DelegationRepo: delegation,
+	}
+}
+
+func (s *ReportService) adviseeIDs(lecturerID string) ([]string, error) {
+	scope, err := resolveAdvisees(s.StudentRepo, s.DelegationRepo, lecturerID, time.Now())
+	if err != nil {
+		return nil, err
 	}
+	return scope.IDs(), nil
+}
+
+// participants: pemilik + anggota tim confirmed dari satu prestasi.
+func participants(a model.AchievementMongo, teams map[string][]string) []string {
+	return append([]string{a.StudentID}, teams[a.ID.Hex()]...)
+}
+
+func (s *ReportService) teamMembers() (map[string][]string, error) {
+	if s.TeamRepo == nil {
+		return map[string][]string{}, nil
+	}
+	return s.TeamRepo.ConfirmedMembersByMongoID()
 }
 
 // ======================================================
@@ -42,7 +77,7 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 		scope = "advisees"
 
 		lecturerID := c.Locals("lecturer_id").(string)
-		studentIDs, err := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
+		studentIDs, err := s.adviseeIDs(lecturerID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -72,6 +107,22 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// =========================
+	// SALDO POIN DARI LEDGER (opsional ?period=)
+	// =========================
+	period := c.Query("period")
+	if period != "" && !validAcademicPeriod(period) {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid period"})
+	}
+	balances, err := s.LedgerRepo.Balances(period)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	teams, err := s.teamMembers()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// =========================
 	// AGGREGATION
 	// =========================
@@ -88,11 +139,21 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 		// =========================
 		// FILTER BERDASARKAN ROLE
 		// =========================
-		if allowedStudentIDs != nil {
-			if !allowedStudentIDs[a.StudentID] {
-				continue
+		// prestasi tim masuk bila salah satu anggotanya boleh dilihat
+		members := []string{}
+		for _, sid := range participants(a, teams) {
+			if allowedStudentIDs == nil || allowedStudentIDs[sid] {
+				members = append(members, sid)
 			}
 		}
+		if len(members) == 0 {
+			continue
+		}
+
+		// ?period= berlaku juga untuk hitungan prestasi, sama dengan ledger
+		if period != "" && achievementPeriod(&a) != period {
+			continue
+		}
 
 		// by type
 		if a.AchievementType != "" {
@@ -112,10 +173,21 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 		}
 
 		// top students
-		stat := studentStats[a.StudentID]
-		stat.Points += a.Points
-		stat.Count++
-		studentStats[a.StudentID] = stat
+		for _, sid := range members {
+			stat := studentStats[sid]
+			stat.Count++
+			studentStats[sid] = stat
+		}
+	}
+
+	// poin top students: saldo ledger, termasuk mahasiswa yang hanya punya bonus
+	for _, b := range balances {
+		if allowedStudentIDs != nil && !allowedStudentIDs[b.StudentID] {
+			continue
+		}
+		stat := studentStats[b.StudentID]
+		stat.Points = b.Points
+		studentStats[b.StudentID] = stat
 	}
 
 	// =========================
@@ -135,6 +207,7 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 	// =========================
 	return c.JSON(fiber.Map{
 		"scope":                          scope,
+		"period":                         period,
 		"total_by_type":                 totalByType,
 		"total_by_period":               totalByPeriod,
 		"competition_level_distribution": competitionLevel,
@@ -143,7 +216,7 @@ func (s *ReportService) Statistics(c *fiber.Ctx) error {
 }
 
 // ======================================================
-// Report Detail per Student (TIDAK DIUBAH)
+// Report Detail per Student
 // ======================================================
 func (s *ReportService) StudentReport(c *fiber.Ctx) error {
 	targetStudentID := c.Params("id")
@@ -160,7 +233,7 @@ func (s *ReportService) StudentReport(c *fiber.Ctx) error {
 
 	if role == "Dosen Wali" {
 		lecturerID := c.Locals("lecturer_id").(string)
-		studentIDs, err := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
+		studentIDs, err := s.adviseeIDs(lecturerID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -186,24 +259,42 @@ func (s *ReportService) StudentReport(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	// =====================
+	// SALDO POIN DARI LEDGER
+	// =====================
+	balances, err := s.LedgerRepo.StudentBalances(targetStudentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	teams, err := s.teamMembers()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	totalPoints := 0
+	pointsByPeriod := map[string]int{}
+	for _, b := range balances {
+		totalPoints += b.Points
+		pointsByPeriod[b.Period] = b.Points
+	}
+
 	// =====================
 	// AGGREGATION
 	// =====================
 	totalAchievements := 0
-	totalPoints := 0
 	byType := map[string]int{}
 	byYear := map[string]int{}
 
 	for _, a := range achievements {
 
-		// filter mahasiswa target
-		if a.StudentID != targetStudentID {
+		// filter mahasiswa target (termasuk sebagai anggota tim)
+		if !contains(participants(a, teams), targetStudentID) {
 			continue
 		}
 
 		// total
 		totalAchievements++
-		totalPoints += a.Points
 
 		// by type
 		if a.AchievementType != "" {
@@ -224,7 +315,8 @@ func (s *ReportService) StudentReport(c *fiber.Ctx) error {
 			"total_achievements": totalAchievements,
 			"total_points":      totalPoints,
 		},
-		"by_type": byType,
-		"by_year": byYear,
+		"by_type":          byType,
+		"by_year":          byYear,
+		"points_by_period": pointsByPeriod,
 	})
 }
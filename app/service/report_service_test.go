@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -77,6 +78,11 @@ func setupReportService() (*ReportService, *fiber.App) {
 	svc := &ReportService{
 		MongoRepo:   &MockReportMongoRepo{},
 		StudentRepo: &MockReportStudentRepo{},
+		LedgerRepo: newMockLedgerRepo(
+			model.PointsLedgerEntry{StudentID: "student-1", EntryType: model.LedgerEarned, Points: 10, Period: "2023/2024-1"},
+			model.PointsLedgerEntry{StudentID: "student-1", EntryType: model.LedgerBonus, Points: 25, Period: "2023/2024-2"},
+			model.PointsLedgerEntry{StudentID: "student-3", EntryType: model.LedgerBonus, Points: 40, Period: "2023/2024-2"},
+		),
 	}
 
 	return svc, app
@@ -223,3 +229,83 @@ func TestReport_Student_DosenWali_Forbidden(t *testing.T) {
 
 	assert.Equal(t, 403, resp.StatusCode)
 }
+
+func TestReport_Student_PointsFromLedger(t *testing.T) {
+	svc, app := setupReportService()
+
+	app.Get("/report/student/:id", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		return svc.StudentReport(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/report/student/student-1", nil)
+	resp, _ := app.Test(req)
+
+	var out struct {
+		Summary struct {
+			TotalAchievements int `json:"total_achievements"`
+			TotalPoints       int `json:"total_points"`
+		} `json:"summary"`
+		PointsByPeriod map[string]int `json:"points_by_period"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 1, out.Summary.TotalAchievements)
+	assert.Equal(t, 35, out.Summary.TotalPoints) // 10 earned + 25 bonus, bukan a.Points
+	assert.Equal(t, map[string]int{"2023/2024-1": 10, "2023/2024-2": 25}, out.PointsByPeriod)
+}
+
+func TestReport_Statistics_TopStudentsIncludeBonusOnly(t *testing.T) {
+	svc, app := setupReportService()
+
+	app.Get("/report/statistics", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		return svc.Statistics(c)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/report/statistics?period=2023/2024-2", nil)
+	resp, _ := app.Test(req)
+
+	var out struct {
+		TopStudents []struct {
+			StudentID   string `json:"student_id"`
+			TotalPoints int    `json:"total_points"`
+		} `json:"top_students"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	points := map[string]int{}
+	for _, s := range out.TopStudents {
+		points[s.StudentID] = s.TotalPoints
+	}
+	assert.Equal(t, map[string]int{"student-1": 25, "student-2": 0, "student-3": 40}, points)
+}
+
+func TestReport_Statistics_PeriodFiltersAchievementCounts(t *testing.T) {
+	svc, app := setupReportService()
+
+	app.Get("/report/statistics", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		return svc.Statistics(c)
+	})
+
+	// prestasi Januari 2024 masuk 2023/2024-1, Februari 2024 masuk 2023/2024-2
+	req := httptest.NewRequest(http.MethodGet, "/report/statistics?period=2023/2024-2", nil)
+	resp, _ := app.Test(req)
+
+	var out struct {
+		TotalByType map[string]int `json:"total_by_type"`
+		TopStudents []struct {
+			StudentID         string `json:"student_id"`
+			TotalAchievements int    `json:"total_achievements"`
+		} `json:"top_students"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, map[string]int{"seminar": 1}, out.TotalByType)
+	counts := map[string]int{}
+	for _, s := range out.TopStudents {
+		counts[s.StudentID] = s.TotalAchievements
+	}
+	assert.Equal(t, map[string]int{"student-1": 0, "student-2": 1, "student-3": 0}, counts)
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Masa berlaku klaim: klaim sendiri singkat, penugasan admin lebih lama.
+const (
+	defaultClaimLease  = 30 * time.Minute
+	maxClaimLease      = 4 * time.Hour
+	defaultAssignLease = 24 * time.Hour
+	maxAssignLease     = 7 * 24 * time.Hour
+
+	defaultQueueLimit = 50
+	maxQueueLimit     = 200
+)
+
+// leaseFrom membaca lease_minutes (opsional) dalam batas max.
+func leaseFrom(minutes int, def, max time.Duration) (time.Duration, bool) {
+	if minutes == 0 {
+		return def, true
+	}
+	lease := time.Duration(minutes) * time.Minute
+	if minutes < 0 || lease > max {
+		return 0, false
+	}
+	return lease, true
+}
+
+// checkClaim: selama klaim aktif hanya pemegang klaim yang boleh review.
+// Kegagalan membaca klaim tidak dianggap "belum diklaim" (→ 500).
+func (s *AchievementService) checkClaim(actor reviewActor, refID string) error {
+	if s.ClaimRepo == nil {
+		return nil
+	}
+	claim, err := s.ClaimRepo.Get(refID)
+	if errors.Is(err, repository.ErrClaimNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !claim.Live(time.Now()) || claim.ReviewerID == actor.UserID {
+		return nil
+	}
+	return &reviewError{409, "prestasi sedang direview oleh reviewer lain"}
+}
+
+// releaseClaim dipanggil setelah prestasi diverifikasi/ditolak.
+func (s *AchievementService) releaseClaim(refID string) {
+	if s.ClaimRepo != nil {
+		s.ClaimRepo.Release(refID)
+	}
+}
+
+// ======================================================
+// REVIEW QUEUE (Dosen Wali & Admin)
+// ======================================================
+
+func (s *AchievementService) ReviewQueue(c *fiber.Ctx) error {
+	if s.ClaimRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "review queue not available"})
+	}
+	actor := reviewActorFrom(c)
+
+	filter := model.ReviewQueueFilter{
+		StudentID:  c.Query("student_id"),
+		Claim:      c.Query("claim"),
+		ReviewerID: actor.UserID,
+		Limit:      c.QueryInt("limit", defaultQueueLimit),
+		Offset:     c.QueryInt("offset", 0),
+	}
+
+	var errs []FieldError
+	if filter.Claim != "" && !contains([]string{"unclaimed", "claimed", "mine"}, filter.Claim) {
+		errs = append(errs, FieldError{"claim", "harus salah satu dari: unclaimed, claimed, mine"})
+	}
+	if filter.Limit < 1 || filter.Limit > maxQueueLimit {
+		errs = append(errs, FieldError{"limit", "harus 1-" + strconv.Itoa(maxQueueLimit)})
+	}
+	if filter.Offset < 0 {
+		errs = append(errs, FieldError{"offset", "tidak boleh negatif"})
+	}
+	for _, q := range []struct {
+		name string
+		dst  **time.Time
+		days int
+	}{{"submitted_from", &filter.SubmittedFrom, 0}, {"submitted_to", &filter.SubmittedTo, 1}} {
+		if v := c.Query(q.name); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				errs = append(errs, FieldError{q.name, "format YYYY-MM-DD"})
+				continue
+			}
+			// submitted_to inklusif sampai akhir hari
+			t = t.AddDate(0, 0, q.days)
+			*q.dst = &t
+		}
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	// Dosen Wali hanya melihat antrian mahasiswa bimbingan
+	if actor.Role == "Dosen Wali" {
+		scope, err := s.advisees(actor.LecturerID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		filter.StudentIDs = scope.IDs()
+	}
+
+	items, err := s.ClaimRepo.Queue(filter, time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	result := []fiber.Map{}
+	for _, item := range items {
+		entry := fiber.Map{
+			"reference": item.Reference,
+			"claim":     item.Claim,
+		}
+		oid, _ := primitive.ObjectIDFromHex(item.Reference.MongoID)
+		if ach, err := s.MongoRepo.GetByID(oid); err == nil {
+			entry["title"] = ach.Title
+			entry["achievementType"] = ach.AchievementType
+		}
+		result = append(result, entry)
+	}
+
+	return c.JSON(fiber.Map{
+		"items":  result,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
+
+// CLAIM — reviewer mengambil prestasi dari antrian
+func (s *AchievementService) ClaimReview(c *fiber.Ctx) error {
+	if s.ClaimRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "review queue not available"})
+	}
+	actor := reviewActorFrom(c)
+
+	var body struct {
+		LeaseMinutes int `json:"lease_minutes"`
+	}
+	c.BodyParser(&body)
+	lease, ok := leaseFrom(body.LeaseMinutes, defaultClaimLease, maxClaimLease)
+	if !ok {
+		return respondValidation(c, []FieldError{{"lease_minutes", "harus 1-" + strconv.Itoa(int(maxClaimLease.Minutes()))}})
+	}
+
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference tidak ditemukan"})
+	}
+	if _, err := s.authorizeReview(actor, ref); err != nil {
+		return respondReviewError(c, err)
+	}
+	if ref.Status != "submitted" {
+		return c.Status(400).JSON(fiber.Map{"error": "hanya status submitted yang bisa diklaim"})
+	}
+
+	now := time.Now()
+	claim := model.ReviewClaim{
+		ReferenceID:  ref.ID,
+		ReviewerID:   actor.UserID,
+		ReviewerRole: actor.Role,
+		ClaimedAt:    now,
+		ExpiresAt:    now.Add(lease),
+	}
+	taken, err := s.ClaimRepo.Claim(&claim)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !taken {
+		current, _ := s.ClaimRepo.Get(ref.ID)
+		return c.Status(409).JSON(fiber.Map{
+			"error": "prestasi sedang direview oleh reviewer lain",
+			"claim": current,
+		})
+	}
+
+	return c.JSON(claim)
+}
+
+// RELEASE — pemegang klaim (atau admin) melepas klaim
+func (s *AchievementService) ReleaseReview(c *fiber.Ctx) error {
+	if s.ClaimRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "review queue not available"})
+	}
+	actor := reviewActorFrom(c)
+	refID := c.Params("refId")
+
+	claim, err := s.ClaimRepo.Get(refID)
+	if err != nil && !errors.Is(err, repository.ErrClaimNotFound) {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil || !claim.Live(time.Now()) {
+		return c.Status(404).JSON(fiber.Map{"error": "review claim not found"})
+	}
+	if claim.ReviewerID != actor.UserID && actor.Role != "Admin" {
+		return c.Status(403).JSON(fiber.Map{"error": "bukan pemegang klaim"})
+	}
+
+	if err := s.ClaimRepo.Release(refID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"message": "Review claim released"})
+}
+
+// ASSIGN — admin menugaskan prestasi ke reviewer tertentu
+func (s *AchievementService) AssignReview(c *fiber.Ctx) error {
+	if s.ClaimRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "review queue not available"})
+	}
+
+	var body struct {
+		ReviewerID   string `json:"reviewer_id"`
+		LeaseMinutes int    `json:"lease_minutes"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	var errs []FieldError
+	if body.ReviewerID == "" {
+		errs = append(errs, FieldError{"reviewer_id", "wajib diisi"})
+	}
+	lease, ok := leaseFrom(body.LeaseMinutes, defaultAssignLease, maxAssignLease)
+	if !ok {
+		errs = append(errs, FieldError{"lease_minutes", "harus 1-" + strconv.Itoa(int(maxAssignLease.Minutes()))})
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference tidak ditemukan"})
+	}
+	if ref.Status != "submitted" {
+		return c.Status(400).JSON(fiber.Map{"error": "hanya status submitted yang bisa ditugaskan"})
+	}
+
+	reviewer, err := s.ClaimRepo.GetReviewer(body.ReviewerID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reviewer not found"})
+	}
+	// reviewer harus bisa memverifikasi prestasi ini
+	assignee := reviewActor{UserID: reviewer.UserID, LecturerID: reviewer.LecturerID, Role: reviewer.Role}
+	if _, err := s.authorizeReview(assignee, ref); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "reviewer tidak berwenang memverifikasi prestasi ini"})
+	}
+
+	adminID, _ := c.Locals("user_id").(string)
+	now := time.Now()
+	claim := model.ReviewClaim{
+		ReferenceID:  ref.ID,
+		ReviewerID:   reviewer.UserID,
+		ReviewerRole: reviewer.Role,
+		AssignedBy:   &adminID,
+		ClaimedAt:    now,
+		ExpiresAt:    now.Add(lease),
+	}
+	if err := s.ClaimRepo.Assign(&claim); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(claim)
+}
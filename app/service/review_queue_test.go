@@ -0,0 +1,206 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCK CLAIM REPOSITORY =================
+
+type MockClaimRepo struct {
+	claims    map[string]*model.ReviewClaim
+	reviewers map[string]model.Reviewer
+	filter    model.ReviewQueueFilter
+	getErr    error // kegagalan database saat membaca klaim
+}
+
+func newMockClaimRepo(reviewers ...model.Reviewer) *MockClaimRepo {
+	m := &MockClaimRepo{claims: map[string]*model.ReviewClaim{}, reviewers: map[string]model.Reviewer{}}
+	for _, r := range reviewers {
+		m.reviewers[r.UserID] = r
+	}
+	return m
+}
+
+func (m *MockClaimRepo) Get(refID string) (*model.ReviewClaim, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	c, ok := m.claims[refID]
+	if !ok {
+		return nil, repository.ErrClaimNotFound
+	}
+	return c, nil
+}
+func (m *MockClaimRepo) Claim(c *model.ReviewClaim) (bool, error) {
+	if cur, ok := m.claims[c.ReferenceID]; ok && cur.Live(c.ClaimedAt) && cur.ReviewerID != c.ReviewerID {
+		return false, nil
+	}
+	m.claims[c.ReferenceID] = c
+	return true, nil
+}
+func (m *MockClaimRepo) Assign(c *model.ReviewClaim) error {
+	m.claims[c.ReferenceID] = c
+	return nil
+}
+func (m *MockClaimRepo) Release(refID string) error {
+	delete(m.claims, refID)
+	return nil
+}
+func (m *MockClaimRepo) Queue(f model.ReviewQueueFilter, now time.Time) ([]model.ReviewQueueItem, error) {
+	m.filter = f
+	return []model.ReviewQueueItem{{Reference: model.AchievementReference{ID: "ref-1", Status: "submitted"}}}, nil
+}
+func (m *MockClaimRepo) GetReviewer(userID string) (*model.Reviewer, error) {
+	r, ok := m.reviewers[userID]
+	if !ok {
+		return nil, errors.New("reviewer not found")
+	}
+	return &r, nil
+}
+
+// MockAdvisorStudentRepo: mahasiswa bimbingan per dosen.
+type MockAdvisorStudentRepo struct {
+	MockStudentPostgresRepo
+	advisees map[string][]string // lecturer id → student ids
+}
+
+func (m *MockAdvisorStudentRepo) GetStudentIDsByAdvisor(advisorID string) ([]string, error) {
+	return m.advisees[advisorID], nil
+}
+
+func claimService(claims *MockClaimRepo) *AchievementService {
+	return &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: &MockAchievementPostgresRepoSubmitted{},
+		StudentRepo:  &MockStudentPostgresRepo{},
+		ClaimRepo:    claims,
+	}
+}
+
+// asReviewer: Dosen Wali dengan user id dari header X-User.
+func asReviewer(h fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("role", "Dosen Wali")
+		c.Locals("lecturer_id", "lect-1")
+		// header disalin: string dari fasthttp dipakai ulang antar request
+		c.Locals("user_id", utils.CopyString(c.Get("X-User")))
+		return h(c)
+	}
+}
+
+func reviewRequest(app *fiber.App, method, path, userID string) *http.Response {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-User", userID)
+	resp, _ := app.Test(req)
+	return resp
+}
+
+// ================= UNIT TESTS =================
+
+func TestClaimReview_ConflictAndExtend(t *testing.T) {
+	claims := newMockClaimRepo()
+	svc := claimService(claims)
+	app := fiber.New()
+	app.Post("/achievements/:refId/claim", asReviewer(svc.ClaimReview))
+
+	assert.Equal(t, 200, reviewRequest(app, http.MethodPost, "/achievements/ref-1/claim", "user-a").StatusCode)
+	assert.Equal(t, 409, reviewRequest(app, http.MethodPost, "/achievements/ref-1/claim", "user-b").StatusCode)
+	// pemegang klaim boleh memperpanjang
+	assert.Equal(t, 200, reviewRequest(app, http.MethodPost, "/achievements/ref-1/claim", "user-a").StatusCode)
+	assert.Equal(t, "user-a", claims.claims["ref-1"].ReviewerID)
+
+	// klaim kedaluwarsa bisa diambil reviewer lain
+	claims.claims["ref-1"].ExpiresAt = time.Now().Add(-time.Minute)
+	assert.Equal(t, 200, reviewRequest(app, http.MethodPost, "/achievements/ref-1/claim", "user-b").StatusCode)
+	assert.Equal(t, "user-b", claims.claims["ref-1"].ReviewerID)
+}
+
+func TestVerifyAchievement_ClaimedByOther_ShouldFail(t *testing.T) {
+	claims := newMockClaimRepo()
+	claims.claims["ref-1"] = &model.ReviewClaim{
+		ReferenceID: "ref-1", ReviewerID: "user-a", ReviewerRole: "Dosen Wali",
+		ClaimedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour),
+	}
+	svc := claimService(claims)
+	app := fiber.New()
+	app.Post("/achievements/:refId/verify", asReviewer(svc.Verify))
+
+	assert.Equal(t, 409, reviewRequest(app, http.MethodPost, "/achievements/ref-1/verify", "user-b").StatusCode)
+	assert.Equal(t, 200, reviewRequest(app, http.MethodPost, "/achievements/ref-1/verify", "user-a").StatusCode)
+	// klaim dilepas setelah diverifikasi
+	assert.NotContains(t, claims.claims, "ref-1")
+}
+
+func TestVerifyAchievement_ClaimLookupError_ShouldFail(t *testing.T) {
+	claims := newMockClaimRepo()
+	claims.getErr = errors.New("connection refused")
+	svc := claimService(claims)
+	app := fiber.New()
+	app.Post("/achievements/:refId/verify", asReviewer(svc.Verify))
+	app.Delete("/achievements/:refId/claim", asReviewer(svc.ReleaseReview))
+
+	// gagal baca klaim bukan berarti belum diklaim
+	assert.Equal(t, 500, reviewRequest(app, http.MethodPost, "/achievements/ref-1/verify", "user-b").StatusCode)
+	assert.Equal(t, 500, reviewRequest(app, http.MethodDelete, "/achievements/ref-1/claim", "user-b").StatusCode)
+
+	// belum ada klaim → siapa pun boleh verifikasi
+	claims.getErr = nil
+	assert.Equal(t, 200, reviewRequest(app, http.MethodPost, "/achievements/ref-1/verify", "user-b").StatusCode)
+}
+
+func TestAssignReview_ReviewerMustBeAuthorized(t *testing.T) {
+	claims := newMockClaimRepo(
+		model.Reviewer{UserID: "user-other", Role: "Dosen Wali", LecturerID: "lect-9"},
+		model.Reviewer{UserID: "user-admin2", Role: "Admin"},
+	)
+	svc := claimService(claims)
+	// dosen lect-9 tidak punya mahasiswa bimbingan student-1
+	svc.StudentRepo = &MockAdvisorStudentRepo{advisees: map[string][]string{"lect-1": {"student-1"}}}
+	app := fiber.New()
+	app.Post("/admin/achievements/:refId/assign", asAdmin(svc.AssignReview))
+
+	assign := func(reviewerID string) *http.Response {
+		body, _ := json.Marshal(map[string]interface{}{"reviewer_id": reviewerID})
+		req := httptest.NewRequest(http.MethodPost, "/admin/achievements/ref-1/assign", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	assert.Equal(t, 404, assign("user-unknown").StatusCode)
+	assert.Equal(t, 400, assign("user-other").StatusCode)
+	assert.Equal(t, 200, assign("user-admin2").StatusCode)
+
+	claim := claims.claims["ref-1"]
+	assert.Equal(t, "user-admin2", claim.ReviewerID)
+	assert.Equal(t, "admin-1", *claim.AssignedBy)
+}
+
+func TestReviewQueue_AdvisorScopedToAdvisees(t *testing.T) {
+	claims := newMockClaimRepo()
+	svc := claimService(claims)
+	app := fiber.New()
+	app.Get("/achievements/review-queue", asReviewer(svc.ReviewQueue))
+
+	resp := reviewRequest(app, http.MethodGet, "/achievements/review-queue?claim=unclaimed&submitted_to=2025-01-31", "user-a")
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, []string{"student-1"}, claims.filter.StudentIDs)
+	assert.Equal(t, "unclaimed", claims.filter.Claim)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), *claims.filter.SubmittedTo)
+
+	resp = reviewRequest(app, http.MethodGet, "/achievements/review-queue?claim=taken&limit=0", "user-a")
+	assert.Equal(t, 400, resp.StatusCode)
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+    "time"
+
     "prestasi_api/app/repository"
     "github.com/gofiber/fiber/v2"
     "prestasi_api/app/model"
@@ -9,13 +11,29 @@ import (
 type StudentService struct {
     StudentRepo  repository.StudentPostgresRepository
     LecturerRepo repository.LecturerPostgresRepository
+    // DelegationRepo boleh nil → tanpa delegasi dosen wali
+    DelegationRepo repository.AdvisorDelegationPostgresRepository
 }
 
-func NewStudentService(studentRepo repository.StudentPostgresRepository, lecturerRepo repository.LecturerPostgresRepository) *StudentService {
+func NewStudentService(
+    studentRepo repository.StudentPostgresRepository,
+    lecturerRepo repository.LecturerPostgresRepository,
+    delegationRepo repository.AdvisorDelegationPostgresRepository,
+) *StudentService {
     return &StudentService{
-        StudentRepo:  studentRepo,
-        LecturerRepo: lecturerRepo,
+        StudentRepo:    studentRepo,
+        LecturerRepo:   lecturerRepo,
+        DelegationRepo: delegationRepo,
+    }
+}
+
+// isAdvisorOf: pembimbing asli atau dosen yang sedang menerima delegasi.
+func (s *StudentService) isAdvisorOf(lecturerID string, student *model.Student) bool {
+    if student.AdvisorID == lecturerID {
+        return true
     }
+    scope, err := resolveAdvisees(s.StudentRepo, s.DelegationRepo, lecturerID, time.Now())
+    return err == nil && scope.OnBehalfOf(student.ID) == student.AdvisorID && student.AdvisorID != ""
 }
 
 
@@ -87,10 +105,11 @@ func (s *StudentService) List(c *fiber.Ctx) error {
     if role == "Dosen Wali" {
         lecturerID := c.Locals("lecturer_id").(string)
 
-        studentIDs, err := s.StudentRepo.GetStudentIDsByAdvisor(lecturerID)
+        scope, err := resolveAdvisees(s.StudentRepo, s.DelegationRepo, lecturerID, time.Now())
         if err != nil {
             return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch advisees"})
         }
+        studentIDs := scope.IDs()
 
         var students []*model.Student
         for _, id := range studentIDs {
@@ -134,7 +153,7 @@ func (s *StudentService) Detail(c *fiber.Ctx) error {
     // Dosen hanya bisa lihat mahasiswa bimbingannnya
     if role == "Dosen Wali" {
         lecturerID := c.Locals("lecturer_id").(string)
-        if student.AdvisorID != lecturerID {
+        if !s.isAdvisorOf(lecturerID, student) {
             return c.Status(403).JSON(fiber.Map{"error": "Anda bukan pembimbing mahasiswa ini"})
         }
         return c.JSON(student)
@@ -159,7 +178,7 @@ func (s *StudentService) Achievements(c *fiber.Ctx) error {
     if role != "Admin" {
         if role == "Dosen Wali" {
             lecturerID := c.Locals("lecturer_id").(string)
-            if student.AdvisorID != lecturerID {
+            if !s.isAdvisorOf(lecturerID, student) {
                 return c.Status(403).JSON(fiber.Map{"error": "Anda bukan pembimbing mahasiswa ini"})
             }
         } else {
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// defaultSubmissionRequirements berlaku untuk tipe yang belum diatur admin.
+// Lomba dan sertifikasi wajib melampirkan sertifikat.
+var defaultSubmissionRequirements = map[string]model.SubmissionRequirement{
+	"competition":   {TypeCode: "competition", MinAttachments: 1, RequiredKinds: []string{"certificate"}},
+	"certification": {TypeCode: "certification", MinAttachments: 1, RequiredKinds: []string{"certificate"}},
+}
+
+const customFieldPrefix = "customFields."
+
+func defaultRequirement(typeCode string) *model.SubmissionRequirement {
+	req, ok := defaultSubmissionRequirements[typeCode]
+	if !ok {
+		req = model.SubmissionRequirement{TypeCode: typeCode}
+	}
+	if req.RequiredKinds == nil {
+		req.RequiredKinds = []string{}
+	}
+	if req.RequiredFields == nil {
+		req.RequiredFields = []string{}
+	}
+	return &req
+}
+
+// loadRequirement: aturan admin bila ada, selain itu default bawaan.
+// Kegagalan database dikembalikan supaya Submit tidak memakai aturan yang salah.
+func loadRequirement(repo repository.SubmissionRequirementPostgresRepository, typeCode string) (*model.SubmissionRequirement, error) {
+	if repo == nil {
+		return defaultRequirement(typeCode), nil
+	}
+	req, err := repo.Get(typeCode)
+	if errors.Is(err, repository.ErrRequirementNotFound) {
+		return defaultRequirement(typeCode), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// evaluateRequirement mengembalikan syarat yang belum terpenuhi.
+func evaluateRequirement(req *model.SubmissionRequirement, a *model.AchievementMongo) []FieldError {
+	var errs []FieldError
+
+	attachments := a.ActiveAttachments()
+	if len(attachments) < req.MinAttachments {
+		errs = append(errs, FieldError{"attachments", fmt.Sprintf("minimal %d lampiran (saat ini %d)", req.MinAttachments, len(attachments))})
+	}
+
+	// lampiran lama (sebelum ada kind) tidak punya Kind; masing-masing
+	// dianggap memenuhi satu jenis wajib yang belum ada
+	kinds := map[string]bool{}
+	untyped := 0
+	for _, att := range attachments {
+		if att.Kind == "" {
+			untyped++
+			continue
+		}
+		kinds[att.Kind] = true
+	}
+	for _, kind := range req.RequiredKinds {
+		if kinds[kind] {
+			continue
+		}
+		if untyped > 0 {
+			untyped--
+			continue
+		}
+		errs = append(errs, FieldError{"attachments." + kind, "lampiran wajib belum diunggah"})
+	}
+	errs = append(errs, scanErrors(a)...)
+
+	for _, name := range req.RequiredFields {
+		if key, ok := strings.CutPrefix(name, customFieldPrefix); ok {
+			if v, set := a.Details.CustomFields[key]; !set || v == nil || v == "" {
+				errs = append(errs, FieldError{"details." + name, "wajib diisi sebelum diajukan"})
+			}
+			continue
+		}
+		if f, ok := detailFields[name]; ok && !f.isSet(&a.Details) {
+			errs = append(errs, FieldError{"details." + name, "wajib diisi sebelum diajukan"})
+		}
+	}
+
+	return errs
+}
+
+// ======================================================
+// READINESS (AchievementService)
+// ======================================================
+
+// submissionRequirement: syarat pengajuan untuk tipe prestasi a.
+func (s *AchievementService) submissionRequirement(a *model.AchievementMongo) (*model.SubmissionRequirement, error) {
+	return loadRequirement(s.RequirementRepo, a.AchievementType)
+}
+
+// READINESS — daftar syarat yang belum dipenuhi sebelum submit
+func (s *AchievementService) Readiness(c *fiber.Ctx) error {
+	role, _ := c.Locals("role").(string)
+	refID := c.Params("refId")
+
+	ref, err := s.PostgresRepo.GetReferenceByID(refID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+
+	if role == "Mahasiswa" {
+		studentID, _ := c.Locals("student_id").(string)
+		if !s.isParticipant(ref, studentID, true) {
+			return c.Status(403).JSON(fiber.Map{"error": "not your achievement"})
+		}
+	}
+	if role == "Dosen Wali" {
+		lecturerID, _ := c.Locals("lecturer_id").(string)
+		if !s.isAdviseeAchievement(lecturerID, ref) {
+			return c.Status(403).JSON(fiber.Map{"error": "not your advisee"})
+		}
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(ref.MongoID)
+	achievement, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement not found"})
+	}
+
+	// urutan sama dengan pemeriksaan di Submit
+	unmet, err := s.validateAchievement(achievement, time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	req, err := s.submissionRequirement(achievement)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	unmet = append(unmet, evaluateRequirement(req, achievement)...)
+
+	pending, err := s.pendingMembers(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, sid := range pending {
+		unmet = append(unmet, FieldError{"teamMembers." + sid, "menunggu konfirmasi anggota tim"})
+	}
+
+	if unmet == nil {
+		unmet = []FieldError{}
+	}
+	return c.JSON(fiber.Map{
+		"reference_id": ref.ID,
+		"status":       ref.Status,
+		"ready":        ref.Status == "draft" && len(unmet) == 0,
+		"requirement":  req,
+		"unmet":        unmet,
+	})
+}
+
+// ======================================================
+// ADMIN — SUBMISSION REQUIREMENTS
+// ======================================================
+
+type SubmissionRequirementService struct {
+	Repo     repository.SubmissionRequirementPostgresRepository
+	TypeRepo repository.AchievementTypePostgresRepository
+	Schemas  *SchemaRegistry
+}
+
+func NewSubmissionRequirementService(
+	repo repository.SubmissionRequirementPostgresRepository,
+	typeRepo repository.AchievementTypePostgresRepository,
+) *SubmissionRequirementService {
+	return &SubmissionRequirementService{
+		Repo:     repo,
+		TypeRepo: typeRepo,
+		Schemas:  DefaultSchemaRegistry,
+	}
+}
+
+// knownType: tipe bawaan atau tipe custom yang terdaftar.
+func (s *SubmissionRequirementService) knownType(code string) bool {
+	if _, ok := s.Schemas.Lookup(code); ok {
+		return true
+	}
+	if s.TypeRepo == nil {
+		return false
+	}
+	_, err := s.TypeRepo.GetTypeByCode(code)
+	return err == nil
+}
+
+// LIST — syarat efektif semua tipe (aturan admin atau default)
+func (s *SubmissionRequirementService) List(c *fiber.Ctx) error {
+	configured, err := s.Repo.List()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	byType := map[string]model.SubmissionRequirement{}
+	for _, req := range configured {
+		byType[req.TypeCode] = req
+	}
+
+	codes := []string{}
+	for _, schema := range s.Schemas.Types() {
+		codes = append(codes, schema.Type)
+	}
+	if s.TypeRepo != nil {
+		custom, err := s.TypeRepo.ListTypes(false)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		for _, t := range custom {
+			codes = append(codes, t.Code)
+		}
+	}
+
+	var list []fiber.Map
+	for _, code := range codes {
+		req, ok := byType[code]
+		if !ok {
+			req = *defaultRequirement(code)
+		}
+		list = append(list, fiber.Map{"requirement": req, "configured": ok})
+	}
+	return c.JSON(list)
+}
+
+// UPSERT — PUT /:code
+func (s *SubmissionRequirementService) Upsert(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if !s.knownType(code) {
+		return c.Status(404).JSON(fiber.Map{"error": "achievement type not found"})
+	}
+
+	var body struct {
+		MinAttachments int      `json:"min_attachments"`
+		RequiredKinds  []string `json:"required_kinds"`
+		RequiredFields []string `json:"required_fields"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	var errs []FieldError
+	if body.MinAttachments < 0 {
+		errs = append(errs, FieldError{"min_attachments", "tidak boleh negatif"})
+	}
+	for i, kind := range body.RequiredKinds {
+		if !contains(model.AttachmentKinds, kind) {
+			errs = append(errs, FieldError{fmt.Sprintf("required_kinds[%d]", i), "harus salah satu dari: " + strings.Join(model.AttachmentKinds, ", ")})
+		}
+	}
+	for i, name := range body.RequiredFields {
+		key, custom := strings.CutPrefix(name, customFieldPrefix)
+		if custom && !fieldKeyPattern.MatchString(key) {
+			errs = append(errs, FieldError{fmt.Sprintf("required_fields[%d]", i), "key custom field tidak valid"})
+		} else if _, ok := detailFields[name]; !custom && !ok {
+			errs = append(errs, FieldError{fmt.Sprintf("required_fields[%d]", i), "field tidak dikenal"})
+		}
+	}
+	if len(errs) > 0 {
+		return respondValidation(c, errs)
+	}
+
+	if body.RequiredKinds == nil {
+		body.RequiredKinds = []string{}
+	}
+	if body.RequiredFields == nil {
+		body.RequiredFields = []string{}
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	req := model.SubmissionRequirement{
+		TypeCode:       code,
+		MinAttachments: body.MinAttachments,
+		RequiredKinds:  body.RequiredKinds,
+		RequiredFields: body.RequiredFields,
+		UpdatedBy:      userID,
+		UpdatedAt:      time.Now(),
+	}
+	if err := s.Repo.Upsert(&req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(req)
+}
+
+// DELETE — kembali ke syarat default
+func (s *SubmissionRequirementService) Delete(c *fiber.Ctx) error {
+	code := c.Params("code")
+	if err := s.Repo.Delete(code); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"message":     "Submission requirement reset to default",
+		"requirement": defaultRequirement(code),
+	})
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK REQUIREMENT REPOSITORY =================
+
+type MockRequirementRepo struct {
+	rows   map[string]model.SubmissionRequirement
+	getErr error // kegagalan database
+}
+
+func newMockRequirementRepo(rows ...model.SubmissionRequirement) *MockRequirementRepo {
+	m := &MockRequirementRepo{rows: map[string]model.SubmissionRequirement{}}
+	for _, r := range rows {
+		m.rows[r.TypeCode] = r
+	}
+	return m
+}
+
+func (m *MockRequirementRepo) List() ([]model.SubmissionRequirement, error) {
+	var list []model.SubmissionRequirement
+	for _, code := range sortedKeys(m.rows) {
+		list = append(list, m.rows[code])
+	}
+	return list, nil
+}
+func (m *MockRequirementRepo) Get(typeCode string) (*model.SubmissionRequirement, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	r, ok := m.rows[typeCode]
+	if !ok {
+		return nil, repository.ErrRequirementNotFound
+	}
+	return &r, nil
+}
+func (m *MockRequirementRepo) Upsert(req *model.SubmissionRequirement) error {
+	m.rows[req.TypeCode] = *req
+	return nil
+}
+func (m *MockRequirementRepo) Delete(typeCode string) error {
+	delete(m.rows, typeCode)
+	return nil
+}
+
+// MockNoAttachmentMongoRepo: prestasi lomba tanpa lampiran.
+type MockNoAttachmentMongoRepo struct {
+	MockAchievementMongoRepo
+}
+
+func (m *MockNoAttachmentMongoRepo) GetByID(id primitive.ObjectID) (*model.AchievementMongo, error) {
+	return &model.AchievementMongo{
+		Title:           "Dummy",
+		AchievementType: "competition",
+		Details: model.AchievementDetails{
+			CompetitionName:  "Dummy Cup",
+			CompetitionLevel: "national",
+		},
+	}, nil
+}
+
+// ================= UNIT TESTS =================
+
+func TestEvaluateRequirement(t *testing.T) {
+	req := &model.SubmissionRequirement{
+		TypeCode:       "competition",
+		MinAttachments: 2,
+		RequiredKinds:  []string{"certificate", "assignment_letter"},
+		RequiredFields: []string{"eventDate", "customFields.nomorSk"},
+	}
+	a := &model.AchievementMongo{
+		AchievementType: "competition",
+		Attachments:     []model.Attachment{{FileName: "sertifikat.pdf", Kind: "certificate"}},
+	}
+
+	assert.Equal(t, []FieldError{
+		{"attachments", "minimal 2 lampiran (saat ini 1)"},
+		{"attachments.assignment_letter", "lampiran wajib belum diunggah"},
+		{"details.eventDate", "wajib diisi sebelum diajukan"},
+		{"details.customFields.nomorSk", "wajib diisi sebelum diajukan"},
+	}, evaluateRequirement(req, a))
+
+	// tanpa aturan admin → default lomba: sertifikat wajib
+	assert.Empty(t, evaluateRequirement(defaultRequirement("competition"), a))
+	assert.Empty(t, evaluateRequirement(defaultRequirement("organization"), &model.AchievementMongo{}))
+}
+
+func TestEvaluateRequirement_LegacyAttachmentWithoutKind(t *testing.T) {
+	legacy := &model.AchievementMongo{
+		AchievementType: "competition",
+		Attachments:     []model.Attachment{{FileName: "lama.pdf", FileURL: "uploads/x/lama.pdf"}},
+	}
+	assert.Empty(t, evaluateRequirement(defaultRequirement("competition"), legacy))
+
+	// satu lampiran lama hanya memenuhi satu jenis wajib
+	req := &model.SubmissionRequirement{RequiredKinds: []string{"certificate", "assignment_letter"}}
+	assert.Equal(t, []FieldError{
+		{"attachments.assignment_letter", "lampiran wajib belum diunggah"},
+	}, evaluateRequirement(req, legacy))
+}
+
+func TestSubmitAchievement_MissingCertificate_ShouldFail(t *testing.T) {
+	svc := &AchievementService{
+		MongoRepo:    &MockNoAttachmentMongoRepo{},
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		StudentRepo:  &MockStudentPostgresRepo{},
+	}
+	app := fiber.New()
+	app.Post("/achievements/:refId/submit", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Submit(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/submit", nil))
+
+	var out struct {
+		Unmet []FieldError `json:"unmet"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []FieldError{
+		{"attachments", "minimal 1 lampiran (saat ini 0)"},
+		{"attachments.certificate", "lampiran wajib belum diunggah"},
+	}, out.Unmet)
+}
+
+func TestSubmitAchievement_RequirementLookupError_ShouldFail(t *testing.T) {
+	repo := newMockRequirementRepo()
+	svc := &AchievementService{
+		MongoRepo:       &MockNoAttachmentMongoRepo{},
+		PostgresRepo:    &MockAchievementPostgresRepoDraft{},
+		StudentRepo:     &MockStudentPostgresRepo{},
+		RequirementRepo: repo,
+	}
+
+	// belum diatur admin → default bawaan
+	req, err := loadRequirement(repo, "competition")
+	assert.NoError(t, err)
+	assert.Equal(t, defaultRequirement("competition"), req)
+
+	// database gagal → 500, bukan diam-diam memakai default
+	repo.getErr = errors.New("connection refused")
+	app := fiber.New()
+	app.Post("/achievements/:refId/submit", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		c.Locals("user_id", "user-1")
+		return svc.Submit(c)
+	})
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/achievements/ref-123/submit", nil))
+	assert.Equal(t, 500, resp.StatusCode)
+}
+
+func TestReadiness_ListsUnmetRequirements(t *testing.T) {
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{}, // ada sertifikat
+		PostgresRepo: &MockAchievementPostgresRepoDraft{},
+		StudentRepo:  &MockStudentPostgresRepo{},
+		RequirementRepo: newMockRequirementRepo(model.SubmissionRequirement{
+			TypeCode:       "competition",
+			RequiredKinds:  []string{"certificate", "photo"},
+			RequiredFields: []string{},
+		}),
+	}
+	app := fiber.New()
+	app.Get("/achievements/:refId/readiness", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		return svc.Readiness(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/achievements/ref-123/readiness", nil))
+
+	var out struct {
+		Ready bool         `json:"ready"`
+		Unmet []FieldError `json:"unmet"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.False(t, out.Ready)
+	assert.Equal(t, []FieldError{{"attachments.photo", "lampiran wajib belum diunggah"}}, out.Unmet)
+}
+
+func TestSubmissionRequirement_Upsert_Invalid_ShouldFail(t *testing.T) {
+	repo := newMockRequirementRepo()
+	svc := NewSubmissionRequirementService(repo, nil)
+	app := fiber.New()
+	app.Put("/submission-requirements/:code", asAdmin(svc.Upsert))
+
+	put := func(code string, payload map[string]interface{}) *http.Response {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPut, "/submission-requirements/"+code, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	resp := put("competition", map[string]interface{}{
+		"min_attachments": -1,
+		"required_kinds":  []string{"certificate", "selfie"},
+		"required_fields": []string{"eventDate", "unknownField"},
+	})
+	var out struct {
+		Fields []FieldError `json:"fields"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	assert.Equal(t, []FieldError{
+		{"min_attachments", "tidak boleh negatif"},
+		{"required_kinds[1]", "harus salah satu dari: certificate, photo, assignment_letter, other"},
+		{"required_fields[1]", "field tidak dikenal"},
+	}, out.Fields)
+	assert.Empty(t, repo.rows)
+
+	assert.Equal(t, 404, put("unknown_type", map[string]interface{}{}).StatusCode)
+
+	resp = put("competition", map[string]interface{}{
+		"min_attachments": 2,
+		"required_kinds":  []string{"certificate", "assignment_letter"},
+	})
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "admin-1", repo.rows["competition"].UpdatedBy)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"crypto/rand"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/qrcode"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kode verifikasi: 12 karakter Crockford base32 (60 bit acak), ditulis
+// XXXX-XXXX-XXXX. Tanpa I, L, O, U supaya tidak tertukar saat diketik ulang.
+const (
+	verificationCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	verificationCodeLength   = 12
+	verificationCodeGroup    = 4
+
+	defaultQRScale = 8
+	maxQRScale     = 20
+)
+
+func newVerificationCode() (string, error) {
+	buf := make([]byte, verificationCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = verificationCodeAlphabet[b&31]
+	}
+	return formatVerificationCode(string(buf)), nil
+}
+
+func formatVerificationCode(raw string) string {
+	var b strings.Builder
+	for i := 0; i < len(raw); i += verificationCodeGroup {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		b.WriteString(raw[i : i+verificationCodeGroup])
+	}
+	return b.String()
+}
+
+// normalizeVerificationCode menerima kode yang diketik manusia: huruf kecil,
+// tanpa/dengan tanda hubung, O untuk 0 dan I/L untuk 1.
+func normalizeVerificationCode(input string) (string, bool) {
+	var raw strings.Builder
+	for _, r := range strings.ToUpper(input) {
+		switch r {
+		case '-', ' ':
+			continue
+		case 'O':
+			r = '0'
+		case 'I', 'L':
+			r = '1'
+		}
+		if !strings.ContainsRune(verificationCodeAlphabet, r) {
+			return "", false
+		}
+		raw.WriteRune(r)
+	}
+	if raw.Len() != verificationCodeLength {
+		return "", false
+	}
+	return formatVerificationCode(raw.String()), true
+}
+
+func (s *AchievementService) verificationURL(code string) string {
+	base := s.PublicBaseURL
+	if base == "" {
+		base = defaultPublicBaseURL
+	}
+	return strings.TrimRight(base, "/") + "/verify/" + url.PathEscape(code)
+}
+
+// issueVerificationCode dipanggil setiap kali prestasi menjadi verified.
+// Kode aktif yang sudah ada dipakai ulang. Gagal menerbitkan tidak
+// membatalkan verifikasi; kode dibuat ulang saat pertama kali diminta.
+func (s *AchievementService) issueVerificationCode(refID string, now time.Time) (*model.VerificationCode, error) {
+	if existing, err := s.CodeRepo.GetLatestByReference(refID); err == nil && existing.RevokedAt == nil {
+		return existing, nil
+	}
+
+	var err error
+	// tabrakan kode (unique) sangat jarang; coba beberapa kali
+	for attempt := 0; attempt < 3; attempt++ {
+		v := &model.VerificationCode{ReferenceID: refID, IssuedAt: now}
+		if v.Code, err = newVerificationCode(); err != nil {
+			return nil, err
+		}
+		if err = s.CodeRepo.Create(v); err == nil {
+			return v, nil
+		}
+	}
+	return nil, err
+}
+
+func (s *AchievementService) recordVerificationCode(refID string) {
+	if s.CodeRepo == nil {
+		return
+	}
+	if _, err := s.issueVerificationCode(refID, time.Now()); err != nil {
+		log.Printf("gagal menerbitkan kode verifikasi untuk %s: %v", refID, err)
+	}
+}
+
+// revokeVerificationCode: prestasi dicabut → kodenya ikut dicabut.
+func (s *AchievementService) revokeVerificationCode(refID, userID, reason string) {
+	if s.CodeRepo == nil {
+		return
+	}
+	if _, err := s.CodeRepo.RevokeByReference(refID, userID, reason, time.Now()); err != nil {
+		log.Printf("gagal mencabut kode verifikasi untuk %s: %v", refID, err)
+	}
+}
+
+// ======================================================
+// KODE VERIFIKASI — pemilik & reviewer (aturan akses sama dengan Detail)
+// ======================================================
+
+func (s *AchievementService) codeView(v *model.VerificationCode) fiber.Map {
+	view := fiber.Map{
+		"code":       v.Code,
+		"status":     v.Status(),
+		"issued_at":  v.IssuedAt,
+		"verify_url": s.verificationURL(v.Code),
+		"qr_url":     "/verify/" + url.PathEscape(v.Code) + "/qr",
+	}
+	if v.RevokedAt != nil {
+		view["revoked_at"] = v.RevokedAt
+		view["revoke_reason"] = v.RevokeReason
+	}
+	return view
+}
+
+// VerificationCode: kode publik prestasi. Prestasi yang diverifikasi sebelum
+// fitur ini ada mendapat kode saat pertama kali diminta.
+func (s *AchievementService) VerificationCode(c *fiber.Ctx) error {
+	if s.CodeRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "kode verifikasi tidak tersedia"})
+	}
+	ref, err := s.PostgresRepo.GetReferenceByID(c.Params("refId"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "reference not found"})
+	}
+	if err := s.authorizeView(c, ref); err != nil {
+		return respondReviewError(c, err)
+	}
+
+	v, err := s.CodeRepo.GetLatestByReference(ref.ID)
+	if err != nil {
+		if ref.Status != "verified" {
+			return c.Status(404).JSON(fiber.Map{"error": "prestasi belum terverifikasi"})
+		}
+		if v, err = s.issueVerificationCode(ref.ID, time.Now()); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	return c.JSON(s.codeView(v))
+}
+
+// RevokeVerificationCode (Admin): kode tidak lagi membuktikan apa pun,
+// misalnya karena prestasi terbukti tidak sah.
+func (s *AchievementService) RevokeVerificationCode(c *fiber.Ctx) error {
+	if s.CodeRepo == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "kode verifikasi tidak tersedia"})
+	}
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid body"})
+	}
+	body.Reason = strings.TrimSpace(body.Reason)
+	if body.Reason == "" {
+		return respondValidation(c, []FieldError{{"reason", "wajib diisi"}})
+	}
+
+	refID := c.Params("refId")
+	userID, _ := c.Locals("user_id").(string)
+	ok, err := s.CodeRepo.RevokeByReference(refID, userID, body.Reason, time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "tidak ada kode verifikasi aktif"})
+	}
+	return c.JSON(fiber.Map{"message": "Kode verifikasi dicabut"})
+}
+
+// ======================================================
+// HALAMAN VERIFIKASI PUBLIK (tanpa login)
+// ======================================================
+
+// PublicVerify: ringkasan minimal untuk pemberi kerja / panitia beasiswa.
+// Kode yang dicabut hanya dilaporkan statusnya, tanpa data mahasiswa.
+func (s *AchievementService) PublicVerify(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	invalid := fiber.Map{"valid": false, "error": "kode verifikasi tidak dikenal"}
+	if s.CodeRepo == nil {
+		return c.Status(404).JSON(invalid)
+	}
+	code, ok := normalizeVerificationCode(c.Params("code"))
+	if !ok {
+		return c.Status(404).JSON(invalid)
+	}
+	summary, err := s.CodeRepo.GetSummary(code)
+	if err != nil {
+		return c.Status(404).JSON(invalid)
+	}
+
+	if summary.RevokedAt != nil || summary.RefStatus != "verified" {
+		revoked := fiber.Map{"valid": false, "code": summary.Code, "status": model.VerificationCodeRevoked}
+		if summary.RevokedAt != nil {
+			revoked["revoked_at"] = summary.RevokedAt
+		}
+		return c.Status(410).JSON(revoked)
+	}
+
+	oid, _ := primitive.ObjectIDFromHex(summary.MongoID)
+	ach, err := s.MongoRepo.GetByID(oid)
+	if err != nil {
+		return c.Status(404).JSON(invalid)
+	}
+
+	result := fiber.Map{
+		"valid":            true,
+		"code":             summary.Code,
+		"status":           model.VerificationCodeActive,
+		"student_name":     summary.StudentName,
+		"title":            ach.Title,
+		"achievement_type": ach.AchievementType,
+		"verified_at":      summary.VerifiedAt,
+		"verifier_unit":    summary.VerifierUnit,
+	}
+	if ach.Details.CompetitionLevel != "" {
+		result["level"] = ach.Details.CompetitionLevel
+	}
+	return c.JSON(result)
+}
+
+// VerificationQR: PNG QR code berisi URL verifikasi (?scale=1-20 piksel
+// per modul). Kode itu sendiri publik, jadi gambar ini juga publik.
+func (s *AchievementService) VerificationQR(c *fiber.Ctx) error {
+	code, ok := normalizeVerificationCode(c.Params("code"))
+	if s.CodeRepo == nil || !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "kode verifikasi tidak dikenal"})
+	}
+	if _, err := s.CodeRepo.GetSummary(code); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "kode verifikasi tidak dikenal"})
+	}
+
+	scale := c.QueryInt("scale", defaultQRScale)
+	if scale < 1 || scale > maxQRScale {
+		return respondValidation(c, []FieldError{{"scale", "harus 1-20"}})
+	}
+
+	q, err := qrcode.Encode(s.verificationURL(code))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	img, err := q.PNG(scale)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+	return c.Send(img)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ================= MOCK =================
+
+type MockCodeRepo struct {
+	codes []*model.VerificationCode
+	// refStatus: status prestasi yang dilaporkan GetSummary
+	refStatus string
+}
+
+func (m *MockCodeRepo) Create(v *model.VerificationCode) error {
+	for _, c := range m.codes {
+		if c.Code == v.Code || (c.ReferenceID == v.ReferenceID && c.RevokedAt == nil) {
+			return errors.New("duplicate key")
+		}
+	}
+	// string dari c.Params dipakai ulang fiber; Postgres menyalinnya
+	cp := *v
+	cp.ReferenceID = strings.Clone(v.ReferenceID)
+	m.codes = append(m.codes, &cp)
+	return nil
+}
+
+func (m *MockCodeRepo) GetLatestByReference(refID string) (*model.VerificationCode, error) {
+	var latest *model.VerificationCode
+	for _, c := range m.codes {
+		if c.ReferenceID == refID && (latest == nil || c.RevokedAt == nil) {
+			latest = c
+		}
+	}
+	if latest == nil {
+		return nil, errors.New("verification code not found")
+	}
+	cp := *latest
+	return &cp, nil
+}
+
+func (m *MockCodeRepo) GetSummary(code string) (*model.VerificationSummary, error) {
+	for _, c := range m.codes {
+		if c.Code == code {
+			verifiedAt := c.IssuedAt
+			return &model.VerificationSummary{
+				VerificationCode: *c,
+				MongoID:          primitive.NewObjectID().Hex(),
+				RefStatus:        m.refStatus,
+				StudentName:      "Budi Santoso",
+				VerifiedAt:       &verifiedAt,
+				VerifierUnit:     "Teknik Informatika",
+			}, nil
+		}
+	}
+	return nil, errors.New("verification code not found")
+}
+
+func (m *MockCodeRepo) RevokeByReference(refID, userID, reason string, at time.Time) (bool, error) {
+	for _, c := range m.codes {
+		if c.ReferenceID == refID && c.RevokedAt == nil {
+			c.RevokedAt, c.RevokedBy, c.RevokeReason = &at, &userID, &reason
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type MockVerifiedPostgresRepo struct {
+	MockAchievementPostgresRepo
+}
+
+func (m *MockVerifiedPostgresRepo) GetReferenceByID(id string) (*model.AchievementReference, error) {
+	return &model.AchievementReference{
+		ID:        id,
+		StudentID: "student-1",
+		MongoID:   primitive.NewObjectID().Hex(),
+		Status:    "verified",
+	}, nil
+}
+
+func decodeJSON(t *testing.T, body io.Reader) map[string]interface{} {
+	var out map[string]interface{}
+	assert.NoError(t, json.NewDecoder(body).Decode(&out))
+	return out
+}
+
+// ================= TESTS =================
+
+func TestVerificationCodeFormat(t *testing.T) {
+	code, err := newVerificationCode()
+	assert.NoError(t, err)
+	assert.Len(t, code, 14)
+	normalized, ok := normalizeVerificationCode(code)
+	assert.True(t, ok)
+	assert.Equal(t, code, normalized)
+
+	// diketik ulang manusia: huruf kecil, tanpa tanda hubung, O/I/L
+	normalized, ok = normalizeVerificationCode("abcd efgh-o1il")
+	assert.True(t, ok)
+	assert.Equal(t, "ABCD-EFGH-0111", normalized)
+
+	for _, bad := range []string{"", "ABCD-EFGH", "ABCD-EFGH-JKMNP", "ABCD-EFGH-JKU1", "../../etc"} {
+		_, ok := normalizeVerificationCode(bad)
+		assert.False(t, ok, bad)
+	}
+}
+
+func TestVerify_IssuesPublicVerificationCode(t *testing.T) {
+	codes := &MockCodeRepo{}
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: &MockAchievementPostgresRepoSubmitted{},
+		StudentRepo:  &MockStudentPostgresRepo{},
+		CodeRepo:     codes,
+	}
+	app := fiber.New()
+	app.Post("/achievements/:refId/verify", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("user_id", "admin-1")
+		return svc.Verify(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/ref-1/verify", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Len(t, codes.codes, 1)
+	assert.Equal(t, "ref-1", codes.codes[0].ReferenceID)
+
+	// verifikasi ulang memakai kode aktif yang sama
+	svc.recordVerificationCode("ref-1")
+	assert.Len(t, codes.codes, 1)
+}
+
+func TestPublicVerify(t *testing.T) {
+	codes := &MockCodeRepo{refStatus: "verified"}
+	svc := &AchievementService{
+		MongoRepo:     &MockAchievementMongoRepo{},
+		PostgresRepo:  &MockVerifiedPostgresRepo{},
+		CodeRepo:      codes,
+		PublicBaseURL: "https://prestasi.example.ac.id/",
+	}
+	app := fiber.New()
+	app.Get("/achievements/:refId/verification-code", func(c *fiber.Ctx) error {
+		c.Locals("role", "Mahasiswa")
+		c.Locals("student_id", "student-1")
+		return svc.VerificationCode(c)
+	})
+	app.Post("/admin/achievements/:refId/verification-code/revoke", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("user_id", "admin-1")
+		return svc.RevokeVerificationCode(c)
+	})
+	app.Get("/verify/:code", svc.PublicVerify)
+	app.Get("/verify/:code/qr", svc.VerificationQR)
+
+	// prestasi lama yang sudah verified mendapat kode saat diminta
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/ref-1/verification-code", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	view := decodeJSON(t, resp.Body)
+	code := view["code"].(string)
+	assert.Equal(t, "https://prestasi.example.ac.id/verify/"+code, view["verify_url"])
+	assert.Equal(t, "active", view["status"])
+
+	// kode boleh diketik tanpa tanda hubung & huruf kecil
+	resp, _ = app.Test(httptest.NewRequest("GET", "/verify/"+strings.ToLower(strings.ReplaceAll(code, "-", "")), nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	out := decodeJSON(t, resp.Body)
+	assert.Equal(t, true, out["valid"])
+	assert.Equal(t, "Budi Santoso", out["student_name"])
+	assert.Equal(t, "Dummy", out["title"])
+	assert.Equal(t, "national", out["level"])
+	assert.Equal(t, "Teknik Informatika", out["verifier_unit"])
+	assert.NotNil(t, out["verified_at"])
+	// data pribadi & internal tidak ikut
+	for _, key := range []string{"student_id", "nim", "points", "attachments", "reference_id"} {
+		assert.NotContains(t, out, key)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/verify/"+code+"/qr?scale=2", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(resp.Body)
+	_, err := png.Decode(bytes.NewReader(body))
+	assert.NoError(t, err)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/verify/ZZZZ-ZZZZ-ZZZZ", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+
+	// pencabutan
+	req := httptest.NewRequest("POST", "/admin/achievements/ref-1/verification-code/revoke", strings.NewReader(`{"reason":""}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	assert.Equal(t, 400, resp.StatusCode)
+
+	req = httptest.NewRequest("POST", "/admin/achievements/ref-1/verification-code/revoke", strings.NewReader(`{"reason":"sertifikat palsu"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/verify/"+code, nil))
+	assert.Equal(t, 410, resp.StatusCode)
+	out = decodeJSON(t, resp.Body)
+	assert.Equal(t, false, out["valid"])
+	assert.Equal(t, "revoked", out["status"])
+	assert.NotContains(t, out, "student_name")
+
+	// kode yang dicabut tidak diterbitkan ulang diam-diam
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievements/ref-1/verification-code", nil))
+	view = decodeJSON(t, resp.Body)
+	assert.Equal(t, code, view["code"])
+	assert.Equal(t, "revoked", view["status"])
+	assert.Len(t, codes.codes, 1)
+}
+
+func TestDeleteVerifiedAchievement_RevokesVerificationCode(t *testing.T) {
+	codes := &MockCodeRepo{}
+	codes.Create(&model.VerificationCode{Code: "ABCD-EFGH-JKMN", ReferenceID: "ref-1", IssuedAt: time.Now()})
+	svc := &AchievementService{
+		MongoRepo:    &MockAchievementMongoRepo{},
+		PostgresRepo: &MockVerifiedPostgresRepo{},
+		CodeRepo:     codes,
+	}
+	app := fiber.New()
+	app.Delete("/achievements/:refId", func(c *fiber.Ctx) error {
+		c.Locals("role", "Admin")
+		c.Locals("user_id", "admin-1")
+		return svc.Delete(c)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/ref-1", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.NotNil(t, codes.codes[0].RevokedAt)
+	assert.Equal(t, "achievement deleted", *codes.codes[0].RevokeReason)
+}
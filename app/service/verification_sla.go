@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"prestasi_api/app/model"
+	"prestasi_api/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// SLAConfig: batas waktu verifikasi dihitung dari submitted_at.
+type SLAConfig struct {
+	ReminderAfter    time.Duration // pengingat pertama ke dosen wali
+	ReminderEvery    time.Duration // jarak antar pengingat berikutnya
+	EscalateAfter    time.Duration // eskalasi ke reviewer cadangan / admin
+	CheckInterval    time.Duration // seberapa sering scheduler memeriksa
+	BackupReviewerID string        // users.id; kosong → eskalasi ke semua admin
+}
+
+var DefaultSLAConfig = SLAConfig{
+	ReminderAfter: 72 * time.Hour,
+	ReminderEvery: 24 * time.Hour,
+	EscalateAfter: 7 * 24 * time.Hour,
+	CheckInterval: 15 * time.Minute,
+}
+
+// SLAConfigFromEnv membaca konfigurasi SLA dari environment:
+//   - VERIFICATION_SLA_REMINDER_HOURS (default 72)
+//   - VERIFICATION_SLA_REMINDER_EVERY_HOURS (default 24)
+//   - VERIFICATION_SLA_ESCALATE_HOURS (default 168)
+//   - VERIFICATION_SLA_CHECK_MINUTES (default 15)
+//   - VERIFICATION_SLA_BACKUP_REVIEWER (user id, opsional)
+func SLAConfigFromEnv() SLAConfig {
+	cfg := DefaultSLAConfig
+	cfg.ReminderAfter = envDuration("VERIFICATION_SLA_REMINDER_HOURS", time.Hour, cfg.ReminderAfter)
+	cfg.ReminderEvery = envDuration("VERIFICATION_SLA_REMINDER_EVERY_HOURS", time.Hour, cfg.ReminderEvery)
+	cfg.EscalateAfter = envDuration("VERIFICATION_SLA_ESCALATE_HOURS", time.Hour, cfg.EscalateAfter)
+	cfg.CheckInterval = envDuration("VERIFICATION_SLA_CHECK_MINUTES", time.Minute, cfg.CheckInterval)
+	cfg.BackupReviewerID = os.Getenv("VERIFICATION_SLA_BACKUP_REVIEWER")
+
+	if cfg.EscalateAfter <= cfg.ReminderAfter {
+		log.Printf("SLA: eskalasi (%s) harus lebih lama dari pengingat (%s), pakai default", cfg.EscalateAfter, cfg.ReminderAfter)
+		cfg.ReminderAfter = DefaultSLAConfig.ReminderAfter
+		cfg.EscalateAfter = DefaultSLAConfig.EscalateAfter
+	}
+	return cfg
+}
+
+func envDuration(key string, unit, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("SLA: %s=%q tidak valid, pakai default %s", key, v, def)
+		return def
+	}
+	return time.Duration(n) * unit
+}
+
+// Notifier mengirim notifikasi ke user (email, push, dsb).
+type Notifier interface {
+	Notify(n model.Notification) error
+}
+
+// LogNotifier: notifier bawaan, hanya menulis ke log.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(n model.Notification) error {
+	log.Printf("notifikasi [%s] ke %s: %s — %s", n.Kind, n.RecipientID, n.Subject, n.Message)
+	return nil
+}
+
+// SLARunResult: ringkasan satu kali pemeriksaan.
+type SLARunResult struct {
+	Checked   int `json:"checked"`
+	Reminded  int `json:"reminded"`
+	Escalated int `json:"escalated"`
+}
+
+type VerificationSLAService struct {
+	SLARepo      repository.VerificationSLAPostgresRepository
+	PostgresRepo repository.AchievementPostgresRepository
+	// ClaimRepo boleh nil → eskalasi ke reviewer cadangan tanpa penugasan klaim
+	ClaimRepo repository.ReviewClaimPostgresRepository
+	Notifier  Notifier
+	Config    SLAConfig
+}
+
+func NewVerificationSLAService(
+	slaRepo repository.VerificationSLAPostgresRepository,
+	postgresRepo repository.AchievementPostgresRepository,
+	claimRepo repository.ReviewClaimPostgresRepository,
+	notifier Notifier,
+	cfg SLAConfig,
+) *VerificationSLAService {
+	if notifier == nil {
+		notifier = LogNotifier{}
+	}
+	return &VerificationSLAService{
+		SLARepo:      slaRepo,
+		PostgresRepo: postgresRepo,
+		ClaimRepo:    claimRepo,
+		Notifier:     notifier,
+		Config:       cfg,
+	}
+}
+
+// Run adalah job scheduler.
+func (s *VerificationSLAService) Run(ctx context.Context) error {
+	_, err := s.Check(time.Now())
+	return err
+}
+
+// Check mengirim pengingat dan eskalasi untuk prestasi yang lewat SLA.
+func (s *VerificationSLAService) Check(now time.Time) (SLARunResult, error) {
+	var result SLARunResult
+
+	overdue, err := s.SLARepo.Overdue(now.Add(-s.Config.ReminderAfter))
+	if err != nil {
+		return result, err
+	}
+
+	for _, o := range overdue {
+		result.Checked++
+		if o.Escalated || o.Reference.SubmittedAt == nil {
+			continue
+		}
+		age := now.Sub(*o.Reference.SubmittedAt)
+
+		if age >= s.Config.EscalateAfter {
+			if err := s.escalate(o, age, now); err != nil {
+				log.Printf("SLA: gagal eskalasi %s: %v", o.Reference.ID, err)
+				continue
+			}
+			result.Escalated++
+			continue
+		}
+
+		if o.AdvisorUserID == "" {
+			continue
+		}
+		if o.LastReminderAt != nil && now.Sub(*o.LastReminderAt) < s.Config.ReminderEvery {
+			continue
+		}
+		if err := s.remind(o, age, now); err != nil {
+			log.Printf("SLA: gagal mengirim pengingat %s: %v", o.Reference.ID, err)
+			continue
+		}
+		result.Reminded++
+	}
+
+	return result, nil
+}
+
+func (s *VerificationSLAService) remind(o model.OverdueReference, age time.Duration, now time.Time) error {
+	if err := s.Notifier.Notify(model.Notification{
+		RecipientID: o.AdvisorUserID,
+		Kind:        model.SLAReminder,
+		ReferenceID: o.Reference.ID,
+		Subject:     "Pengingat verifikasi prestasi",
+		Message:     fmt.Sprintf("Prestasi %s menunggu verifikasi selama %d jam", o.Reference.ID, int(age.Hours())),
+		CreatedAt:   now,
+	}); err != nil {
+		return err
+	}
+
+	return s.SLARepo.RecordEvent(&model.VerificationSLAEvent{
+		ID:          uuid.New().String(),
+		ReferenceID: o.Reference.ID,
+		Kind:        model.SLAReminder,
+		RecipientID: o.AdvisorUserID,
+		CreatedAt:   now,
+	})
+}
+
+// escalate: reviewer cadangan (sekaligus ditugaskan lewat klaim) atau
+// semua admin, lalu dicatat sebagai history prestasi.
+func (s *VerificationSLAService) escalate(o model.OverdueReference, age time.Duration, now time.Time) error {
+	recipients, target, err := s.escalationRecipients()
+	if err != nil {
+		return err
+	}
+	if len(recipients) == 0 {
+		return fmt.Errorf("tidak ada admin atau reviewer cadangan")
+	}
+
+	if s.Config.BackupReviewerID != "" && s.ClaimRepo != nil {
+		if reviewer, err := s.ClaimRepo.GetReviewer(s.Config.BackupReviewerID); err == nil {
+			if err := s.ClaimRepo.Assign(&model.ReviewClaim{
+				ReferenceID:  o.Reference.ID,
+				ReviewerID:   reviewer.UserID,
+				ReviewerRole: reviewer.Role,
+				ClaimedAt:    now,
+				ExpiresAt:    now.Add(defaultAssignLease),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	message := fmt.Sprintf("Prestasi %s belum diverifikasi selama %d jam (batas %d jam)",
+		o.Reference.ID, int(age.Hours()), int(s.Config.EscalateAfter.Hours()))
+
+	// event hanya dicatat untuk notifikasi yang terkirim
+	delivered := 0
+	for _, id := range recipients {
+		if err := s.Notifier.Notify(model.Notification{
+			RecipientID: id,
+			Kind:        model.SLAEscalation,
+			ReferenceID: o.Reference.ID,
+			Subject:     "Eskalasi verifikasi prestasi",
+			Message:     message,
+			CreatedAt:   now,
+		}); err != nil {
+			log.Printf("SLA: gagal notifikasi eskalasi ke %s: %v", id, err)
+			continue
+		}
+		delivered++
+		if err := s.SLARepo.RecordEvent(&model.VerificationSLAEvent{
+			ID:          uuid.New().String(),
+			ReferenceID: o.Reference.ID,
+			Kind:        model.SLAEscalation,
+			RecipientID: id,
+			CreatedAt:   now,
+		}); err != nil {
+			return err
+		}
+	}
+	// tidak ada yang menerima → belum dianggap tereskalasi, dicoba lagi
+	if delivered == 0 {
+		return fmt.Errorf("notifikasi eskalasi tidak terkirim")
+	}
+
+	// HISTORY: eskalasi (status tetap submitted), dilakukan sistem sehingga
+	// changed_by kosong, bukan penerima notifikasi.
+	return s.PostgresRepo.InsertHistory(&model.AchievementReferenceHistory{
+		ID:            uuid.New().String(),
+		ReferenceID:   o.Reference.ID,
+		OldStatus:     o.Reference.Status,
+		NewStatus:     o.Reference.Status,
+		Note:          "eskalasi otomatis ke " + target + ": " + message,
+		ChangedByRole: "System",
+		CreatedAt:     now,
+	})
+}
+
+func (s *VerificationSLAService) escalationRecipients() ([]string, string, error) {
+	if s.Config.BackupReviewerID != "" {
+		return []string{s.Config.BackupReviewerID}, "reviewer cadangan", nil
+	}
+	admins, err := s.SLARepo.AdminUserIDs()
+	return admins, "admin", err
+}
+
+// ======================================================
+// ADMIN — SLA VERIFIKASI
+// ======================================================
+
+// OVERDUE — prestasi submitted yang melewati batas pengingat
+func (s *VerificationSLAService) Overdue(c *fiber.Ctx) error {
+	now := time.Now()
+	overdue, err := s.SLARepo.Overdue(now.Add(-s.Config.ReminderAfter))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	items := []fiber.Map{}
+	for _, o := range overdue {
+		age := 0
+		if o.Reference.SubmittedAt != nil {
+			age = int(now.Sub(*o.Reference.SubmittedAt).Hours())
+		}
+		items = append(items, fiber.Map{
+			"reference":        o.Reference,
+			"age_hours":        age,
+			"last_reminder_at": o.LastReminderAt,
+			"escalated":        o.Escalated,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"config": fiber.Map{
+			"reminder_after_hours": int(s.Config.ReminderAfter.Hours()),
+			"reminder_every_hours": int(s.Config.ReminderEvery.Hours()),
+			"escalate_after_hours": int(s.Config.EscalateAfter.Hours()),
+			"backup_reviewer_id":   s.Config.BackupReviewerID,
+		},
+		"items": items,
+	})
+}
+
+// RUN — jalankan pemeriksaan SLA sekarang (tanpa menunggu scheduler)
+func (s *VerificationSLAService) RunNow(c *fiber.Ctx) error {
+	result, err := s.Check(time.Now())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(result)
+}
+
+// EVENTS — riwayat pengingat/eskalasi satu prestasi
+func (s *VerificationSLAService) Events(c *fiber.Ctx) error {
+	events, err := s.SLARepo.ListEvents(c.Params("refId"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if events == nil {
+		events = []model.VerificationSLAEvent{}
+	}
+	return c.JSON(events)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"prestasi_api/app/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= MOCKS =================
+
+type MockSLARepo struct {
+	overdue []model.OverdueReference
+	events  []model.VerificationSLAEvent
+	admins  []string
+}
+
+func (m *MockSLARepo) Overdue(before time.Time) ([]model.OverdueReference, error) {
+	var list []model.OverdueReference
+	for _, o := range m.overdue {
+		if !o.Reference.SubmittedAt.After(before) {
+			list = append(list, o)
+		}
+	}
+	return list, nil
+}
+func (m *MockSLARepo) RecordEvent(e *model.VerificationSLAEvent) error {
+	m.events = append(m.events, *e)
+	return nil
+}
+func (m *MockSLARepo) ListEvents(refID string) ([]model.VerificationSLAEvent, error) {
+	return m.events, nil
+}
+func (m *MockSLARepo) AdminUserIDs() ([]string, error) {
+	return m.admins, nil
+}
+
+type MockNotifier struct {
+	sent   []model.Notification
+	failTo map[string]bool // penerima yang gagal dikirimi
+}
+
+func (m *MockNotifier) Notify(n model.Notification) error {
+	if m.failTo[n.RecipientID] {
+		return errors.New("smtp unavailable")
+	}
+	m.sent = append(m.sent, n)
+	return nil
+}
+
+// MockHistoryPostgresRepo mencatat history yang disimpan.
+type MockHistoryPostgresRepo struct {
+	MockAchievementPostgresRepo
+	history []model.AchievementReferenceHistory
+}
+
+func (m *MockHistoryPostgresRepo) InsertHistory(h *model.AchievementReferenceHistory) error {
+	m.history = append(m.history, *h)
+	return nil
+}
+
+func overdueRef(id string, submittedAgo time.Duration, now time.Time) model.OverdueReference {
+	at := now.Add(-submittedAgo)
+	return model.OverdueReference{
+		Reference:     model.AchievementReference{ID: id, StudentID: "student-1", Status: "submitted", SubmittedAt: &at},
+		AdvisorUserID: "advisor-user-1",
+	}
+}
+
+func slaService(repo *MockSLARepo, notifier *MockNotifier, pg *MockHistoryPostgresRepo, claims *MockClaimRepo, cfg SLAConfig) *VerificationSLAService {
+	svc := NewVerificationSLAService(repo, pg, nil, notifier, cfg)
+	if claims != nil {
+		svc.ClaimRepo = claims
+	}
+	return svc
+}
+
+// ================= UNIT TESTS =================
+
+func TestVerificationSLA_RemindsAdvisorOncePerInterval(t *testing.T) {
+	now := time.Now()
+	repo := &MockSLARepo{overdue: []model.OverdueReference{
+		overdueRef("ref-fresh", 10*time.Hour, now),
+		overdueRef("ref-late", 80*time.Hour, now),
+	}}
+	notifier := &MockNotifier{}
+	pg := &MockHistoryPostgresRepo{}
+	svc := slaService(repo, notifier, pg, nil, DefaultSLAConfig)
+
+	result, err := svc.Check(now)
+	assert.NoError(t, err)
+	assert.Equal(t, SLARunResult{Checked: 1, Reminded: 1}, result)
+	assert.Len(t, notifier.sent, 1)
+	assert.Equal(t, "advisor-user-1", notifier.sent[0].RecipientID)
+	assert.Equal(t, model.SLAReminder, notifier.sent[0].Kind)
+
+	// pengingat terakhir baru 2 jam lalu → tidak dikirim ulang
+	last := now.Add(-2 * time.Hour)
+	repo.overdue[1].LastReminderAt = &last
+	result, _ = svc.Check(now)
+	assert.Equal(t, 0, result.Reminded)
+	assert.Empty(t, pg.history)
+}
+
+func TestVerificationSLA_EscalatesToBackupReviewer(t *testing.T) {
+	now := time.Now()
+	repo := &MockSLARepo{overdue: []model.OverdueReference{overdueRef("ref-1", 200*time.Hour, now)}}
+	notifier := &MockNotifier{}
+	pg := &MockHistoryPostgresRepo{}
+	claims := newMockClaimRepo(model.Reviewer{UserID: "backup-1", Role: "Admin"})
+
+	cfg := DefaultSLAConfig
+	cfg.BackupReviewerID = "backup-1"
+	svc := slaService(repo, notifier, pg, claims, cfg)
+
+	result, err := svc.Check(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Escalated)
+	assert.Equal(t, 0, result.Reminded)
+
+	assert.Len(t, notifier.sent, 1)
+	assert.Equal(t, "backup-1", notifier.sent[0].RecipientID)
+	assert.Equal(t, "backup-1", claims.claims["ref-1"].ReviewerID)
+
+	assert.Len(t, pg.history, 1)
+	assert.Equal(t, "submitted", pg.history[0].NewStatus)
+	assert.Equal(t, "System", pg.history[0].ChangedByRole)
+	assert.Empty(t, pg.history[0].ChangedBy) // bukan penerima notifikasi
+	assert.Contains(t, pg.history[0].Note, "reviewer cadangan")
+
+	// sudah dieskalasi → tidak diulang
+	repo.overdue[0].Escalated = true
+	result, _ = svc.Check(now)
+	assert.Equal(t, 0, result.Escalated)
+}
+
+func TestVerificationSLA_EscalatesToAdminsWithoutBackup(t *testing.T) {
+	now := time.Now()
+	repo := &MockSLARepo{
+		overdue: []model.OverdueReference{overdueRef("ref-1", 200*time.Hour, now)},
+		admins:  []string{"admin-1", "admin-2"},
+	}
+	notifier := &MockNotifier{}
+	pg := &MockHistoryPostgresRepo{}
+	svc := slaService(repo, notifier, pg, nil, DefaultSLAConfig)
+
+	result, _ := svc.Check(now)
+	assert.Equal(t, 1, result.Escalated)
+	assert.Len(t, notifier.sent, 2)
+	assert.Len(t, repo.events, 2)
+	assert.Len(t, pg.history, 1)
+}
+
+func TestVerificationSLA_EscalationEventsOnlyForDeliveredNotifications(t *testing.T) {
+	now := time.Now()
+	repo := &MockSLARepo{
+		overdue: []model.OverdueReference{overdueRef("ref-1", 200*time.Hour, now)},
+		admins:  []string{"admin-1", "admin-2"},
+	}
+	notifier := &MockNotifier{failTo: map[string]bool{"admin-1": true, "admin-2": true}}
+	pg := &MockHistoryPostgresRepo{}
+	svc := slaService(repo, notifier, pg, nil, DefaultSLAConfig)
+
+	// semua gagal → tidak tercatat, dicoba lagi pada pemeriksaan berikutnya
+	result, _ := svc.Check(now)
+	assert.Equal(t, 0, result.Escalated)
+	assert.Empty(t, repo.events)
+	assert.Empty(t, pg.history)
+
+	notifier.failTo = map[string]bool{"admin-1": true}
+	result, _ = svc.Check(now)
+	assert.Equal(t, 1, result.Escalated)
+	assert.Len(t, repo.events, 1)
+	assert.Equal(t, "admin-2", repo.events[0].RecipientID)
+	assert.Len(t, pg.history, 1)
+	assert.Empty(t, pg.history[0].ChangedBy)
+}
+
+func TestSLAConfigFromEnv(t *testing.T) {
+	t.Setenv("VERIFICATION_SLA_REMINDER_HOURS", "48")
+	t.Setenv("VERIFICATION_SLA_ESCALATE_HOURS", "abc")
+	t.Setenv("VERIFICATION_SLA_CHECK_MINUTES", "5")
+	t.Setenv("VERIFICATION_SLA_BACKUP_REVIEWER", "backup-1")
+
+	cfg := SLAConfigFromEnv()
+	assert.Equal(t, 48*time.Hour, cfg.ReminderAfter)
+	assert.Equal(t, DefaultSLAConfig.EscalateAfter, cfg.EscalateAfter)
+	assert.Equal(t, 5*time.Minute, cfg.CheckInterval)
+	assert.Equal(t, "backup-1", cfg.BackupReviewerID)
+}
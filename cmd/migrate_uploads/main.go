@@ -0,0 +1,56 @@
+// Command migrate_uploads memindahkan lampiran lama di ./uploads ke backend
+// storage yang dikonfigurasi (STORAGE_BACKEND, lihat storage.FromEnv).
+//
+//	go run ./cmd/migrate_uploads -dry-run
+//	go run ./cmd/migrate_uploads -delete-local
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"prestasi_api/app/repository"
+	"prestasi_api/app/service"
+	"prestasi_api/database"
+	"prestasi_api/storage"
+)
+
+func main() {
+	root := flag.String("root", ".", "direktori acuan path fileUrl lama")
+	dryRun := flag.Bool("dry-run", false, "hanya laporkan, tanpa mengunggah atau mengubah metadata")
+	deleteLocal := flag.Bool("delete-local", false, "hapus file lokal setelah berhasil dipindahkan")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("⚠️  .env file tidak ditemukan atau gagal dibaca")
+	}
+	if err := database.ConnectMongo(); err != nil {
+		log.Fatal(err)
+	}
+
+	store, err := storage.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := service.MigrateUploads(context.Background(), repository.NewAchievementMongoRepository(), store, service.UploadMigrationOptions{
+		Root:        *root,
+		DryRun:      *dryRun,
+		DeleteLocal: *deleteLocal,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if len(report.Failed) > 0 {
+		os.Exit(1)
+	}
+}
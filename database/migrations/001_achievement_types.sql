@@ -0,0 +1,25 @@
+-- Custom achievement types & custom field definitions
+
+CREATE TABLE IF NOT EXISTS achievement_types (
+    id             UUID PRIMARY KEY,
+    code           VARCHAR(50) NOT NULL UNIQUE,
+    name           VARCHAR(100) NOT NULL,
+    description    TEXT NOT NULL DEFAULT '',
+    default_points INTEGER NOT NULL DEFAULT 0,
+    is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS achievement_custom_fields (
+    id             UUID PRIMARY KEY,
+    type_code      VARCHAR(50) NOT NULL,
+    key            VARCHAR(50) NOT NULL,
+    label          VARCHAR(100) NOT NULL,
+    data_type      VARCHAR(20) NOT NULL,
+    required       BOOLEAN NOT NULL DEFAULT FALSE,
+    allowed_values TEXT[] NOT NULL DEFAULT '{}',
+    display_order  INTEGER NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (type_code, key)
+);
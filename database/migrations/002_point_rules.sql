@@ -0,0 +1,37 @@
+-- Versioned point rule sets & recalculation audit
+
+CREATE TABLE IF NOT EXISTS point_rule_sets (
+    id           UUID PRIMARY KEY,
+    name         VARCHAR(100) NOT NULL,
+    version      INTEGER NOT NULL UNIQUE,
+    status       VARCHAR(20) NOT NULL DEFAULT 'draft',
+    rules        JSONB NOT NULL DEFAULT '[]',
+    max_points   INTEGER NOT NULL DEFAULT 0,
+    created_by   UUID NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMP
+);
+
+-- maksimal satu rule set aktif
+CREATE UNIQUE INDEX IF NOT EXISTS point_rule_sets_one_active
+    ON point_rule_sets (status) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS point_recalculation_runs (
+    id           UUID PRIMARY KEY,
+    rule_set_id  UUID NOT NULL REFERENCES point_rule_sets(id),
+    status       VARCHAR(20) NOT NULL,
+    total        INTEGER NOT NULL DEFAULT 0,
+    changed      INTEGER NOT NULL DEFAULT 0,
+    error        TEXT NOT NULL DEFAULT '',
+    triggered_by UUID NOT NULL,
+    started_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS point_recalculation_audit (
+    run_id       UUID NOT NULL REFERENCES point_recalculation_runs(id),
+    reference_id UUID NOT NULL,
+    old_points   INTEGER NOT NULL,
+    new_points   INTEGER NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW()
+);
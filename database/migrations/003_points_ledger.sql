@@ -0,0 +1,32 @@
+-- Append-only points ledger
+
+CREATE TABLE IF NOT EXISTS points_ledger (
+    id           UUID PRIMARY KEY,
+    student_id   UUID NOT NULL REFERENCES students(id),
+    reference_id UUID REFERENCES achievement_references(id),
+    entry_type   VARCHAR(20) NOT NULL,
+    points       INTEGER NOT NULL,
+    period       VARCHAR(20) NOT NULL,
+    reason       TEXT NOT NULL DEFAULT '',
+    actor_id     UUID NOT NULL,
+    actor_role   VARCHAR(50) NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT points_ledger_entry_type_check
+        CHECK (entry_type IN ('earned', 'revoked', 'recalculated', 'adjustment', 'bonus'))
+);
+
+CREATE INDEX IF NOT EXISTS points_ledger_student_period ON points_ledger (student_id, period);
+CREATE INDEX IF NOT EXISTS points_ledger_reference ON points_ledger (reference_id);
+
+-- ledger hanya boleh ditambah, tidak diubah/dihapus
+CREATE OR REPLACE FUNCTION points_ledger_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'points_ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS points_ledger_no_update ON points_ledger;
+CREATE TRIGGER points_ledger_no_update
+    BEFORE UPDATE OR DELETE ON points_ledger
+    FOR EACH ROW EXECUTE FUNCTION points_ledger_append_only();
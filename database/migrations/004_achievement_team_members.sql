@@ -0,0 +1,16 @@
+-- Team achievements: anggota tim per prestasi
+
+CREATE TABLE IF NOT EXISTS achievement_team_members (
+    reference_id UUID NOT NULL REFERENCES achievement_references(id),
+    student_id   UUID NOT NULL REFERENCES students(id),
+    team_role    VARCHAR(50) NOT NULL,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending',
+    responded_at TIMESTAMP,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (reference_id, student_id),
+    CONSTRAINT achievement_team_members_status_check
+        CHECK (status IN ('pending', 'confirmed', 'declined'))
+);
+
+CREATE INDEX IF NOT EXISTS achievement_team_members_student ON achievement_team_members (student_id);
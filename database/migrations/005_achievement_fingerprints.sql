@@ -0,0 +1,13 @@
+-- Fingerprint prestasi untuk deteksi duplikat
+
+CREATE TABLE IF NOT EXISTS achievement_fingerprints (
+    reference_id UUID NOT NULL REFERENCES achievement_references(id),
+    student_id   UUID NOT NULL REFERENCES students(id),
+    kind         VARCHAR(30) NOT NULL,
+    value        TEXT NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (reference_id, kind, value)
+);
+
+CREATE INDEX IF NOT EXISTS achievement_fingerprints_lookup ON achievement_fingerprints (kind, value);
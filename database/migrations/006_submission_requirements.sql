@@ -0,0 +1,10 @@
+-- Syarat pengajuan prestasi per tipe
+
+CREATE TABLE IF NOT EXISTS submission_requirements (
+    type_code       VARCHAR(50) PRIMARY KEY,
+    min_attachments INTEGER NOT NULL DEFAULT 0,
+    required_kinds  TEXT[] NOT NULL DEFAULT '{}',
+    required_fields TEXT[] NOT NULL DEFAULT '{}',
+    updated_by      UUID,
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
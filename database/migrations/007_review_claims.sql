@@ -0,0 +1,14 @@
+-- Review queue: klaim reviewer dengan masa berlaku
+
+CREATE TABLE IF NOT EXISTS review_claims (
+    reference_id  UUID PRIMARY KEY REFERENCES achievement_references(id),
+    reviewer_id   UUID NOT NULL REFERENCES users(id),
+    reviewer_role VARCHAR(50) NOT NULL,
+    assigned_by   UUID REFERENCES users(id),
+    claimed_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS review_claims_reviewer ON review_claims (reviewer_id, expires_at);
+CREATE INDEX IF NOT EXISTS achievement_references_submitted_queue
+    ON achievement_references (submitted_at) WHERE status = 'submitted';
@@ -0,0 +1,15 @@
+-- SLA verifikasi: pengingat dan eskalasi yang sudah dikirim
+
+CREATE TABLE IF NOT EXISTS verification_sla_events (
+    id           UUID PRIMARY KEY,
+    reference_id UUID NOT NULL REFERENCES achievement_references(id),
+    kind         VARCHAR(20) NOT NULL,
+    recipient_id UUID NOT NULL REFERENCES users(id),
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT verification_sla_events_kind_check
+        CHECK (kind IN ('reminder', 'escalation'))
+);
+
+CREATE INDEX IF NOT EXISTS verification_sla_events_reference
+    ON verification_sla_events (reference_id, kind, created_at);
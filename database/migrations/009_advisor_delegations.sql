@@ -0,0 +1,24 @@
+-- Delegasi tugas dosen wali selama cuti
+
+CREATE TABLE IF NOT EXISTS advisor_delegations (
+    id               UUID PRIMARY KEY,
+    from_lecturer_id UUID NOT NULL REFERENCES lecturers(id),
+    to_lecturer_id   UUID NOT NULL REFERENCES lecturers(id),
+    starts_at        TIMESTAMP NOT NULL,
+    ends_at          TIMESTAMP NOT NULL,
+    reason           TEXT NOT NULL DEFAULT '',
+    created_by       UUID NOT NULL REFERENCES users(id),
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at       TIMESTAMP,
+    revoked_by       UUID REFERENCES users(id),
+
+    CONSTRAINT advisor_delegations_distinct CHECK (from_lecturer_id <> to_lecturer_id),
+    CONSTRAINT advisor_delegations_window CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS advisor_delegations_to ON advisor_delegations (to_lecturer_id, starts_at, ends_at);
+CREATE INDEX IF NOT EXISTS advisor_delegations_from ON advisor_delegations (from_lecturer_id);
+
+-- Aksi delegasi dicatat "atas nama" dosen wali asli
+ALTER TABLE achievement_reference_history
+    ADD COLUMN IF NOT EXISTS on_behalf_of UUID REFERENCES lecturers(id);
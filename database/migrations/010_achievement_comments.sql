@@ -0,0 +1,26 @@
+-- Diskusi per prestasi: komentar berutas, catatan internal reviewer,
+-- dan penanda baca per user
+
+CREATE TABLE IF NOT EXISTS achievement_comments (
+    id           UUID PRIMARY KEY,
+    reference_id UUID NOT NULL REFERENCES achievement_references(id),
+    parent_id    UUID REFERENCES achievement_comments(id),
+    author_id    UUID NOT NULL REFERENCES users(id),
+    author_role  VARCHAR(50) NOT NULL,
+    body         TEXT NOT NULL,
+    internal     BOOLEAN NOT NULL DEFAULT FALSE,
+    attachments  TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    edited_at    TIMESTAMP,
+    deleted_at   TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS achievement_comments_reference
+    ON achievement_comments (reference_id, created_at);
+
+CREATE TABLE IF NOT EXISTS achievement_comment_reads (
+    reference_id UUID NOT NULL REFERENCES achievement_references(id),
+    user_id      UUID NOT NULL REFERENCES users(id),
+    last_read_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (reference_id, user_id)
+);
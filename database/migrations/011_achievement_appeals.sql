@@ -0,0 +1,19 @@
+-- Banding atas penolakan prestasi. Status reference baru: 'appealed'.
+
+CREATE TABLE IF NOT EXISTS achievement_appeals (
+    id             UUID PRIMARY KEY,
+    reference_id   UUID NOT NULL UNIQUE REFERENCES achievement_references(id),
+    student_id     UUID NOT NULL REFERENCES students(id),
+    submitted_by   UUID NOT NULL REFERENCES users(id),
+    justification  TEXT NOT NULL,
+    evidence       TEXT[] NOT NULL DEFAULT '{}',
+    rejected_by    UUID REFERENCES users(id),
+    rejection_note TEXT,
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    decided_by     UUID REFERENCES users(id),
+    decision_note  TEXT,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    decided_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS achievement_appeals_status ON achievement_appeals (status, created_at);
@@ -0,0 +1,26 @@
+-- Review komite dengan voting untuk prestasi bernilai tinggi
+
+CREATE TABLE IF NOT EXISTS committees (
+    id         UUID PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    applies_to JSONB NOT NULL DEFAULT '{}',
+    members    UUID[] NOT NULL DEFAULT '{}',
+    quorum     INTEGER NOT NULL,
+    majority   VARCHAR(20) NOT NULL DEFAULT 'simple',
+    active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- catatan suara disimpan berdampingan dengan achievement_reference_history
+CREATE TABLE IF NOT EXISTS committee_votes (
+    reference_id UUID NOT NULL REFERENCES achievement_references(id),
+    committee_id UUID NOT NULL REFERENCES committees(id),
+    voter_id     UUID NOT NULL REFERENCES users(id),
+    voter_role   VARCHAR(50) NOT NULL,
+    vote         VARCHAR(10) NOT NULL,
+    comment      TEXT NOT NULL DEFAULT '',
+    voted_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (reference_id, voter_id)
+);
@@ -0,0 +1,21 @@
+-- Verifikasi eksternal oleh penyelenggara lomba lewat tautan bertanda tangan
+
+CREATE TABLE IF NOT EXISTS organizer_verifications (
+    id              UUID PRIMARY KEY,
+    reference_id    UUID NOT NULL REFERENCES achievement_references(id),
+    organizer_email VARCHAR(255) NOT NULL,
+    organizer_name  VARCHAR(255) NOT NULL DEFAULT '',
+    claimed_rank    INTEGER NOT NULL DEFAULT 0,
+    requested_by    UUID NOT NULL REFERENCES users(id),
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at      TIMESTAMP NOT NULL,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    responded_at    TIMESTAMP,
+    confirmed_rank  INTEGER,
+    response_note   TEXT,
+    responder_name  VARCHAR(255),
+    responder_ip    VARCHAR(64)
+);
+
+CREATE INDEX IF NOT EXISTS organizer_verifications_reference
+    ON organizer_verifications (reference_id, created_at);
@@ -0,0 +1,4 @@
+-- Hash SHA-256 lampiran yang tercatat pada riwayat verifikasi
+
+ALTER TABLE achievement_reference_history
+    ADD COLUMN IF NOT EXISTS evidence JSONB;
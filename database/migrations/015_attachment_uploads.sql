@@ -0,0 +1,18 @@
+-- Sesi upload lampiran bertahap (resumable, protokol tus); potongan file
+-- disimpan di backend storage, urutan key-nya di chunk_keys
+
+CREATE TABLE IF NOT EXISTS attachment_uploads (
+    id            UUID PRIMARY KEY,
+    reference_id  UUID NOT NULL REFERENCES achievement_references(id),
+    uploaded_by   UUID NOT NULL REFERENCES users(id),
+    file_name     TEXT NOT NULL,
+    kind          VARCHAR(50) NOT NULL,
+    length        BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    chunk_keys    TEXT[] NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS attachment_uploads_expires
+    ON attachment_uploads (expires_at);
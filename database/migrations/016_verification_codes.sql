@@ -0,0 +1,16 @@
+-- Kode verifikasi publik untuk prestasi terverifikasi, dicek pihak luar
+-- lewat GET /verify/:code atau QR code
+
+CREATE TABLE IF NOT EXISTS achievement_verification_codes (
+    code          VARCHAR(16) PRIMARY KEY,
+    reference_id  UUID NOT NULL REFERENCES achievement_references(id),
+    issued_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at    TIMESTAMP,
+    revoked_by    UUID REFERENCES users(id),
+    revoke_reason TEXT
+);
+
+-- satu kode aktif per prestasi; kode yang dicabut tetap disimpan supaya
+-- pemeriksa melihat "dicabut", bukan "tidak dikenal"
+CREATE UNIQUE INDEX IF NOT EXISTS achievement_verification_codes_active
+    ON achievement_verification_codes (reference_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,5 @@
+-- Aksi sistem (mis. eskalasi SLA) dicatat tanpa user: changed_by NULL
+-- dengan changed_by_role 'System'
+
+ALTER TABLE achievement_reference_history
+    ALTER COLUMN changed_by DROP NOT NULL;
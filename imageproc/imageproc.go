@@ -0,0 +1,168 @@
+// Package imageproc memproses lampiran gambar: membuang metadata (EXIF/GPS),
+// menormalkan orientasi dan membuat thumbnail. Hanya memakai library
+// standar (image/jpeg, image/png).
+package imageproc
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // decoder PNG untuk image.Decode
+)
+
+const (
+	// maxPixels: batas dimensi gambar yang mau di-decode (melindungi dari
+	// "decompression bomb").
+	maxPixels = 50_000_000
+
+	normalizedQuality = 92
+	thumbnailQuality  = 80
+)
+
+// StripMetadata mengembalikan isi gambar tanpa metadata. JPEG dengan
+// orientasi EXIF selain normal diputar dulu (di-encode ulang) karena
+// informasi orientasinya ikut terbuang. Tipe selain JPEG/PNG dikembalikan
+// apa adanya.
+func StripMetadata(data []byte, mimeType string) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		out, orientation, err := stripJPEG(data)
+		if err != nil {
+			return nil, err
+		}
+		if orientation == 1 {
+			return out, nil
+		}
+		img, err := decode(out)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, orient(toRGBA(img), orientation), &jpeg.Options{Quality: normalizedQuality}); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "image/png":
+		return stripPNG(data)
+	}
+	return data, nil
+}
+
+// Thumbnail: JPEG dengan sisi terpanjang maxEdge piksel (tidak diperbesar).
+// Transparansi PNG diganti latar putih.
+func Thumbnail(data []byte, maxEdge int) ([]byte, error) {
+	img, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	src := toRGBA(img)
+
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	tw, th := w, h
+	if w > maxEdge || h > maxEdge {
+		if w >= h {
+			tw, th = maxEdge, max(1, h*maxEdge/w)
+		} else {
+			tw, th = max(1, w*maxEdge/h), maxEdge
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resize(src, tw, th), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return img, nil
+}
+
+// toRGBA: salinan RGBA berawal (0,0) di atas latar putih.
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Rect, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Rect, img, b.Min, draw.Over)
+	return dst
+}
+
+// orient menerapkan orientasi EXIF (2–8) sehingga gambar tampil tegak.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // cermin horizontal
+				sx, sy = w-1-x, y
+			case 3: // putar 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // cermin vertikal
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // putar 90° searah jarum jam
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // putar 90° berlawanan jarum jam
+				sx, sy = w-1-y, x
+			default:
+				sx, sy = x, y
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// resize mengecilkan gambar dengan rata-rata area (box filter).
+func resize(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*sh/dh, max((y+1)*sh/dh, y*sh/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*sw/dw, max((x+1)*sw/dw, x*sw/dw+1)
+
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			o := dst.PixOffset(x, y)
+			dst.Pix[o], dst.Pix[o+1], dst.Pix[o+2], dst.Pix[o+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
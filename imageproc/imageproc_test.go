@@ -0,0 +1,215 @@
+package imageproc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ================= HELPERS =================
+
+// testImage: w×h, piksel kiri-atas merah, sisanya biru.
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{0, 0, 255, 255})
+		}
+	}
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	return img
+}
+
+// exifSegment: APP1 berisi tag Orientation + teks GPS palsu.
+func exifSegment(orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a\x00\x00\x00\x08")
+	binary.Write(&tiff, binary.BigEndian, uint16(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&tiff, binary.BigEndian, uint32(0))
+	tiff.WriteString("GPSLatitude -6.2088")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	seg := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+func jpegWithExif(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}))
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, exifSegment(orientation)...)
+	return append(out, data[2:]...)
+}
+
+func pngChunk(typ string, data []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.WriteString(typ)
+	buf.Write(data)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(typ), data...)))
+	return buf.Bytes()
+}
+
+// ================= TESTS =================
+
+func TestStripMetadata_JPEGRemovesExif(t *testing.T) {
+	data := jpegWithExif(t, testImage(4, 2), 1)
+	assert.Equal(t, 6, exifOrientation(exifSegment(6)[4:]))
+
+	out, err := StripMetadata(data, "image/jpeg")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "Exif")
+	assert.NotContains(t, string(out), "GPSLatitude")
+	// tanpa rotasi: isi gambar tidak di-encode ulang
+	assert.Equal(t, len(data)-len(exifSegment(1)), len(out))
+
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), img.Bounds())
+}
+
+func TestStripMetadata_JPEGNormalizesOrientation(t *testing.T) {
+	// orientasi 6: kamera diputar, gambar harus diputar 90° searah jarum jam
+	src := testImage(32, 16)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			src.Set(x, y, color.RGBA{255, 0, 0, 255})
+		}
+	}
+	out, err := StripMetadata(jpegWithExif(t, src, 6), "image/jpeg")
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "GPSLatitude")
+
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 32), img.Bounds())
+
+	// blok merah kiri-atas pindah ke kanan-atas
+	r, _, b, _ := img.At(12, 3).RGBA()
+	assert.Greater(t, r, b)
+	r, _, b, _ = img.At(3, 3).RGBA()
+	assert.Greater(t, b, r)
+}
+
+func TestOrient_AllOrientations(t *testing.T) {
+	src := testImage(3, 2)
+	red := color.RGBA{255, 0, 0, 255}
+	// posisi piksel kiri-atas setelah orientasi diterapkan
+	want := map[int]image.Point{
+		1: {0, 0}, 2: {2, 0}, 3: {2, 1}, 4: {0, 1},
+		5: {0, 0}, 6: {1, 0}, 7: {1, 2}, 8: {0, 2},
+	}
+	for o, p := range want {
+		dst := orient(src, o)
+		assert.Equal(t, red, dst.RGBAAt(p.X, p.Y), "orientasi %d", o)
+	}
+}
+
+func TestStripMetadata_PNGRemovesTextChunks(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(2, 2)))
+	data := buf.Bytes()
+
+	// sisipkan tEXt setelah IHDR (8 signature + 25 IHDR)
+	withText := append(append(append([]byte{}, data[:33]...), pngChunk("tEXt", []byte("GPS\x00-6.2088"))...), data[33:]...)
+
+	out, err := StripMetadata(withText, "image/png")
+	assert.NoError(t, err)
+	assert.Equal(t, data, out)
+
+	_, err = StripMetadata([]byte("bukan png"), "image/png")
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestStripMetadata_OtherTypesUnchanged(t *testing.T) {
+	out, err := StripMetadata([]byte("%PDF-1.4"), "application/pdf")
+	assert.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4", string(out))
+}
+
+func TestThumbnail(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(400, 100)))
+
+	out, err := Thumbnail(buf.Bytes(), 160)
+	assert.NoError(t, err)
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 160, 40), img.Bounds())
+
+	// gambar kecil tidak diperbesar
+	buf.Reset()
+	png.Encode(&buf, testImage(50, 80))
+	out, _ = Thumbnail(buf.Bytes(), 160)
+	img, _ = jpeg.Decode(bytes.NewReader(out))
+	assert.Equal(t, image.Rect(0, 0, 50, 80), img.Bounds())
+
+	_, err = Thumbnail([]byte("rusak"), 160)
+	assert.ErrorIs(t, err, ErrInvalidImage)
+}
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	p := NewPool(2, 10)
+	defer p.Close()
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Do(context.Background(), func() error {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					old := atomic.LoadInt32(&peak)
+					if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, peak, int32(2))
+}
+
+func TestPool_GoQueueFull(t *testing.T) {
+	p := NewPool(1, 1)
+	defer p.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	assert.True(t, p.Go(func() { close(started); <-block }))
+	<-started
+	assert.True(t, p.Go(func() {}))
+	assert.False(t, p.Go(func() {}))
+	close(block)
+}
+
+func TestPool_NilRunsInline(t *testing.T) {
+	var p *Pool
+	ran := false
+	assert.True(t, p.Go(func() { ran = true }))
+	assert.True(t, ran)
+	assert.NoError(t, p.Do(context.Background(), func() error { return nil }))
+	p.Close()
+}
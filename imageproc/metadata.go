@@ -0,0 +1,176 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	ErrInvalidImage = errors.New("imageproc: file gambar rusak")
+	ErrTooLarge     = errors.New("imageproc: dimensi gambar terlalu besar")
+)
+
+// ======================================================
+// JPEG — segmen metadata & orientasi EXIF
+// ======================================================
+
+// dropJPEGSegment: segmen yang bisa berisi data pribadi (EXIF/GPS, XMP,
+// IPTC, komentar). APP0 (JFIF), APP2 (profil warna) dan APP14 (Adobe,
+// dibutuhkan untuk CMYK) dipertahankan.
+func dropJPEGSegment(marker byte) bool {
+	switch {
+	case marker == 0xE1, marker == 0xFE:
+		return true
+	case marker >= 0xE3 && marker <= 0xED, marker == 0xEF:
+		return true
+	}
+	return false
+}
+
+// walkJPEG memanggil fn untuk setiap segmen sebelum SOS; rest = data mulai
+// SOS (isi gambar) yang disalin apa adanya.
+func walkJPEG(data []byte, fn func(marker byte, segment []byte)) (rest []byte, err error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrInvalidImage
+	}
+	i := 2
+	for i+1 < len(data) {
+		if data[i] != 0xFF {
+			return nil, ErrInvalidImage
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// byte pengisi
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			return data[i:], nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			fn(marker, data[i:i+2])
+			i += 2
+			continue
+		}
+		if i+4 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:i+4]))
+		if end > len(data) || end < i+4 {
+			return nil, ErrInvalidImage
+		}
+		fn(marker, data[i:end])
+		i = end
+	}
+	return nil, ErrInvalidImage
+}
+
+// stripJPEG membuang segmen metadata tanpa meng-encode ulang gambar.
+func stripJPEG(data []byte) (out []byte, orientation int, err error) {
+	orientation = 1
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+	buf.Write(data[:2])
+	rest, err := walkJPEG(data, func(marker byte, segment []byte) {
+		if marker == 0xE1 && orientation == 1 {
+			orientation = exifOrientation(segment[4:])
+		}
+		if !dropJPEGSegment(marker) {
+			buf.Write(segment)
+		}
+	})
+	if err != nil {
+		return nil, 1, err
+	}
+	buf.Write(rest)
+	return buf.Bytes(), orientation, nil
+}
+
+// exifOrientation membaca tag Orientation (0x0112) dari IFD0 payload APP1
+// "Exif\0\0". Nilai tidak dikenal dianggap 1 (normal).
+func exifOrientation(payload []byte) int {
+	if !bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+		return 1
+	}
+	tiff := payload[6:]
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for k := 0; k < entries; k++ {
+		e := ifd + 2 + 12*k
+		if e+12 > len(tiff) {
+			return 1
+		}
+		// tag Orientation bertipe SHORT (3)
+		if order.Uint16(tiff[e:e+2]) != 0x0112 || order.Uint16(tiff[e+2:e+4]) != 3 {
+			continue
+		}
+		if v := int(order.Uint16(tiff[e+8 : e+10])); v >= 1 && v <= 8 {
+			return v
+		}
+		return 1
+	}
+	return 1
+}
+
+// ======================================================
+// PNG — chunk teks/EXIF
+// ======================================================
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// dropPNGChunk: chunk metadata (teks bebas, EXIF, waktu pembuatan).
+var dropPNGChunk = map[string]bool{
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"eXIf": true,
+	"tIME": true,
+}
+
+// stripPNG membuang chunk metadata; chunk gambar disalin apa adanya.
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrInvalidImage
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+	buf.Write(pngSignature)
+
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		// [panjang 4][tipe 4][data][crc 4]
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:i+4]))
+		if end > len(data) || end < i+12 {
+			return nil, ErrInvalidImage
+		}
+		chunkType := string(data[i+4 : i+8])
+		if !dropPNGChunk[chunkType] {
+			buf.Write(data[i:end])
+		}
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return buf.Bytes(), nil
+}
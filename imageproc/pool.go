@@ -0,0 +1,125 @@
+package imageproc
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// Pool membatasi jumlah pemrosesan gambar yang berjalan bersamaan.
+// Pekerjaan dari request (Do) selalu didahulukan dari pekerjaan latar
+// (Go, mis. thumbnail). Pool nil menjalankan pekerjaan langsung di
+// goroutine pemanggil.
+type Pool struct {
+	urgent     chan func()
+	background chan func()
+	quit       chan struct{}
+	wg         sync.WaitGroup
+	closeOnce  sync.Once
+}
+
+// NewPool menjalankan workers goroutine; queue = kapasitas antrean latar.
+func NewPool(workers, queue int) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool{
+		urgent:     make(chan func()),
+		background: make(chan func(), queue),
+		quit:       make(chan struct{}),
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// PoolFromEnv: IMAGE_WORKERS (default jumlah CPU, maks 4) dan IMAGE_QUEUE
+// (default 64).
+func PoolFromEnv() *Pool {
+	return NewPool(envInt("IMAGE_WORKERS", min(runtime.NumCPU(), 4)), envInt("IMAGE_QUEUE", 64))
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case fn := <-p.urgent:
+			fn()
+			continue
+		default:
+		}
+		select {
+		case fn := <-p.urgent:
+			fn()
+		case fn := <-p.background:
+			fn()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// Do menjalankan fn di worker dan menunggu hasilnya. ctx selesai sebelum
+// fn dijalankan → ctx.Err().
+func (p *Pool) Do(ctx context.Context, fn func() error) error {
+	if p == nil {
+		return fn()
+	}
+	done := make(chan error, 1)
+	task := func() {
+		if ctx.Err() != nil {
+			done <- ctx.Err()
+			return
+		}
+		done <- fn()
+	}
+	select {
+	case p.urgent <- task:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.quit:
+		return context.Canceled
+	}
+	return <-done
+}
+
+// Go mengantrekan fn tanpa menunggu. false = antrean penuh (fn tidak
+// dijalankan).
+func (p *Pool) Go(fn func()) bool {
+	if p == nil {
+		fn()
+		return true
+	}
+	select {
+	case <-p.quit:
+		return false
+	default:
+	}
+	select {
+	case p.background <- fn:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close menghentikan worker setelah pekerjaan yang sedang berjalan selesai;
+// antrean latar yang tersisa dibuang.
+func (p *Pool) Close() {
+	if p == nil {
+		return
+	}
+	p.closeOnce.Do(func() { close(p.quit) })
+	p.wg.Wait()
+}
+
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"time"
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
 
 	"prestasi_api/app/repository"
 	"prestasi_api/app/service"
 	"prestasi_api/database"
+	"prestasi_api/imageproc"
 	"prestasi_api/route"
+	"prestasi_api/scanner"
+	"prestasi_api/scheduler"
+	"prestasi_api/storage"
 
 	
 )
@@ -30,7 +37,9 @@ if err := database.ConnectMongo(); err != nil {
     log.Fatal(err)
 }
 
-	app := fiber.New()
+	// BodyLimit sedikit di atas batas lampiran (5 MB) supaya upload terlalu
+	// besar tetap mendapat pesan validasi dari UploadAttachment
+	app := fiber.New(fiber.Config{BodyLimit: 6 << 20})
 
 	// ===== REPOSITORY =====
 	achievementMongoRepo := repository.NewAchievementMongoRepository()
@@ -39,6 +48,28 @@ if err := database.ConnectMongo(); err != nil {
 	userRepo := repository.NewUserPostgresRepository()
 	roleRepo := repository.NewRolePostgresRepository()
 	lecturerRepo := repository.NewLecturerPostgresRepository()
+	achievementTypeRepo := repository.NewAchievementTypePostgresRepository()
+	pointRuleRepo := repository.NewPointRulePostgresRepository()
+	pointsLedgerRepo := repository.NewPointsLedgerPostgresRepository()
+	achievementTeamRepo := repository.NewAchievementTeamPostgresRepository()
+	achievementFingerprintRepo := repository.NewAchievementFingerprintPostgresRepository()
+	submissionRequirementRepo := repository.NewSubmissionRequirementPostgresRepository()
+	reviewClaimRepo := repository.NewReviewClaimPostgresRepository()
+	verificationSLARepo := repository.NewVerificationSLAPostgresRepository()
+	advisorDelegationRepo := repository.NewAdvisorDelegationPostgresRepository()
+	achievementCommentRepo := repository.NewAchievementCommentPostgresRepository()
+	achievementAppealRepo := repository.NewAchievementAppealPostgresRepository()
+	committeeRepo := repository.NewCommitteePostgresRepository()
+	organizerVerificationRepo := repository.NewOrganizerVerificationPostgresRepository()
+	attachmentUploadRepo := repository.NewAttachmentUploadPostgresRepository()
+	verificationCodeRepo := repository.NewVerificationCodePostgresRepository()
+	attachmentStore, err := storage.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	attachmentScanner := scanner.FromEnv()
+	imagePool := imageproc.PoolFromEnv()
+	defer imagePool.Close()
 	// ===== SERVICE =====
 	authSvc := service.NewAuthService(
     userRepo,
@@ -48,12 +79,69 @@ if err := database.ConnectMongo(); err != nil {
 )
 
 
+	linkSigner := service.LinkSignerFromEnv()
 	achievementSvc := &service.AchievementService{
-		MongoRepo:    achievementMongoRepo,
-		PostgresRepo: achievementPostgresRepo,
-		StudentRepo:  studentRepo,
-		
+		MongoRepo:           achievementMongoRepo,
+		PostgresRepo:        achievementPostgresRepo,
+		StudentRepo:         studentRepo,
+		TypeRepo:            achievementTypeRepo,
+		RuleRepo:            pointRuleRepo,
+		LedgerRepo:          pointsLedgerRepo,
+		TeamRepo:            achievementTeamRepo,
+		FingerprintRepo:     achievementFingerprintRepo,
+		RequirementRepo:     submissionRequirementRepo,
+		ClaimRepo:           reviewClaimRepo,
+		DelegationRepo:      advisorDelegationRepo,
+		CommentRepo:         achievementCommentRepo,
+		AppealRepo:          achievementAppealRepo,
+		CommitteeRepo:       committeeRepo,
+		Storage:             attachmentStore,
+		Scanner:             attachmentScanner,
+		Images:              imagePool,
+		UploadRepo:          attachmentUploadRepo,
+		Signer:              &linkSigner,
+		CodeRepo:            verificationCodeRepo,
+		PublicBaseURL:       os.Getenv("PUBLIC_BASE_URL"),
+		UploadExpiry:        service.UploadExpiryFromEnv(),
+		ResumableMaxSize:    service.ResumableMaxSizeFromEnv(),
+		AttachmentRetention: service.AttachmentRetentionFromEnv(),
+		IntegrityRecheck:    service.IntegrityRecheckFromEnv(),
 	}
+	achievementTypeSvc := service.NewAchievementTypeService(achievementTypeRepo)
+	submissionRequirementSvc := service.NewSubmissionRequirementService(submissionRequirementRepo, achievementTypeRepo)
+	pointRuleSvc := service.NewPointRuleService(
+		pointRuleRepo,
+		achievementMongoRepo,
+		achievementPostgresRepo,
+		achievementTypeRepo,
+	)
+	pointsLedgerSvc := service.NewPointsLedgerService(
+		pointsLedgerRepo,
+		studentRepo,
+		achievementMongoRepo,
+		achievementPostgresRepo,
+		pointRuleRepo,
+		achievementTypeRepo,
+		achievementTeamRepo,
+		advisorDelegationRepo,
+	)
+	advisorDelegationSvc := service.NewAdvisorDelegationService(advisorDelegationRepo, lecturerRepo)
+	committeeSvc := service.NewCommitteeService(committeeRepo, reviewClaimRepo)
+	slaCfg := service.SLAConfigFromEnv()
+	verificationSLASvc := service.NewVerificationSLAService(
+		verificationSLARepo,
+		achievementPostgresRepo,
+		reviewClaimRepo,
+		service.LogNotifier{},
+		slaCfg,
+	)
+	organizerVerificationSvc := service.NewOrganizerVerificationService(
+		organizerVerificationRepo,
+		achievementSvc,
+		service.MailerFromEnv(),
+		linkSigner,
+		os.Getenv("PUBLIC_BASE_URL"),
+	)
 // === Tambahkan service lainnya sesuai modul ===
 // userSvc := service.NewUserService(userRepo, roleRepo)
 userSvc := service.NewUserService(
@@ -65,7 +153,7 @@ userSvc := service.NewUserService(
 
 lecturerSvc := service.NewLecturerService(studentRepo, lecturerRepo)
 
-reportSvc := service.NewReportService(achievementMongoRepo, studentRepo) // nuat 5.8 yang pertama
+reportSvc := service.NewReportService(achievementMongoRepo, studentRepo, pointsLedgerRepo, achievementTeamRepo, advisorDelegationRepo) // nuat 5.8 yang pertama
 // 	// ===== ROUTES =====
 	route.AuthRouter(app, authSvc)
 route.AchievementRouter(app, achievementSvc)
@@ -73,8 +161,28 @@ route.UserRouter(app, userSvc)
 route.LecturerRouter(app, lecturerSvc)
 route.AdminAchievementRouter(app, achievementSvc)
 route.ReportRouter(app, reportSvc)
-studentSvc := service.NewStudentService(studentRepo, lecturerRepo)
+studentSvc := service.NewStudentService(studentRepo, lecturerRepo, advisorDelegationRepo)
 route.StudentRouter(app, studentSvc)
+route.AchievementTypeRouter(app, achievementTypeSvc)
+route.PointRuleRouter(app, pointRuleSvc)
+route.PointsLedgerRouter(app, pointsLedgerSvc)
+route.SubmissionRequirementRouter(app, submissionRequirementSvc)
+route.VerificationSLARouter(app, verificationSLASvc)
+route.AdvisorDelegationRouter(app, advisorDelegationSvc)
+route.CommitteeRouter(app, committeeSvc)
+route.OrganizerVerificationRouter(app, organizerVerificationSvc)
+
+	// ===== SCHEDULER =====
+	jobs := scheduler.New()
+	jobs.Every("verification-sla", slaCfg.CheckInterval, verificationSLASvc.Run)
+	jobs.Every("attachment-purge", 6*time.Hour, achievementSvc.RunAttachmentPurge)
+	jobs.Every("upload-expiry", time.Hour, achievementSvc.RunUploadExpiry)
+	jobs.Every("attachment-integrity", service.IntegrityCheckIntervalFromEnv(), achievementSvc.RunIntegrityCheck)
+	if attachmentScanner != nil {
+		jobs.Every("attachment-scan", service.ScanIntervalFromEnv(), achievementSvc.RunAttachmentScan)
+	}
+	jobs.Start(context.Background())
+	defer jobs.Stop()
 
 
 
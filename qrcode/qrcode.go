@@ -0,0 +1,477 @@
+// Package qrcode membuat QR code (ISO/IEC 18004) mode byte dengan tingkat
+// koreksi error M, versi 1–10 (maksimal 213 byte) — cukup untuk URL
+// verifikasi. Hanya memakai standard library.
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+var ErrTooLong = errors.New("qrcode: data terlalu panjang")
+
+const (
+	minVersion = 1
+	maxVersion = 10
+
+	// quietZone: batas kosong di sekeliling simbol (modul)
+	quietZone = 4
+)
+
+// Tingkat koreksi M per versi (indeks 0 tidak dipakai).
+var (
+	eccPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	numBlocks   = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// Code: matriks modul QR; true = modul gelap.
+type Code struct {
+	Version int
+	Size    int
+	Mask    int
+
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// Dark: warna modul di kolom x, baris y.
+func (q *Code) Dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < q.Size && y < q.Size && q.modules[y][x]
+}
+
+// Encode memilih versi terkecil yang muat dan mask dengan penalti terendah.
+func Encode(data string) (*Code, error) {
+	for v := minVersion; v <= maxVersion; v++ {
+		if capacityBits(v) >= dataBits(v, len(data)) {
+			return build(v, encodeData(v, []byte(data)))
+		}
+	}
+	return nil, ErrTooLong
+}
+
+// ======================================================
+// DATA & ECC
+// ======================================================
+
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+func dataBits(version, n int) int {
+	return 4 + countBits(version) + 8*n
+}
+
+// rawModules: modul yang tersedia untuk data+ECC setelah pola fungsi.
+func rawModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func capacityBits(version int) int {
+	return (rawModules(version)/8 - eccPerBlock[version]*numBlocks[version]) * 8
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (val>>uint(i))&1 != 0)
+	}
+}
+
+// encodeData: segmen mode byte + terminator + padding, lalu ECC dan
+// interleave antar blok.
+func encodeData(version int, data []byte) []byte {
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(len(data), countBits(version))
+	for _, c := range data {
+		bb.append(int(c), 8)
+	}
+
+	capacity := capacityBits(version)
+	bb.append(0, min(4, capacity-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << uint(7-i&7)
+		}
+	}
+	return addECC(version, codewords)
+}
+
+func addECC(version int, data []byte) []byte {
+	blocks, eccLen := numBlocks[version], eccPerBlock[version]
+	raw := rawModules(version) / 8
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw/blocks - eccLen
+
+	divisor := rsDivisor(eccLen)
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen
+		if i >= shortBlocks {
+			n++
+		}
+		dataBlocks[i] = data[k : k+n]
+		eccBlocks[i] = rsRemainder(dataBlocks[i], divisor)
+		k += n
+	}
+
+	result := make([]byte, 0, raw)
+	for i := 0; i <= shortLen; i++ {
+		for _, b := range dataBlocks {
+			if i < len(b) {
+				result = append(result, b[i])
+			}
+		}
+	}
+	for i := 0; i < eccLen; i++ {
+		for _, b := range eccBlocks {
+			result = append(result, b[i])
+		}
+	}
+	return result
+}
+
+// ======================================================
+// MATRIKS
+// ======================================================
+
+func build(version int, codewords []byte) (*Code, error) {
+	size := version*4 + 17
+	q := &Code{Version: version, Size: size}
+	q.modules = make([][]bool, size)
+	q.isFunction = make([][]bool, size)
+	for i := range q.modules {
+		q.modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+
+	q.drawFunctionPatterns()
+	q.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		q.applyMask(mask) // XOR: kembalikan
+	}
+	q.Mask = best
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return q, nil
+}
+
+func (q *Code) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	numAlign := version/7 + 2
+	step := (version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+func (q *Code) drawFunctionPatterns() {
+	for i := 0; i < q.Size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinder(3, 3)
+	q.drawFinder(q.Size-4, 3)
+	q.drawFinder(3, q.Size-4)
+
+	pos := alignmentPositions(q.Version)
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			// posisi yang bertabrakan dengan finder dilewati
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignment(pos[i], pos[j])
+		}
+	}
+
+	q.drawFormatBits(0) // cadangan, ditimpa setelah mask dipilih
+	q.drawVersion()
+}
+
+func (q *Code) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || yy < 0 || xx >= q.Size || yy >= q.Size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			q.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *Code) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// formatBits: 2 bit tingkat koreksi (M = 00) + 3 bit mask, BCH(15,5).
+func formatBits(mask int) int {
+	data := mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (q *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.Size-15+i, bit(i))
+	}
+	q.setFunction(8, q.Size-8, true) // modul gelap tetap
+}
+
+// versionBits: 6 bit versi, BCH(18,6); hanya versi 7 ke atas.
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+func (q *Code) drawVersion() {
+	if q.Version < 7 {
+		return
+	}
+	bits := versionBits(q.Version)
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := q.Size-11+i%3, i/3
+		q.setFunction(a, b, dark)
+		q.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords: zig-zag dua kolom dari kanan bawah, melewati kolom timing.
+func (q *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.Size; vert++ {
+			y := vert
+			if upward {
+				y = q.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.isFunction[y][x] || i >= len(data)*8 {
+					continue
+				}
+				q.modules[y][x] = (data[i>>3]>>uint(7-i&7))&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+func (q *Code) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if !q.isFunction[y][x] && maskBit(mask, x, y) {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// ======================================================
+// PENALTI MASK (ISO/IEC 18004 bagian 7.8.3)
+// ======================================================
+
+const (
+	penaltyRun     = 3
+	penaltyBlock   = 3
+	penaltyFinder  = 40
+	penaltyBalance = 10
+)
+
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+func (q *Code) penalty() int {
+	result := 0
+	line := make([]bool, q.Size)
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < q.Size; i++ {
+			for j := 0; j < q.Size; j++ {
+				if vertical {
+					line[j] = q.modules[j][i]
+				} else {
+					line[j] = q.modules[i][j]
+				}
+			}
+			result += linePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			c := q.modules[y][x]
+			if c {
+				dark++
+			}
+			if x+1 < q.Size && y+1 < q.Size &&
+				c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+				result += penaltyBlock
+			}
+		}
+	}
+
+	total := q.Size * q.Size
+	result += abs(dark*100/total-50) / 5 * penaltyBalance
+	return result
+}
+
+func linePenalty(line []bool) int {
+	result, run := 0, 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			result += penaltyRun + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike[0]) <= len(line); i++ {
+		for _, pattern := range finderLike {
+			match := true
+			for k, p := range pattern {
+				if line[i+k] != p {
+					match = false
+					break
+				}
+			}
+			if match {
+				result += penaltyFinder
+			}
+		}
+	}
+	return result
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// ======================================================
+// OUTPUT
+// ======================================================
+
+// PNG: gambar hitam-putih, scale piksel per modul, dengan quiet zone.
+func (q *Code) PNG(scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+	side := (q.Size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			if q.Dark(x/scale-quietZone, y/scale-quietZone) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
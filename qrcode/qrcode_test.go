@@ -0,0 +1,139 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReedSolomon_SpecExample(t *testing.T) {
+	// contoh 1-M "HELLO WORLD" (ISO/IEC 18004 lampiran I)
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	assert.Equal(t, want, rsRemainder(data, rsDivisor(10)))
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	// tabel format string tingkat M, mask 0–7
+	want := []string{
+		"101010000010010", "101000100100101", "101111001111100", "101101101001011",
+		"100010111111001", "100000011001110", "100111110010111", "100101010100000",
+	}
+	for mask, s := range want {
+		bits, _ := strconv.ParseInt(s, 2, 32)
+		assert.Equal(t, int(bits), formatBits(mask), "mask %d", mask)
+	}
+
+	v7, _ := strconv.ParseInt("000111110010010100", 2, 32)
+	assert.Equal(t, int(v7), versionBits(7))
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	assert.Nil(t, alignmentPositions(1))
+	assert.Equal(t, []int{6, 18}, alignmentPositions(2))
+	assert.Equal(t, []int{6, 22, 38}, alignmentPositions(7))
+	assert.Equal(t, []int{6, 28, 50}, alignmentPositions(10))
+}
+
+// readCodewords membaca ulang matriks: format info, unmask, zig-zag.
+func readCodewords(q *Code) (int, []byte) {
+	format := 0
+	for i := 0; i <= 5; i++ {
+		if q.modules[i][8] {
+			format |= 1 << uint(i)
+		}
+	}
+	for i, p := range [][2]int{{8, 7}, {8, 8}, {7, 8}} {
+		if q.modules[p[1]][p[0]] {
+			format |= 1 << uint(6+i)
+		}
+	}
+	for i := 9; i < 15; i++ {
+		if q.modules[8][14-i] {
+			format |= 1 << uint(i)
+		}
+	}
+	mask := (format ^ 0x5412) >> 10 & 7
+
+	var out []byte
+	var cur byte
+	n := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.Size; vert++ {
+			y := vert
+			if upward {
+				y = q.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if q.isFunction[y][x] {
+					continue
+				}
+				bit := q.modules[y][x] != maskBit(mask, x, y)
+				cur <<= 1
+				if bit {
+					cur |= 1
+				}
+				if n++; n%8 == 0 {
+					out = append(out, cur)
+					cur = 0
+				}
+			}
+		}
+	}
+	return format, out
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		data    string
+		version int
+	}{
+		{"HELLO", 1},
+		{"https://prestasi.example.ac.id/verify/ABCD-EFGH-JKMN", 4},
+		{strings.Repeat("x", 150), 8},
+		{strings.Repeat("y", 213), 10},
+	} {
+		q, err := Encode(tc.data)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.version, q.Version, tc.data)
+		assert.Equal(t, tc.version*4+17, q.Size)
+
+		format, codewords := readCodewords(q)
+		assert.Equal(t, formatBits(q.Mask), format)
+		want := encodeData(q.Version, []byte(tc.data))
+		assert.Equal(t, want, codewords[:len(want)])
+
+		// modul gelap tetap & pola finder
+		assert.True(t, q.Dark(8, q.Size-8))
+		assert.True(t, q.Dark(0, 0) && q.Dark(6, 6) && !q.Dark(1, 1) && q.Dark(2, 2))
+	}
+
+	_, err := Encode(strings.Repeat("z", 214))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestPNG(t *testing.T) {
+	q, _ := Encode("HELLO")
+	out, err := q.PNG(4)
+	assert.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(out))
+	assert.NoError(t, err)
+	side := (21 + 2*quietZone) * 4
+	assert.Equal(t, side, img.Bounds().Dx())
+
+	// quiet zone putih, sudut finder hitam
+	r, _, _, _ := img.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	r, _, _, _ = img.At(quietZone*4, quietZone*4).RGBA()
+	assert.Equal(t, uint32(0), r)
+}
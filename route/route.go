@@ -15,6 +15,9 @@ func AuthRouter(app *fiber.App, svc *service.AuthService) {
 }
 // ACHIEVEMENT (Mahasiswa)
 func AchievementRouter(app *fiber.App, svc *service.AchievementService) {
+    // Discovery tus tanpa login; didaftarkan sebelum grup supaya tidak
+    // melewati JWTMiddleware
+    app.Options("/api/v1/achievements/:refId/uploads", svc.ResumableOptions)
     api := app.Group("/api/v1/achievements",
         middleware.JWTMiddleware(),
     )
@@ -29,13 +32,60 @@ api.Put("/:refId", middleware.RoleGuard("Mahasiswa", "Admin"), svc.Update)
     api.Post("/:refId/submit", middleware.RoleGuard("Mahasiswa", "Admin"), svc.Submit)
     // Upload attachment => Mahasiswa & Admin
     api.Post("/:refId/attachments", middleware.RoleGuard("Mahasiswa", "Admin"), svc.UploadAttachment)
+    // Daftar & unduh lampiran => aturan akses sama dengan Detail
+    api.Get("/:refId/attachments", svc.ListAttachments)
+    api.Post("/:refId/uploads", middleware.RoleGuard("Mahasiswa", "Admin"), svc.CreateResumableUpload)
+    api.Head("/:refId/uploads/:uploadId", middleware.RoleGuard("Mahasiswa", "Admin"), svc.ResumableUploadStatus)
+    api.Patch("/:refId/uploads/:uploadId", middleware.RoleGuard("Mahasiswa", "Admin"), svc.PatchResumableUpload)
+    api.Delete("/:refId/uploads/:uploadId", middleware.RoleGuard("Mahasiswa", "Admin"), svc.DeleteResumableUpload)
+    api.Get("/:refId/attachments/:attachmentId", svc.DownloadAttachment)
+    api.Get("/:refId/attachments/:attachmentId/thumbnail", svc.AttachmentThumbnail)
+    // Tautan bertanda tangan untuk <img>/<iframe> => aturan akses sama dengan unduh
+    api.Post("/:refId/attachments/:attachmentId/signed-url", svc.SignAttachmentURL)
+    // Ganti & hapus lampiran => Mahasiswa (draft) & Admin; versi lama => reviewer
+    api.Put("/:refId/attachments/:attachmentId", middleware.RoleGuard("Mahasiswa", "Admin"), svc.ReplaceAttachment)
+    api.Delete("/:refId/attachments/:attachmentId", middleware.RoleGuard("Mahasiswa", "Admin"), svc.DeleteAttachment)
+    api.Get("/:refId/attachments/:attachmentId/versions/:version", middleware.RoleGuard("Dosen Wali", "Admin"), svc.DownloadAttachmentVersion)
+    // Batch verify / reject => Dosen Wali & Admin (sebelum /:refId supaya "batch" tidak dianggap refId)
+    api.Post("/batch/verify", middleware.RoleGuard("Dosen Wali", "Admin"), svc.BatchVerify)
+    api.Post("/batch/reject", middleware.RoleGuard("Dosen Wali", "Admin"), svc.BatchReject)
+    // Review queue & klaim => Dosen Wali & Admin
+    api.Get("/review-queue", middleware.RoleGuard("Dosen Wali", "Admin"), svc.ReviewQueue)
+    // Diskusi => semua yang boleh melihat prestasi (aturan sama dengan Detail)
+    api.Get("/comments/unread", svc.UnreadComments)
+    api.Get("/:refId/comments", svc.ListComments)
+    api.Post("/:refId/comments", svc.CreateComment)
+    api.Put("/:refId/comments/:commentId", svc.UpdateComment)
+    api.Delete("/:refId/comments/:commentId", svc.DeleteComment)
+    api.Post("/:refId/claim", middleware.RoleGuard("Dosen Wali", "Admin"), svc.ClaimReview)
+    api.Delete("/:refId/claim", middleware.RoleGuard("Dosen Wali", "Admin"), svc.ReleaseReview)
     // Verify / Reject => Dosen Wali & Admin
     api.Post("/:refId/verify", middleware.RoleGuard("Dosen Wali", "Admin"), svc.Verify)
     api.Post("/:refId/reject", middleware.RoleGuard("Dosen Wali", "Admin"), svc.Reject)
+    // Voting komite => anggota komite (Dosen Wali & Admin)
+    api.Post("/:refId/votes", middleware.RoleGuard("Dosen Wali", "Admin"), svc.Vote)
+    api.Get("/:refId/votes", svc.Votes)
+    // Konfirmasi keanggotaan tim => Mahasiswa anggota
+    api.Post("/:refId/team/confirm", middleware.RoleGuard("Mahasiswa"), svc.ConfirmTeam)
+    api.Post("/:refId/team/decline", middleware.RoleGuard("Mahasiswa"), svc.DeclineTeam)
+    // Banding atas penolakan => Mahasiswa pemilik
+    api.Post("/:refId/appeal", middleware.RoleGuard("Mahasiswa"), svc.Appeal)
+    api.Get("/:refId/appeal", svc.GetAppeal)
     // Everyone with token can read
     api.Get("/", svc.List)
     api.Get("/:refId", svc.Detail)
     api.Get("/:refId/history", svc.History)
+    api.Get("/:refId/readiness", svc.Readiness)
+    // Kode verifikasi publik => pemilik & reviewer (aturan sama dengan Detail)
+    api.Get("/:refId/verification-code", svc.VerificationCode)
+
+    // Unduhan lewat tautan bertanda tangan (tanpa JWT)
+    public := app.Group("/api/v1/public/attachments")
+    public.Get("/:token", svc.SignedAttachment)
+
+    // Verifikasi prestasi oleh pihak luar (tanpa JWT)
+    app.Get("/verify/:code", svc.PublicVerify)
+    app.Get("/verify/:code/qr", svc.VerificationQR)
 }
 // Dosen Wali
 func LecturerRouter(app *fiber.App, svc *service.LecturerService) {
@@ -66,6 +116,13 @@ func AdminAchievementRouter(app *fiber.App, svc *service.AchievementService) {
 		middleware.RoleGuard("Admin"),
 	)
 	api.Get("/", svc.AdminList)
+	api.Post("/:refId/assign", svc.AssignReview)
+	api.Get("/appeals", svc.ListAppeals)
+	api.Post("/:refId/appeal/decide", svc.DecideAppeal)
+	api.Post("/attachments/purge", svc.PurgeAttachmentsNow)
+	api.Get("/attachments/integrity", svc.ListIntegrityIssues)
+	api.Post("/attachments/integrity/check", svc.CheckIntegrityNow)
+	api.Post("/:refId/verification-code/revoke", svc.RevokeVerificationCode)
 }
 // REPORT ROUTER
 func ReportRouter(app *fiber.App, svc *service.ReportService) {
@@ -87,3 +144,113 @@ func StudentRouter(app *fiber.App, svc *service.StudentService) {
     api.Put("/:id/advisor", middleware.RoleGuard("Admin"), svc.AssignAdvisor)
 }
 
+
+// ACHIEVEMENT TYPES (metadata untuk semua role, kelola oleh Admin)
+func AchievementTypeRouter(app *fiber.App, svc *service.AchievementTypeService) {
+	meta := app.Group("/api/v1/achievement-types",
+		middleware.JWTMiddleware(),
+	)
+	meta.Get("/", svc.Metadata)
+
+	admin := app.Group("/api/v1/admin/achievement-types",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Admin"),
+	)
+	admin.Get("/", svc.ListTypes)
+	admin.Post("/", svc.CreateType)
+	admin.Put("/:code", svc.UpdateType)
+	admin.Delete("/:code", svc.DeleteType)
+	admin.Get("/:code/fields", svc.ListFields)
+	admin.Post("/:code/fields", svc.CreateField)
+	admin.Put("/:code/fields/:fieldId", svc.UpdateField)
+	admin.Delete("/:code/fields/:fieldId", svc.DeleteField)
+}
+
+// POINT RULES (Admin)
+func PointRuleRouter(app *fiber.App, svc *service.PointRuleService) {
+	api := app.Group("/api/v1/admin/point-rules",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Admin"),
+	)
+	api.Get("/", svc.List)
+	api.Post("/", svc.Create)
+	api.Get("/runs/:runId", svc.Run)
+	api.Get("/:id", svc.Detail)
+	api.Put("/:id", svc.Update)
+	api.Post("/:id/preview", svc.Preview)
+	api.Post("/:id/activate", svc.Activate)
+}
+
+// POINTS LEDGER (saldo per mahasiswa, adjustment oleh Admin)
+func PointsLedgerRouter(app *fiber.App, svc *service.PointsLedgerService) {
+	api := app.Group("/api/v1/points",
+		middleware.JWTMiddleware(),
+	)
+	api.Get("/students/:id", svc.StudentLedger)
+
+	admin := app.Group("/api/v1/admin/points",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Admin"),
+	)
+	admin.Get("/balances", svc.Balances)
+	admin.Post("/adjustments", svc.Adjust)
+	admin.Post("/recalculate/:refId", svc.Recalculate)
+}
+
+// SUBMISSION REQUIREMENTS (Admin)
+func SubmissionRequirementRouter(app *fiber.App, svc *service.SubmissionRequirementService) {
+	api := app.Group("/api/v1/admin/submission-requirements",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Admin"),
+	)
+	api.Get("/", svc.List)
+	api.Put("/:code", svc.Upsert)
+	api.Delete("/:code", svc.Delete)
+}
+
+// VERIFICATION SLA (Admin)
+func VerificationSLARouter(app *fiber.App, svc *service.VerificationSLAService) {
+	api := app.Group("/api/v1/admin/verification-sla",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Admin"),
+	)
+	api.Get("/overdue", svc.Overdue)
+	api.Post("/run", svc.RunNow)
+	api.Get("/:refId/events", svc.Events)
+}
+
+// ADVISOR DELEGATION (Dosen Wali & Admin)
+func AdvisorDelegationRouter(app *fiber.App, svc *service.AdvisorDelegationService) {
+	api := app.Group("/api/v1/delegations",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Dosen Wali", "Admin"),
+	)
+	api.Get("/", svc.List)
+	api.Post("/", svc.Create)
+	api.Delete("/:id", svc.Revoke)
+}
+
+// COMMITTEE (Admin)
+func CommitteeRouter(app *fiber.App, svc *service.CommitteeService) {
+	api := app.Group("/api/v1/admin/committees",
+		middleware.JWTMiddleware(),
+		middleware.RoleGuard("Admin"),
+	)
+	api.Get("/", svc.List)
+	api.Post("/", svc.Create)
+	api.Put("/:id", svc.Update)
+	api.Delete("/:id", svc.Delete)
+}
+
+// ORGANIZER VERIFICATION: permintaan oleh reviewer, jawaban lewat tautan publik (tanpa JWT)
+func OrganizerVerificationRouter(app *fiber.App, svc *service.OrganizerVerificationService) {
+	// guard per route: middleware group berlaku untuk seluruh prefix /api/v1/achievements
+	api := app.Group("/api/v1/achievements")
+	reviewer := []fiber.Handler{middleware.JWTMiddleware(), middleware.RoleGuard("Dosen Wali", "Admin")}
+	api.Post("/:refId/organizer-verifications", append(reviewer, svc.Request)...)
+	api.Get("/:refId/organizer-verifications", append(reviewer, svc.List)...)
+
+	public := app.Group("/api/v1/public/organizer-verifications")
+	public.Get("/:token", svc.Show)
+	public.Post("/:token", svc.Respond)
+}
@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const defaultChunkSize = 64 << 10
+
+// Clamd berbicara dengan daemon ClamAV (clamd) memakai perintah INSTREAM:
+// isi file dikirim dalam potongan [panjang uint32 big-endian][data], diakhiri
+// potongan berpanjang 0. Balasan: "stream: OK" atau "stream: <nama> FOUND".
+type Clamd struct {
+	Network   string // "tcp" atau "unix"
+	Address   string
+	Timeout   time.Duration
+	ChunkSize int
+}
+
+func (c *Clamd) dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(c.timeout())
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+	return conn, nil
+}
+
+func (c *Clamd) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 60 * time.Second
+	}
+	return c.Timeout
+}
+
+// Ping memeriksa daemon hidup ("PONG").
+func (c *Clamd) Ping(ctx context.Context) error {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zPING\x00")); err != nil {
+		return err
+	}
+	reply, err := readReply(conn)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("clamd: balasan PING tidak dikenal: %q", reply)
+	}
+	return nil
+}
+
+func (c *Clamd) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriter(conn)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return Result{}, err
+	}
+
+	size := c.ChunkSize
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	buf := make([]byte, size)
+	var header [4]byte
+	for {
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(header[:], uint32(n))
+			if _, err := w.Write(header[:]); err != nil {
+				return Result{}, err
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return Result{}, err
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return Result{}, rerr
+		}
+	}
+	binary.BigEndian.PutUint32(header[:], 0)
+	if _, err := w.Write(header[:]); err != nil {
+		return Result{}, err
+	}
+	if err := w.Flush(); err != nil {
+		return Result{}, err
+	}
+
+	reply, err := readReply(conn)
+	if err != nil {
+		return Result{}, err
+	}
+	return parseReply(reply)
+}
+
+// readReply membaca balasan sampai \0 (mode "z") atau koneksi ditutup.
+func readReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if err != nil && !(errors.Is(err, io.EOF) && len(reply) > 0) {
+		return "", err
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+func parseReply(reply string) (Result, error) {
+	// "stream: OK", "stream: Eicar-Signature FOUND", "... ERROR"
+	body := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case body == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(body, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(body, " FOUND")}, nil
+	default:
+		return Result{}, fmt.Errorf("clamd: %s", reply)
+	}
+}
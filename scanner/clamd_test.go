@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd: pengganti clamd untuk test. Mendukung zPING dan zINSTREAM,
+// menandai isi yang mengandung string EICAR.
+type fakeClamd struct {
+	ln       net.Listener
+	maxBytes int
+	received [][]byte
+}
+
+func startFakeClamd(t *testing.T) *fakeClamd {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeClamd{ln: ln, maxBytes: 1 << 20}
+	go f.serve()
+	t.Cleanup(func() { ln.Close() })
+	return f
+}
+
+func (f *fakeClamd) serve() {
+	for {
+		conn, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		f.handle(conn)
+	}
+}
+
+func (f *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	cmd, err := r.ReadString(0)
+	if err != nil {
+		return
+	}
+
+	switch cmd {
+	case "zPING\x00":
+		conn.Write([]byte("PONG\x00"))
+	case "zINSTREAM\x00":
+		var data bytes.Buffer
+		var header [4]byte
+		for {
+			if _, err := io.ReadFull(r, header[:]); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(header[:])
+			if n == 0 {
+				break
+			}
+			if data.Len()+int(n) > f.maxBytes {
+				conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+				return
+			}
+			io.CopyN(&data, r, int64(n))
+		}
+		f.received = append(f.received, data.Bytes())
+		if bytes.Contains(data.Bytes(), []byte(eicar)) {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		} else {
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	default:
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+	}
+}
+
+func TestClamd_ScanAgainstFakeDaemon(t *testing.T) {
+	fake := startFakeClamd(t)
+	clamd := &Clamd{Network: "tcp", Address: fake.ln.Addr().String(), Timeout: 5 * time.Second, ChunkSize: 7}
+	ctx := context.Background()
+
+	assert.NoError(t, clamd.Ping(ctx))
+
+	res, err := clamd.Scan(ctx, strings.NewReader("%PDF-1.4 sertifikat lomba"))
+	assert.NoError(t, err)
+	assert.False(t, res.Infected)
+	// dikirim dalam beberapa potongan, diterima utuh
+	assert.Equal(t, "%PDF-1.4 sertifikat lomba", string(fake.received[0]))
+
+	res, err = clamd.Scan(ctx, strings.NewReader("%PDF-1.4 "+eicar))
+	assert.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", res.Signature)
+
+	fake.maxBytes = 4
+	_, err = clamd.Scan(ctx, strings.NewReader("terlalu besar"))
+	assert.ErrorContains(t, err, "size limit exceeded")
+}
+
+func TestClamd_Unreachable(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err := (&Clamd{Network: "tcp", Address: addr}).Scan(context.Background(), strings.NewReader("x"))
+	assert.Error(t, err)
+}
+
+func TestParseReply(t *testing.T) {
+	res, err := parseReply("stream: OK")
+	assert.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	res, err = parseReply("stream: Win.Test.EICAR_HDB-1 FOUND")
+	assert.NoError(t, err)
+	assert.Equal(t, Result{Infected: true, Signature: "Win.Test.EICAR_HDB-1"}, res)
+
+	_, err = parseReply("stream: Can't allocate memory ERROR")
+	assert.Error(t, err)
+}
+
+func TestParseAddress(t *testing.T) {
+	n, a := parseAddress("unix:///var/run/clamav/clamd.ctl")
+	assert.Equal(t, "unix", n)
+	assert.Equal(t, "/var/run/clamav/clamd.ctl", a)
+
+	n, a = parseAddress("tcp://clamav:3310")
+	assert.Equal(t, "tcp", n)
+	assert.Equal(t, "clamav:3310", a)
+
+	n, a = parseAddress("127.0.0.1:3310")
+	assert.Equal(t, "tcp", n)
+	assert.Equal(t, "127.0.0.1:3310", a)
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result: hasil pemindaian satu file.
+type Result struct {
+	Infected  bool
+	Signature string // nama malware bila Infected
+}
+
+// Scanner memindai isi file lampiran sebelum boleh dibuka reviewer.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// FromEnv memilih scanner dari environment:
+//   - SCANNER=clamd: CLAMD_ADDRESS (default tcp://127.0.0.1:3310, atau
+//     unix:///var/run/clamav/clamd.ctl), CLAMD_TIMEOUT_SECONDS (default 60)
+//   - kosong / none: pemindaian dimatikan (nil)
+func FromEnv() Scanner {
+	switch kind := os.Getenv("SCANNER"); kind {
+	case "clamd":
+		network, address := parseAddress(envOr("CLAMD_ADDRESS", "tcp://127.0.0.1:3310"))
+		timeout := 60 * time.Second
+		if n, err := strconv.Atoi(os.Getenv("CLAMD_TIMEOUT_SECONDS")); err == nil && n > 0 {
+			timeout = time.Duration(n) * time.Second
+		}
+		log.Printf("scanner: clamd (%s %s)", network, address)
+		return &Clamd{Network: network, Address: address, Timeout: timeout}
+	case "", "none":
+		log.Println("scanner: dimatikan, lampiran tidak dipindai")
+		return nil
+	default:
+		log.Printf("scanner: SCANNER=%q tidak dikenal, pemindaian dimatikan", kind)
+		return nil
+	}
+}
+
+// parseAddress: "unix:///path" → unix socket, "tcp://host:port" atau
+// "host:port" → tcp.
+func parseAddress(addr string) (network, address string) {
+	if rest, ok := strings.CutPrefix(addr, "unix://"); ok {
+		return "unix", rest
+	}
+	return "tcp", strings.TrimPrefix(addr, "tcp://")
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job dijalankan berkala oleh Scheduler. Error hanya dicatat di log.
+type Job func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      Job
+}
+
+// Scheduler menjalankan job berkala di dalam proses API. Setiap job punya
+// goroutine sendiri, jadi satu job tidak pernah berjalan tumpang tindih.
+type Scheduler struct {
+	jobs   []job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every mendaftarkan job; harus dipanggil sebelum Start.
+func (s *Scheduler) Every(name string, interval time.Duration, run Job) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		if j.interval <= 0 {
+			log.Printf("scheduler: job %s dilewati, interval tidak valid", j.name)
+			continue
+		}
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop menghentikan semua job dan menunggu run yang sedang berjalan selesai.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runJob(ctx, j)
+		}
+	}
+}
+
+func runJob(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("scheduler: job %s panic: %v", j.name, r)
+		}
+	}()
+
+	if err := j.run(ctx); err != nil {
+		log.Printf("scheduler: job %s gagal: %v", j.name, err)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+// ObjectInfo: metadata objek yang tersimpan.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage menyimpan file lampiran berdasarkan key (mis. "<mongoId>/<file>"),
+// bukan path filesystem, sehingga beberapa replika API bisa berbagi backend.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	// GetRange membaca length byte mulai offset; ObjectInfo.Size tetap
+	// ukuran objek penuh.
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey: key relatif dengan segmen dipisah "/", tanpa "." / ".." dan
+// tanpa segmen kosong.
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// FromEnv memilih backend dari environment:
+//   - STORAGE_BACKEND=local (default): STORAGE_LOCAL_DIR (default ./uploads)
+//   - STORAGE_BACKEND=s3: S3_ENDPOINT, S3_REGION (default us-east-1), S3_BUCKET,
+//     S3_ACCESS_KEY, S3_SECRET_KEY, S3_PATH_STYLE (default true, untuk MinIO)
+func FromEnv() (Storage, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := envOr("STORAGE_LOCAL_DIR", "uploads")
+		log.Printf("storage: local (%s)", dir)
+		return NewLocal(dir), nil
+	case "s3":
+		cfg := S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    envOr("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") != "false",
+		}
+		if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+			return nil, errors.New("storage s3: S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY dan S3_SECRET_KEY wajib diisi")
+		}
+		log.Printf("storage: s3 (%s, bucket %s)", cfg.Endpoint, cfg.Bucket)
+		return NewS3(cfg), nil
+	default:
+		return nil, errors.New("storage: STORAGE_BACKEND tidak dikenal: " + backend)
+	}
+}
+
+// limitedReadCloser: Reader terbatas yang tetap menutup sumbernya.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func limitReadCloser(rc io.ReadCloser, n int64) io.ReadCloser {
+	return limitedReadCloser{io.LimitReader(rc, n), rc}
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}